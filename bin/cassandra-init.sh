@@ -3,4 +3,6 @@
 cqlsh -f /0chain/sql/zerochain_keyspace.sql cassandra
 cqlsh -f /0chain/sql/magic_block_map.sql cassandra
 cqlsh -f /0chain/sql/txn_summary.sql cassandra
+cqlsh -f /0chain/sql/txn_event.sql cassandra
+cqlsh -f /0chain/sql/client_txn.sql cassandra
 echo "cassandra initialized"
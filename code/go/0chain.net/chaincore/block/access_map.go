@@ -0,0 +1,135 @@
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+)
+
+// AccessMapVersion - the version of the access map encoding used for the
+// blocks generated by this node, a block without an access map has version 0
+// and its hash doesn't include the access map
+const AccessMapVersion = 1
+
+var (
+	// ErrAccessMapVersion - the block access map is encoded with an unknown version
+	ErrAccessMapVersion = common.NewError("access_map_version", "unsupported access map version")
+
+	// ErrAccessMapUnknownTxn - the block access map has a list for a transaction not in the block
+	ErrAccessMapUnknownTxn = common.NewError("access_map_unknown_txn", "access list of a transaction not in the block")
+
+	// ErrAccessListMissing - a transaction of the block has no access list
+	ErrAccessListMissing = common.NewError("access_list_missing", "transaction without an access list")
+)
+
+// EncodeAccessMap - the deterministic encoding of the access lists of the
+// transactions in the block order. The keys of a list are sorted and
+// deduplicated, so the lists with the same sets of keys encode the same.
+func EncodeAccessMap(version int, txns []*transaction.Transaction,
+	accessMap map[datastore.Key]*AccessList) ([]byte, error) {
+
+	if version != AccessMapVersion {
+		return nil, ErrAccessMapVersion
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(byte(version))
+	writeUvarint(&buf, uint64(len(txns)))
+	for _, txn := range txns {
+		writeString(&buf, txn.GetKey())
+		al := accessMap[txn.GetKey()]
+		if al == nil {
+			buf.WriteByte(0)
+			continue
+		}
+		buf.WriteByte(1)
+		writeKeys(&buf, al.Reads)
+		writeKeys(&buf, al.Writes)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	writeUvarint(buf, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+func writeKeys(buf *bytes.Buffer, keys []datastore.Key) {
+	sorted := sortedKeys(keys)
+	writeUvarint(buf, uint64(len(sorted)))
+	for _, k := range sorted {
+		writeString(buf, k)
+	}
+}
+
+// sortedKeys - a sorted copy of the keys without duplicates
+func sortedKeys(keys []datastore.Key) []datastore.Key {
+	sorted := make([]datastore.Key, len(keys))
+	copy(sorted, keys)
+	sort.Strings(sorted)
+
+	var n int
+	for i, k := range sorted {
+		if i == 0 || k != sorted[n-1] {
+			sorted[n] = k
+			n++
+		}
+	}
+	return sorted[:n]
+}
+
+// GetAccessMapHash - the hash of the encoded access map of the block, the
+// hash of nothing if the version of the block access map is not supported
+func (b *Block) GetAccessMapHash() string {
+	data, err := EncodeAccessMap(b.AccessMapVersion, b.Txns, b.AccessMap)
+	if err != nil {
+		return encryption.EmptyHash
+	}
+	return encryption.Hash(data)
+}
+
+// ValidateAccessMap - check the versioned access map covers exactly the
+// transactions of the block. Without strict checking the transactions may
+// have no access list.
+func (b *Block) ValidateAccessMap(strict bool) error {
+	if b.AccessMapVersion == 0 {
+		// an access map without a version, as of the blocks generated before
+		// the versions, is not part of the block hash and is ignored, the
+		// transactions are not scheduled with it
+		if strict && len(b.Txns) > 0 {
+			return ErrAccessListMissing
+		}
+		return nil
+	}
+	if b.AccessMapVersion != AccessMapVersion {
+		return ErrAccessMapVersion
+	}
+
+	var listed int
+	for _, txn := range b.Txns {
+		if al, ok := b.AccessMap[txn.GetKey()]; ok {
+			listed++
+			if al != nil {
+				continue
+			}
+		}
+		if strict {
+			return common.NewErrorf(ErrAccessListMissing.Code,
+				"%s: %s", ErrAccessListMissing.Msg, txn.GetKey())
+		}
+	}
+	if listed != len(b.AccessMap) {
+		return ErrAccessMapUnknownTxn
+	}
+	return nil
+}
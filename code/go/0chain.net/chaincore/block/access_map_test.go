@@ -0,0 +1,275 @@
+package block
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+)
+
+func TestEncodeAccessMap(t *testing.T) {
+	txs, accessMap := testAccessMap()
+
+	data, err := EncodeAccessMap(AccessMapVersion, txs, accessMap)
+	require.NoError(t, err)
+	require.Equal(t, byte(AccessMapVersion), data[0])
+
+	// the same sets of keys in another order and with duplicates
+	shuffled := make(map[datastore.Key]*AccessList, len(accessMap))
+	for key, al := range accessMap {
+		clone := &AccessList{}
+		for i := len(al.Reads) - 1; i >= 0; i-- {
+			clone.Reads = append(clone.Reads, al.Reads[i], al.Reads[i])
+		}
+		for i := len(al.Writes) - 1; i >= 0; i-- {
+			clone.Writes = append(clone.Writes, al.Writes[i])
+		}
+		shuffled[key] = clone
+	}
+	got, err := EncodeAccessMap(AccessMapVersion, txs, shuffled)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	// a read moved to the writes
+	moved := make(map[datastore.Key]*AccessList, len(accessMap))
+	for key, al := range accessMap {
+		moved[key] = al.Clone()
+	}
+	moved["0"] = &AccessList{Writes: []datastore.Key{"a6"}}
+	got, err = EncodeAccessMap(AccessMapVersion, txs, moved)
+	require.NoError(t, err)
+	assert.NotEqual(t, data, got)
+
+	// the transactions in another order
+	reordered := append([]*transaction.Transaction{txs[1], txs[0]}, txs[2:]...)
+	got, err = EncodeAccessMap(AccessMapVersion, reordered, accessMap)
+	require.NoError(t, err)
+	assert.NotEqual(t, data, got)
+
+	_, err = EncodeAccessMap(AccessMapVersion+1, txs, accessMap)
+	assert.Equal(t, ErrAccessMapVersion, err)
+}
+
+func TestBlock_AccessMapHash(t *testing.T) {
+	txs, accessMap := testAccessMap()
+	b := NewBlock("", 1)
+	b.Txns = txs
+	b.AccessMap = accessMap
+
+	// the access map of a block without a version is not hashed
+	b.HashBlock()
+	unversioned := b.Hash
+	b.AccessMap = nil
+	b.HashBlock()
+	require.Equal(t, unversioned, b.Hash)
+
+	b.AccessMap = accessMap
+	b.AccessMapVersion = AccessMapVersion
+	b.HashBlock()
+	versioned := b.Hash
+	require.NotEqual(t, unversioned, versioned)
+
+	b.AccessMap = b.UnverifiedBlockBody.Clone().AccessMap
+	b.HashBlock()
+	require.Equal(t, versioned, b.Hash, "clone")
+
+	b.AccessMap["14"] = &AccessList{Writes: []datastore.Key{"a14"}}
+	b.HashBlock()
+	require.NotEqual(t, versioned, b.Hash, "changed list")
+
+	delete(b.AccessMap, "14")
+	b.HashBlock()
+	require.NotEqual(t, versioned, b.Hash, "missing list")
+}
+
+func TestBlock_ValidateAccessMap(t *testing.T) {
+	txs, accessMap := testAccessMap()
+
+	without := func(key datastore.Key) map[datastore.Key]*AccessList {
+		m := make(map[datastore.Key]*AccessList, len(accessMap))
+		for k, al := range accessMap {
+			if k != key {
+				m[k] = al
+			}
+		}
+		return m
+	}
+	with := func(key datastore.Key, al *AccessList) map[datastore.Key]*AccessList {
+		m := without(key)
+		m[key] = al
+		return m
+	}
+
+	tests := []struct {
+		name      string
+		txns      []*transaction.Transaction
+		version   int
+		accessMap map[datastore.Key]*AccessList
+		strict    bool
+		wantErr   error
+	}{
+		{
+			name:    "empty block",
+			version: AccessMapVersion,
+			strict:  true,
+		},
+		{
+			name:      "complete",
+			txns:      txs,
+			version:   AccessMapVersion,
+			accessMap: accessMap,
+			strict:    true,
+		},
+		{
+			name:      "missing list",
+			txns:      txs,
+			version:   AccessMapVersion,
+			accessMap: without("3"),
+		},
+		{
+			name:      "missing list strict",
+			txns:      txs,
+			version:   AccessMapVersion,
+			accessMap: without("3"),
+			strict:    true,
+			wantErr:   ErrAccessListMissing,
+		},
+		{
+			name:      "nil list strict",
+			txns:      txs,
+			version:   AccessMapVersion,
+			accessMap: with("3", nil),
+			strict:    true,
+			wantErr:   ErrAccessListMissing,
+		},
+		{
+			name:      "list of unknown transaction",
+			txns:      txs,
+			version:   AccessMapVersion,
+			accessMap: with("15", &AccessList{}),
+			wantErr:   ErrAccessMapUnknownTxn,
+		},
+		{
+			name: "no access map",
+			txns: txs,
+		},
+		{
+			name:    "no access map strict",
+			txns:    txs,
+			strict:  true,
+			wantErr: ErrAccessListMissing,
+		},
+		{
+			name:      "unversioned access map ignored",
+			txns:      txs,
+			accessMap: accessMap,
+		},
+		{
+			name:      "unversioned access map strict",
+			txns:      txs,
+			accessMap: accessMap,
+			strict:    true,
+			wantErr:   ErrAccessListMissing,
+		},
+		{
+			name:      "unknown version",
+			txns:      txs,
+			version:   AccessMapVersion + 1,
+			accessMap: accessMap,
+			wantErr:   ErrAccessMapVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBlock("", 1)
+			b.Txns = tt.txns
+			b.AccessMapVersion = tt.version
+			b.AccessMap = tt.accessMap
+
+			err := b.ValidateAccessMap(tt.strict)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, errors.Is(err, tt.wantErr), "got %v, want %v", err, tt.wantErr)
+		})
+	}
+}
+
+func TestBlock_checkTxnState(t *testing.T) {
+	txn := &transaction.Transaction{HashIDField: datastore.HashIDField{Hash: "txn"}}
+	keys := func(keys ...datastore.Key) map[datastore.Key]bool {
+		set := make(map[datastore.Key]bool, len(keys))
+		for _, k := range keys {
+			set[k] = true
+		}
+		return set
+	}
+
+	tests := []struct {
+		name    string
+		al      *AccessList
+		rset    map[datastore.Key]bool
+		wset    map[datastore.Key]bool
+		strict  bool
+		wantErr bool
+	}{
+		{
+			name: "exact",
+			al:   &AccessList{Reads: []datastore.Key{"a"}, Writes: []datastore.Key{"b"}},
+			rset: keys("a"), wset: keys("b"),
+			strict: true,
+		},
+		{
+			name: "over-broad",
+			al:   &AccessList{Reads: []datastore.Key{"a", "c"}, Writes: []datastore.Key{"b"}},
+			rset: keys("a"), wset: keys("b"),
+		},
+		{
+			name: "over-broad strict",
+			al:   &AccessList{Reads: []datastore.Key{"a"}, Writes: []datastore.Key{"b", "c"}},
+			rset: keys("a"), wset: keys("b"),
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name: "under-broad",
+			al:   &AccessList{Reads: []datastore.Key{"a"}},
+			rset: keys("a"), wset: keys("b"),
+			wantErr: true,
+		},
+		{
+			name: "missing",
+			rset: keys("a"), wset: keys("b"),
+		},
+		{
+			name: "missing strict",
+			rset: keys("a"), wset: keys("b"),
+			strict:  true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBlock("", 2)
+			b.PrevBlock = NewBlock("", 1)
+			b.AccessMap = map[datastore.Key]*AccessList{}
+			if tt.al != nil {
+				b.AccessMap[txn.GetKey()] = tt.al
+			}
+
+			ts, err := b.checkTxnState(txn, NewTxnState(txn, nil, tt.rset, tt.wset), nil, tt.strict)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.EqualValues(t, StateFailed, b.GetStateStatus())
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, ts)
+		})
+	}
+}
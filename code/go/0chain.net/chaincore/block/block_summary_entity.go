@@ -26,6 +26,18 @@ type BlockSummary struct {
 	ReceiptMerkleTreeRoot string        `json:"receipt_merkle_tree_root"`
 	NumTxns               int           `json:"num_txns"`
 	*MagicBlock           `json:"maigc_block,omitempty"`
+
+	// the rest of the block fields covered by the block hash, so the hash
+	// can be checked with the summary only
+	PrevHash         string `json:"prev_hash,omitempty"`
+	HeaderVersion    int    `json:"header_version,omitempty"`
+	ReceiptsVersion  int    `json:"receipts_version,omitempty"`
+	AccessMapVersion int    `json:"accesses_version,omitempty"`
+	AccessMapHash    string `json:"accesses_hash,omitempty"`
+
+	// the magic block the block refers to, not covered by the block hash
+	LatestFinalizedMagicBlockHash  string `json:"latest_finalized_magic_block_hash,omitempty"`
+	LatestFinalizedMagicBlockRound int64  `json:"latest_finalized_magic_block_round,omitempty"`
 }
 
 var blockSummaryEntityMetadata *datastore.EntityMetadataImpl
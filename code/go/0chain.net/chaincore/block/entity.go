@@ -7,7 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"strconv"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -41,6 +41,12 @@ var (
 
 	//StateChangeSizeMetric - a metric that tracks how many state nodes are changing with each block
 	StateChangeSizeMetric metrics.Histogram
+
+	//TxnCriticalPathMetric - a metric that tracks the longest transaction dependency chain of each block
+	TxnCriticalPathMetric metrics.Histogram
+
+	//TxnParallelismMetric - a metric that tracks how many transactions of a block are executed at a time on average
+	TxnParallelismMetric metrics.GaugeFloat64
 )
 
 var (
@@ -85,6 +91,8 @@ const (
 func init() {
 	StateSaveTimer = metrics.GetOrRegisterTimer("state_save_timer", nil)
 	StateChangeSizeMetric = metrics.NewHistogram(metrics.NewUniformSample(1024))
+	TxnCriticalPathMetric = metrics.GetOrRegisterHistogram("txn_critical_path", nil, metrics.NewUniformSample(1024))
+	TxnParallelismMetric = metrics.GetOrRegisterGaugeFloat64("txn_parallelism", nil)
 }
 
 // UnverifiedBlockBody - used to compute the signature
@@ -104,8 +112,13 @@ type UnverifiedBlockBody struct {
 	RoundTimeoutCount int           `json:"round_timeout_count"`
 
 	ClientStateHash util.Key `json:"state_hash"`
+	HeaderVersion   int      `json:"header_version,omitempty"`
+
+	AccessMapVersion int                           `json:"accesses_version,omitempty"`
+	AccessMap        map[datastore.Key]*AccessList `json:"accesses,omitempty"`
 
-	AccessMap map[datastore.Key]*AccessList `json:"accesses,omitempty"`
+	ReceiptsVersion int                       `json:"receipts_version,omitempty"`
+	Receipts        []*transaction.TxnReceipt `json:"receipts,omitempty"`
 	// The entire transaction payload to represent full block
 	Txns []*transaction.Transaction `json:"transactions,omitempty"`
 }
@@ -124,6 +137,8 @@ func NewAccessList(rset, wset map[datastore.Key]bool) *AccessList {
 		w = append(w, wkey)
 	}
 
+	sort.Strings(r)
+	sort.Strings(w)
 	return &AccessList{
 		Reads:  r,
 		Writes: w,
@@ -148,6 +163,12 @@ func (al *AccessList) Includes(rset, wset map[datastore.Key]bool) bool {
 	return true
 }
 
+// Matches - check the access list has exactly the given keys, neither more
+// nor fewer
+func (al *AccessList) Matches(rset, wset map[datastore.Key]bool) bool {
+	return al.Includes(rset, wset) && NewAccessList(rset, wset).Includes(al.Rset(), al.Wset())
+}
+
 func (al *AccessList) Rset() (rset map[datastore.Key]bool) {
 	rset = make(map[datastore.Key]bool)
 	for _, r := range al.Reads {
@@ -167,8 +188,8 @@ func (al *AccessList) Wset() (wset map[datastore.Key]bool) {
 
 func (al *AccessList) Clone() *AccessList {
 	clone := &AccessList{
-		Reads:  make([]datastore.Key, len(al.Reads)),
-		Writes: make([]datastore.Key, len(al.Writes)),
+		Reads:  make([]datastore.Key, 0, len(al.Reads)),
+		Writes: make([]datastore.Key, 0, len(al.Writes)),
 	}
 	for _, r := range al.Reads {
 		clone.Reads = append(clone.Reads, r)
@@ -209,6 +230,10 @@ func (u *UnverifiedBlockBody) Clone() *UnverifiedBlockBody {
 			cloneU.AccessMap[tx_key] = nil
 		}
 	}
+	if u.Receipts != nil {
+		cloneU.Receipts = make([]*transaction.TxnReceipt, len(u.Receipts))
+		copy(cloneU.Receipts, u.Receipts)
+	}
 
 	return &cloneU
 }
@@ -237,6 +262,8 @@ type Block struct {
 	isNotarized           bool
 	ticketsMutex          sync.RWMutex
 	verificationStatus    int
+	receipts              map[string]*transaction.TxnReceipt
+	receiptsMutex         sync.Mutex
 	RunningTxnCount       int64           `json:"running_txn_count"`
 	UniqueBlockExtensions map[string]bool `json:"-"`
 	*MagicBlock           `json:"magic_block,omitempty"`
@@ -333,6 +360,16 @@ func (b *Block) Validate(ctx context.Context) error {
 	}
 	b.mutexTxns.RUnlock()
 
+	if b.AccessMapVersion > AccessMapVersion {
+		return ErrAccessMapVersion
+	}
+	if b.HeaderVersion > HeaderVersion {
+		return ErrHeaderVersion
+	}
+	if err = b.validateReceipts(); err != nil {
+		return err
+	}
+
 	hash := b.ComputeHash()
 	if b.Hash != hash {
 		return common.NewError("incorrect_block_hash", fmt.Sprintf("computed block hash doesn't match with the hash of the block: %v: %v: %v", b.Hash, hash, b.getHashData()))
@@ -419,6 +456,7 @@ func (b *Block) SetStateDB(prevBlock *Block, stateDB util.NodeDB) {
 		zap.String("prev_block", prevBlock.Hash),
 		zap.String("root", util.ToHex(rootHash)))
 	b.CreateState(pndb, rootHash)
+	b.resetReceipts()
 }
 
 // InitStateDB - initialize the block's state from the db
@@ -510,18 +548,9 @@ func (b *Block) GetMerkleTree() *util.MerkleTree {
 }
 
 func (b *Block) getHashData() string {
-	mt := b.GetMerkleTree()
-	merkleRoot := mt.GetRoot()
-	rmt := b.GetReceiptsMerkleTree()
-	rMerkleRoot := rmt.GetRoot()
-	hashData := b.MinerID + ":" + b.PrevHash + ":" + common.TimeToString(b.CreationDate) + ":" + strconv.FormatInt(b.Round, 10) + ":" + strconv.FormatInt(b.GetRoundRandomSeed(), 10) + ":" + merkleRoot + ":" + rMerkleRoot
-	if b.MagicBlock != nil {
-		if b.MagicBlock.Hash == "" {
-			b.MagicBlock.Hash = b.MagicBlock.GetHash()
-		}
-		hashData += ":" + b.MagicBlock.Hash
-	}
-	return hashData
+	var bs BlockSummary
+	b.setSummary(&bs)
+	return bs.getHashData()
 }
 
 /*ComputeHash - compute the hash of the block */
@@ -557,6 +586,12 @@ func (b *Block) HasTransaction(hash string) bool {
 /*GetSummary - get the block summary of this block */
 func (b *Block) GetSummary() *BlockSummary {
 	bs := datastore.GetEntityMetadata("block_summary").Instance().(*BlockSummary)
+	b.setSummary(bs)
+	return bs
+}
+
+// setSummary - set the fields of the summary of the block
+func (b *Block) setSummary(bs *BlockSummary) {
 	bs.Version = b.Version
 	bs.Hash = b.Hash
 	bs.MinerID = b.MinerID
@@ -568,7 +603,15 @@ func (b *Block) GetSummary() *BlockSummary {
 	bs.ReceiptMerkleTreeRoot = b.GetReceiptsMerkleTree().GetRoot()
 	bs.NumTxns = len(b.Txns)
 	bs.MagicBlock = b.MagicBlock
-	return bs
+	bs.PrevHash = b.PrevHash
+	bs.LatestFinalizedMagicBlockHash = b.LatestFinalizedMagicBlockHash
+	bs.LatestFinalizedMagicBlockRound = b.LatestFinalizedMagicBlockRound
+	bs.HeaderVersion = b.HeaderVersion
+	bs.ReceiptsVersion = b.ReceiptsVersion
+	bs.AccessMapVersion = b.AccessMapVersion
+	if b.AccessMapVersion > 0 {
+		bs.AccessMapHash = b.GetAccessMapHash()
+	}
 }
 
 /*Weight - weight of the block */
@@ -651,17 +694,6 @@ func (b *Block) SetStateStatus(status int8) {
 	b.stateStatus = status
 }
 
-/*GetReceiptsMerkleTree - return the merkle tree of this block using the transactions as leaf nodes */
-func (b *Block) GetReceiptsMerkleTree() *util.MerkleTree {
-	var hashables = make([]util.Hashable, len(b.Txns))
-	for idx, txn := range b.Txns {
-		hashables[idx] = transaction.NewTransactionReceipt(txn)
-	}
-	var mt util.MerkleTree
-	mt.ComputeTree(hashables)
-	return &mt
-}
-
 //GetTransaction - get the transaction from the block
 func (b *Block) GetTransaction(hash string) *transaction.Transaction {
 	for _, txn := range b.Txns {
@@ -818,7 +850,38 @@ type Chainer interface {
 	GetBlockStateChange(b *Block) error
 	ComputeState(ctx context.Context, pb *Block) error
 	GetStateDB() util.NodeDB
-	UpdateState(ctx context.Context, b *Block, txn *transaction.Transaction) (rset, wset map[datastore.Key]bool, err error)
+	ExecuteTransaction(ctx context.Context, b *Block, txn *transaction.Transaction) (*TxnState, error)
+	MergeTransaction(b *Block, ts *TxnState) error
+	GetExecutor() Executor
+	IsAccessMapStrict() bool
+}
+
+// TxnState - the result of executing a transaction against its own
+// copy-on-write view of the block state, pending merge into the block state.
+type TxnState struct {
+	Txn   *transaction.Transaction
+	State util.MerklePatriciaTrieI
+	Rset  map[datastore.Key]bool
+	Wset  map[datastore.Key]bool
+	// added to the block when merged
+	Receipt *transaction.TxnReceipt
+}
+
+// NewTxnState - create a new transaction state
+func NewTxnState(txn *transaction.Transaction, s util.MerklePatriciaTrieI,
+	rset, wset map[datastore.Key]bool) *TxnState {
+
+	return &TxnState{Txn: txn, State: s, Rset: rset, Wset: wset}
+}
+
+// WriteKeys - the keys written by the transaction in a deterministic order
+func (ts *TxnState) WriteKeys() []datastore.Key {
+	keys := make([]datastore.Key, 0, len(ts.Wset))
+	for k := range ts.Wset {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // ComputeState computes block client state
@@ -916,10 +979,18 @@ func (b *Block) ComputeState(ctx context.Context, c Chainer) error {
 	}
 	b.SetStateDB(pb, c.GetStateDB())
 
+	if err := b.ValidateAccessMap(c.IsAccessMapStrict()); err != nil {
+		b.SetStateStatus(StateFailed)
+		logging.Logger.Error("compute state - invalid access map",
+			zap.Int64("round", b.Round),
+			zap.String("block", b.Hash),
+			zap.Error(err))
+		return err
+	}
+
 	beginState := b.ClientState.GetRoot()
 
-	batcher := &ContentionFreeBatcher{8}
-	err := b.applyTransactions(ctx, c, batcher)
+	err := c.GetExecutor().Apply(ctx, b, c)
 	if err != nil {
 		return err
 	}
@@ -942,6 +1013,14 @@ func (b *Block) ComputeState(ctx context.Context, c Chainer) error {
 			zap.String("computed_state_hash", util.ToHex(b.ClientState.GetRoot())))
 		return ErrStateMismatch
 	}
+	if err := b.checkComputedReceipts(); err != nil {
+		b.SetStateStatus(StateFailed)
+		logging.Logger.Error("compute state - receipts mismatch",
+			zap.Int64("round", b.Round), zap.String("block", b.Hash),
+			zap.Int("block_size", len(b.Txns)),
+			zap.Error(err))
+		return err
+	}
 	StateSanityCheck(ctx, b)
 	b.SetStateStatus(StateSuccessful)
 	logging.Logger.Info("compute state successful", zap.Int64("round", b.Round),
@@ -952,61 +1031,19 @@ func (b *Block) ComputeState(ctx context.Context, c Chainer) error {
 	return nil
 }
 
-func (b *Block) applyTransactions(ctx context.Context, c Chainer, batcher Batcher) error {
-	batches := batcher.Batch(b)
-
-	var wg sync.WaitGroup
-	errChan := make(chan error, 1)
-	finishChan := make(chan bool, 1)
-
-	for i, batch := range batches {
-		logging.Logger.Debug("apply transactions - running batch",
-			zap.Int("batch", i),
-			zap.Int("tx_count", len(batch)),
-		)
-
-		for _, txn := range batch {
-			ctx, cancel := context.WithCancel(ctx)
-			txn := txn
-			wg.Add(1)
-
-			go func() {
-				defer wg.Done()
-				if err := b.applyTransaction(txn, c, ctx); err != nil {
-					errChan <- err
-				}
-			}()
-
-			go func() {
-				wg.Wait()
-				finishChan <- true
-			}()
-
-			select {
-			case err := <-errChan:
-				logging.Logger.Error("apply transactions - batch failed with error",
-					zap.Error(err))
-				cancel()
-				return err
-			case <-ctx.Done():
-				cancel()
-				return errors.New("batch stopped due to context.Done()")
-			case <-finishChan:
-				logging.Logger.Debug("apply transactions - batch processed successfully",
-					zap.Int("batch", i))
-			}
-
-		}
-	}
-
-	return nil
-}
-
-func (b *Block) applyTransaction(txn *transaction.Transaction, c Chainer, ctx context.Context) error {
+func (b *Block) applyTransaction(txn *transaction.Transaction, c Chainer, ctx context.Context) (*TxnState, error) {
 	if datastore.IsEmpty(txn.ClientID) {
 		txn.ComputeClientID()
 	}
-	rset, wset, err := c.UpdateState(ctx, b, txn)
+	ts, err := c.ExecuteTransaction(ctx, b, txn)
+	return b.checkTxnState(txn, ts, err, c.IsAccessMapStrict())
+}
+
+// checkTxnState - check the result of executing a transaction of the block,
+// the execution must succeed and stay within the access list of the
+// transaction, if the block has one. With strict checking the access list
+// must have exactly the keys the transaction accessed.
+func (b *Block) checkTxnState(txn *transaction.Transaction, ts *TxnState, err error, strict bool) (*TxnState, error) {
 	if err != nil {
 		b.SetStateStatus(StateFailed)
 		logging.Logger.Error("compute state - update state failed",
@@ -1016,23 +1053,31 @@ func (b *Block) applyTransaction(txn *transaction.Transaction, c Chainer, ctx co
 			zap.String("prev_block", b.PrevHash),
 			zap.String("prev_client_state", util.ToHex(b.PrevBlock.ClientStateHash)),
 			zap.Error(err))
-		return common.NewError("state_update_error", "error updating state")
+		return nil, common.NewError("state_update_error", "error updating state")
 	}
 
-	//we skip this check for blocks that do not contain access maps yet, in the future we will check more strictly
-	if bal, ok := b.AccessMap[txn.GetKey()]; ok && !bal.Includes(rset, wset) {
+	//without strict checking we skip this check for the transactions without an access list
+	bal := b.AccessMap[txn.GetKey()]
+	if bal == nil && strict {
+		b.SetStateStatus(StateFailed)
+		logging.Logger.Error("compute state - access list is missing",
+			zap.Int64("round", b.Round),
+			zap.String("block", b.Hash),
+			zap.String("txn", txn.Hash))
+		return nil, common.NewError("state_access_error", "error access lists")
+	}
+	if bal != nil && (strict && !bal.Matches(ts.Rset, ts.Wset) || !bal.Includes(ts.Rset, ts.Wset)) {
 		b.SetStateStatus(StateFailed)
 		logging.Logger.Error("compute state - access lists are not equal",
 			zap.Int64("round", b.Round),
 			zap.String("block", b.Hash),
 			zap.String("client_state", util.ToHex(b.ClientStateHash)),
 			zap.String("prev_block", b.PrevHash),
-			zap.String("prev_client_state", util.ToHex(b.PrevBlock.ClientStateHash)),
-			zap.Error(err))
-		return common.NewError("state_access_error", "error access lists")
+			zap.String("prev_client_state", util.ToHex(b.PrevBlock.ClientStateHash)))
+		return nil, common.NewError("state_access_error", "error access lists")
 	}
 
-	return nil
+	return ts, nil
 }
 
 // ApplyBlockStateChange apply and merge the state changes
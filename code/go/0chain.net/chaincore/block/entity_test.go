@@ -4,9 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"go.uber.org/atomic"
 	"reflect"
-	"runtime"
 	"strconv"
 	"sync"
 	"testing"
@@ -3510,35 +3508,23 @@ func TestBlock_DoReadLock(t *testing.T) {
 	}
 }
 
-func TestBlock_applyTransactions(t *testing.T) {
-	runtime.GOMAXPROCS(8)
-
-	b := NewBlock("", 2)
-	pb := NewBlock("", 1)
-	b.PrevBlock = pb
-
-	type args struct {
-		ctx     context.Context
-		c       *StubChainer
-		batcher *StubBatcher
-	}
-
+// testAccessMap - the access map of the transactions "0" to "14" used by the
+// scheduler and executor tests, its dependency graph is
+//
+//	0 -> 6 -> 10
+//	1 -> 7 -> 8 -> 11 -> 12
+//	2 -> 8
+//	3 -> 7, 3 -> 9 -> 13
+//	4 -> 9
+//	5 -> 14
+//	7 -> 11
+func testAccessMap() ([]*transaction.Transaction, map[datastore.Key]*AccessList) {
 	txs := make([]*transaction.Transaction, 15)
 	for i := 0; i < len(txs); i++ {
 		txs[i] = &transaction.Transaction{HashIDField: datastore.HashIDField{Hash: strconv.Itoa(i)}}
 	}
 
-	rich := [][]*transaction.Transaction{
-		{txs[0], txs[1], txs[2]},
-		{txs[3], txs[4], txs[5], txs[6]},
-		{txs[7]},
-		{txs[8]},
-		{txs[9], txs[10], txs[11]},
-		{txs[12], txs[13]},
-		{txs[14]},
-	}
-
-	b.AccessMap = map[datastore.Key]*AccessList{
+	return txs, map[datastore.Key]*AccessList{
 		"0": {
 			Reads:  []datastore.Key{"a6"},
 			Writes: []datastore.Key{},
@@ -3600,104 +3586,150 @@ func TestBlock_applyTransactions(t *testing.T) {
 			Writes: []datastore.Key{"a14"},
 		},
 	}
+}
+
+func TestDAGExecutor_Apply(t *testing.T) {
+	txs, accessMap := testAccessMap()
+	newBlock := func(txns []*transaction.Transaction) *Block {
+		b := NewBlock("", 2)
+		b.PrevBlock = NewBlock("", 1)
+		b.Txns = txns
+		b.AccessMap, b.AccessMapVersion = accessMap, AccessMapVersion
+		return b
+	}
+
+	all := make([]string, len(txs))
+	for i, txn := range txs {
+		all[i] = txn.GetKey()
+	}
 
-	cancel, cf := context.WithCancel(context.Background())
 	tests := []struct {
-		name          string
-		args          args
-		wantErr       bool
-		wantExecCount int32
+		name       string
+		txns       []*transaction.Transaction
+		errorKey   datastore.Key
+		cancel     bool
+		wantErr    bool
+		wantMerged []string
+		notMerged  []string
 	}{
 		{
-			name: "test for empty batch",
-			args: args{
-				ctx:     cancel,
-				c:       &StubChainer{b: b, callCount: atomic.NewInt32(0)},
-				batcher: &StubBatcher{nil},
-			},
-			wantErr: false,
-		}, {
-			name: "test for rich batch",
-			args: args{
-				ctx:     cancel,
-				c:       &StubChainer{callCount: atomic.NewInt32(0)},
-				batcher: &StubBatcher{rich},
-			},
-			wantErr: false,
-		}, {
-			name: "test for rich batch with error",
-			args: args{
-				ctx:     cancel,
-				c:       &StubChainer{errorIndex: 9, callCount: atomic.NewInt32(0)},
-				batcher: &StubBatcher{rich},
-			},
-			wantErr:       true,
-			wantExecCount: 9,
-		}, {
-			name: "test for cancelled context",
-			args: args{
-				ctx:     cancel,
-				c:       &StubChainer{errorIndex: 8, cancel: cf, callCount: atomic.NewInt32(0)},
-				batcher: &StubBatcher{rich},
-			},
-			wantErr:       true,
-			wantExecCount: 8,
+			name: "test for empty block",
+		},
+		{
+			name:       "test for rich block",
+			txns:       txs,
+			wantMerged: all,
+		},
+		{
+			name:      "test for rich block with error",
+			txns:      txs,
+			errorKey:  "8",
+			wantErr:   true,
+			notMerged: []string{"8", "11", "12"},
+		},
+		{
+			name:      "test for cancelled context",
+			txns:      txs,
+			errorKey:  "8",
+			cancel:    true,
+			wantErr:   true,
+			notMerged: []string{"11", "12"},
 		},
 	}
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := b.applyTransactions(tt.args.ctx, tt.args.c, tt.args.batcher); (err != nil) != tt.wantErr {
-				t.Errorf("applyTransactions() error = %v, wantErr %v", err, tt.wantErr)
-			}
-			if tt.wantExecCount != 0 && tt.wantExecCount != tt.args.c.callCount.Load() {
-				t.Errorf("applyTransactions() execCount = %v, wantExecCount  %v", tt.args.c.callCount, tt.wantExecCount)
-			}
-		})
+		for _, workers := range []int{1, 4, 16} {
+			t.Run(tt.name+"/workers "+strconv.Itoa(workers), func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				c := &StubChainer{errorKey: tt.errorKey}
+				if tt.cancel {
+					c.cancel = cancel
+				}
+
+				b := newBlock(tt.txns)
+				err := NewDAGExecutor(workers).Apply(ctx, b, c)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+				}
+
+				order := make(map[string]int, len(c.merged))
+				for i, key := range c.merged {
+					order[key] = i
+				}
+				if tt.wantMerged != nil {
+					assert.ElementsMatch(t, tt.wantMerged, c.merged)
+				}
+				for _, key := range tt.notMerged {
+					assert.NotContains(t, order, key)
+				}
+
+				// every merged transaction is merged after the ones it depends on
+				g := NewTxnGraph(b.Txns, b.AccessMap)
+				for i := 0; i < g.Len(); i++ {
+					for _, j := range g.Dependents(i) {
+						oj, ok := order[b.Txns[j].GetKey()]
+						if !ok {
+							continue
+						}
+						oi, ok := order[b.Txns[i].GetKey()]
+						if assert.True(t, ok, "%d merged without %d", j, i) {
+							assert.Less(t, oi, oj, "%d merged before %d", j, i)
+						}
+					}
+				}
+			})
+		}
 	}
 }
 
 type StubChainer struct {
-	b          *Block
-	callCount  *atomic.Int32
-	errorIndex int32
-	cancel     context.CancelFunc
+	errorKey datastore.Key
+	cancel   context.CancelFunc
+	mutex    sync.Mutex
+	merged   []string
 }
 
-func (s StubChainer) GetPreviousBlock(ctx context.Context, b *Block) *Block {
+func (s *StubChainer) GetPreviousBlock(ctx context.Context, b *Block) *Block {
 	panic("implement me")
 }
 
-func (s StubChainer) GetBlockStateChange(b *Block) error {
+func (s *StubChainer) GetBlockStateChange(b *Block) error {
 	panic("implement me")
 }
 
-func (s StubChainer) ComputeState(ctx context.Context, pb *Block) error {
+func (s *StubChainer) ComputeState(ctx context.Context, pb *Block) error {
 	return nil
 }
 
-func (s StubChainer) GetStateDB() util.NodeDB {
+func (s *StubChainer) GetStateDB() util.NodeDB {
 	panic("implement me")
 }
 
-func (s StubChainer) UpdateState(ctx context.Context, b *Block, txn *transaction.Transaction) (rset, wset map[datastore.Key]bool, err error) {
-	s.callCount.Inc()
+func (s *StubChainer) ExecuteTransaction(ctx context.Context, b *Block, txn *transaction.Transaction) (*TxnState, error) {
 	al := b.AccessMap[txn.GetKey()]
 
-	if s.callCount.Load() == s.errorIndex {
+	if txn.GetKey() == s.errorKey {
 		if s.cancel != nil {
 			s.cancel()
-			return al.Rset(), al.Wset(), nil
+			return NewTxnState(txn, nil, al.Rset(), al.Wset()), nil
 		}
-		return nil, nil, errors.New("failed")
+		return nil, errors.New("failed")
 	}
 
-	return al.Rset(), al.Wset(), nil
+	return NewTxnState(txn, nil, al.Rset(), al.Wset()), nil
+}
+
+func (s *StubChainer) MergeTransaction(b *Block, ts *TxnState) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.merged = append(s.merged, ts.Txn.GetKey())
+	return nil
 }
 
-type StubBatcher struct {
-	ret [][]*transaction.Transaction
+func (s *StubChainer) GetExecutor() Executor {
+	return NewDAGExecutor(0)
 }
 
-func (s StubBatcher) Batch(b *Block) (ret [][]*transaction.Transaction) {
-	return s.ret
+func (s *StubChainer) IsAccessMapStrict() bool {
+	return false
 }
@@ -0,0 +1,389 @@
+package block
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/logging"
+	"go.uber.org/zap"
+)
+
+// Executor - applies the transactions of a block to the block state, the
+// resulting state must be the same as if they were applied one by one
+type Executor interface {
+	Apply(ctx context.Context, b *Block, c Chainer) error
+}
+
+// ScheduleStats - how parallel the transactions of a block were executed
+type ScheduleStats struct {
+	Txns         int
+	Dependencies int
+	// the number of transactions on the longest dependency chain
+	CriticalPath int
+	// the average number of transactions executed at a time
+	Parallelism float64
+}
+
+// DAGExecutor - executes the transactions of a block following their
+// dependency graph built from the access map of the block. A transaction is
+// executed by one of the workers as soon as all the transactions it depends
+// on are merged into the block state, and merged right after its execution.
+// The transactions not depending on each other touch different keys, so the
+// order they are merged in doesn't change the resulting state.
+type DAGExecutor struct {
+	workers int
+}
+
+// NewDAGExecutor - create a new dependency graph executor running at most
+// the given number of transactions at a time, the number of CPUs if not positive
+func NewDAGExecutor(workers int) *DAGExecutor {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &DAGExecutor{workers: workers}
+}
+
+type txnResult struct {
+	idx      int
+	err      error
+	duration time.Duration
+}
+
+// Apply - implement Executor
+func (de *DAGExecutor) Apply(ctx context.Context, b *Block, c Chainer) error {
+	var (
+		start = time.Now()
+		g     = NewBlockTxnGraph(b)
+		n     = g.Len()
+	)
+	if n == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		work     = make(chan int, n)
+		finished = make(chan txnResult)
+	)
+	for i := 0; i < de.workers && i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range work {
+				finished <- de.apply(ctx, b, c, idx)
+			}
+		}()
+	}
+
+	var (
+		waiting = make([]int, n)
+		running int
+		done    int
+		busy    time.Duration
+		aErr    error
+	)
+	copy(waiting, g.dependencies)
+	for _, idx := range g.Roots() {
+		work <- idx
+		running++
+	}
+	for running > 0 {
+		r := <-finished
+		running--
+		busy += r.duration
+		if r.err != nil {
+			if aErr == nil {
+				aErr = r.err
+				cancel()
+			}
+			continue
+		}
+		done++
+		if aErr != nil {
+			continue
+		}
+		for _, d := range g.Dependents(r.idx) {
+			if waiting[d]--; waiting[d] == 0 {
+				work <- d
+				running++
+			}
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if aErr != nil {
+		logging.Logger.Error("apply transactions - failed",
+			zap.Int64("round", b.Round), zap.String("block", b.Hash),
+			zap.Int("applied", done), zap.Int("txns", n), zap.Error(aErr))
+		return aErr
+	}
+
+	stats := &ScheduleStats{
+		Txns:         n,
+		Dependencies: g.Edges(),
+		CriticalPath: g.CriticalPath(),
+	}
+	if wall := time.Since(start); wall > 0 {
+		stats.Parallelism = float64(busy) / float64(wall)
+	}
+	TxnCriticalPathMetric.Update(int64(stats.CriticalPath))
+	TxnParallelismMetric.Update(stats.Parallelism)
+	logging.Logger.Info("apply transactions - schedule",
+		zap.Int64("round", b.Round), zap.String("block", b.Hash),
+		zap.Int("txns", stats.Txns),
+		zap.Int("dependencies", stats.Dependencies),
+		zap.Int("critical_path", stats.CriticalPath),
+		zap.Int("workers", de.workers),
+		zap.Float64("parallelism", stats.Parallelism))
+	return nil
+}
+
+// apply executes the transaction and merges it into the block state
+func (de *DAGExecutor) apply(ctx context.Context, b *Block, c Chainer, idx int) txnResult {
+	var txn = b.Txns[idx]
+	if ctx.Err() != nil {
+		return txnResult{idx: idx, err: errors.New("apply transactions stopped due to context.Done()")}
+	}
+	// the waiting for the verification isn't counted as busy
+	if err := waitVerified(ctx, b, txn); err != nil {
+		return txnResult{idx: idx, err: err}
+	}
+
+	var start = time.Now()
+
+	ts, err := b.applyTransaction(txn, c, ctx)
+	if err != nil {
+		return txnResult{idx: idx, err: err, duration: time.Since(start)}
+	}
+	if err := c.MergeTransaction(b, ts); err != nil {
+		b.SetStateStatus(StateFailed)
+		logging.Logger.Error("apply transactions - merge failed",
+			zap.Int64("round", b.Round),
+			zap.String("block", b.Hash),
+			zap.String("txn", txn.Hash),
+			zap.Error(err))
+		return txnResult{idx: idx, duration: time.Since(start),
+			err: common.NewError("state_update_error", "error merging state")}
+	}
+	return txnResult{idx: idx, duration: time.Since(start)}
+}
+
+// OptimisticExecutor - executes the transactions speculatively in parallel,
+// Block-STM style, without relying on the access map of the block.
+//
+// Every transaction runs against its own view of the block state as of the
+// last merged transaction and records the keys it reads and writes. The
+// results are then merged in the block order. A result is only merged if
+// none of the keys it accessed were written by a transaction merged after
+// its view was taken, otherwise it is dropped and the transaction runs again
+// on the next round together with all the other invalidated transactions.
+// The first not yet merged transaction always runs on the up to date state,
+// so each round merges at least one transaction.
+type OptimisticExecutor struct {
+	workers int
+}
+
+// NewOptimisticExecutor - create a new optimistic executor running at most
+// the given number of transactions at a time, the number of CPUs if not positive
+func NewOptimisticExecutor(workers int) *OptimisticExecutor {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &OptimisticExecutor{workers: workers}
+}
+
+// speculativeTxn - the latest speculative execution of a transaction
+type speculativeTxn struct {
+	txn      *transaction.Transaction
+	executed bool
+	ts       *TxnState
+	err      error
+	// the number of transactions merged before the execution
+	version int
+}
+
+// Apply - implement Executor
+func (oe *OptimisticExecutor) Apply(ctx context.Context, b *Block, c Chainer) error {
+	var (
+		applyErr error
+		strict   = c.IsAccessMapStrict()
+	)
+	err := oe.execute(ctx, b, c, b.Txns, func(txn *transaction.Transaction, ts *TxnState, err error) (bool, bool) {
+		if _, applyErr = b.checkTxnState(txn, ts, err, strict); applyErr != nil {
+			return false, true
+		}
+		return true, false
+	})
+	if err != nil {
+		return err
+	}
+	return applyErr
+}
+
+// Fill - execute the candidate transactions speculatively in parallel and
+// merge them in the given order as long as accept allows it. The accept
+// callback gets every candidate in order with the result of its execution
+// against the state with all the previously accepted candidates merged.
+// It decides whether the candidate is merged and whether to stop after it.
+func (oe *OptimisticExecutor) Fill(ctx context.Context, b *Block, c Chainer,
+	txns []*transaction.Transaction,
+	accept func(txn *transaction.Transaction, ts *TxnState, err error) (merge, stop bool)) error {
+
+	return oe.execute(ctx, b, c, txns, accept)
+}
+
+func (oe *OptimisticExecutor) execute(ctx context.Context, b *Block, c Chainer,
+	txns []*transaction.Transaction,
+	accept func(txn *transaction.Transaction, ts *TxnState, err error) (merge, stop bool)) error {
+
+	var (
+		stxns = make([]*speculativeTxn, len(txns))
+		// the merge order of the last merged transaction writing a key
+		versions   = make(map[datastore.Key]int)
+		merged     int
+		rounds     int
+		executions int
+	)
+	for i, txn := range txns {
+		if datastore.IsEmpty(txn.ClientID) {
+			txn.ComputeClientID()
+		}
+		stxns[i] = &speculativeTxn{txn: txn}
+	}
+
+	conflicts := func(st *speculativeTxn) bool {
+		if st.ts == nil {
+			// the execution failed before running the transaction logic
+			return false
+		}
+		for _, set := range []map[datastore.Key]bool{st.ts.Rset, st.ts.Wset} {
+			for k := range set {
+				if v, ok := versions[k]; ok && v >= st.version {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for next := 0; next < len(stxns); {
+		rounds++
+		var pending []*speculativeTxn
+		for _, st := range stxns[next:] {
+			if !st.executed {
+				st.version = merged
+				pending = append(pending, st)
+			}
+		}
+		executions += len(pending)
+		if err := oe.run(ctx, b, c, pending); err != nil {
+			return err
+		}
+
+		for ; next < len(stxns); next++ {
+			st := stxns[next]
+			if conflicts(st) {
+				break
+			}
+			merge, stop := accept(st.txn, st.ts, st.err)
+			if st.err != nil {
+				merge = false
+			}
+			if merge {
+				if err := c.MergeTransaction(b, st.ts); err != nil {
+					b.SetStateStatus(StateFailed)
+					logging.Logger.Error("optimistic execution - merge failed",
+						zap.Int64("round", b.Round),
+						zap.String("block", b.Hash),
+						zap.String("txn", st.txn.Hash),
+						zap.Error(err))
+					return common.NewError("state_update_error", "error merging state")
+				}
+				for k := range st.ts.Wset {
+					versions[k] = merged
+				}
+				merged++
+			}
+			if stop {
+				next = len(stxns)
+				break
+			}
+		}
+
+		// drop the results that are already known to be stale, so they are
+		// executed again on the next round
+		for _, st := range stxns[next:] {
+			if st.executed && conflicts(st) {
+				st.executed, st.ts, st.err = false, nil, nil
+			}
+		}
+	}
+
+	logging.Logger.Debug("optimistic execution",
+		zap.Int64("round", b.Round),
+		zap.Int("txns", len(txns)),
+		zap.Int("merged", merged),
+		zap.Int("rounds", rounds),
+		zap.Int("executions", executions))
+	return nil
+}
+
+// run executes the transactions on at most oe.workers goroutines
+func (oe *OptimisticExecutor) run(ctx context.Context, b *Block, c Chainer,
+	stxns []*speculativeTxn) error {
+
+	var (
+		wg   sync.WaitGroup
+		work = make(chan *speculativeTxn)
+
+		mutex     sync.Mutex
+		verifyErr error
+	)
+	for i := 0; i < oe.workers && i < len(stxns); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for st := range work {
+				if err := waitVerified(ctx, b, st.txn); err != nil {
+					mutex.Lock()
+					if verifyErr == nil {
+						verifyErr = err
+					}
+					mutex.Unlock()
+					continue
+				}
+				st.ts, st.err = c.ExecuteTransaction(ctx, b, st.txn)
+				st.executed = true
+			}
+		}()
+	}
+
+feed:
+	for _, st := range stxns {
+		select {
+		case work <- st:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if verifyErr != nil {
+		return verifyErr
+	}
+	if ctx.Err() != nil {
+		return errors.New("optimistic execution stopped due to context.Done()")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package block
+
+import (
+	"strconv"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+// HeaderVersion - the version of the header of the blocks generated by this
+// node. From version 1 the block hash covers the client state hash, so the
+// state proofs can be checked against a notarized block header.
+const HeaderVersion = 1
+
+// ErrHeaderVersion - the block header has an unknown version
+var ErrHeaderVersion = common.NewError("header_version", "unsupported header version")
+
+// getHashData - the data of the block hash
+func (bs *BlockSummary) getHashData() string {
+	hashData := bs.MinerID + ":" + bs.PrevHash + ":" + common.TimeToString(bs.CreationDate) + ":" + strconv.FormatInt(bs.Round, 10) + ":" + strconv.FormatInt(bs.RoundRandomSeed, 10) + ":" + bs.MerkleTreeRoot + ":" + bs.ReceiptMerkleTreeRoot
+	if bs.MagicBlock != nil {
+		if bs.MagicBlock.Hash == "" {
+			bs.MagicBlock.Hash = bs.MagicBlock.GetHash()
+		}
+		hashData += ":" + bs.MagicBlock.Hash
+	}
+	if bs.AccessMapVersion > 0 {
+		hashData += ":" + strconv.Itoa(bs.AccessMapVersion) + ":" + bs.AccessMapHash
+	}
+	if bs.ReceiptsVersion > 0 {
+		hashData += ":" + strconv.Itoa(bs.ReceiptsVersion)
+	}
+	if bs.HeaderVersion > 0 {
+		hashData += ":" + strconv.Itoa(bs.HeaderVersion) + ":" + util.ToHex(bs.ClientStateHash)
+	}
+	return hashData
+}
+
+// ComputeHash - the hash of the block of the summary, computed from the
+// summary fields only
+func (bs *BlockSummary) ComputeHash() string {
+	return encryption.Hash(bs.getHashData())
+}
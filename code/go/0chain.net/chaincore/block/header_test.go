@@ -0,0 +1,34 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"0chain.net/chaincore/node"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+func TestBlockSummary_ComputeHash(t *testing.T) {
+	for _, version := range []int{0, HeaderVersion} {
+		b := NewBlock("", 7)
+		b.PrevHash = encryption.Hash("6")
+		b.MinerID = encryption.Hash("miner")
+		b.RoundRandomSeed = 42
+		b.ClientStateHash = util.Key(encryption.RawHash("state"))
+		b.HeaderVersion = version
+		b.ReceiptsVersion = ReceiptsVersion
+		b.MagicBlock = NewMagicBlock()
+		b.MagicBlock.MagicBlockNumber = 1
+		b.MagicBlock.Miners = node.NewPool(node.NodeTypeMiner)
+		b.MagicBlock.Sharders = node.NewPool(node.NodeTypeSharder)
+		b.HashBlock()
+
+		bs := b.GetSummary()
+		assert.Equal(t, b.Hash, bs.ComputeHash())
+		bs.ClientStateHash = util.Key(encryption.RawHash("other"))
+		assert.Equal(t, version > 0, b.Hash != bs.ComputeHash(),
+			"the state hash is covered from version 1")
+	}
+}
@@ -0,0 +1,174 @@
+package block
+
+import (
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/util"
+)
+
+// ReceiptsVersion - the version of the receipts of the blocks generated by
+// this node. The receipts Merkle tree of a block with version 0 has the
+// output hashes of the transactions as leaves and the block carries no
+// receipts, from version 1 it has the hashes of the full receipts.
+const ReceiptsVersion = 1
+
+var (
+	// ErrReceiptsVersion - the block receipts have an unknown version
+	ErrReceiptsVersion = common.NewError("receipts_version", "unsupported receipts version")
+
+	// ErrReceiptsInvalid - the block receipts don't match its transactions
+	ErrReceiptsInvalid = common.NewError("receipts_invalid", "receipts don't match the transactions")
+
+	// ErrReceiptMissing - a transaction of the block has no receipt
+	ErrReceiptMissing = common.NewError("receipt_missing", "transaction without a receipt")
+
+	// ErrReceiptsMismatch - the receipts of the block execution are different
+	// from the receipts of the block
+	ErrReceiptsMismatch = common.NewError("receipts_mismatch", "computed receipts don't match")
+
+	// ErrReceiptNotFound - the transaction isn't in the block or the block
+	// predates the full receipts
+	ErrReceiptNotFound = common.NewError("receipt_not_found", "receipt not available")
+)
+
+// outputReceipt - the receipt of a version 0 block, only its output hash
+type outputReceipt struct {
+	txn *transaction.Transaction
+}
+
+func (or outputReceipt) GetHash() string {
+	return or.txn.OutputHash
+}
+
+func (or outputReceipt) GetHashBytes() []byte {
+	return util.HashStringToBytes(or.txn.OutputHash)
+}
+
+/*GetReceiptsMerkleTree - return the merkle tree of this block using the receipts as leaf nodes */
+func (b *Block) GetReceiptsMerkleTree() *util.MerkleTree {
+	var hashables = make([]util.Hashable, len(b.Txns))
+	for idx, txn := range b.Txns {
+		if b.ReceiptsVersion > 0 && idx < len(b.Receipts) {
+			hashables[idx] = b.Receipts[idx]
+		} else {
+			hashables[idx] = outputReceipt{txn: txn}
+		}
+	}
+	var mt util.MerkleTree
+	mt.ComputeTree(hashables)
+	return &mt
+}
+
+// GetReceiptPath - the path of the receipt of the transaction in the receipts
+// Merkle tree, nil if the transaction isn't in the block
+func (b *Block) GetReceiptPath(txn *transaction.Transaction) *util.MTPath {
+	for idx, t := range b.Txns {
+		if t.Hash == txn.Hash {
+			return b.GetReceiptsMerkleTree().GetPathByIndex(idx)
+		}
+	}
+	return nil
+}
+
+// GetReceipt - the receipt of the transaction of the block with its path in
+// the receipts Merkle tree
+func (b *Block) GetReceipt(txnHash string) (*transaction.TxnReceipt, *util.MTPath, error) {
+	if b.ReceiptsVersion == 0 {
+		return nil, nil, ErrReceiptNotFound
+	}
+	for idx, r := range b.Receipts {
+		if r.TxnHash == txnHash {
+			return r, b.GetReceiptsMerkleTree().GetPathByIndex(idx), nil
+		}
+	}
+	return nil, nil, ErrReceiptNotFound
+}
+
+// validateReceipts - the block has a receipt for each of its transactions
+// in the same order, or none with version 0
+func (b *Block) validateReceipts() error {
+	if b.ReceiptsVersion > ReceiptsVersion {
+		return ErrReceiptsVersion
+	}
+	if b.ReceiptsVersion == 0 {
+		if len(b.Receipts) > 0 {
+			return ErrReceiptsVersion
+		}
+		return nil
+	}
+	if len(b.Receipts) != len(b.Txns) {
+		return ErrReceiptsInvalid
+	}
+	for idx, r := range b.Receipts {
+		if r == nil || r.TxnHash != b.Txns[idx].Hash {
+			return ErrReceiptsInvalid
+		}
+	}
+	return nil
+}
+
+// AddReceipt - add the receipt of a transaction merged into the block state
+func (b *Block) AddReceipt(r *transaction.TxnReceipt) {
+	b.receiptsMutex.Lock()
+	defer b.receiptsMutex.Unlock()
+	if b.receipts == nil {
+		b.receipts = make(map[string]*transaction.TxnReceipt)
+	}
+	b.receipts[r.TxnHash] = r
+}
+
+// resetReceipts - drop the receipts added so far, the state of the block is
+// computed again
+func (b *Block) resetReceipts() {
+	b.receiptsMutex.Lock()
+	defer b.receiptsMutex.Unlock()
+	b.receipts = nil
+}
+
+// CollectReceipts - the receipts added for the transactions of the block in
+// the block order
+func (b *Block) CollectReceipts() ([]*transaction.TxnReceipt, error) {
+	b.receiptsMutex.Lock()
+	defer b.receiptsMutex.Unlock()
+	receipts := make([]*transaction.TxnReceipt, len(b.Txns))
+	for idx, txn := range b.Txns {
+		r, ok := b.receipts[txn.Hash]
+		if !ok {
+			return nil, ErrReceiptMissing
+		}
+		receipts[idx] = r
+	}
+	return receipts, nil
+}
+
+// SetReceipts - set the receipts of a generated block to the ones added
+// while executing its transactions
+func (b *Block) SetReceipts() error {
+	receipts, err := b.CollectReceipts()
+	if err != nil {
+		return err
+	}
+	b.ReceiptsVersion, b.Receipts = ReceiptsVersion, receipts
+	return nil
+}
+
+// checkComputedReceipts - check the receipts added while computing the
+// state of the block are the receipts of the block
+func (b *Block) checkComputedReceipts() error {
+	if b.ReceiptsVersion == 0 {
+		return nil
+	}
+	receipts, err := b.CollectReceipts()
+	if err != nil {
+		return err
+	}
+	if len(receipts) != len(b.Receipts) {
+		return ErrReceiptsMismatch
+	}
+	for idx, r := range receipts {
+		if r.GetHash() != b.Receipts[idx].GetHash() {
+			return ErrReceiptsMismatch
+		}
+	}
+	return nil
+}
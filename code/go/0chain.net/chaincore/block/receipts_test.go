@@ -0,0 +1,181 @@
+package block
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/util"
+)
+
+func testReceipts(txs []*transaction.Transaction) []*transaction.TxnReceipt {
+	receipts := make([]*transaction.TxnReceipt, len(txs))
+	for i, txn := range txs {
+		txn.OutputHash = strconv.Itoa(i)
+		receipts[i] = &transaction.TxnReceipt{
+			TxnHash:   txn.Hash,
+			Status:    transaction.TxnSuccess,
+			Transfers: []*state.Transfer{state.NewTransfer("from", "to", state.Balance(i))},
+			Logs:      []*transaction.Log{{Type: "test", Tag: txn.Hash}},
+		}
+	}
+	return receipts
+}
+
+func TestBlock_SetReceipts(t *testing.T) {
+	txs, _ := testAccessMap()
+	receipts := testReceipts(txs)
+
+	b := NewBlock("", 1)
+	b.Txns = txs
+	// added in any order, set in the block order
+	for i := len(receipts) - 1; i >= 0; i-- {
+		b.AddReceipt(receipts[i])
+	}
+	require.NoError(t, b.SetReceipts())
+	assert.Equal(t, ReceiptsVersion, b.ReceiptsVersion)
+	assert.Equal(t, receipts, b.Receipts)
+	require.NoError(t, b.validateReceipts())
+
+	b.resetReceipts()
+	_, err := b.CollectReceipts()
+	assert.Equal(t, ErrReceiptMissing, err)
+}
+
+func TestBlock_ReceiptsHash(t *testing.T) {
+	txs, _ := testAccessMap()
+	receipts := testReceipts(txs)
+
+	b := NewBlock("", 1)
+	b.Txns = txs
+	b.HashBlock()
+	unversioned := b.Hash
+	root := b.GetReceiptsMerkleTree().GetRoot()
+
+	b.ReceiptsVersion, b.Receipts = ReceiptsVersion, receipts
+	b.HashBlock()
+	versioned := b.Hash
+	require.NotEqual(t, unversioned, versioned)
+	require.NotEqual(t, root, b.GetReceiptsMerkleTree().GetRoot())
+
+	b.Receipts = b.UnverifiedBlockBody.Clone().Receipts
+	b.HashBlock()
+	require.Equal(t, versioned, b.Hash, "clone")
+
+	// the root commits to the full receipts, not only to the outputs
+	changed := *receipts[3]
+	changed.Logs = append([]*transaction.Log{}, changed.Logs...)
+	changed.Logs[0] = &transaction.Log{Type: "test", Tag: "other"}
+	b.Receipts[3] = &changed
+	b.HashBlock()
+	require.NotEqual(t, versioned, b.Hash)
+}
+
+func TestBlock_validateReceipts(t *testing.T) {
+	txs, _ := testAccessMap()
+	tests := []struct {
+		name     string
+		version  int
+		receipts func([]*transaction.TxnReceipt) []*transaction.TxnReceipt
+		wantErr  error
+	}{
+		{
+			name:     "no_receipts",
+			receipts: func([]*transaction.TxnReceipt) []*transaction.TxnReceipt { return nil },
+		},
+		{
+			name:     "receipts_without_version",
+			receipts: func(rs []*transaction.TxnReceipt) []*transaction.TxnReceipt { return rs },
+			wantErr:  ErrReceiptsVersion,
+		},
+		{
+			name:     "unknown_version",
+			version:  ReceiptsVersion + 1,
+			receipts: func(rs []*transaction.TxnReceipt) []*transaction.TxnReceipt { return rs },
+			wantErr:  ErrReceiptsVersion,
+		},
+		{
+			name:     "ok",
+			version:  ReceiptsVersion,
+			receipts: func(rs []*transaction.TxnReceipt) []*transaction.TxnReceipt { return rs },
+		},
+		{
+			name:     "missing",
+			version:  ReceiptsVersion,
+			receipts: func(rs []*transaction.TxnReceipt) []*transaction.TxnReceipt { return rs[1:] },
+			wantErr:  ErrReceiptsInvalid,
+		},
+		{
+			name:    "reordered",
+			version: ReceiptsVersion,
+			receipts: func(rs []*transaction.TxnReceipt) []*transaction.TxnReceipt {
+				return append([]*transaction.TxnReceipt{rs[1], rs[0]}, rs[2:]...)
+			},
+			wantErr: ErrReceiptsInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBlock("", 1)
+			b.Txns = txs
+			b.ReceiptsVersion = tt.version
+			b.Receipts = tt.receipts(testReceipts(txs))
+			assert.Equal(t, tt.wantErr, b.validateReceipts())
+		})
+	}
+}
+
+func TestBlock_checkComputedReceipts(t *testing.T) {
+	txs, _ := testAccessMap()
+
+	b := NewBlock("", 1)
+	b.Txns = txs
+	b.ReceiptsVersion, b.Receipts = ReceiptsVersion, testReceipts(txs)
+	for _, r := range testReceipts(txs) {
+		b.AddReceipt(r)
+	}
+	require.NoError(t, b.checkComputedReceipts())
+
+	mismatch := *b.Receipts[5]
+	mismatch.Output = "other output"
+	b.AddReceipt(&mismatch)
+	assert.Equal(t, ErrReceiptsMismatch, b.checkComputedReceipts())
+}
+
+func TestBlock_GetReceipt(t *testing.T) {
+	txs, _ := testAccessMap()
+
+	b := NewBlock("", 1)
+	b.Txns = txs
+	_, _, err := b.GetReceipt(txs[2].Hash)
+	assert.Equal(t, ErrReceiptNotFound, err, "no receipts in a version 0 block")
+	path := b.GetReceiptPath(txs[2])
+	require.NotNil(t, path)
+	assert.True(t, util.VerifyMerklePath(txs[2].OutputHash, path, b.GetReceiptsMerkleTree().GetRoot()))
+
+	b.ReceiptsVersion, b.Receipts = ReceiptsVersion, testReceipts(txs)
+	root := b.GetReceiptsMerkleTree().GetRoot()
+	for _, txn := range txs {
+		r, path, err := b.GetReceipt(txn.Hash)
+		require.NoError(t, err)
+		rc := &transaction.ReceiptConfirmation{
+			Hash:                  txn.Hash,
+			Receipt:               r,
+			ReceiptMerkleTreeRoot: root,
+			ReceiptMerkleTreePath: path,
+		}
+		require.True(t, rc.Verify())
+
+		forged := *r
+		forged.Status = transaction.TxnFail
+		rc.Receipt = &forged
+		require.False(t, rc.Verify())
+	}
+
+	_, _, err = b.GetReceipt("unknown")
+	assert.Equal(t, ErrReceiptNotFound, err)
+}
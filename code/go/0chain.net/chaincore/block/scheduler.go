@@ -0,0 +1,192 @@
+package block
+
+import (
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+)
+
+// TxnGraph - the dependency graph of the transactions of a block, built from
+// the access map of the block.
+//
+// A transaction depends on the earlier transactions writing a key it reads
+// (read after write), reading a key it writes (write after read) or writing
+// a key it writes (write after write). Only the last writer of a key and the
+// readers since then are linked, the dependencies on the older accesses are
+// implied by the transitivity. A transaction without an access list depends
+// on all the earlier transactions and all the later ones depend on it, so a
+// block without an access map is executed sequentially. The transactions of
+// a client with a nonce depend on the previous one of the client with a
+// nonce, whatever their access lists are.
+type TxnGraph struct {
+	txns []*transaction.Transaction
+	// the transactions waiting for a transaction, in the block order
+	dependents [][]int
+	// the number of transactions a transaction waits for
+	dependencies []int
+	edges        int
+}
+
+// NewBlockTxnGraph - build the dependency graph scheduling the transactions
+// of the block, only an access map part of the block hash is used, the
+// transactions of a block without one are chained in order
+func NewBlockTxnGraph(b *Block) *TxnGraph {
+	var accessMap map[datastore.Key]*AccessList
+	if b.AccessMapVersion > 0 {
+		accessMap = b.AccessMap
+	}
+	return NewTxnGraph(b.Txns, accessMap)
+}
+
+// NewTxnGraph - build the dependency graph of the transactions
+func NewTxnGraph(txns []*transaction.Transaction, accessMap map[datastore.Key]*AccessList) *TxnGraph {
+	g := &TxnGraph{
+		txns:         txns,
+		dependents:   make([][]int, len(txns)),
+		dependencies: make([]int, len(txns)),
+	}
+
+	var (
+		lastWriter = make(map[datastore.Key]int)
+		readers    = make(map[datastore.Key][]int)
+		barrier    = -1
+		// the last transaction of a client with a nonce
+		lastNonced = make(map[datastore.Key]int)
+		// the last transaction given an edge to the one being added,
+		// to not link the same pair twice
+		linked = make([]int, len(txns))
+	)
+	for i := range linked {
+		linked[i] = -1
+	}
+
+	link := func(from, to int) {
+		if from == to || linked[from] == to {
+			return
+		}
+		linked[from] = to
+		g.dependents[from] = append(g.dependents[from], to)
+		g.dependencies[to]++
+		g.edges++
+	}
+
+	for j, txn := range txns {
+		if txn.Nonce != 0 {
+			if datastore.IsEmpty(txn.ClientID) {
+				txn.ComputeClientID()
+			}
+			if i, ok := lastNonced[txn.ClientID]; ok {
+				link(i, j)
+			}
+			lastNonced[txn.ClientID] = j
+		}
+
+		al := accessMap[txn.GetKey()]
+		if al == nil {
+			// no access list, wait for everything since the last barrier,
+			// the transactions in between wait for the barrier already
+			if barrier >= 0 && barrier == j-1 {
+				link(barrier, j)
+			}
+			for i := barrier + 1; i < j; i++ {
+				link(i, j)
+			}
+			barrier = j
+			continue
+		}
+		if barrier >= 0 {
+			link(barrier, j)
+		}
+
+		for _, k := range al.Reads {
+			if i, ok := lastWriter[k]; ok {
+				link(i, j)
+			}
+		}
+		for _, k := range al.Writes {
+			if i, ok := lastWriter[k]; ok {
+				link(i, j)
+			}
+			for _, i := range readers[k] {
+				link(i, j)
+			}
+		}
+
+		for _, k := range al.Writes {
+			lastWriter[k] = j
+			delete(readers, k)
+		}
+		for _, k := range al.Reads {
+			// the later writers wait for j as the writer anyway
+			if i, ok := lastWriter[k]; !ok || i != j {
+				readers[k] = append(readers[k], j)
+			}
+		}
+	}
+
+	return g
+}
+
+// Len - the number of transactions in the graph
+func (g *TxnGraph) Len() int {
+	return len(g.txns)
+}
+
+// Edges - the number of dependencies in the graph
+func (g *TxnGraph) Edges() int {
+	return g.edges
+}
+
+// Dependents - the transactions waiting for the given transaction
+func (g *TxnGraph) Dependents(i int) []int {
+	return g.dependents[i]
+}
+
+// Roots - the transactions not waiting for any other transaction
+func (g *TxnGraph) Roots() (roots []int) {
+	for i, n := range g.dependencies {
+		if n == 0 {
+			roots = append(roots, i)
+		}
+	}
+	return roots
+}
+
+// CriticalPath - the number of transactions on the longest dependency chain,
+// no schedule can execute the block in fewer steps
+func (g *TxnGraph) CriticalPath() (length int) {
+	for _, d := range g.depths() {
+		if d > length {
+			length = d
+		}
+	}
+	return length
+}
+
+// Levels - the transactions grouped by the length of the longest dependency
+// chain ending with them, in the block order within a group. The groups are
+// ordered by the length and the transactions of a group don't depend on each
+// other, so each group can be executed at once after the previous ones.
+func (g *TxnGraph) Levels() (levels [][]int) {
+	for i, d := range g.depths() {
+		if d > len(levels) {
+			levels = append(levels, make([][]int, d-len(levels))...)
+		}
+		levels[d-1] = append(levels[d-1], i)
+	}
+	return levels
+}
+
+// depths - the length of the longest dependency chain ending with each transaction
+func (g *TxnGraph) depths() []int {
+	depth := make([]int, len(g.txns))
+	// the transactions only depend on earlier ones, the block order is topological
+	for i := range g.txns {
+		depth[i]++
+		for _, j := range g.dependents[i] {
+			if depth[i] > depth[j] {
+				depth[j] = depth[i]
+			}
+		}
+	}
+	return depth
+}
@@ -0,0 +1,190 @@
+package block
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+)
+
+func TestNewTxnGraph(t *testing.T) {
+	txs, rich := testAccessMap()
+
+	newTxns := func(n int) []*transaction.Transaction {
+		return txs[:n]
+	}
+
+	tests := []struct {
+		name             string
+		txns             []*transaction.Transaction
+		accessMap        map[datastore.Key]*AccessList
+		wantDependents   [][]int
+		wantRoots        []int
+		wantCriticalPath int
+		wantLevels       [][]int
+	}{
+		{
+			name:             "empty block",
+			wantDependents:   [][]int{},
+			wantRoots:        nil,
+			wantCriticalPath: 0,
+			wantLevels:       nil,
+		},
+		{
+			name:             "no access map executed sequentially",
+			txns:             newTxns(4),
+			wantDependents:   [][]int{{1}, {2}, {3}, nil},
+			wantRoots:        []int{0},
+			wantCriticalPath: 4,
+			wantLevels:       [][]int{{0}, {1}, {2}, {3}},
+		},
+		{
+			name: "no contention",
+			txns: newTxns(3),
+			accessMap: map[datastore.Key]*AccessList{
+				"0": {Reads: []datastore.Key{"a"}, Writes: []datastore.Key{"b"}},
+				"1": {Reads: []datastore.Key{"a"}, Writes: []datastore.Key{"c"}},
+				"2": {Reads: []datastore.Key{"a"}},
+			},
+			wantDependents:   [][]int{nil, nil, nil},
+			wantRoots:        []int{0, 1, 2},
+			wantCriticalPath: 1,
+			wantLevels:       [][]int{{0, 1, 2}},
+		},
+		{
+			name: "read after write, write after read, write after write",
+			txns: newTxns(4),
+			accessMap: map[datastore.Key]*AccessList{
+				"0": {Writes: []datastore.Key{"a"}},
+				"1": {Reads: []datastore.Key{"a"}},
+				"2": {Reads: []datastore.Key{"a"}},
+				"3": {Writes: []datastore.Key{"a"}},
+			},
+			wantDependents:   [][]int{{1, 2, 3}, {3}, {3}, nil},
+			wantRoots:        []int{0},
+			wantCriticalPath: 3,
+			wantLevels:       [][]int{{0}, {1, 2}, {3}},
+		},
+		{
+			name: "transaction without access list is a barrier",
+			txns: newTxns(5),
+			accessMap: map[datastore.Key]*AccessList{
+				"0": {Writes: []datastore.Key{"a"}},
+				"1": {Writes: []datastore.Key{"b"}},
+				"3": {Writes: []datastore.Key{"c"}},
+				"4": {Writes: []datastore.Key{"a"}},
+			},
+			wantDependents:   [][]int{{2, 4}, {2}, {3, 4}, nil, nil},
+			wantRoots:        []int{0, 1},
+			wantCriticalPath: 3,
+			wantLevels:       [][]int{{0, 1}, {2}, {3, 4}},
+		},
+		{
+			name:      "rich access map",
+			txns:      txs,
+			accessMap: rich,
+			wantDependents: [][]int{
+				{6}, {7}, {8}, {7, 9}, {9}, {14}, {10}, {8, 11}, {11},
+				{13}, nil, {12}, nil, nil, nil,
+			},
+			wantRoots:        []int{0, 1, 2, 3, 4, 5},
+			wantCriticalPath: 5,
+			wantLevels: [][]int{
+				{0, 1, 2, 3, 4, 5}, {6, 7, 9, 14}, {8, 10, 13}, {11}, {12},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewTxnGraph(tt.txns, tt.accessMap)
+			if g.Len() != len(tt.txns) {
+				t.Errorf("Len() = %v, want %v", g.Len(), len(tt.txns))
+			}
+
+			dependents := make([][]int, g.Len())
+			edges := 0
+			for i := range dependents {
+				dependents[i] = g.Dependents(i)
+				edges += len(dependents[i])
+			}
+			if !reflect.DeepEqual(dependents, tt.wantDependents) {
+				t.Errorf("Dependents() = %v, want %v", dependents, tt.wantDependents)
+			}
+			if g.Edges() != edges {
+				t.Errorf("Edges() = %v, want %v", g.Edges(), edges)
+			}
+			if got := g.Roots(); !reflect.DeepEqual(got, tt.wantRoots) {
+				t.Errorf("Roots() = %v, want %v", got, tt.wantRoots)
+			}
+			if got := g.CriticalPath(); got != tt.wantCriticalPath {
+				t.Errorf("CriticalPath() = %v, want %v", got, tt.wantCriticalPath)
+			}
+			if got := g.Levels(); !reflect.DeepEqual(got, tt.wantLevels) {
+				t.Errorf("Levels() = %v, want %v", got, tt.wantLevels)
+			}
+		})
+	}
+}
+
+func TestTxnGraph_Dependencies(t *testing.T) {
+	txs, accessMap := testAccessMap()
+	g := NewTxnGraph(txs, accessMap)
+
+	// every pair of conflicting transactions must be ordered by the graph
+	reachable := make([]map[int]bool, g.Len())
+	for i := g.Len() - 1; i >= 0; i-- {
+		reachable[i] = make(map[int]bool)
+		for _, j := range g.Dependents(i) {
+			reachable[i][j] = true
+			for k := range reachable[j] {
+				reachable[i][k] = true
+			}
+		}
+	}
+
+	for i := 0; i < g.Len(); i++ {
+		for j := i + 1; j < g.Len(); j++ {
+			ai, aj := accessMap[strconv.Itoa(i)], accessMap[strconv.Itoa(j)]
+			conflict := hasAny(ai.Wset(), aj.Rset(), aj.Wset()) || hasAny(aj.Wset(), ai.Rset())
+			if conflict && !reachable[i][j] {
+				t.Errorf("%d and %d conflict but are not ordered", i, j)
+			}
+		}
+	}
+}
+
+func hasAny(set map[datastore.Key]bool, others ...map[datastore.Key]bool) bool {
+	for _, other := range others {
+		for k := range other {
+			if set[k] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestNewTxnGraph_Nonces(t *testing.T) {
+	var (
+		txns      = make([]*transaction.Transaction, 5)
+		accessMap = make(map[datastore.Key]*AccessList)
+	)
+	for i := range txns {
+		txns[i] = &transaction.Transaction{
+			HashIDField: datastore.HashIDField{Hash: strconv.Itoa(i)},
+			ClientID:    "a",
+		}
+		// no conflicts by the access lists
+		accessMap[strconv.Itoa(i)] = &AccessList{Writes: []datastore.Key{"k" + strconv.Itoa(i)}}
+	}
+	txns[1].Nonce, txns[3].Nonce, txns[4].Nonce = 1, 2, 1
+	txns[4].ClientID = "b"
+
+	g := NewTxnGraph(txns, accessMap)
+	want := [][]int{nil, {3}, nil, nil, nil}
+	if !reflect.DeepEqual(g.dependents, want) {
+		t.Errorf("dependents = %v, want %v", g.dependents, want)
+	}
+}
@@ -0,0 +1,157 @@
+package block
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"0chain.net/chaincore/transaction"
+)
+
+// TxnVerification - the verification of the transactions of a block running
+// along with the execution of the block. The verifier marks the transactions
+// verified as it goes, the executors wait for a transaction to be verified
+// before executing it, so a block is verified and executed in about the time
+// of the longest of the two instead of their sum. A failed verification
+// stops the executors waiting.
+type TxnVerification struct {
+	block *Block
+	index map[string]int
+	// closed when the transaction is verified
+	verified []chan struct{}
+	pending  int64
+	done     chan struct{}
+	once     sync.Once
+	err      error
+
+	start    time.Time
+	duration time.Duration
+	// the total time the executors waited for the verification
+	waited int64
+}
+
+// NewTxnVerification - create a new verification of the transactions of the block
+func NewTxnVerification(b *Block) *TxnVerification {
+	tv := &TxnVerification{
+		block:    b,
+		index:    make(map[string]int, len(b.Txns)),
+		verified: make([]chan struct{}, len(b.Txns)),
+		pending:  int64(len(b.Txns)),
+		done:     make(chan struct{}),
+		start:    time.Now(),
+	}
+	for i, txn := range b.Txns {
+		tv.index[txn.Hash] = i
+		tv.verified[i] = make(chan struct{})
+	}
+	if len(b.Txns) == 0 {
+		tv.finish(nil)
+	}
+	return tv
+}
+
+func (tv *TxnVerification) finish(err error) {
+	tv.once.Do(func() {
+		tv.err = err
+		tv.duration = time.Since(tv.start)
+		close(tv.done)
+	})
+}
+
+// Verified - mark the transaction at the index of the block verified
+func (tv *TxnVerification) Verified(idx int) {
+	close(tv.verified[idx])
+	if atomic.AddInt64(&tv.pending, -1) == 0 {
+		tv.finish(nil)
+	}
+}
+
+// Fail - fail the verification, the first error is kept
+func (tv *TxnVerification) Fail(err error) {
+	tv.finish(err)
+}
+
+// Done - closed when all the transactions are verified or the verification failed
+func (tv *TxnVerification) Done() <-chan struct{} {
+	return tv.done
+}
+
+// Wait - wait for the verification to end
+func (tv *TxnVerification) Wait(ctx context.Context) error {
+	select {
+	case <-tv.done:
+		return tv.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitTxn - wait for the transaction to be verified, the transactions not
+// in the block aren't waited for
+func (tv *TxnVerification) WaitTxn(ctx context.Context, txn *transaction.Transaction) error {
+	idx, ok := tv.index[txn.Hash]
+	if !ok {
+		return nil
+	}
+	select {
+	case <-tv.verified[idx]:
+		return nil
+	default:
+	}
+
+	start := time.Now()
+	defer func() {
+		atomic.AddInt64(&tv.waited, int64(time.Since(start)))
+	}()
+	select {
+	case <-tv.verified[idx]:
+		return nil
+	case <-tv.done:
+		if tv.err != nil {
+			return tv.err
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Duration - the time the verification took, valid once it's done
+func (tv *TxnVerification) Duration() time.Duration {
+	<-tv.done
+	return tv.duration
+}
+
+// Waited - the total time the executors waited for the transactions to be verified
+func (tv *TxnVerification) Waited() time.Duration {
+	return time.Duration(atomic.LoadInt64(&tv.waited))
+}
+
+type txnVerificationKey struct{}
+
+// WithTxnVerification - return a context with the verification of the
+// transactions of a block, the executors of the block wait for it
+func WithTxnVerification(ctx context.Context, tv *TxnVerification) context.Context {
+	return context.WithValue(ctx, txnVerificationKey{}, tv)
+}
+
+// GetTxnVerification - returns the verification of the transactions of the
+// block from the context, nil if there is none for the block
+func GetTxnVerification(ctx context.Context, b *Block) *TxnVerification {
+	tv, ok := ctx.Value(txnVerificationKey{}).(*TxnVerification)
+	if !ok || tv.block != b {
+		return nil
+	}
+	return tv
+}
+
+// waitVerified - wait for the transaction to be verified if the block is
+// verified along with its execution
+func waitVerified(ctx context.Context, b *Block, txn *transaction.Transaction) error {
+	tv := GetTxnVerification(ctx, b)
+	if tv == nil {
+		return nil
+	}
+	return tv.WaitTxn(ctx, txn)
+}
@@ -0,0 +1,118 @@
+package block
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"0chain.net/chaincore/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// verifiedChainer - fails the transactions executed before they are verified
+type verifiedChainer struct {
+	StubChainer
+	verified map[string]bool
+}
+
+func (vc *verifiedChainer) ExecuteTransaction(ctx context.Context, b *Block,
+	txn *transaction.Transaction) (*TxnState, error) {
+
+	vc.mutex.Lock()
+	ok := vc.verified[txn.Hash]
+	vc.mutex.Unlock()
+	if !ok {
+		return nil, errors.New("not verified")
+	}
+	return vc.StubChainer.ExecuteTransaction(ctx, b, txn)
+}
+
+func (vc *verifiedChainer) verify(tv *TxnVerification, idx int, txn *transaction.Transaction) {
+	vc.mutex.Lock()
+	vc.verified[txn.Hash] = true
+	vc.mutex.Unlock()
+	tv.Verified(idx)
+}
+
+func TestTxnVerification_Executors(t *testing.T) {
+	txs, accessMap := testAccessMap()
+
+	executors := map[string]Executor{
+		"dag":        NewDAGExecutor(4),
+		"optimistic": NewOptimisticExecutor(4),
+	}
+	for name, executor := range executors {
+		t.Run(name, func(t *testing.T) {
+			b := NewBlock("", 2)
+			b.PrevBlock = NewBlock("", 1)
+			b.Txns, b.AccessMap = txs, accessMap
+			b.AccessMapVersion = AccessMapVersion
+
+			c := &verifiedChainer{verified: make(map[string]bool)}
+			tv := NewTxnVerification(b)
+			// verified from the last one, the executors have to wait
+			go func() {
+				for i := len(b.Txns) - 1; i >= 0; i-- {
+					time.Sleep(time.Millisecond)
+					c.verify(tv, i, b.Txns[i])
+				}
+			}()
+
+			ctx := WithTxnVerification(context.Background(), tv)
+			require.NoError(t, executor.Apply(ctx, b, c))
+			require.NoError(t, tv.Wait(context.Background()))
+			assert.Len(t, c.merged, len(txs))
+			assert.NotZero(t, tv.Waited())
+			assert.NotZero(t, tv.Duration())
+		})
+	}
+}
+
+func TestTxnVerification_Fail(t *testing.T) {
+	txs, accessMap := testAccessMap()
+	executors := map[string]Executor{
+		"dag":        NewDAGExecutor(4),
+		"optimistic": NewOptimisticExecutor(4),
+	}
+	for name, executor := range executors {
+		t.Run(name, func(t *testing.T) {
+			b := NewBlock("", 2)
+			b.PrevBlock = NewBlock("", 1)
+			b.Txns, b.AccessMap = txs, accessMap
+			b.AccessMapVersion = AccessMapVersion
+
+			var (
+				c      = &verifiedChainer{verified: make(map[string]bool)}
+				tv     = NewTxnVerification(b)
+				failed = errors.New("invalid signature")
+			)
+			c.verify(tv, 0, b.Txns[0])
+			go func() {
+				time.Sleep(time.Millisecond)
+				tv.Fail(failed)
+			}()
+
+			ctx := WithTxnVerification(context.Background(), tv)
+			require.Equal(t, failed, executor.Apply(ctx, b, c))
+			require.Equal(t, failed, tv.Wait(context.Background()))
+			assert.NotContains(t, c.merged, b.Txns[len(b.Txns)-1].GetKey())
+		})
+	}
+}
+
+func TestGetTxnVerification(t *testing.T) {
+	var (
+		b     = NewBlock("", 2)
+		other = NewBlock("", 1)
+		tv    = NewTxnVerification(b)
+		ctx   = WithTxnVerification(context.Background(), tv)
+	)
+	assert.Equal(t, tv, GetTxnVerification(ctx, b))
+	assert.Nil(t, GetTxnVerification(ctx, other), "not the verification of the block")
+	assert.Nil(t, GetTxnVerification(context.Background(), b))
+
+	// nothing to verify
+	require.NoError(t, tv.Wait(context.Background()))
+}
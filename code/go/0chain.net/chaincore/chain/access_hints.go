@@ -0,0 +1,85 @@
+package chain
+
+import (
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/config"
+	"0chain.net/chaincore/smartcontract"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	"0chain.net/smartcontract/minersc"
+)
+
+// GetAccessHints - the MPT paths the transaction may access, keyed the same
+// way as the access lists of the blocks, known before executing it. Nil is
+// returned when the transaction can't tell or its hints are serial, making
+// it a barrier no other transaction of the block runs concurrently with.
+func (c *Chain) GetAccessHints(txn *transaction.Transaction) *block.AccessList {
+	if datastore.IsEmpty(txn.ClientID) {
+		txn.ComputeClientID()
+	}
+
+	var (
+		reads   = make(map[datastore.Key]bool)
+		writes  = make(map[datastore.Key]bool)
+		clients = []datastore.Key{txn.ClientID}
+	)
+	switch txn.TransactionType {
+	case transaction.TxnTypeSmartContract:
+		hints := smartcontract.GetAccessHints(txn)
+		if hints == nil || hints.Serial {
+			return nil
+		}
+		for _, k := range hints.Reads {
+			reads[encryption.Hash(k)] = true
+		}
+		for _, k := range hints.Writes {
+			writes[encryption.Hash(k)] = true
+		}
+		clients = append(clients, hints.Clients...)
+		if txn.Value > 0 {
+			clients = append(clients, txn.ToClientID)
+		}
+	case transaction.TxnTypeData:
+	case transaction.TxnTypeSend:
+		if txn.Value > 0 {
+			clients = append(clients, txn.ToClientID)
+		}
+	default:
+		return nil
+	}
+	if config.DevConfiguration.IsFeeEnabled && txn.Fee > 0 {
+		clients = append(clients, minersc.ADDRESS)
+	}
+
+	// the client states are read before they are changed
+	for _, k := range clients {
+		reads[k] = true
+		writes[k] = true
+	}
+	return block.NewAccessList(reads, writes)
+}
+
+// GroupTransactions - split the transactions into the groups that don't
+// conflict according to their access hints, to be executed one group after
+// another. The transactions without hints get a group of their own.
+func (c *Chain) GroupTransactions(txns []*transaction.Transaction) [][]*transaction.Transaction {
+	hints := make(map[datastore.Key]*block.AccessList, len(txns))
+	for _, txn := range txns {
+		if al := c.GetAccessHints(txn); al != nil {
+			hints[txn.GetKey()] = al
+		}
+	}
+
+	var (
+		levels = block.NewTxnGraph(txns, hints).Levels()
+		groups = make([][]*transaction.Transaction, len(levels))
+	)
+	for i, level := range levels {
+		groups[i] = make([]*transaction.Transaction, len(level))
+		for j, idx := range level {
+			groups[i][j] = txns[idx]
+		}
+	}
+	return groups
+}
@@ -0,0 +1,97 @@
+package chain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/smartcontract"
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+)
+
+const hintedSCAddress = "hinted_sc_address"
+
+// hintedSC - a smart contract declaring the access hints of its functions
+type hintedSC struct {
+	sci.SmartContractInterface
+	hints map[string]*sci.AccessHints
+}
+
+func (sc *hintedSC) GetAccessHints(_ *transaction.Transaction, funcName string,
+	_ []byte) *sci.AccessHints {
+
+	return sc.hints[funcName]
+}
+
+func TestChain_GroupTransactions(t *testing.T) {
+	smartcontract.ContractMap[hintedSCAddress] = &hintedSC{
+		hints: map[string]*sci.AccessHints{
+			"pool":   {Reads: []datastore.Key{"config"}, Writes: []datastore.Key{"pool"}},
+			"serial": {Serial: true},
+		},
+	}
+	defer delete(smartcontract.ContractMap, hintedSCAddress)
+
+	newTxn := func(txnType int, from, to string, value int64, funcName string) *transaction.Transaction {
+		txn := &transaction.Transaction{
+			HashIDField:     datastore.HashIDField{Hash: encryption.Hash(from + to + funcName)},
+			ClientID:        from,
+			ToClientID:      to,
+			Value:           value,
+			TransactionType: txnType,
+		}
+		if funcName != "" {
+			data, err := json.Marshal(&sci.SmartContractTransactionData{FunctionName: funcName})
+			require.NoError(t, err)
+			txn.TransactionData = string(data)
+		}
+		return txn
+	}
+
+	txns := []*transaction.Transaction{
+		newTxn(transaction.TxnTypeSend, "a", "b", 1, ""),
+		newTxn(transaction.TxnTypeSend, "c", "d", 1, ""),
+		newTxn(transaction.TxnTypeData, "e", "", 0, ""),
+		newTxn(transaction.TxnTypeSend, "b", "f", 1, ""),
+		newTxn(transaction.TxnTypeSmartContract, "g", hintedSCAddress, 0, "pool"),
+		newTxn(transaction.TxnTypeSmartContract, "h", hintedSCAddress, 0, "pool"),
+		newTxn(transaction.TxnTypeSmartContract, "i", hintedSCAddress, 0, "serial"),
+		newTxn(transaction.TxnTypeSend, "j", "k", 1, ""),
+		newTxn(transaction.TxnTypeSmartContract, "l", "unknown_sc_address", 0, "pool"),
+	}
+
+	c := &Chain{}
+	require.Equal(t, &block.AccessList{
+		Reads:  []datastore.Key{"a", "b"},
+		Writes: []datastore.Key{"a", "b"},
+	}, c.GetAccessHints(txns[0]))
+	require.Equal(t, &block.AccessList{
+		Reads:  []datastore.Key{"e"},
+		Writes: []datastore.Key{"e"},
+	}, c.GetAccessHints(txns[2]))
+	require.Equal(t, block.NewAccessList(
+		map[datastore.Key]bool{"g": true, encryption.Hash("config"): true},
+		map[datastore.Key]bool{"g": true, encryption.Hash("pool"): true},
+	), c.GetAccessHints(txns[4]))
+	require.Nil(t, c.GetAccessHints(txns[6]), "serial")
+	require.Nil(t, c.GetAccessHints(txns[8]), "unknown smart contract")
+
+	var got [][]int
+	for _, group := range c.GroupTransactions(txns) {
+		var idxs []int
+		for _, txn := range group {
+			for i := range txns {
+				if txns[i] == txn {
+					idxs = append(idxs, i)
+				}
+			}
+		}
+		got = append(got, idxs)
+	}
+	require.Equal(t, [][]int{{0, 1, 2, 4}, {3, 5}, {6}, {7}, {8}}, got)
+}
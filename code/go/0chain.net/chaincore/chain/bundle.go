@@ -0,0 +1,80 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+
+	bcstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+)
+
+// ErrBundleValue - the value of a bundle transaction isn't the total value
+// of its operations
+var ErrBundleValue = common.NewError("invalid_bundle",
+	"Transaction value must be the total value of the bundle operations")
+
+// executeBundle - apply the operations of the bundle transaction in order,
+// each one seeing the changes of the ones before. They all run in the scope
+// of the transaction state, so nothing is committed unless they all succeed.
+// The output is the list of the outputs of the operations.
+func (c *Chain) executeBundle(ctx context.Context, sctx *bcstate.StateContext, txn *transaction.Transaction) (string, error) {
+	bundle, err := transaction.DecodeBundle(txn.TransactionData)
+	if err != nil {
+		return "", err
+	}
+	var total int64
+	for _, op := range bundle.Operations {
+		if total += op.Value; total < 0 {
+			return "", ErrBundleValue
+		}
+	}
+	if total != txn.Value {
+		return "", ErrBundleValue
+	}
+
+	outputs := make([]string, len(bundle.Operations))
+	for i, op := range bundle.Operations {
+		var (
+			ot    = txn.BundleOperation(i, op)
+			osctx = sctx.NewOperationContext(ot)
+		)
+		if outputs[i], err = c.executeOperation(ctx, osctx, ot); err != nil {
+			return "", common.NewErrorf("bundle_operation_failed",
+				"operation %v: %v", i, err)
+		}
+	}
+	buff, err := json.Marshal(outputs)
+	if err != nil {
+		return "", err
+	}
+	return string(buff), nil
+}
+
+// executeOperation - run an operation of a bundle and apply its transfers and
+// mints, the same way as for a transaction of its own
+func (c *Chain) executeOperation(ctx context.Context, osctx *bcstate.StateContext, ot *transaction.Transaction) (output string, err error) {
+	switch ot.TransactionType {
+	case transaction.TxnTypeSmartContract:
+		if output, err = c.ExecuteSmartContract(ctx, ot, osctx); err != nil {
+			return "", err
+		}
+	case transaction.TxnTypeSend:
+		err = osctx.AddTransfer(state.NewTransfer(ot.ClientID, ot.ToClientID,
+			state.Balance(ot.Value)))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// applying the transfers isn't charged, as for a transaction
+	if err = osctx.StopMetering(); err != nil {
+		return "", err
+	}
+	defer osctx.StartMetering()
+	if err = c.applyTransfers(osctx, ot); err != nil {
+		return "", err
+	}
+	return output, nil
+}
@@ -75,6 +75,13 @@ type Config struct {
 
 	ReuseTransactions bool `json:"reuse_txns"` // indicates if transactions from unrelated blocks can be reused
 
+	PipelinedValidation bool `json:"pipelined_validation"` // indicates if block transactions are executed while their signatures are verified
+	ValidationWorkers   int  `json:"validation_workers"`   // max number of transactions verified at a time in the pipelined validation
+
+	OptimisticExecution bool `json:"optimistic_execution"` // indicates if block transactions are executed speculatively in parallel
+	ExecutionWorkers    int  `json:"execution_workers"`    // max number of transactions executed at a time while applying a block
+	StrictAccessMap     bool `json:"strict_access_map"`    // indicates if blocks with missing, over-broad or under-broad access lists are rejected
+
 	ClientSignatureScheme string `json:"client_signature_scheme"` // indicates which signature scheme is being used
 
 	MinActiveSharders    int `json:"min_active_sharders"`    // Minimum active sharders required to validate blocks
@@ -104,6 +111,7 @@ func (conf *Config) Update(cf *minersc.GlobalSettings) {
 	conf.MinActiveReplicators = cf.GetInt(minersc.BlockShardingMinActiveReplicators)
 	conf.ValidationBatchSize = cf.GetInt(minersc.BlockValidationBatchSize)
 	conf.ReuseTransactions = cf.GetBool(minersc.BlockReuseTransactions)
+	conf.StrictAccessMap = cf.GetBool(minersc.BlockStrictAccessMap)
 	conf.MinGenerators = cf.GetInt(minersc.BlockMinGenerators)
 	conf.GeneratorsPercent = cf.GetFloat64(minersc.BlockGeneratorsPercent)
 	conf.RoundRange = cf.GetInt64(minersc.RoundRange)
@@ -65,6 +65,7 @@ func TestUpdate(t *testing.T) {
 						"server_chain.block.proposal.max_wait_time":          "180ms",
 						"server_chain.block.proposal.wait_mode":              "static",
 						"server_chain.block.reuse_txns":                      "false",
+						"server_chain.block.execution.strict_access_map":     "false",
 						"server_chain.block.sharding.min_active_sharders":    "25",
 						"server_chain.block.sharding.min_active_replicators": "25",
 						"server_chain.smart_contract.timeout":                "8000ms",
@@ -156,6 +157,8 @@ server_chain:
     validation:
       batch_size: 1000
     reuse_txns: false
+    execution:
+      strict_access_map: false
     storage:
       provider: blockstore.FSBlockStore # blockstore.FSBlockStore or blockstore.BlockDBStore
   round_range: 10000000
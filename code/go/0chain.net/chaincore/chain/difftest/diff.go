@@ -0,0 +1,170 @@
+package difftest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"0chain.net/core/util"
+)
+
+// NodeDiff - a path of the state trie leading to different nodes or values
+// in the expected and the actual states, the keys are empty where absent
+type NodeDiff struct {
+	Path     string `json:"path"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+func (nd *NodeDiff) String() string {
+	var path = nd.Path
+	if path == "" {
+		path = "<root>"
+	}
+	return fmt.Sprintf("%s: %s -> %s", path, orNone(nd.Expected), orNone(nd.Actual))
+}
+
+func orNone(key string) string {
+	if key == "" {
+		return "none"
+	}
+	return key
+}
+
+// StateDiff - the difference of two states node by node
+type StateDiff struct {
+	// the differing nodes top down, from the roots to the nodes the
+	// tries stop having the same shape at
+	Nodes []*NodeDiff `json:"nodes"`
+	// the changed paths, the values added, removed or changed, by the hash
+	// of the values
+	Paths []*NodeDiff `json:"paths"`
+}
+
+// IsEmpty - whether the states are the same
+func (sd *StateDiff) IsEmpty() bool {
+	return len(sd.Nodes) == 0
+}
+
+func (sd *StateDiff) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d nodes differ:\n", len(sd.Nodes))
+	for _, nd := range sd.Nodes {
+		fmt.Fprintf(&sb, "  %v\n", nd)
+	}
+	fmt.Fprintf(&sb, "%d paths changed:\n", len(sd.Paths))
+	for _, nd := range sd.Paths {
+		fmt.Fprintf(&sb, "  %v\n", nd)
+	}
+	return sb.String()
+}
+
+// DiffStates - compare the state tries node by node. The tries are walked
+// from the roots down the differing nodes only, the subtries with the same
+// key are the same.
+func DiffStates(ctx context.Context, expected, actual util.MerklePatriciaTrieI) (*StateDiff, error) {
+	sd := &StateDiff{}
+	err := sd.walk(ctx, expected, actual, util.Path{}, expected.GetRoot(), actual.GetRoot())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sd.Paths, func(i, j int) bool {
+		return sd.Paths[i].Path < sd.Paths[j].Path
+	})
+	return sd, nil
+}
+
+func (sd *StateDiff) walk(ctx context.Context, expected, actual util.MerklePatriciaTrieI,
+	path util.Path, ek, ak util.Key) error {
+
+	if bytes.Equal(ek, ak) {
+		return nil
+	}
+	sd.Nodes = append(sd.Nodes, &NodeDiff{
+		Path:     string(path),
+		Expected: util.ToHex(ek),
+		Actual:   util.ToHex(ak),
+	})
+
+	en, err := getNode(expected, ek)
+	if err != nil {
+		return err
+	}
+	an, err := getNode(actual, ak)
+	if err != nil {
+		return err
+	}
+	if efn, ok := en.(*util.FullNode); ok {
+		if afn, ok := an.(*util.FullNode); ok {
+			if ev, av := valueHash(efn.Value), valueHash(afn.Value); ev != av {
+				sd.Paths = append(sd.Paths, &NodeDiff{Path: string(path), Expected: ev, Actual: av})
+			}
+			for i, pe := range util.PathElements {
+				npath := append(append(util.Path{}, path...), pe)
+				if err := sd.walk(ctx, expected, actual, npath, efn.Children[i], afn.Children[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	// the tries differ in shape here, compare the values below
+	evs, err := values(ctx, expected, path, ek)
+	if err != nil {
+		return err
+	}
+	avs, err := values(ctx, actual, path, ak)
+	if err != nil {
+		return err
+	}
+	for p, ev := range evs {
+		if av := avs[p]; av != ev {
+			sd.Paths = append(sd.Paths, &NodeDiff{Path: p, Expected: ev, Actual: av})
+		}
+	}
+	for p, av := range avs {
+		if _, ok := evs[p]; !ok {
+			sd.Paths = append(sd.Paths, &NodeDiff{Path: p, Actual: av})
+		}
+	}
+	return nil
+}
+
+func getNode(mpt util.MerklePatriciaTrieI, key util.Key) (util.Node, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+	node, err := mpt.GetNodeDB().GetNode(key)
+	if err != nil {
+		return nil, fmt.Errorf("get node %s: %v", util.ToHex(key), err)
+	}
+	return node, nil
+}
+
+func valueHash(vn *util.ValueNode) string {
+	if vn == nil || !vn.HasValue() {
+		return ""
+	}
+	return vn.GetHash()
+}
+
+// values - the hashes of the values of the subtrie by their full paths
+func values(ctx context.Context, mpt util.MerklePatriciaTrieI, path util.Path,
+	key util.Key) (map[string]string, error) {
+
+	var vs = make(map[string]string)
+	if len(key) == 0 {
+		return vs, nil
+	}
+	handler := func(ctx context.Context, vpath util.Path, _ util.Key, node util.Node) error {
+		vs[string(path)+string(vpath)] = node.GetHash()
+		return nil
+	}
+	if err := mpt.IterateFrom(ctx, key, handler, util.NodeTypeValueNode); err != nil {
+		return nil, err
+	}
+	return vs, nil
+}
@@ -0,0 +1,406 @@
+// Package difftest - the differential tester of the parallel block
+// executors. A block is applied sequentially, the way a generator does,
+// and by the parallel executors with random but reproducible interleavings
+// of their goroutines. The resulting states must be the same, if not the
+// states are compared node by node and the block is shrunk to a minimal set
+// of transactions still reproducing the mismatch.
+package difftest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/chain"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+)
+
+// Input - a block to test, the transactions and the state they apply on
+type Input struct {
+	Name  string
+	Round int64
+	Txns  []*transaction.Transaction
+	// the state the transactions are applied on, it's never written to
+	StateDB util.NodeDB
+	Root    util.Key
+}
+
+// Executor - a parallel executor under test
+type Executor struct {
+	Name string
+	New  func(workers int) block.Executor
+}
+
+// DefaultExecutors - the executors the chain applies the blocks with
+func DefaultExecutors() []Executor {
+	return []Executor{
+		{
+			Name: "dag",
+			New:  func(workers int) block.Executor { return block.NewDAGExecutor(workers) },
+		},
+		{
+			Name: "optimistic",
+			New:  func(workers int) block.Executor { return block.NewOptimisticExecutor(workers) },
+		},
+	}
+}
+
+// Options - the options of the tester
+type Options struct {
+	Seed int64
+	// the number of runs of each executor per block, each run with its
+	// own interleaving
+	Trials int
+	// the most workers of a run, each run has a random number of workers
+	Workers int
+	// the longest random delay before a transaction is executed or merged
+	MaxDelay time.Duration
+	// shrink the failing blocks to a minimal failing set of transactions
+	Shrink    bool
+	Executors []Executor
+}
+
+// Mismatch - a parallel run not ending up with the sequential state
+type Mismatch struct {
+	Executor string `json:"executor"`
+	// the seed of the interleaving and the number of workers of the run,
+	// enough to replay it
+	Seed    int64  `json:"seed"`
+	Workers int    `json:"workers"`
+	Error   string `json:"error,omitempty"`
+	// the difference from the sequential state if the run succeeded
+	Diff *StateDiff `json:"diff,omitempty"`
+}
+
+func (m *Mismatch) String() string {
+	if m.Error != "" {
+		return fmt.Sprintf("%s (seed %d, %d workers) failed: %s",
+			m.Executor, m.Seed, m.Workers, m.Error)
+	}
+	return fmt.Sprintf("%s (seed %d, %d workers) state mismatch, %v",
+		m.Executor, m.Seed, m.Workers, m.Diff)
+}
+
+// Report - the outcome of testing a block
+type Report struct {
+	Name string `json:"name"`
+	Txns int    `json:"txns"`
+	// the number of transactions failed sequentially, they are excluded
+	// from the block as a generator does
+	Excluded int `json:"excluded"`
+	// the number of transactions the DAG executor starts with, those not
+	// depending on any other, a block of one root is executed in order
+	Roots      int         `json:"roots"`
+	Runs       int         `json:"runs"`
+	Mismatches []*Mismatch `json:"mismatches,omitempty"`
+	// the minimal set of the transactions of the block reproducing the
+	// first mismatch, if shrinking
+	Minimal []*transaction.Transaction `json:"minimal,omitempty"`
+}
+
+// Failed - whether any of the parallel runs didn't match the sequential one
+func (r *Report) Failed() bool {
+	return len(r.Mismatches) > 0
+}
+
+// Tester - the differential tester of the parallel executors
+type Tester struct {
+	opts  Options
+	chain *chain.Chain
+}
+
+// NewChain - create a chain to execute the transactions with, the smart
+// contracts to execute must be registered separately
+func NewChain() *chain.Chain {
+	c := chain.Provider().(*chain.Chain)
+	c.SmartContractTimeout = time.Minute
+	c.SetSignatureScheme("ed25519")
+	return c
+}
+
+// NewTester - create a new tester executing the transactions with the
+// given chain, the default executors are tested if none are given
+func NewTester(c *chain.Chain, opts Options) *Tester {
+	if opts.Trials <= 0 {
+		opts.Trials = 1
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if len(opts.Executors) == 0 {
+		opts.Executors = DefaultExecutors()
+	}
+	return &Tester{opts: opts, chain: c}
+}
+
+// Run - test the block of the input
+func (t *Tester) Run(ctx context.Context, in *Input) (*Report, error) {
+	seq, included, accessMap, err := t.sequential(ctx, in, in.Txns)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{
+		Name:     in.Name,
+		Txns:     len(in.Txns),
+		Excluded: len(in.Txns) - len(included),
+		Roots:    len(block.NewBlockTxnGraph(t.newParallelBlock(in, included, accessMap)).Roots()),
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(in.Name))
+	rnd := rand.New(rand.NewSource(t.opts.Seed ^ int64(h.Sum64())))
+	for trial := 0; trial < t.opts.Trials; trial++ {
+		for _, ex := range t.opts.Executors {
+			seed, workers := rnd.Int63(), t.randomWorkers(rnd)
+			m, err := t.parallel(ctx, in, ex, seed, workers, seq, included, accessMap)
+			if err != nil {
+				return nil, err
+			}
+			report.Runs++
+			if m != nil {
+				report.Mismatches = append(report.Mismatches, m)
+			}
+		}
+	}
+
+	if report.Failed() && t.opts.Shrink {
+		if report.Minimal, err = t.shrink(ctx, in, report.Mismatches[0], included); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// randomWorkers - at least two workers, so the transactions can interleave
+func (t *Tester) randomWorkers(rnd *rand.Rand) int {
+	if t.opts.Workers < 2 {
+		return t.opts.Workers
+	}
+	return 2 + rnd.Intn(t.opts.Workers-1)
+}
+
+func (t *Tester) newBlock(in *Input) *block.Block {
+	b := &block.Block{PrevBlock: &block.Block{}}
+	b.Round = in.Round
+	b.CreateState(in.StateDB, in.Root)
+	return b
+}
+
+// newParallelBlock - the block of the transactions applied sequentially
+// with their versioned access map, as a generator makes it
+func (t *Tester) newParallelBlock(in *Input, txns []*transaction.Transaction,
+	accessMap map[datastore.Key]*block.AccessList) *block.Block {
+
+	b := t.newBlock(in)
+	b.Txns, b.AccessMap = txns, accessMap
+	b.AccessMapVersion = block.AccessMapVersion
+	return b
+}
+
+// sequential - apply the transactions one by one, keeping the successful
+// ones only along with what they accessed
+func (t *Tester) sequential(ctx context.Context, in *Input, txns []*transaction.Transaction) (
+	*block.Block, []*transaction.Transaction, map[datastore.Key]*block.AccessList, error) {
+
+	var (
+		b         = t.newBlock(in)
+		included  = make([]*transaction.Transaction, 0, len(txns))
+		accessMap = make(map[datastore.Key]*block.AccessList)
+	)
+	if _, err := b.ClientState.GetNodeDB().GetNode(in.Root); err != nil {
+		return nil, nil, nil, fmt.Errorf("%s: missing state root %s: %v",
+			in.Name, util.ToHex(in.Root), err)
+	}
+	for _, txn := range txns {
+		if datastore.IsEmpty(txn.ClientID) {
+			txn.ComputeClientID()
+		}
+		rset, wset, err := t.chain.UpdateState(ctx, b, txn)
+		if err != nil {
+			continue
+		}
+		included = append(included, txn)
+		accessMap[txn.GetKey()] = block.NewAccessList(rset, wset)
+	}
+	return b, included, accessMap, nil
+}
+
+// parallel - apply the transactions with the executor, nil if the state
+// matches the sequential one
+func (t *Tester) parallel(ctx context.Context, in *Input, ex Executor, seed int64, workers int,
+	seq *block.Block, txns []*transaction.Transaction,
+	accessMap map[datastore.Key]*block.AccessList) (*Mismatch, error) {
+
+	b := t.newParallelBlock(in, txns, accessMap)
+
+	m := &Mismatch{Executor: ex.Name, Seed: seed, Workers: workers}
+	if err := ex.New(workers).Apply(ctx, b, newInterleaver(t.chain, seed, t.opts.MaxDelay)); err != nil {
+		m.Error = err.Error()
+		return m, nil
+	}
+	if bytes.Equal(b.ClientState.GetRoot(), seq.ClientState.GetRoot()) {
+		return nil, nil
+	}
+	diff, err := DiffStates(ctx, seq.ClientState, b.ClientState)
+	if err != nil {
+		return nil, fmt.Errorf("%s: diff states: %v", in.Name, err)
+	}
+	m.Diff = diff
+	return m, nil
+}
+
+// shrink - delta debug the transactions down to a minimal set still failing
+// with the executor of the mismatch. The runs replay the interleaving of the
+// mismatch first, then try the random ones, since a race doesn't always
+// show up with less transactions the same way.
+func (t *Tester) shrink(ctx context.Context, in *Input, m *Mismatch,
+	txns []*transaction.Transaction) ([]*transaction.Transaction, error) {
+
+	var ex Executor
+	for _, e := range t.opts.Executors {
+		if e.Name == m.Executor {
+			ex = e
+		}
+	}
+
+	fails := func(txns []*transaction.Transaction) (bool, error) {
+		seq, included, accessMap, err := t.sequential(ctx, in, txns)
+		if err != nil || len(included) == 0 {
+			return false, err
+		}
+		var (
+			rnd           = rand.New(rand.NewSource(m.Seed))
+			seed, workers = m.Seed, m.Workers
+		)
+		for trial := 0; trial < t.opts.Trials; trial++ {
+			if trial > 0 {
+				seed, workers = rnd.Int63(), t.randomWorkers(rnd)
+			}
+			mm, err := t.parallel(ctx, in, ex, seed, workers, seq, included, accessMap)
+			if err != nil || mm != nil {
+				return mm != nil, err
+			}
+		}
+		return false, nil
+	}
+
+	var n = 2
+	for len(txns) >= 2 {
+		var (
+			chunks  = split(txns, n)
+			reduced bool
+		)
+		for _, chunk := range chunks {
+			failed, err := fails(chunk)
+			if err != nil {
+				return nil, err
+			}
+			if failed {
+				txns, n, reduced = chunk, 2, true
+				break
+			}
+		}
+		if !reduced && n > 2 {
+			for i := range chunks {
+				complement := make([]*transaction.Transaction, 0, len(txns))
+				for j, chunk := range chunks {
+					if j != i {
+						complement = append(complement, chunk...)
+					}
+				}
+				failed, err := fails(complement)
+				if err != nil {
+					return nil, err
+				}
+				if failed {
+					txns, n, reduced = complement, n-1, true
+					break
+				}
+			}
+		}
+		if reduced {
+			continue
+		}
+		if n >= len(txns) {
+			break
+		}
+		if n *= 2; n > len(txns) {
+			n = len(txns)
+		}
+	}
+	return txns, nil
+}
+
+// split the transactions into n chunks keeping their order
+func split(txns []*transaction.Transaction, n int) [][]*transaction.Transaction {
+	var chunks = make([][]*transaction.Transaction, 0, n)
+	for i := 0; i < n; i++ {
+		from, to := i*len(txns)/n, (i+1)*len(txns)/n
+		if from < to {
+			chunks = append(chunks, txns[from:to])
+		}
+	}
+	return chunks
+}
+
+// interleaver - the chain delaying the executions and the merges of the
+// transactions by random but reproducible amounts of time, so the
+// goroutines of the executors interleave differently from run to run
+type interleaver struct {
+	*chain.Chain
+	seed     int64
+	maxDelay time.Duration
+
+	mutex    sync.Mutex
+	attempts map[string]int
+}
+
+func newInterleaver(c *chain.Chain, seed int64, maxDelay time.Duration) *interleaver {
+	return &interleaver{
+		Chain:    c,
+		seed:     seed,
+		maxDelay: maxDelay,
+		attempts: make(map[string]int),
+	}
+}
+
+// ExecuteTransaction - implement block.Chainer
+func (il *interleaver) ExecuteTransaction(ctx context.Context, b *block.Block,
+	txn *transaction.Transaction) (*block.TxnState, error) {
+
+	il.pause("execute", txn)
+	return il.Chain.ExecuteTransaction(ctx, b, txn)
+}
+
+// MergeTransaction - implement block.Chainer
+func (il *interleaver) MergeTransaction(b *block.Block, ts *block.TxnState) error {
+	il.pause("merge", ts.Txn)
+	return il.Chain.MergeTransaction(b, ts)
+}
+
+// pause for the time given by the seed, the stage, the transaction and the
+// number of times the transaction was at the stage
+func (il *interleaver) pause(stage string, txn *transaction.Transaction) {
+	var key = stage + ":" + txn.Hash
+	il.mutex.Lock()
+	attempt := il.attempts[key]
+	il.attempts[key]++
+	il.mutex.Unlock()
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d:%s:%d", il.seed, key, attempt)
+	delay := time.Duration(h.Sum64() % uint64(il.maxDelay+1))
+	if delay == 0 {
+		runtime.Gosched()
+		return
+	}
+	time.Sleep(delay)
+}
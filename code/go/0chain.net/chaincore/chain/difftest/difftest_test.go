@@ -0,0 +1,213 @@
+package difftest
+
+import (
+	"compress/zlib"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+	"0chain.net/core/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	logging.InitLogging("testing")
+}
+
+func TestTester_Run(t *testing.T) {
+	inputs, err := RandomInputs(1, 3, 20, 60)
+	require.NoError(t, err)
+	require.Len(t, inputs, 3)
+
+	tester := NewTester(NewChain(), Options{
+		Seed:     1,
+		Trials:   4,
+		Workers:  4,
+		MaxDelay: 100 * time.Microsecond,
+		Shrink:   true,
+	})
+	var excluded int
+	for _, in := range inputs {
+		report, err := tester.Run(context.Background(), in)
+		require.NoError(t, err)
+		require.False(t, report.Failed(), "%v", report.Mismatches)
+		require.Equal(t, 8, report.Runs)
+		require.Empty(t, report.Minimal)
+		require.True(t, report.Roots > 1, "the transactions are executed concurrently")
+		excluded += report.Excluded
+	}
+	require.NotZero(t, excluded, "some transactions fail")
+}
+
+func TestRandomInputs(t *testing.T) {
+	a, err := RandomInputs(7, 2, 5, 10)
+	require.NoError(t, err)
+	b, err := RandomInputs(7, 2, 5, 10)
+	require.NoError(t, err)
+	for i := range a {
+		require.Equal(t, a[i].Root, b[i].Root)
+		require.Len(t, a[i].Txns, 10)
+		for j := range a[i].Txns {
+			require.Equal(t, a[i].Txns[j].Hash, b[i].Txns[j].Hash)
+		}
+	}
+}
+
+func TestBlockStoreInputs(t *testing.T) {
+	random, err := RandomInputs(5, 1, 10, 30)
+	require.NoError(t, err)
+
+	var (
+		dir = t.TempDir()
+		b   = &block.Block{}
+	)
+	b.Round, b.Txns = 12, random[0].Txns
+	b.Hash = encryption.Hash("block")
+	var file = filepath.Join(dir, "0", b.Hash[0:3], b.Hash[3:6], b.Hash[6:9],
+		b.Hash[9:]+blockFileExt)
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	w := zlib.NewWriter(f)
+	require.NoError(t, datastore.WriteJSON(w, b))
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	inputs, err := BlockStoreInputs(dir, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, inputs, 1)
+	require.EqualValues(t, 12, inputs[0].Round)
+	require.Len(t, inputs[0].Txns, 30)
+
+	report, err := NewTester(NewChain(), Options{Seed: 5, Trials: 2, Workers: 4}).
+		Run(context.Background(), inputs[0])
+	require.NoError(t, err)
+	require.False(t, report.Failed(), "%v", report.Mismatches)
+	require.Zero(t, report.Excluded, "the clients are funded")
+}
+
+// racyExecutor - executes all the transactions on the block state at once
+// and merges them in order, losing the updates of the conflicting ones
+type racyExecutor struct{}
+
+func (re *racyExecutor) Apply(ctx context.Context, b *block.Block, c block.Chainer) error {
+	var (
+		wg  sync.WaitGroup
+		tss = make([]*block.TxnState, len(b.Txns))
+		ers = make([]error, len(b.Txns))
+	)
+	for i := range b.Txns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tss[i], ers[i] = c.ExecuteTransaction(ctx, b, b.Txns[i])
+		}(i)
+	}
+	wg.Wait()
+	for i, ts := range tss {
+		if ers[i] != nil {
+			return ers[i]
+		}
+		if err := c.MergeTransaction(b, ts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestTester_Shrink(t *testing.T) {
+	inputs, err := RandomInputs(3, 1, 10, 40)
+	require.NoError(t, err)
+
+	tester := NewTester(NewChain(), Options{
+		Seed:    3,
+		Trials:  2,
+		Workers: 4,
+		Shrink:  true,
+		Executors: []Executor{{
+			Name: "racy",
+			New:  func(int) block.Executor { return &racyExecutor{} },
+		}},
+	})
+	report, err := tester.Run(context.Background(), inputs[0])
+	require.NoError(t, err)
+	require.True(t, report.Failed())
+	for _, m := range report.Mismatches {
+		require.Equal(t, "racy", m.Executor)
+		// a transaction spending what it received fails on the block state
+		if m.Error != "" {
+			continue
+		}
+		require.False(t, m.Diff.IsEmpty())
+		require.NotEmpty(t, m.Diff.Paths)
+		require.Equal(t, "", m.Diff.Nodes[0].Path, "the roots differ")
+	}
+
+	// two transactions of the same client are enough to race
+	require.Len(t, report.Minimal, 2)
+	var clients = make(map[string]int)
+	for _, txn := range report.Minimal {
+		clients[txn.ClientID]++
+		clients[txn.ToClientID]++
+	}
+	var shared bool
+	for _, n := range clients {
+		shared = shared || n > 1
+	}
+	require.True(t, shared, "the transactions conflict")
+}
+
+func newTestState(balance int64) *state.State {
+	s := &state.State{Balance: state.Balance(balance)}
+	s.SetTxnHash(strings.Repeat("00", 32))
+	return s
+}
+
+func TestDiffStates(t *testing.T) {
+	var (
+		expected = util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 0, nil)
+		actual   = util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 0, nil)
+		paths    = []string{"0123", "0124", "1234", "5678", "5679", "abcd"}
+	)
+	for i, p := range paths {
+		s := newTestState(int64(i))
+		_, err := expected.Insert(util.Path(p), s)
+		require.NoError(t, err)
+		if p == "5679" {
+			s = newTestState(100)
+		}
+		if p != "abcd" {
+			_, err = actual.Insert(util.Path(p), s)
+			require.NoError(t, err)
+		}
+	}
+	_, err := actual.Insert(util.Path("abce"), newTestState(1))
+	require.NoError(t, err)
+
+	diff, err := DiffStates(context.Background(), expected, actual)
+	require.NoError(t, err)
+	require.False(t, diff.IsEmpty())
+	require.Equal(t, "", diff.Nodes[0].Path)
+
+	var changed []string
+	for _, nd := range diff.Paths {
+		changed = append(changed, nd.Path)
+	}
+	require.Equal(t, []string{"5679", "abcd", "abce"}, changed)
+	require.Empty(t, diff.Paths[1].Actual)
+	require.Empty(t, diff.Paths[2].Expected)
+
+	same, err := DiffStates(context.Background(), expected, expected)
+	require.NoError(t, err)
+	require.True(t, same.IsEmpty())
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"0chain.net/chaincore/chain/difftest"
+	"0chain.net/chaincore/config"
+	"0chain.net/core/logging"
+	"0chain.net/core/util"
+	"0chain.net/core/viper"
+	"0chain.net/sharder/blockstore"
+	"0chain.net/smartcontract/setupsc"
+)
+
+func main() {
+	blockStore := flag.String("blockstore", "", "the sharder block store directory to read the blocks from, random blocks if empty")
+	dictionary := flag.String("dictionary", "", "the zstd dictionary file of the blocks of the block store written with the zstddict codec")
+	stateDir := flag.String("state", "", "the sharder state database directory, for the blocks of the block store to apply on their own states")
+	scConfig := flag.String("sc_config", "", "the smart contracts configuration file, the smart contracts are executed if given")
+	limit := flag.Int("limit", 0, "the most blocks of the block store to test, all if not positive")
+	seed := flag.Int64("seed", 1, "the seed of the random blocks and the interleavings")
+	blocks := flag.Int("blocks", 10, "the number of random blocks")
+	wallets := flag.Int("wallets", 50, "the number of wallets of the random blocks")
+	txns := flag.Int("txns", 200, "the number of transactions of a random block")
+	trials := flag.Int("trials", 10, "the number of runs of each executor per block")
+	workers := flag.Int("workers", 0, "the most workers of a run, the number of CPUs if not positive")
+	maxDelay := flag.Duration("max_delay", 0, "the longest random delay before a transaction is executed or merged")
+	shrink := flag.Bool("shrink", true, "shrink the failing blocks to a minimal set of transactions")
+	jsonOutput := flag.Bool("json", false, "print the reports as JSON")
+	flag.Parse()
+
+	logging.InitLogging("testing")
+
+	var (
+		inputs []*difftest.Input
+		err    error
+	)
+	if *dictionary != "" {
+		dict, err := ioutil.ReadFile(*dictionary)
+		if err != nil {
+			fatal("read dictionary: %v", err)
+		}
+		if _, err = blockstore.AddBlockDictionary(dict); err != nil {
+			fatal("add dictionary: %v", err)
+		}
+	}
+	if *blockStore != "" {
+		var stateDB util.NodeDB
+		if *stateDir != "" {
+			pndb, err := util.NewPNodeDB(*stateDir, os.TempDir())
+			if err != nil {
+				fatal("open state database: %v", err)
+			}
+			stateDB = pndb
+		}
+		inputs, err = difftest.BlockStoreInputs(*blockStore, *limit, stateDB)
+	} else {
+		inputs, err = difftest.RandomInputs(*seed, *blocks, *wallets, *txns)
+	}
+	if err != nil {
+		fatal("read blocks: %v", err)
+	}
+
+	c := difftest.NewChain()
+	if *scConfig != "" {
+		if err := config.SmartContractConfig.ReadConfigFile(*scConfig); err != nil {
+			fatal("read smart contracts configuration: %v", err)
+		}
+		for _, name := range setupsc.SCNames {
+			viper.Set(fmt.Sprintf("development.smart_contract.%v", name), true)
+		}
+		setupsc.SetupSmartContracts()
+	}
+
+	var (
+		ctx    = context.Background()
+		tester = difftest.NewTester(c, difftest.Options{
+			Seed:     *seed,
+			Trials:   *trials,
+			Workers:  *workers,
+			MaxDelay: *maxDelay,
+			Shrink:   *shrink,
+		})
+		failed int
+	)
+	for _, in := range inputs {
+		report, err := tester.Run(ctx, in)
+		if err != nil {
+			fatal("%v", err)
+		}
+		if report.Failed() {
+			failed++
+		}
+		if *jsonOutput {
+			out, err := json.Marshal(report)
+			if err != nil {
+				fatal("%v", err)
+			}
+			fmt.Println(string(out))
+			continue
+		}
+		printReport(report)
+	}
+	if !*jsonOutput {
+		fmt.Printf("%d of %d blocks failed\n", failed, len(inputs))
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printReport(report *difftest.Report) {
+	fmt.Printf("%s: %d transactions, %d excluded, %d roots, %d runs, %d mismatches\n",
+		report.Name, report.Txns, report.Excluded, report.Roots, report.Runs, len(report.Mismatches))
+	for _, m := range report.Mismatches {
+		fmt.Printf("  %v\n", m)
+	}
+	if len(report.Minimal) > 0 {
+		fmt.Printf("  minimal failing set of %d transactions:\n", len(report.Minimal))
+		for _, txn := range report.Minimal {
+			fmt.Printf("    %s %s -> %s value %d\n", txn.Hash, txn.ClientID, txn.ToClientID, txn.Value)
+		}
+	}
+}
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(2)
+}
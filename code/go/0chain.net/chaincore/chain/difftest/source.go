@@ -0,0 +1,249 @@
+package difftest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ed25519"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/chaincore/wallet"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	"0chain.net/core/memorystore"
+	"0chain.net/core/util"
+	"0chain.net/sharder/blockstore"
+)
+
+// the extension of the block files of the sharder block store
+const blockFileExt = ".dat.zlib"
+
+var setupOnce sync.Once
+
+// setupEntities - the wallets create the transactions with the transaction
+// entity metadata
+func setupEntities() {
+	setupOnce.Do(func() {
+		if common.GetRootContext() == nil {
+			common.SetupRootContext(node.GetNodeContext())
+		}
+		if datastore.GetEntityMetadata("txn") == nil {
+			transaction.SetupEntity(memorystore.GetStorageProvider())
+		}
+		wallet.SetupWallet()
+	})
+}
+
+// newWallet - a wallet with the keys generated from the random source
+func newWallet(rnd *rand.Rand) (*wallet.Wallet, error) {
+	seed := make([]byte, ed25519.SeedSize)
+	rnd.Read(seed)
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+
+	scheme := encryption.NewED25519Scheme()
+	keys := hex.EncodeToString(publicKey) + "\n" + hex.EncodeToString(privateKey) + "\n"
+	if err := scheme.ReadKeys(strings.NewReader(keys)); err != nil {
+		return nil, err
+	}
+	w := &wallet.Wallet{}
+	if err := w.SetSignatureScheme(scheme); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// newState - the state with the given balances of the clients
+func newState(balances map[datastore.Key]state.Balance) (util.NodeDB, util.Key, error) {
+	var (
+		mndb = util.NewMemoryNodeDB()
+		mpt  = util.NewMerklePatriciaTrie(mndb, 0, nil)
+		ids  = make([]string, 0, len(balances))
+	)
+	for id := range balances {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		s := &state.State{Balance: balances[id]}
+		s.SetTxnHash(strings.Repeat("00", 32))
+		if _, err := mpt.Insert(util.Path(id), s); err != nil {
+			return nil, nil, err
+		}
+	}
+	return mndb, mpt.GetRoot(), nil
+}
+
+// RandomInputs - generate blocks of random transactions of wallets with
+// random balances, the way the wallet tests do. The wallets send random
+// amounts to each other and to new clients, some more than they have, and
+// store data. Every block applies on the same state with all the wallets.
+// The blocks only depend on the seed.
+func RandomInputs(seed int64, blocks, wallets, txns int) ([]*Input, error) {
+	setupEntities()
+
+	var (
+		rnd      = rand.New(rand.NewSource(seed))
+		ws       = make([]*wallet.Wallet, wallets)
+		balances = make(map[datastore.Key]state.Balance)
+	)
+	for i := range ws {
+		w, err := newWallet(rnd)
+		if err != nil {
+			return nil, err
+		}
+		w.Balance = rnd.Int63n(1000)
+		ws[i] = w
+		balances[w.ClientID] = state.Balance(w.Balance)
+	}
+	stateDB, root, err := newState(balances)
+	if err != nil {
+		return nil, err
+	}
+
+	var inputs = make([]*Input, 0, blocks)
+	for i := 0; i < blocks; i++ {
+		in := &Input{
+			Name:    fmt.Sprintf("random block %d of seed %d", i, seed),
+			Round:   int64(i + 1),
+			StateDB: stateDB,
+			Root:    root,
+		}
+		for j := 0; j < txns; j++ {
+			var (
+				wf  = ws[rnd.Intn(len(ws))]
+				msg = fmt.Sprintf("difftest %d:%d:%d", seed, i, j)
+				txn *transaction.Transaction
+			)
+			switch r := rnd.Float64(); {
+			case r < 0.1:
+				txn = wf.CreateDataTransaction(msg, 0)
+			case r < 0.2:
+				to := encryption.Hash(fmt.Sprintf("new client %d", rnd.Int63()))
+				txn = wf.CreateSendTransaction(to, rnd.Int63n(wf.Balance+1), msg, 0)
+			default:
+				wt := ws[rnd.Intn(len(ws))]
+				for wt == wf && len(ws) > 1 {
+					wt = ws[rnd.Intn(len(ws))]
+				}
+				txn = wf.CreateSendTransaction(wt.ClientID, rnd.Int63n(wf.Balance*5/4+1), msg, 0)
+			}
+			// the same transactions for the same seed
+			txn.CreationDate = common.Timestamp(in.Round)
+			if _, err := txn.Sign(wf.SignatureScheme); err != nil {
+				return nil, err
+			}
+			in.Txns = append(in.Txns, txn)
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, nil
+}
+
+// BlockStoreInputs - read the blocks of a sharder block store directory, at
+// most limit of them by round if positive. A block applies on the state of
+// the previous block if it's in the block store and its state is in the
+// given state database, otherwise on the state funding the clients of the
+// block with what they send. The smart contract transactions need the smart
+// contract states, they fail without and are left out of the block.
+func BlockStoreInputs(dir string, limit int, stateDB util.NodeDB) ([]*Input, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() && strings.HasSuffix(path, blockFileExt) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks = make([]*block.Block, 0, len(files))
+	for _, file := range files {
+		b, err := readBlockFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		blocks = append(blocks, b)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Round != blocks[j].Round {
+			return blocks[i].Round < blocks[j].Round
+		}
+		return blocks[i].Hash < blocks[j].Hash
+	})
+
+	var byHash = make(map[string]*block.Block, len(blocks))
+	for _, b := range blocks {
+		byHash[b.Hash] = b
+	}
+	var inputs []*Input
+	for _, b := range blocks {
+		if limit > 0 && len(inputs) == limit {
+			break
+		}
+		if len(b.Txns) == 0 {
+			continue
+		}
+		in := &Input{
+			Name:  fmt.Sprintf("round %d block %s", b.Round, b.Hash),
+			Round: b.Round,
+			Txns:  b.Txns,
+		}
+		if pb := byHash[b.PrevHash]; pb != nil && stateDB != nil {
+			if _, err := stateDB.GetNode(pb.ClientStateHash); err == nil {
+				in.StateDB, in.Root = stateDB, pb.ClientStateHash
+			}
+		}
+		if in.StateDB == nil {
+			if in.StateDB, in.Root, err = fundingState(b.Txns); err != nil {
+				return nil, err
+			}
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, nil
+}
+
+func readBlockFile(file string) (*block.Block, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = blockstore.DecompressBlock(data); err != nil {
+		return nil, err
+	}
+	b := &block.Block{}
+	if err := datastore.ReadJSON(bytes.NewReader(data), b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// fundingState - the state with the clients of the transactions having
+// enough to send what they send
+func fundingState(txns []*transaction.Transaction) (util.NodeDB, util.Key, error) {
+	var balances = make(map[datastore.Key]state.Balance)
+	for _, txn := range txns {
+		if datastore.IsEmpty(txn.ClientID) {
+			txn.ComputeClientID()
+		}
+		balances[txn.ClientID] += state.Balance(txn.Value + txn.Fee)
+	}
+	return newState(balances)
+}
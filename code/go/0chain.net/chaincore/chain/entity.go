@@ -16,6 +16,7 @@ import (
 	"0chain.net/chaincore/config"
 	"0chain.net/chaincore/node"
 	"0chain.net/chaincore/round"
+	"0chain.net/chaincore/snapshot"
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
@@ -133,6 +134,14 @@ type Chain struct {
 
 	// syncStateTimeout is the timeout for syncing a MPT state from network
 	syncStateTimeout time.Duration
+	// stateArchive indicates the client state is never pruned
+	stateArchive bool
+	// snapshotConfig is the state snapshots configuration
+	snapshotConfig StateSnapshotConfig
+	// snapshotStore keeps the state snapshots taken, nil if none are taken
+	snapshotStore *snapshot.Store
+	// snapshotC queues the finalized blocks to take a state snapshot of
+	snapshotC chan *block.Block
 	// bcStuckCheckInterval represents the BC stuck checking period
 	bcStuckCheckInterval time.Duration
 	// bcStuckTimeThreshold is the threshold time for checking if a BC is stuck
@@ -149,6 +158,7 @@ type Chain struct {
 	viewChanger                  ViewChanger
 	afterFetcher                 AfterFetcher
 	magicBlockSaver              MagicBlockSaver
+	roundSummaryGetter           RoundSummaryGetter
 
 	pruneStats *util.PruneStats
 
@@ -186,6 +196,17 @@ func (c *Chain) SetSyncStateTimeout(syncStateTimeout time.Duration) {
 	c.syncStateTimeout = syncStateTimeout
 }
 
+// SetStateArchive turns the client state pruning off, so the state of any
+// past round can be queried
+func (c *Chain) SetStateArchive(archive bool) {
+	c.stateArchive = archive
+}
+
+// IsStateArchive returns true if the client state is never pruned
+func (c *Chain) IsStateArchive() bool {
+	return c.stateArchive
+}
+
 var chainEntityMetadata *datastore.EntityMetadataImpl
 
 func getNodePath(path string) util.Path {
@@ -349,6 +370,11 @@ func NewChainFromConfig() *Chain {
 	chain.ThresholdByStake = viper.GetInt("server_chain.block.consensus.threshold_by_stake")
 	chain.OwnerID = viper.GetString("server_chain.owner")
 	chain.ValidationBatchSize = viper.GetInt("server_chain.block.validation.batch_size")
+	chain.PipelinedValidation = viper.GetBool("server_chain.block.validation.pipelined")
+	chain.ValidationWorkers = viper.GetInt("server_chain.block.validation.workers")
+	chain.OptimisticExecution = viper.GetBool("server_chain.block.execution.optimistic")
+	chain.ExecutionWorkers = viper.GetInt("server_chain.block.execution.workers")
+	chain.StrictAccessMap = viper.GetBool("server_chain.block.execution.strict_access_map")
 	chain.RoundRange = viper.GetInt64("server_chain.round_range")
 	chain.TxnMaxPayload = viper.GetInt("server_chain.transaction.payload.max_size")
 	chain.PruneStateBelowCount = viper.GetInt("server_chain.state.prune_below_count")
@@ -1224,6 +1250,10 @@ func (c *Chain) SetMagicBlockSaver(mbs MagicBlockSaver) {
 	c.magicBlockSaver = mbs
 }
 
+func (c *Chain) SetRoundSummaryGetter(rsg RoundSummaryGetter) {
+	c.roundSummaryGetter = rsg
+}
+
 //GetPruneStats - get the current prune stats
 func (c *Chain) GetPruneStats() *util.PruneStats {
 	return c.pruneStats
@@ -1243,6 +1273,18 @@ func (c *Chain) InitBlockState(b *block.Block) (err error) {
 			zap.String("state", util.ToHex(b.ClientStateHash)),
 			zap.Error(err))
 
+		if err == util.ErrNodeNotFound && c.snapshotConfig.Sync {
+			serr := c.SyncStateFromSnapshot(context.Background(), b)
+			if serr == nil {
+				err = b.InitStateDB(c.stateDB)
+				logging.Logger.Info("init block state by state snapshot",
+					zap.Int64("round", b.Round), zap.Error(err))
+				return
+			}
+			logging.Logger.Error("init block state by state snapshot failed",
+				zap.Int64("round", b.Round), zap.Error(serr))
+		}
+
 		if err == util.ErrNodeNotFound {
 			// get state from network
 			logging.Logger.Info("init block state by synching block state from network")
@@ -130,6 +130,8 @@ func GetBlockResponse(b *block.Block, contentParts []string) (interface{}, error
 			data["header"] = b.GetSummary()
 		case "merkle_tree":
 			data["merkle_tree"] = b.GetMerkleTree().GetTree()
+		case "verification_tickets":
+			data["verification_tickets"] = b.GetVerificationTickets()
 		}
 	}
 	return data, nil
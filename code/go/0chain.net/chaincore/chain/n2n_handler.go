@@ -59,6 +59,9 @@ func SetupX2MRequestors() {
 
 	stateNodesEntityMetadata := datastore.GetEntityMetadata("state_nodes")
 	StateNodesRequestor = node.RequestEntityHandler("/v1/_x2x/state/get_nodes", options, stateNodesEntityMetadata)
+
+	StateSnapshotRequestor = node.RequestEntityHandler("/v1/_x2x/state/snapshot/get", options, datastore.GetEntityMetadata("state_snapshot"))
+	StateSnapshotChunkRequestor = node.RequestEntityHandler("/v1/_x2x/state/snapshot/chunk/get", options, datastore.GetEntityMetadata("state_snapshot_chunk"))
 }
 
 func SetupX2SRequestors() {
@@ -77,6 +80,8 @@ func SetupX2SRequestors() {
 
 func SetupX2XResponders() {
 	http.HandleFunc("/v1/_x2x/state/get_nodes", common.N2NRateLimit(node.ToN2NSendEntityHandler(StateNodesHandler)))
+	http.HandleFunc("/v1/_x2x/state/snapshot/get", common.N2NRateLimit(node.ToN2NSendEntityHandler(StateSnapshotHandler)))
+	http.HandleFunc("/v1/_x2x/state/snapshot/chunk/get", common.N2NRateLimit(node.ToN2NSendEntityHandler(StateSnapshotChunkHandler)))
 }
 
 //StateNodesHandler - return a list of state nodes
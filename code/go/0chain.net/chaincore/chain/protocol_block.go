@@ -282,6 +282,7 @@ func (c *Chain) finalizeBlock(ctx context.Context, fb *block.Block, bsh BlockSta
 		return
 	}
 	c.rebaseState(fb)
+	c.takeStateSnapshot(fb)
 	c.updateFeeStats(fb)
 
 	if fb.MagicBlock != nil {
@@ -1,8 +1,10 @@
 package chain
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"math/bits"
 	"time"
 
 	"errors"
@@ -31,6 +33,14 @@ func init() {
 
 var ErrInsufficientBalance = common.NewError("insufficient_balance", "Balance not sufficient for transfer")
 
+// ErrOldNonce - the client state is past the nonce of the transaction, it or
+// another transaction of the client with the same nonce is applied already
+var ErrOldNonce = common.NewError("invalid_nonce", "Transaction nonce is already used")
+
+// ErrFutureNonce - the transactions of the client with the nonces before the
+// nonce of the transaction aren't applied yet
+var ErrFutureNonce = common.NewError("future_nonce", "Transaction nonce is ahead of the client nonce")
+
 /*ComputeState - compute the state for the block */
 func (c *Chain) ComputeState(ctx context.Context, b *block.Block) error {
 	return c.computeState(ctx, b)
@@ -153,13 +163,8 @@ func (c *Chain) NewStateContext(b *block.Block, s util.MerklePatriciaTrieI,
 }
 
 func (c *Chain) updateState(ctx context.Context, b *block.Block, txn *transaction.Transaction) (rset map[datastore.Key]bool, wset map[datastore.Key]bool, err error) {
-
-	// check if the block's ClientState has root value
-	_, err = b.ClientState.GetNodeDB().GetNode(b.ClientState.GetRoot())
-	if err != nil {
-		return nil, nil, common.NewErrorf("update_state_failed",
-			"block state root is incorrect, block hash: %v, state hash: %v, root: %v, round: %d",
-			b.Hash, b.ClientStateHash, b.ClientState.GetRoot(), b.Round)
+	if err = c.checkStateRoot(b); err != nil {
+		return nil, nil, err
 	}
 
 	var (
@@ -168,12 +173,186 @@ func (c *Chain) updateState(ctx context.Context, b *block.Block, txn *transactio
 		sctx        = c.NewStateContext(b, clientState, txn)
 	)
 
+	if err = c.executeTransaction(ctx, sctx, txn); err != nil {
+		return
+	}
+
+	// commit transaction
+	if err = b.ClientState.MergeMPTChanges(clientState); err != nil {
+		if state.DebugTxn() {
+			logging.Logger.DPanic("update state - merge mpt error",
+				zap.Int64("round", b.Round), zap.String("block", b.Hash),
+				zap.Any("txn", txn), zap.Error(err))
+		}
+
+		logging.Logger.Error("error committing txn", zap.Any("error", err))
+		return
+	}
+
+	if state.DebugTxn() {
+		if err = block.ValidateState(context.TODO(), b, startRoot); err != nil {
+			logging.Logger.DPanic("update state - state validation failure",
+				zap.Any("txn", txn), zap.Error(err))
+		}
+		var os *state.State
+		os, err = c.getState(b.ClientState, c.OwnerID)
+		if err != nil || os == nil || os.Balance == 0 {
+			logging.Logger.DPanic("update state - owner account",
+				zap.Int64("round", b.Round), zap.String("block", b.Hash),
+				zap.Any("txn", txn), zap.Any("os", os), zap.Error(err))
+		}
+	}
+
+	txn.Status = transaction.TxnSuccess
+	b.AddReceipt(newReceipt(sctx, txn, nil))
+	rset, wset = sctx.GetRWSets()
+	return rset, wset, nil
+}
+
+// ExecuteTransaction - execute the transaction against its own copy-on-write
+// view of the block state without committing it. Only a read lock is taken,
+// so the transactions of a contention free batch can be executed concurrently
+// and merged into the block state afterwards with MergeTransaction. The state
+// is returned along with the error when the transaction logic failed.
+func (c *Chain) ExecuteTransaction(ctx context.Context, b *block.Block, txn *transaction.Transaction) (*block.TxnState, error) {
+	c.stateMutex.RLock()
+	defer c.stateMutex.RUnlock()
+
+	if err := c.checkStateRoot(b); err != nil {
+		return nil, err
+	}
+
+	var (
+		clientState = CreateTxnMPT(b.ClientState) // begin transaction
+		sctx        = c.NewStateContext(b, clientState, txn)
+	)
+
+	err := c.executeTransaction(ctx, sctx, txn)
+	rset, wset := sctx.GetRWSets()
+	ts := block.NewTxnState(txn, clientState, rset, wset)
+	if err != nil {
+		// keep what the failed execution accessed, the optimistic
+		// executor needs it to tell whether the failure is final
+		ts.Receipt = newReceipt(sctx, txn, err)
+		return ts, err
+	}
+
+	txn.Status = transaction.TxnSuccess
+	ts.Receipt = newReceipt(sctx, txn, nil)
+	return ts, nil
+}
+
+// GetExecutor - get the executor applying the block transactions to the
+// block state, both produce the state of the sequential execution. The
+// optimistic one doesn't rely on the access map of the block.
+func (c *Chain) GetExecutor() block.Executor {
+	if c.OptimisticExecution {
+		return block.NewOptimisticExecutor(c.ExecutionWorkers)
+	}
+	return block.NewDAGExecutor(c.ExecutionWorkers)
+}
+
+// IsAccessMapStrict - whether the blocks must have an access list matching
+// exactly the keys accessed by each transaction
+func (c *Chain) IsAccessMapStrict() bool {
+	return c.StrictAccessMap
+}
+
+// MergeTransaction - commit the view of a transaction executed by
+// ExecuteTransaction into the block state. When the block state has moved on
+// since the view was created (an earlier transaction of the same batch was
+// merged first), the writes of the transaction are replayed on a fresh view
+// of the current state before merging. This is only correct because the
+// executors never merge a transaction that touches keys written into the
+// block state since its view was created.
+func (c *Chain) MergeTransaction(b *block.Block, ts *block.TxnState) error {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	clientState := ts.State
+	if _, _, _, startRoot := clientState.GetChanges(); !bytes.Equal(startRoot, b.ClientState.GetRoot()) {
+		var err error
+		if clientState, err = rebaseTxnMPT(b.ClientState, ts); err != nil {
+			return err
+		}
+	}
+
+	if err := b.ClientState.MergeMPTChanges(clientState); err != nil {
+		logging.Logger.Error("error committing txn", zap.String("txn", ts.Txn.Hash),
+			zap.Error(err))
+		return err
+	}
+	if ts.Receipt != nil {
+		b.AddReceipt(ts.Receipt)
+	}
+	return nil
+}
+
+// txnFee - the fee taken for the execution of the transaction, the fee of a
+// transaction with a max cost is the price of the max cost, so only the part
+// of it for the cost used is taken
+func txnFee(txn *transaction.Transaction, cost int64) state.Balance {
+	if txn.MaxCost <= 0 || txn.Fee <= 0 || cost >= txn.MaxCost {
+		return state.Balance(txn.Fee)
+	}
+	// fee * cost / max cost without overflowing, cost < max cost
+	hi, lo := bits.Mul64(uint64(txn.Fee), uint64(cost))
+	fee, _ := bits.Div64(hi, lo, uint64(txn.MaxCost))
+	return state.Balance(fee)
+}
+
+// newReceipt - the receipt of the execution of the transaction, with the
+// ones of the operations of a bundle before the ones of the bundle itself
+func newReceipt(sctx *bcstate.StateContext, txn *transaction.Transaction, err error) *transaction.TxnReceipt {
+	r := transaction.NewTransactionReceipt(txn)
+	if err != nil {
+		r.Status, r.Error, r.Output = transaction.TxnFail, err.Error(), ""
+	}
+	r.Cost = sctx.GetCost()
+	add := func(ctx *bcstate.StateContext) {
+		r.Transfers = append(r.Transfers, ctx.GetTransfers()...)
+		r.SignedTransfers = append(r.SignedTransfers, ctx.GetSignedTransfers()...)
+		r.Mints = append(r.Mints, ctx.GetMints()...)
+		r.Logs = append(r.Logs, ctx.GetLogs()...)
+	}
+	for _, op := range sctx.GetOperations() {
+		add(op)
+	}
+	add(sctx)
+	return r
+}
+
+func (c *Chain) checkStateRoot(b *block.Block) error {
+	// check if the block's ClientState has root value
+	if _, err := b.ClientState.GetNodeDB().GetNode(b.ClientState.GetRoot()); err != nil {
+		return common.NewErrorf("update_state_failed",
+			"block state root is incorrect, block hash: %v, state hash: %v, root: %v, round: %d",
+			b.Hash, b.ClientStateHash, b.ClientState.GetRoot(), b.Round)
+	}
+	return nil
+}
+
+// executeTransaction - run the transaction logic and apply the resulting
+// transfers and mints to the state the given context is built on.
+func (c *Chain) executeTransaction(ctx context.Context, sctx *bcstate.StateContext, txn *transaction.Transaction) (err error) {
+	if err = c.checkNonce(sctx, txn); err != nil {
+		return
+	}
+
+	sctx.StartMetering()
+	if err = sctx.ChargeInput(); err != nil {
+		return
+	}
+
 	switch txn.TransactionType {
 
 	case transaction.TxnTypeSmartContract:
 		var output string
 		t := time.Now()
 		if output, err = c.ExecuteSmartContract(ctx, txn, sctx); err != nil {
+			if cerr := sctx.StopMetering(); cerr != nil {
+				err = cerr // the same error whatever the SC made of it
+			}
 			logging.Logger.Error("Error executing the SC", zap.Any("txn", txn),
 				zap.Error(err))
 			return
@@ -192,19 +371,47 @@ func (c *Chain) updateState(ctx context.Context, b *block.Block, txn *transactio
 		if err != nil {
 			return
 		}
+
+	case transaction.TxnTypeBundle:
+		if txn.TransactionOutput, err = c.executeBundle(ctx, sctx, txn); err != nil {
+			if cerr := sctx.StopMetering(); cerr != nil {
+				err = cerr
+			}
+			logging.Logger.Error("Error executing the bundle", zap.Any("txn", txn),
+				zap.Error(err))
+			return
+		}
+
 	default:
 		logging.Logger.Error("Invalid transaction type", zap.Int("txn type", txn.TransactionType))
-		return nil, nil, fmt.Errorf("invalid transaction type: %v", txn.TransactionType)
+		return fmt.Errorf("invalid transaction type: %v", txn.TransactionType)
+	}
+
+	if err = sctx.StopMetering(); err != nil {
+		return
 	}
 
 	if config.DevConfiguration.IsFeeEnabled {
 		err = sctx.AddTransfer(state.NewTransfer(txn.ClientID, minersc.ADDRESS,
-			state.Balance(txn.Fee)))
+			txnFee(txn, sctx.GetCost())))
 		if err != nil {
 			return
 		}
 	}
 
+	if err = c.applyTransfers(sctx, txn); err != nil {
+		return
+	}
+
+	if txn.Nonce != 0 {
+		return c.updateNonce(sctx, txn)
+	}
+	return nil
+}
+
+// applyTransfers - validate the transfers and the mints added to the state
+// context and apply them to its state
+func (c *Chain) applyTransfers(sctx *bcstate.StateContext, txn *transaction.Transaction) (err error) {
 	if err = sctx.Validate(); err != nil {
 		return
 	}
@@ -233,36 +440,42 @@ func (c *Chain) updateState(ctx context.Context, b *block.Block, txn *transactio
 			// return
 		}
 	}
+	return nil
+}
 
-	// commit transaction
-	if err = b.ClientState.MergeMPTChanges(clientState); err != nil {
-		if state.DebugTxn() {
-			logging.Logger.DPanic("update state - merge mpt error",
-				zap.Int64("round", b.Round), zap.String("block", b.Hash),
-				zap.Any("txn", txn), zap.Error(err))
-		}
-
-		logging.Logger.Error("error committing txn", zap.Any("error", err))
-		return
+// checkNonce - the nonce of a transaction with a nonce must be the one after
+// the nonce of the client state, so a transaction can't be applied twice and
+// the transactions of a client are applied in the order of their nonces
+func (c *Chain) checkNonce(sctx bcstate.StateContextI, txn *transaction.Transaction) error {
+	if txn.Nonce == 0 {
+		return nil
 	}
-
-	if state.DebugTxn() {
-		if err = block.ValidateState(context.TODO(), b, startRoot); err != nil {
-			logging.Logger.DPanic("update state - state validation failure",
-				zap.Any("txn", txn), zap.Error(err))
-		}
-		var os *state.State
-		os, err = c.getState(b.ClientState, c.OwnerID)
-		if err != nil || os == nil || os.Balance == 0 {
-			logging.Logger.DPanic("update state - owner account",
-				zap.Int64("round", b.Round), zap.String("block", b.Hash),
-				zap.Any("txn", txn), zap.Any("os", os), zap.Error(err))
-		}
+	s, err := sctx.GetClientState(txn.ClientID)
+	if !isValid(err) {
+		return err
+	}
+	switch {
+	case txn.Nonce <= s.Nonce:
+		return ErrOldNonce
+	case txn.Nonce > s.Nonce+1:
+		return ErrFutureNonce
 	}
+	return nil
+}
 
-	txn.Status = transaction.TxnSuccess
-	rset, wset = sctx.GetRWSets()
-	return rset, wset, nil
+// updateNonce - set the nonce of the client state to the nonce of the
+// transaction, after the transfers of the transaction changed the state
+func (c *Chain) updateNonce(sctx bcstate.StateContextI, txn *transaction.Transaction) error {
+	s, err := sctx.GetClientState(txn.ClientID)
+	if !isValid(err) {
+		return err
+	}
+	if err = sctx.SetStateContext(s); err != nil {
+		return err
+	}
+	s.Nonce = txn.Nonce
+	_, err = sctx.InsertClientTrieNode(txn.ClientID, s)
+	return err
 }
 
 /*
@@ -316,7 +529,9 @@ func (c *Chain) transferAmount(sctx bcstate.StateContextI, fromClient, toClient
 	}
 	sctx.SetStateContext(fs)
 	fs.Balance -= amount
-	if fs.Balance == 0 {
+	// the drained clients with a nonce are kept, their transactions could
+	// be replayed with the nonce starting over otherwise
+	if fs.Balance == 0 && fs.Nonce == 0 {
 		logging.Logger.Info("transfer amount - remove client", zap.Int64("round", b.Round), zap.String("block", b.Hash), zap.String("client", fromClient), zap.Any("txn", txn))
 		_, err = sctx.DeleteClientTrieNode(fromClient)
 	} else {
@@ -407,6 +622,29 @@ func CreateTxnMPT(mpt util.MerklePatriciaTrieI) util.MerklePatriciaTrieI {
 	return tmpt
 }
 
+// rebaseTxnMPT - create a new transaction MPT on top of the current state of
+// mpt and replay the values the transaction wrote to its own view on it.
+// Keys missing from the view were deleted by the transaction.
+func rebaseTxnMPT(mpt util.MerklePatriciaTrieI, ts *block.TxnState) (util.MerklePatriciaTrieI, error) {
+	tmpt := CreateTxnMPT(mpt)
+	for _, key := range ts.WriteKeys() {
+		path := util.Path(key)
+		value, err := ts.State.GetNodeValue(path)
+		switch err {
+		case nil:
+			_, err = tmpt.Insert(path, value)
+		case util.ErrValueNotPresent:
+			if _, err = tmpt.Delete(path); err == util.ErrValueNotPresent {
+				err = nil
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tmpt, nil
+}
+
 func (c *Chain) getState(clientState util.MerklePatriciaTrieI, clientID string) (*state.State, error) {
 	if clientState == nil {
 		return nil, common.NewError("getState", "client state does not exist")
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"0chain.net/core/common"
+)
+
+// the cost of the operations of a transaction on the state context, the
+// cost of a transaction is deterministic, the same on every node
+const (
+	CostGetTrieNode    int64 = 10
+	CostInsertTrieNode int64 = 50
+	CostDeleteTrieNode int64 = 20
+	CostInputByte      int64 = 1
+	CostTransfer       int64 = 30
+)
+
+// ErrCostExceeded - the cost of the transaction execution is over its max cost
+var ErrCostExceeded = common.NewError("cost_exceeded",
+	"transaction execution cost exceeds its max cost")
+
+// meter - the cost charged to a transaction, shared by the operations of a
+// transaction bundle
+type meter struct {
+	metering bool
+	cost     int64
+	maxCost  int64
+	exceeded bool
+}
+
+// StartMetering - start charging the operations on the state context, up to
+// the max cost of the transaction when it has one
+func (sc *StateContext) StartMetering() {
+	sc.meter.metering = true
+	if sc.txn != nil {
+		sc.meter.maxCost = sc.txn.MaxCost
+	}
+}
+
+// StopMetering - stop charging the operations, the error is ErrCostExceeded
+// when the max cost has been exceeded, even if the smart contract ignored
+// the errors of the operations over the limit
+func (sc *StateContext) StopMetering() error {
+	sc.meter.metering = false
+	if sc.meter.exceeded {
+		return ErrCostExceeded
+	}
+	return nil
+}
+
+// Charge - add the cost of an operation, once the max cost is exceeded all
+// the metered operations fail
+func (sc *StateContext) Charge(cost int64) error {
+	m := sc.meter
+	if !m.metering {
+		return nil
+	}
+	if m.exceeded {
+		return ErrCostExceeded
+	}
+	m.cost += cost
+	if m.maxCost > 0 && m.cost > m.maxCost {
+		m.exceeded = true
+		return ErrCostExceeded
+	}
+	return nil
+}
+
+// ChargeInput - charge the bytes of the transaction data
+func (sc *StateContext) ChargeInput() error {
+	return sc.Charge(CostInputByte * int64(len(sc.txn.TransactionData)))
+}
+
+// GetCost - the cost charged so far
+func (sc *StateContext) GetCost() int64 {
+	return sc.meter.cost
+}
@@ -0,0 +1,69 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/util"
+)
+
+func TestStateContext_Cost(t *testing.T) {
+	const client = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d0"
+
+	newContext := func(maxCost int64) *StateContext {
+		mpt := util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 1, nil)
+		txn := &transaction.Transaction{
+			ClientID:        client,
+			TransactionData: "0123456789",
+			MaxCost:         maxCost,
+		}
+		return NewStateContext(&block.Block{}, mpt, &state.Deserializer{},
+			txn, nil, nil, nil, nil)
+	}
+	value := &util.SecureSerializableValue{Buffer: []byte("value")}
+
+	// not charged unless metering
+	sc := newContext(1)
+	_, err := sc.InsertTrieNode("key", value)
+	require.NoError(t, err)
+	require.Zero(t, sc.GetCost())
+
+	// all the operations charged
+	sc = newContext(0)
+	sc.StartMetering()
+	require.NoError(t, sc.ChargeInput())
+	_, err = sc.InsertTrieNode("key", value)
+	require.NoError(t, err)
+	_, err = sc.GetTrieNode("key")
+	require.NoError(t, err)
+	_, err = sc.DeleteTrieNode("key")
+	require.NoError(t, err)
+	_, err = sc.GetClientBalance(client)
+	require.Equal(t, util.ErrValueNotPresent, err)
+	require.NoError(t, sc.AddTransfer(state.NewTransfer(client, "to", 1)))
+	require.NoError(t, sc.StopMetering())
+	require.Equal(t, 10*CostInputByte+CostInsertTrieNode+2*CostGetTrieNode+
+		CostDeleteTrieNode+CostTransfer, sc.GetCost())
+
+	// the max cost exceeded, even if the error is ignored
+	sc = newContext(10*CostInputByte + CostInsertTrieNode)
+	sc.StartMetering()
+	require.NoError(t, sc.ChargeInput())
+	_, err = sc.InsertTrieNode("key", value)
+	require.NoError(t, err)
+	_, err = sc.InsertTrieNode("other", value)
+	require.Equal(t, ErrCostExceeded, err)
+	_, err = sc.GetTrieNode("key")
+	require.Equal(t, ErrCostExceeded, err, "all operations fail once exceeded")
+	require.Equal(t, ErrCostExceeded, sc.AddSignedTransfer(&state.SignedTransfer{
+		Transfer: *state.NewTransfer(client, "to", 1),
+	}))
+	require.Empty(t, sc.GetSignedTransfers(), "not added")
+	require.Equal(t, ErrCostExceeded, sc.StopMetering())
+	_, err = sc.GetTrieNode("other")
+	require.Equal(t, util.ErrValueNotPresent, err, "not inserted")
+}
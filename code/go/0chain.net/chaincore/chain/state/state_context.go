@@ -1,6 +1,8 @@
 package state
 
 import (
+	"encoding/json"
+
 	"0chain.net/chaincore/block"
 	"0chain.net/chaincore/config"
 	"0chain.net/chaincore/state"
@@ -44,11 +46,14 @@ type StateContextI interface {
 	DeleteTrieNode(key datastore.Key) (datastore.Key, error)
 	DeleteClientTrieNode(clientId datastore.Key) (datastore.Key, error)
 	AddTransfer(t *state.Transfer) error
-	AddSignedTransfer(st *state.SignedTransfer)
+	AddSignedTransfer(st *state.SignedTransfer) error
 	AddMint(m *state.Mint) error
 	GetTransfers() []*state.Transfer
 	GetSignedTransfers() []*state.SignedTransfer
 	GetMints() []*state.Mint
+	AddLog(l *transaction.Log)
+	GetLogs() []*transaction.Log
+	EmitEvent(eventType, tag string, payload interface{})
 	Validate() error
 	GetBlockSharders(b *block.Block) []string
 	GetSignatureScheme() encryption.SignatureScheme
@@ -65,6 +70,9 @@ type StateContext struct {
 	transfers                     []*state.Transfer
 	signedTransfers               []*state.SignedTransfer
 	mints                         []*state.Mint
+	logs                          []*transaction.Log
+	meter                         *meter
+	operations                    []*StateContext
 	clientStateDeserializer       state.DeserializerI
 	rset                          map[datastore.Key]bool
 	wset                          map[datastore.Key]bool
@@ -91,6 +99,7 @@ func NewStateContext(
 		state:                         s,
 		txn:                           t,
 		clientStateDeserializer:       csd,
+		meter:                         &meter{},
 		rset:                          make(map[string]bool),
 		wset:                          make(map[string]bool),
 		getSharders:                   getSharderFunc,
@@ -119,14 +128,21 @@ func (sc *StateContext) AddTransfer(t *state.Transfer) error {
 	if t.ClientID != sc.txn.ClientID && t.ClientID != sc.txn.ToClientID {
 		return state.ErrInvalidTransfer
 	}
+	if err := sc.Charge(CostTransfer); err != nil {
+		return err
+	}
 	sc.transfers = append(sc.transfers, t)
 	return nil
 }
 
 //AddSignedTransfer - add the signed transfer
-func (sc *StateContext) AddSignedTransfer(st *state.SignedTransfer) {
+func (sc *StateContext) AddSignedTransfer(st *state.SignedTransfer) error {
 	// Signature on the signed transfer will be checked on call to sc.Validate()
+	if err := sc.Charge(CostTransfer); err != nil {
+		return err
+	}
 	sc.signedTransfers = append(sc.signedTransfers, st)
+	return nil
 }
 
 //AddMint - add the mint
@@ -162,6 +178,35 @@ func (sc *StateContext) GetMints() []*state.Mint {
 	return sc.mints
 }
 
+//AddLog - add a structured event log of the transaction receipt
+func (sc *StateContext) AddLog(l *transaction.Log) {
+	sc.logs = append(sc.logs, l)
+}
+
+//GetLogs - get all the logs
+func (sc *StateContext) GetLogs() []*transaction.Log {
+	return sc.logs
+}
+
+//EmitEvent - emit a structured event of the smart contract as a log of the transaction
+//receipt, the payload is JSON encoded unless it's a string
+func (sc *StateContext) EmitEvent(eventType, tag string, payload interface{}) {
+	var data string
+	switch p := payload.(type) {
+	case nil:
+	case string:
+		data = p
+	default:
+		buff, err := json.Marshal(p)
+		if err != nil {
+			data = err.Error()
+		} else {
+			data = string(buff)
+		}
+	}
+	sc.AddLog(&transaction.Log{Contract: sc.txn.ToClientID, Type: eventType, Tag: tag, Data: data})
+}
+
 //Validate - implement interface
 func (sc *StateContext) Validate() error {
 	var amount state.Balance
@@ -199,6 +244,9 @@ func (sc *StateContext) Validate() error {
 }
 
 func (sc *StateContext) getClientState(clientID string) (*state.State, error) {
+	if err := sc.Charge(CostGetTrieNode); err != nil {
+		return nil, err
+	}
 	s := &state.State{}
 	s.Balance = state.Balance(0)
 	ss, err := sc.state.GetNodeValue(util.Path(clientID))
@@ -206,6 +254,8 @@ func (sc *StateContext) getClientState(clientID string) (*state.State, error) {
 		if err != util.ErrValueNotPresent {
 			return nil, err
 		}
+		// the absence of the client is observed state as well
+		sc.rset[clientID] = true
 		return s, err
 	}
 	sc.rset[clientID] = true
@@ -254,8 +304,11 @@ func (sc *StateContext) GetClientTrieNode(clientId datastore.Key) (util.Serializ
 }
 
 func (sc *StateContext) getTrieNode(key_hash string) (util.Serializable, error) {
+	if err := sc.Charge(CostGetTrieNode); err != nil {
+		return nil, err
+	}
 	value, err := sc.state.GetNodeValue(util.Path(key_hash))
-	if err == nil {
+	if err == nil || err == util.ErrValueNotPresent {
 		sc.rset[key_hash] = true
 	}
 
@@ -272,6 +325,9 @@ func (sc *StateContext) InsertClientTrieNode(clientId datastore.Key, node util.S
 }
 
 func (sc *StateContext) insertTrieNode(key_hash datastore.Key, node util.Serializable) (datastore.Key, error) {
+	if err := sc.Charge(CostInsertTrieNode); err != nil {
+		return "", err
+	}
 	byteKey, err := sc.state.Insert(util.Path(key_hash), node)
 	if err == nil {
 		sc.wset[key_hash] = true
@@ -290,23 +346,48 @@ func (sc *StateContext) DeleteClientTrieNode(clientId datastore.Key) (datastore.
 }
 
 func (sc *StateContext) deleteTrieNode(key_hash datastore.Key) (datastore.Key, error) {
+	if err := sc.Charge(CostDeleteTrieNode); err != nil {
+		return "", err
+	}
 	byteKey, err := sc.state.Delete(util.Path(key_hash))
-
-	if err == nil {
+	switch err {
+	case nil:
 		sc.wset[key_hash] = true
+	case util.ErrValueNotPresent:
+		// nothing was deleted, but the result depends on the key being absent
+		sc.rset[key_hash] = true
 	}
 
 	return datastore.Key(byteKey), err
 }
 
+// NewOperationContext - the context of an operation of a transaction bundle,
+// it shares the state, the read and write sets and the cost meter of the
+// bundle, the transfers, the mints and the logs are its own
+func (sc *StateContext) NewOperationContext(t *transaction.Transaction) *StateContext {
+	op := *sc
+	op.txn = t
+	op.transfers, op.signedTransfers, op.mints, op.logs = nil, nil, nil, nil
+	op.operations = nil
+	sc.operations = append(sc.operations, &op)
+	return &op
+}
+
+// GetOperations - the contexts of the operations of the transaction bundle
+func (sc *StateContext) GetOperations() []*StateContext {
+	return sc.operations
+}
+
 //SetStateContext - set the state context
 func (sc *StateContext) SetStateContext(s *state.State) error {
 	s.SetRound(sc.block.Round)
 	return s.SetTxnHash(sc.txn.Hash)
 }
 
+//GetRWSets - get the MPT paths read and written through this context, keyed
+//the same way as the block access lists
 func (sc *StateContext) GetRWSets() (rset map[datastore.Key]bool, wset map[datastore.Key]bool) {
-	return rset, wset
+	return sc.rset, sc.wset
 }
 
 func (sc *StateContext) GetVersion() util.Sequence {
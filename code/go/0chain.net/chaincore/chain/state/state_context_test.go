@@ -0,0 +1,130 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+func TestStateContext_GetRWSets(t *testing.T) {
+	const (
+		existing = "existing"
+		absent   = "absent"
+		client   = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d0"
+		noClient = "6dba10422e368813802877a85039d3985d96760ed844092319743fb3a76712d1"
+	)
+
+	newState := func(t *testing.T, balance state.Balance) *state.State {
+		s := &state.State{Balance: balance}
+		require.NoError(t, s.SetTxnHash(encryption.Hash("txn")))
+		return s
+	}
+
+	newContext := func(t *testing.T) *StateContext {
+		mpt := util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 1, nil)
+		_, err := mpt.Insert(util.Path(encryption.Hash(existing)),
+			&util.SecureSerializableValue{Buffer: []byte("value")})
+		require.NoError(t, err)
+		_, err = mpt.Insert(util.Path(client), newState(t, 10))
+		require.NoError(t, err)
+		return NewStateContext(&block.Block{}, mpt, &state.Deserializer{},
+			&transaction.Transaction{}, nil, nil, nil, nil)
+	}
+
+	tests := []struct {
+		name  string
+		run   func(t *testing.T, sc *StateContext)
+		wantR []datastore.Key
+		wantW []datastore.Key
+	}{
+		{
+			name: "get existing and absent nodes",
+			run: func(t *testing.T, sc *StateContext) {
+				_, err := sc.GetTrieNode(existing)
+				require.NoError(t, err)
+				_, err = sc.GetTrieNode(absent)
+				require.Equal(t, util.ErrValueNotPresent, err)
+			},
+			wantR: []datastore.Key{encryption.Hash(existing), encryption.Hash(absent)},
+		},
+		{
+			name: "get existing and absent clients",
+			run: func(t *testing.T, sc *StateContext) {
+				_, err := sc.GetClientBalance(client)
+				require.NoError(t, err)
+				_, err = sc.GetClientState(noClient)
+				require.Equal(t, util.ErrValueNotPresent, err)
+				_, err = sc.GetClientTrieNode(noClient)
+				require.Equal(t, util.ErrValueNotPresent, err)
+			},
+			wantR: []datastore.Key{client, noClient},
+		},
+		{
+			name: "insert nodes",
+			run: func(t *testing.T, sc *StateContext) {
+				_, err := sc.InsertTrieNode(absent,
+					&util.SecureSerializableValue{Buffer: []byte("value")})
+				require.NoError(t, err)
+				_, err = sc.InsertClientTrieNode(noClient, newState(t, 1))
+				require.NoError(t, err)
+			},
+			wantW: []datastore.Key{encryption.Hash(absent), noClient},
+		},
+		{
+			name: "delete existing and absent nodes",
+			run: func(t *testing.T, sc *StateContext) {
+				_, err := sc.DeleteTrieNode(existing)
+				require.NoError(t, err)
+				_, err = sc.DeleteClientTrieNode(client)
+				require.NoError(t, err)
+				_, err = sc.DeleteTrieNode(absent)
+				require.Equal(t, util.ErrValueNotPresent, err)
+			},
+			wantR: []datastore.Key{encryption.Hash(absent)},
+			wantW: []datastore.Key{encryption.Hash(existing), client},
+		},
+	}
+
+	toSet := func(keys []datastore.Key) map[datastore.Key]bool {
+		set := make(map[datastore.Key]bool, len(keys))
+		for _, k := range keys {
+			set[k] = true
+		}
+		return set
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := newContext(t)
+			tt.run(t, sc)
+			rset, wset := sc.GetRWSets()
+			require.Equal(t, toSet(tt.wantR), rset)
+			require.Equal(t, toSet(tt.wantW), wset)
+		})
+	}
+}
+
+func TestStateContext_EmitEvent(t *testing.T) {
+	sc := NewStateContext(&block.Block{}, nil, &state.Deserializer{},
+		&transaction.Transaction{ToClientID: "sc"}, nil, nil, nil, nil)
+
+	sc.EmitEvent("text", "tag 1", "payload")
+	sc.EmitEvent("struct", "tag 2", struct {
+		ID     string `json:"id"`
+		Amount int64  `json:"amount"`
+	}{ID: "pool", Amount: 10})
+	sc.EmitEvent("empty", "", nil)
+
+	require.Equal(t, []*transaction.Log{
+		{Contract: "sc", Type: "text", Tag: "tag 1", Data: "payload"},
+		{Contract: "sc", Type: "struct", Tag: "tag 2", Data: `{"id":"pool","amount":10}`},
+		{Contract: "sc", Type: "empty"},
+	}, sc.GetLogs())
+}
@@ -15,6 +15,7 @@ import (
 
 	"0chain.net/core/common"
 	"0chain.net/core/encryption"
+	"0chain.net/core/stateproof"
 	"0chain.net/core/util"
 )
 
@@ -23,6 +24,7 @@ func SetupStateHandlers() {
 	c := GetServerChain()
 	http.HandleFunc("/v1/client/get/balance", common.UserRateLimit(common.ToJSONResponse(c.GetBalanceHandler)))
 	http.HandleFunc("/v1/scstate/get", common.UserRateLimit(common.ToJSONResponse(c.GetNodeFromSCState)))
+	http.HandleFunc("/v1/state/proof", common.UserRateLimit(common.ToJSONResponse(c.GetStateProofHandler)))
 	http.HandleFunc("/v1/scstats/", common.UserRateLimit(c.GetSCStats))
 	http.HandleFunc("/v1/screst/", common.UserRateLimit(c.HandleSCRest))
 	http.HandleFunc("/_smart_contract_stats", common.UserRateLimit(c.SCStats))
@@ -62,11 +64,18 @@ func (c *Chain) GetSCRestOutput(ctx context.Context, r *http.Request) (interface
 	if lfb == nil || lfb.ClientState == nil {
 		return nil, common.NewError("empty_lfb", "empty latest finalized block or state")
 	}
-	clientState := CreateTxnMPT(lfb.ClientState) // begin transaction
-	sctx := c.NewStateContext(lfb, clientState, &transaction.Transaction{})
+	b, err := c.getRequestStateBlock(ctx, r, lfb)
+	if err != nil {
+		return nil, err
+	}
+	clientState := CreateTxnMPT(b.ClientState) // begin transaction
+	sctx := c.NewStateContext(b, clientState, &transaction.Transaction{})
 	resp, err := smartcontract.ExecuteRestAPI(ctx, scAddress, scRestPath, r.URL.Query(), sctx)
 
 	if err != nil {
+		if b != lfb {
+			return nil, roundStateError(err)
+		}
 		return nil, err
 	}
 
@@ -83,10 +92,17 @@ func (c *Chain) GetNodeFromSCState(ctx context.Context, r *http.Request) (interf
 	if lfb.ClientState == nil {
 		return nil, common.NewError("failed to get sc state", "finalized block's state doesn't exist")
 	}
+	b, err := c.getRequestStateBlock(ctx, r, lfb)
+	if err != nil {
+		return nil, err
+	}
 	c.stateMutex.RLock()
 	defer c.stateMutex.RUnlock()
-	node, err := lfb.ClientState.GetNodeValue(util.Path(encryption.Hash(scAddress + key)))
+	node, err := b.ClientState.GetNodeValue(util.Path(encryption.Hash(scAddress + key)))
 	if err != nil {
+		if b != lfb {
+			return nil, roundStateError(err)
+		}
 		return nil, err
 	}
 	if node == nil {
@@ -107,14 +123,63 @@ func (c *Chain) GetBalanceHandler(ctx context.Context, r *http.Request) (interfa
 	if lfb == nil {
 		return nil, common.ErrTemporaryFailure
 	}
-	state, err := c.GetState(lfb, clientID)
+	b, err := c.getRequestStateBlock(ctx, r, lfb)
 	if err != nil {
 		return nil, err
 	}
+	state, err := c.GetState(b, clientID)
+	if err != nil {
+		if b != lfb {
+			return nil, roundStateError(err)
+		}
+		return nil, err
+	}
 	state.ComputeProperties()
 	return state, nil
 }
 
+// StateProofResponse - the proof of a state value against the state root of
+// a finalized block
+type StateProofResponse struct {
+	Round     int64  `json:"round"`
+	BlockHash string `json:"block_hash"`
+	*stateproof.Proof
+}
+
+/*GetStateProofHandler - get the inclusion or non-inclusion proof of the state of a client or of a smart contract key */
+func (c *Chain) GetStateProofHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	var path util.Path
+	if clientID := r.FormValue("client_id"); clientID != "" {
+		path = util.Path(clientID)
+	} else if scAddress := r.FormValue("sc_address"); scAddress != "" {
+		path = util.Path(encryption.Hash(scAddress + r.FormValue("key")))
+	} else {
+		return nil, common.InvalidRequest("missing client_id or sc_address")
+	}
+	lfb := c.GetLatestFinalizedBlock()
+	if lfb == nil || lfb.ClientState == nil {
+		return nil, common.ErrTemporaryFailure
+	}
+	b, err := c.getRequestStateBlock(ctx, r, lfb)
+	if err != nil {
+		return nil, err
+	}
+	c.stateMutex.RLock()
+	defer c.stateMutex.RUnlock()
+	proof, err := stateproof.New(b.ClientState.GetRoot(), path,
+		util.EncodedNodeGetter(b.ClientState.GetNodeDB()))
+	if err != nil {
+		if err == stateproof.ErrInvalidPath {
+			return nil, common.InvalidRequest("invalid client_id")
+		}
+		if b != lfb {
+			return nil, roundStateError(err)
+		}
+		return nil, err
+	}
+	return &StateProofResponse{Round: b.Round, BlockHash: b.Hash, Proof: proof}, nil
+}
+
 func (c *Chain) GetSCStats(w http.ResponseWriter, r *http.Request) {
 	scRestRE := regexp.MustCompile(`/v1/scstats/(.*)`)
 	pathParams := scRestRE.FindStringSubmatch(r.URL.Path)
@@ -1809,3 +1809,56 @@ func TestGetSCRestOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestChain_GetStateProofHandler(t *testing.T) {
+	var (
+		clientID = encryption.Hash("client")
+		ctx      = common.GetRootContext()
+	)
+
+	lfb := block.NewBlock("", 1)
+	lfb.ClientState = util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 1, nil)
+	for i := 0; i < 20; i++ {
+		s := &state.State{Balance: state.Balance(i)}
+		require.NoError(t, s.SetTxnHash(encryption.Hash("txn")))
+		_, err := lfb.ClientState.Insert(util.Path(encryption.Hash(fmt.Sprintf("client %d", i))), s)
+		require.NoError(t, err)
+	}
+	s := &state.State{Balance: 100}
+	require.NoError(t, s.SetTxnHash(encryption.Hash("txn")))
+	_, err := lfb.ClientState.Insert(util.Path(clientID), s)
+	require.NoError(t, err)
+	serverChain := chain.NewChainFromConfig()
+	serverChain.LatestFinalizedBlock = lfb
+
+	get := func(query string) (*chain.StateProofResponse, error) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/state/proof?"+query, nil)
+		resp, err := serverChain.GetStateProofHandler(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return resp.(*chain.StateProofResponse), nil
+	}
+	root := lfb.ClientState.GetRoot()
+
+	resp, err := get("client_id=" + clientID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, resp.Round)
+	value, err := resp.Verify(root, util.Path(clientID))
+	require.NoError(t, err)
+	var got state.State
+	require.NoError(t, got.Decode(value))
+	require.Equal(t, state.Balance(100), got.Balance)
+
+	// a smart contract key not in the state
+	resp, err = get("sc_address=" + storagesc.ADDRESS + "&key=missing")
+	require.NoError(t, err)
+	value, err = resp.Verify(root, util.Path(encryption.Hash(storagesc.ADDRESS+"missing")))
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	_, err = get("")
+	require.Error(t, err)
+	_, err = get("client_id=invalid")
+	require.Error(t, err)
+}
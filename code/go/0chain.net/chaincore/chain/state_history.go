@@ -0,0 +1,101 @@
+package chain
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+	"0chain.net/core/util"
+)
+
+// ErrStatePruned - the state of the requested round isn't kept anymore
+var ErrStatePruned = common.NewError("state_pruned",
+	"state of the round has been pruned, query an archive sharder")
+
+// RoundSummaryGetter represents a node able to find the summary of the
+// finalized block of a past round.
+type RoundSummaryGetter interface {
+	// GetRoundBlockSummary returns the summary of the block finalized in
+	// the round.
+	GetRoundBlockSummary(ctx context.Context, round int64) (
+		*block.BlockSummary, error)
+}
+
+// isStatePruned - the state of the round is below the pruning window of
+// the latest finalized round
+func (c *Chain) isStatePruned(round, lfbRound int64) bool {
+	if c.stateArchive || c.PruneStateBelowCount <= 0 {
+		return false
+	}
+	return round < lfbRound-int64(c.PruneStateBelowCount)
+}
+
+// GetRoundStateBlock returns a block with the client state of the block
+// finalized in the round, read from the state db as long as the round
+// isn't pruned.
+func (c *Chain) GetRoundStateBlock(ctx context.Context, round int64) (
+	*block.Block, error) {
+
+	lfb := c.GetLatestFinalizedBlock()
+	if lfb == nil || lfb.ClientState == nil {
+		return nil, common.ErrTemporaryFailure
+	}
+	if round > lfb.Round {
+		return nil, common.NewErrorf("round_not_finalized",
+			"round %v isn't finalized yet, latest finalized round is %v",
+			round, lfb.Round)
+	}
+	if round == lfb.Round {
+		return lfb, nil
+	}
+	if c.isStatePruned(round, lfb.Round) {
+		return nil, ErrStatePruned
+	}
+	if c.roundSummaryGetter == nil {
+		return nil, common.NewError("state_not_available",
+			"state of past rounds isn't available on this node")
+	}
+	bs, err := c.roundSummaryGetter.GetRoundBlockSummary(ctx, round)
+	if err != nil {
+		return nil, common.NewErrorf("state_not_available",
+			"getting block summary of round %v: %v", round, err)
+	}
+
+	b := block.NewBlock(c.GetKey(), bs.Round)
+	b.Hash = bs.Hash
+	b.ClientStateHash = bs.ClientStateHash
+	if err = b.InitStateDB(c.stateDB); err != nil {
+		return nil, roundStateError(err)
+	}
+	// the state of a finalized round is computed, a value missing from it
+	// is absent rather than not yet computed
+	b.SetStateStatus(block.StateSuccessful)
+	return b, nil
+}
+
+// getRequestStateBlock - the block of the state to query, the latest
+// finalized one unless the request has a round
+func (c *Chain) getRequestStateBlock(ctx context.Context, r *http.Request,
+	lfb *block.Block) (*block.Block, error) {
+
+	rs := r.FormValue("round")
+	if rs == "" {
+		return lfb, nil
+	}
+	round, err := strconv.ParseInt(rs, 10, 64)
+	if err != nil || round < 0 {
+		return nil, common.InvalidRequest("invalid round: " + rs)
+	}
+	return c.GetRoundStateBlock(ctx, round)
+}
+
+// roundStateError - the nodes of the state of a past round missing from the
+// state db have been pruned
+func roundStateError(err error) error {
+	if err == util.ErrNodeNotFound {
+		return ErrStatePruned
+	}
+	return err
+}
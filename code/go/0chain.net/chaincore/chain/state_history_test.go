@@ -0,0 +1,111 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+type testRoundSummaries map[int64]*block.BlockSummary
+
+func (ts testRoundSummaries) GetRoundBlockSummary(_ context.Context, round int64) (
+	*block.BlockSummary, error) {
+
+	if bs, ok := ts[round]; ok {
+		return bs, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestChain_GetRoundStateBlock(t *testing.T) {
+	var (
+		client    = encryption.Hash("client")
+		c         = NewChainFromConfig()
+		ctx       = context.Background()
+		summaries = make(testRoundSummaries)
+	)
+	c.stateDB = util.NewMemoryNodeDB()
+
+	// the states of the rounds 5 to 10 in the state db, the client has the
+	// round number as balance
+	var lfb *block.Block
+	for round := int64(5); round <= 10; round++ {
+		mpt := util.NewMerklePatriciaTrie(c.stateDB, util.Sequence(round), nil)
+		s := &state.State{Balance: state.Balance(round)}
+		require.NoError(t, s.SetTxnHash(encryption.Hash("txn")))
+		_, err := mpt.Insert(util.Path(client), s)
+		require.NoError(t, err)
+
+		bs := &block.BlockSummary{Round: round, ClientStateHash: mpt.GetRoot()}
+		bs.Hash = encryption.Hash(string(rune(round)))
+		summaries[round] = bs
+		lfb = block.NewBlock("", round)
+		lfb.Hash = bs.Hash
+		lfb.ClientStateHash = bs.ClientStateHash
+		require.NoError(t, lfb.InitStateDB(c.stateDB))
+	}
+	c.LatestFinalizedBlock = lfb
+
+	balance := func(round string) (state.Balance, error) {
+		r := httptest.NewRequest("GET", "/v1/client/get/balance?client_id="+client+"&round="+round, nil)
+		resp, err := c.GetBalanceHandler(ctx, r)
+		if err != nil {
+			return 0, err
+		}
+		return resp.(*state.State).Balance, nil
+	}
+
+	b, err := balance("")
+	require.NoError(t, err)
+	require.Equal(t, state.Balance(10), b, "latest finalized state")
+	b, err = balance("10")
+	require.NoError(t, err)
+	require.Equal(t, state.Balance(10), b)
+
+	_, err = balance("7")
+	require.Error(t, err, "no round summary getter")
+
+	c.SetRoundSummaryGetter(summaries)
+	b, err = balance("7")
+	require.NoError(t, err)
+	require.Equal(t, state.Balance(7), b)
+
+	// a client without a state at the round
+	r := httptest.NewRequest("GET", "/v1/client/get/balance?client_id="+
+		encryption.Hash("other")+"&round=7", nil)
+	_, err = c.GetBalanceHandler(ctx, r)
+	require.Equal(t, util.ErrValueNotPresent, err)
+
+	_, err = balance("11")
+	require.Error(t, err, "not finalized round")
+	_, err = balance("x")
+	require.Error(t, err, "invalid round")
+	_, err = balance("4")
+	require.Error(t, err, "no block summary")
+
+	// the rounds below the pruning window
+	c.PruneStateBelowCount = 3
+	_, err = balance("6")
+	require.Equal(t, ErrStatePruned, err)
+	b, err = balance("7")
+	require.NoError(t, err)
+	require.Equal(t, state.Balance(7), b)
+
+	c.SetStateArchive(true)
+	b, err = balance("6")
+	require.NoError(t, err)
+	require.Equal(t, state.Balance(6), b)
+
+	// the state of the round missing from the state db
+	summaries[6].ClientStateHash = util.Key(encryption.RawHash("pruned"))
+	_, err = balance("6")
+	require.Equal(t, ErrStatePruned, err)
+}
@@ -147,6 +147,13 @@ func (c *Chain) pruneClientState(ctx context.Context) {
 		return
 	}
 
+	// the archive nodes keep the state of all the rounds, only the missing
+	// nodes are synced
+	if c.stateArchive {
+		ps.Stage = util.PruneStateCommplete
+		return
+	}
+
 	var t1 = time.Now()
 	ps.Stage = util.PruneStateDelete
 	err = c.stateDB.PruneBelowVersion(pctx, newVersion)
@@ -0,0 +1,335 @@
+package chain
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/snapshot"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+	"0chain.net/core/util"
+	"go.uber.org/zap"
+)
+
+var (
+	// StateSnapshotRequestor - request the manifest of the latest state
+	// snapshot at or before a round.
+	StateSnapshotRequestor node.EntityRequestor
+	// StateSnapshotChunkRequestor - request a chunk of a state snapshot.
+	StateSnapshotChunkRequestor node.EntityRequestor
+)
+
+// StateSnapshotConfig - the state snapshots taken and used by the node
+type StateSnapshotConfig struct {
+	Dir       string // the directory of the snapshots
+	Interval  int64  // take a snapshot every interval rounds, 0 to take none
+	Keep      int    // the number of the latest snapshots kept
+	ChunkSize int    // the size of the chunks, in bytes
+	// Sync restores a missing state from the snapshots of the other nodes
+	Sync         bool
+	SyncParallel int           // the number of chunks downloaded at once
+	SyncTimeout  time.Duration // the timeout of a snapshot sync
+}
+
+// SetupStateSnapshots - set the state snapshots configuration up, the
+// snapshots are taken by the StateSnapshotWorker
+func (c *Chain) SetupStateSnapshots(conf StateSnapshotConfig) (err error) {
+	c.snapshotConfig = conf
+	if conf.Interval <= 0 {
+		return nil
+	}
+	if c.snapshotStore, err = snapshot.NewStore(conf.Dir); err != nil {
+		return err
+	}
+	c.snapshotC = make(chan *block.Block, 1)
+	return nil
+}
+
+// takeStateSnapshot - queue a snapshot of the state of the finalized block
+// if its round is due, a snapshot still being taken skips the round
+func (c *Chain) takeStateSnapshot(fb *block.Block) {
+	if c.snapshotStore == nil || fb.Round%c.snapshotConfig.Interval != 0 {
+		return
+	}
+	select {
+	case c.snapshotC <- fb:
+	default:
+		logging.Logger.Info("state snapshot - skip round, previous one in progress",
+			zap.Int64("round", fb.Round))
+	}
+}
+
+// StateSnapshotWorker - take the state snapshots of the finalized rounds
+func (c *Chain) StateSnapshotWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case fb := <-c.snapshotC:
+			if err := c.saveStateSnapshot(ctx, fb); err != nil {
+				logging.Logger.Error("state snapshot failed",
+					zap.Int64("round", fb.Round), zap.String("block", fb.Hash),
+					zap.Error(err))
+			}
+		}
+	}
+}
+
+func (c *Chain) saveStateSnapshot(ctx context.Context, fb *block.Block) error {
+	if len(fb.ClientStateHash) == 0 {
+		return nil
+	}
+	var (
+		ts  = time.Now()
+		mpt = util.NewMerklePatriciaTrie(c.stateDB, util.Sequence(fb.Round),
+			fb.ClientStateHash)
+	)
+	m, err := snapshot.Export(ctx, mpt, c.snapshotConfig.ChunkSize,
+		c.snapshotStore)
+	if err != nil {
+		return err
+	}
+	m.Round, m.BlockHash = fb.Round, fb.Hash
+	if err = m.Sign(node.Self.Underlying().GetKey(), node.Self.Sign); err != nil {
+		return err
+	}
+	if err = c.snapshotStore.SaveManifest(m); err != nil {
+		return err
+	}
+	if err = c.snapshotStore.Prune(c.snapshotConfig.Keep); err != nil {
+		return err
+	}
+	logging.Logger.Info("state snapshot",
+		zap.Int64("round", m.Round), zap.String("state_hash", m.StateHash),
+		zap.Int64("nodes", m.Nodes), zap.Int("chunks", len(m.Chunks)),
+		zap.Int64("size", m.Size), zap.Duration("duration", time.Since(ts)))
+	return nil
+}
+
+// StateSnapshotHandler - the manifest of the latest state snapshot at or
+// before the round
+func StateSnapshotHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	c := GetServerChain()
+	if c.snapshotStore == nil {
+		return nil, snapshot.ErrNotFound
+	}
+	var round int64
+	if rs := r.FormValue("round"); rs != "" {
+		var err error
+		if round, err = strconv.ParseInt(rs, 10, 64); err != nil {
+			return nil, common.InvalidRequest("invalid round")
+		}
+	}
+	return c.snapshotStore.GetManifest(round)
+}
+
+// StateSnapshotChunkHandler - a chunk of the state snapshots
+func StateSnapshotChunkHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	c := GetServerChain()
+	if c.snapshotStore == nil {
+		return nil, snapshot.ErrNotFound
+	}
+	hash := r.FormValue("hash")
+	data, err := c.snapshotStore.GetChunk(hash)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.NewChunk(hash, data), nil
+}
+
+// SyncStateFromSnapshot - restore the state of the block from the latest
+// state snapshot of the other nodes at or before its round, then compute
+// the state of the blocks after the snapshot
+func (c *Chain) SyncStateFromSnapshot(ctx context.Context, b *block.Block) error {
+	if c.snapshotConfig.SyncTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.snapshotConfig.SyncTimeout)
+		defer cancel()
+	}
+
+	m, peers, err := c.getStateSnapshotManifest(ctx, b.Round)
+	if err != nil {
+		return err
+	}
+	logging.Logger.Info("sync state from snapshot",
+		zap.Int64("round", b.Round), zap.Int64("snapshot_round", m.Round),
+		zap.Int64("nodes", m.Nodes), zap.Int("chunks", len(m.Chunks)),
+		zap.Int("peers", len(peers)))
+
+	sb := b
+	if m.BlockHash != b.Hash {
+		sb, err = c.getFinalizedBlockFromSharders(ctx,
+			&LFBTicket{Round: m.Round, LFBHash: m.BlockHash})
+		if err != nil {
+			return err
+		}
+	}
+	if util.ToHex(sb.ClientStateHash) != m.StateHash {
+		return common.NewErrorf(snapshot.ErrInvalidManifest.Code,
+			"state hash %v of the snapshot doesn't match the block %v",
+			m.StateHash, util.ToHex(sb.ClientStateHash))
+	}
+
+	ts := time.Now()
+	err = snapshot.Restore(ctx, m, c.fetchStateSnapshotChunk(peers),
+		c.snapshotConfig.SyncParallel, c.stateDB)
+	if err != nil {
+		return err
+	}
+	logging.Logger.Info("sync state from snapshot - restored",
+		zap.Int64("snapshot_round", m.Round),
+		zap.Duration("duration", time.Since(ts)))
+	if sb == b {
+		return nil
+	}
+	if err = sb.InitStateDB(c.stateDB); err != nil {
+		return err
+	}
+	return c.replayStateSince(ctx, sb, b)
+}
+
+// getStateSnapshotManifest - the manifest of the latest snapshot at or
+// before the round of the nodes of the current magic block, and the nodes
+// having it
+func (c *Chain) getStateSnapshotManifest(ctx context.Context, round int64) (
+	*snapshot.Manifest, []*node.Node, error) {
+
+	var (
+		mb        = c.GetCurrentMagicBlock()
+		mutex     sync.Mutex
+		manifests = make(map[string]*snapshot.Manifest)
+		peers     = make(map[string][]*node.Node)
+	)
+	handler := func(ctx context.Context, entity datastore.Entity) (interface{}, error) {
+		m, ok := entity.(*snapshot.Manifest)
+		if !ok {
+			return nil, datastore.ErrInvalidEntity
+		}
+		signer := mb.Miners.GetNode(m.NodeID)
+		if signer == nil {
+			signer = mb.Sharders.GetNode(m.NodeID)
+		}
+		if signer == nil {
+			return nil, common.NewErrorf(snapshot.ErrInvalidManifest.Code,
+				"unknown node %v", m.NodeID)
+		}
+		if err := m.Verify(signer.Verify); err != nil {
+			return nil, err
+		}
+		if m.Round > round {
+			return nil, common.NewErrorf(snapshot.ErrInvalidManifest.Code,
+				"snapshot round %v after %v", m.Round, round)
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		manifests[m.ID] = m
+		peers[m.ID] = append(peers[m.ID], signer)
+		return m, nil
+	}
+	params := &url.Values{}
+	params.Add("round", strconv.FormatInt(round, 10))
+	mb.Miners.RequestEntityFromAll(ctx, StateSnapshotRequestor, params, handler)
+	mb.Sharders.RequestEntityFromAll(ctx, StateSnapshotRequestor, params, handler)
+
+	var best *snapshot.Manifest
+	for id, m := range manifests {
+		if best == nil || m.Round > best.Round ||
+			m.Round == best.Round && len(peers[id]) > len(peers[best.ID]) {
+			best = m
+		}
+	}
+	if best == nil {
+		return nil, nil, snapshot.ErrNotFound
+	}
+	return best, peers[best.ID], nil
+}
+
+// fetchStateSnapshotChunk - get a chunk from the nodes having the snapshot,
+// spreading the chunks over the nodes and trying the next node on a failure
+func (c *Chain) fetchStateSnapshotChunk(peers []*node.Node) snapshot.FetchFunc {
+	return func(ctx context.Context, hash string) ([]byte, error) {
+		if !encryption.IsHash(hash) || len(peers) == 0 {
+			return nil, common.NewErrorf(snapshot.ErrNotFound.Code,
+				"no node to give the chunk %q", hash)
+		}
+		var data []byte
+		handler := func(ctx context.Context, entity datastore.Entity) (interface{}, error) {
+			chunk, ok := entity.(*snapshot.Chunk)
+			if !ok {
+				return nil, datastore.ErrInvalidEntity
+			}
+			if err := snapshot.VerifyChunk(hash, chunk.Data); err != nil {
+				return nil, err
+			}
+			data = chunk.Data
+			return chunk, nil
+		}
+		params := &url.Values{}
+		params.Add("hash", hash)
+
+		start := int(hash[0]) % len(peers)
+		for i := range peers {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			peer := peers[(start+i)%len(peers)]
+			if peer.RequestEntityFromNode(ctx, StateSnapshotChunkRequestor,
+				params, handler) && data != nil {
+				return data, nil
+			}
+		}
+		return nil, common.NewErrorf(snapshot.ErrNotFound.Code,
+			"no node gave the chunk %v", hash)
+	}
+}
+
+// replayStateSince - compute and save the state of the blocks after the
+// snapshot block up to the block
+func (c *Chain) replayStateSince(ctx context.Context, sb, b *block.Block) error {
+	var blocks = []*block.Block{b}
+	for pb := b; pb.Round > sb.Round+1; {
+		prev, err := c.GetBlock(ctx, pb.PrevHash)
+		if err != nil {
+			prev, err = c.getFinalizedBlockFromSharders(ctx,
+				&LFBTicket{Round: pb.Round - 1, LFBHash: pb.PrevHash})
+			if err != nil {
+				return err
+			}
+		}
+		blocks = append(blocks, prev)
+		pb = prev
+	}
+	if last := blocks[len(blocks)-1]; last.PrevHash != sb.Hash {
+		return common.NewErrorf("replay_state",
+			"block %v of round %v doesn't follow the snapshot block %v",
+			last.Hash, last.Round, sb.Hash)
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].Round < blocks[j].Round
+	})
+	prev := sb
+	for _, rb := range blocks {
+		rb.SetPreviousBlock(prev)
+		if err := c.ComputeState(ctx, rb); err != nil {
+			return err
+		}
+		if err := c.SaveChanges(ctx, rb); err != nil {
+			return err
+		}
+		prev = rb
+	}
+	logging.Logger.Info("sync state from snapshot - replayed",
+		zap.Int64("snapshot_round", sb.Round), zap.Int64("round", b.Round),
+		zap.Int("blocks", len(blocks)))
+	return nil
+}
@@ -0,0 +1,70 @@
+package chain
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/snapshot"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+func TestChain_StateSnapshot(t *testing.T) {
+	var (
+		c   = NewChainFromConfig()
+		ctx = context.Background()
+		ss  = encryption.NewED25519Scheme()
+	)
+	require.NoError(t, ss.GenerateKeys())
+	node.Self.SetSignatureScheme(ss)
+
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, c.SetupStateSnapshots(StateSnapshotConfig{
+		Dir: dir, Interval: 5, Keep: 1, ChunkSize: 256,
+	}))
+
+	c.stateDB = util.NewMemoryNodeDB()
+	mpt := util.NewMerklePatriciaTrie(c.stateDB, 10, nil)
+	for i := 0; i < 100; i++ {
+		s := &state.State{Balance: state.Balance(i)}
+		require.NoError(t, s.SetTxnHash(encryption.Hash("txn")))
+		_, err := mpt.Insert(util.Path(encryption.Hash(strconv.Itoa(i))), s)
+		require.NoError(t, err)
+	}
+	fb := block.NewBlock("", 10)
+	fb.Hash = encryption.Hash("block")
+	fb.ClientStateHash = mpt.GetRoot()
+
+	c.takeStateSnapshot(block.NewBlock("", 7))
+	assert.Len(t, c.snapshotC, 0, "not a snapshot round")
+	c.takeStateSnapshot(fb)
+	require.Len(t, c.snapshotC, 1)
+	require.NoError(t, c.saveStateSnapshot(ctx, <-c.snapshotC))
+
+	m, err := c.snapshotStore.GetManifest(0)
+	require.NoError(t, err)
+	assert.Equal(t, fb.Round, m.Round)
+	assert.Equal(t, fb.Hash, m.BlockHash)
+	assert.Equal(t, node.Self.Underlying().GetKey(), m.NodeID)
+	require.NoError(t, m.Verify(ss.Verify))
+
+	ndb := util.NewMemoryNodeDB()
+	fetch := func(ctx context.Context, hash string) ([]byte, error) {
+		return c.snapshotStore.GetChunk(hash)
+	}
+	require.NoError(t, snapshot.Restore(ctx, m, fetch, 2, ndb))
+	restored := block.NewBlock("", 10)
+	restored.ClientStateHash = fb.ClientStateHash
+	require.NoError(t, restored.InitStateDB(ndb))
+}
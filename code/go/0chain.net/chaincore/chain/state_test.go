@@ -0,0 +1,477 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	bcstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+func TestChain_MergeTransaction(t *testing.T) {
+	value := func(s string) util.Serializable {
+		return &util.SecureSerializableValue{Buffer: []byte(s)}
+	}
+	txn := func(hash string) *transaction.Transaction {
+		return &transaction.Transaction{HashIDField: datastore.HashIDField{Hash: hash}}
+	}
+
+	newBlock := func() *block.Block {
+		b := block.NewBlock("", 1)
+		b.CreateState(util.NewMemoryNodeDB(), nil)
+		for _, k := range []string{"aa01", "bb01", "cc01"} {
+			_, err := b.ClientState.Insert(util.Path(k), value("initial "+k))
+			require.NoError(t, err)
+		}
+		return b
+	}
+
+	// sequential application of both transactions
+	expected := newBlock()
+	_, err := expected.ClientState.Insert(util.Path("aa01"), value("first"))
+	require.NoError(t, err)
+	_, err = expected.ClientState.Insert(util.Path("dd01"), value("second"))
+	require.NoError(t, err)
+	_, err = expected.ClientState.Delete(util.Path("cc01"))
+	require.NoError(t, err)
+
+	// both transactions run against their own view of the same state
+	b := newBlock()
+	first := CreateTxnMPT(b.ClientState)
+	_, err = first.Insert(util.Path("aa01"), value("first"))
+	require.NoError(t, err)
+
+	second := CreateTxnMPT(b.ClientState)
+	_, err = second.Insert(util.Path("dd01"), value("second"))
+	require.NoError(t, err)
+	_, err = second.Delete(util.Path("cc01"))
+	require.NoError(t, err)
+
+	c := NewChainFromConfig()
+	require.NoError(t, c.MergeTransaction(b, block.NewTxnState(txn("1"), first,
+		nil, map[datastore.Key]bool{"aa01": true})))
+	require.NoError(t, c.MergeTransaction(b, block.NewTxnState(txn("2"), second,
+		nil, map[datastore.Key]bool{"dd01": true, "cc01": true})))
+
+	require.Equal(t, util.ToHex(expected.ClientState.GetRoot()),
+		util.ToHex(b.ClientState.GetRoot()))
+}
+
+func TestChain_transferAmount_RWSets(t *testing.T) {
+	var (
+		from    = encryption.Hash("from client")
+		to      = encryption.Hash("to client")
+		drained = encryption.Hash("drained client")
+	)
+
+	newContext := func(t *testing.T) *bcstate.StateContext {
+		mpt := util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 1, nil)
+		for _, id := range []string{from, drained} {
+			s := &state.State{Balance: 10}
+			require.NoError(t, s.SetTxnHash(encryption.Hash("txn")))
+			_, err := mpt.Insert(util.Path(id), s)
+			require.NoError(t, err)
+		}
+		b := block.NewBlock("", 1)
+		txn := &transaction.Transaction{HashIDField: datastore.HashIDField{
+			Hash: encryption.Hash("transfer")}}
+		return bcstate.NewStateContext(b, mpt, &state.Deserializer{}, txn,
+			nil, nil, nil, nil)
+	}
+
+	tests := []struct {
+		name  string
+		run   func(c *Chain, sctx bcstate.StateContextI) error
+		wantR map[datastore.Key]bool
+		wantW map[datastore.Key]bool
+	}{
+		{
+			name: "transfer to new client",
+			run: func(c *Chain, sctx bcstate.StateContextI) error {
+				return c.transferAmount(sctx, from, to, 5)
+			},
+			wantR: map[datastore.Key]bool{from: true, to: true},
+			wantW: map[datastore.Key]bool{from: true, to: true},
+		},
+		{
+			name: "transfer whole balance",
+			run: func(c *Chain, sctx bcstate.StateContextI) error {
+				return c.transferAmount(sctx, drained, from, 10)
+			},
+			wantR: map[datastore.Key]bool{drained: true, from: true},
+			wantW: map[datastore.Key]bool{drained: true, from: true},
+		},
+		{
+			name: "insufficient balance",
+			run: func(c *Chain, sctx bcstate.StateContextI) error {
+				if err := c.transferAmount(sctx, to, from, 5); err != ErrInsufficientBalance {
+					return err
+				}
+				return nil
+			},
+			wantR: map[datastore.Key]bool{to: true},
+			wantW: map[datastore.Key]bool{},
+		},
+		{
+			name: "mint to new client",
+			run: func(c *Chain, sctx bcstate.StateContextI) error {
+				return c.mintAmount(sctx, to, 5)
+			},
+			wantR: map[datastore.Key]bool{to: true},
+			wantW: map[datastore.Key]bool{to: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sctx := newContext(t)
+			require.NoError(t, tt.run(&Chain{}, sctx))
+			rset, wset := sctx.GetRWSets()
+			require.Equal(t, tt.wantR, rset)
+			require.Equal(t, tt.wantW, wset)
+		})
+	}
+}
+
+func TestOptimisticExecutor(t *testing.T) {
+	const (
+		numClients = 6
+		numTxns    = 120
+	)
+
+	clients := make([]string, numClients)
+	for i := range clients {
+		clients[i] = encryption.Hash(fmt.Sprintf("client %d", i))
+	}
+
+	newBlock := func(t *testing.T) *block.Block {
+		b := block.NewBlock("", 1)
+		b.PrevBlock = block.NewBlock("", 0)
+		b.CreateState(util.NewMemoryNodeDB(), nil)
+		for _, id := range clients {
+			s := &state.State{Balance: 100}
+			require.NoError(t, s.SetTxnHash(encryption.Hash("genesis")))
+			_, err := b.ClientState.Insert(util.Path(id), s)
+			require.NoError(t, err)
+		}
+		return b
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	txns := make([]*transaction.Transaction, numTxns)
+	for i := range txns {
+		from := rnd.Intn(numClients)
+		to := (from + 1 + rnd.Intn(numClients-1)) % numClients
+		txns[i] = &transaction.Transaction{
+			HashIDField:     datastore.HashIDField{Hash: encryption.Hash(fmt.Sprintf("txn %d", i))},
+			ClientID:        clients[from],
+			ToClientID:      clients[to],
+			Value:           int64(1 + rnd.Intn(60)),
+			TransactionType: transaction.TxnTypeSend,
+		}
+	}
+
+	c := NewChainFromConfig()
+	ctx := context.Background()
+
+	// sequential execution, the failing transactions don't make it into the block
+	expected := newBlock(t)
+	var included []*transaction.Transaction
+	for _, txn := range txns {
+		if _, _, err := c.updateState(ctx, expected, txn); err == nil {
+			included = append(included, txn)
+		}
+	}
+	require.NotEmpty(t, included)
+	require.NotEqual(t, len(txns), len(included), "some transfers must fail")
+
+	for _, workers := range []int{1, 4, 16} {
+		t.Run(fmt.Sprintf("fill with %d workers", workers), func(t *testing.T) {
+			b := newBlock(t)
+			var filled []*transaction.Transaction
+			err := block.NewOptimisticExecutor(workers).Fill(ctx, b, c, txns,
+				func(txn *transaction.Transaction, ts *block.TxnState, err error) (bool, bool) {
+					if err != nil {
+						return false, false
+					}
+					filled = append(filled, txn)
+					return true, false
+				})
+			require.NoError(t, err)
+			require.Equal(t, included, filled)
+			require.Equal(t, util.ToHex(expected.ClientState.GetRoot()),
+				util.ToHex(b.ClientState.GetRoot()))
+		})
+
+		t.Run(fmt.Sprintf("apply with %d workers", workers), func(t *testing.T) {
+			b := newBlock(t)
+			b.Txns = included
+			require.NoError(t, block.NewOptimisticExecutor(workers).Apply(ctx, b, c))
+			require.Equal(t, util.ToHex(expected.ClientState.GetRoot()),
+				util.ToHex(b.ClientState.GetRoot()))
+		})
+	}
+
+	t.Run("apply failing block", func(t *testing.T) {
+		b := newBlock(t)
+		b.Txns = txns
+		require.Error(t, block.NewOptimisticExecutor(4).Apply(ctx, b, c))
+	})
+}
+
+func TestChain_updateState_Nonce(t *testing.T) {
+	var (
+		from = encryption.Hash("from client")
+		to   = encryption.Hash("to client")
+		c    = NewChainFromConfig()
+		ctx  = context.Background()
+	)
+
+	b := block.NewBlock("", 1)
+	b.PrevBlock = block.NewBlock("", 0)
+	b.CreateState(util.NewMemoryNodeDB(), nil)
+	s := &state.State{Balance: 10}
+	require.NoError(t, s.SetTxnHash(encryption.Hash("genesis")))
+	_, err := b.ClientState.Insert(util.Path(from), s)
+	require.NoError(t, err)
+
+	send := func(nonce, value int64) *transaction.Transaction {
+		return &transaction.Transaction{
+			HashIDField: datastore.HashIDField{
+				Hash: encryption.Hash(fmt.Sprintf("send %d %d", nonce, value))},
+			ClientID:        from,
+			ToClientID:      to,
+			Value:           value,
+			Nonce:           nonce,
+			TransactionType: transaction.TxnTypeSend,
+		}
+	}
+	nonce := func() int64 {
+		s, err := c.getState(b.ClientState, from)
+		require.NoError(t, err)
+		return s.Nonce
+	}
+
+	_, _, err = c.updateState(ctx, b, send(2, 1))
+	require.Equal(t, ErrFutureNonce, err)
+
+	_, wset, err := c.updateState(ctx, b, send(1, 1))
+	require.NoError(t, err)
+	require.True(t, wset[from])
+	require.EqualValues(t, 1, nonce())
+
+	// a replay or another transaction with the same nonce
+	_, _, err = c.updateState(ctx, b, send(1, 1))
+	require.Equal(t, ErrOldNonce, err)
+	_, _, err = c.updateState(ctx, b, send(1, 2))
+	require.Equal(t, ErrOldNonce, err)
+
+	// a failed transaction doesn't use the nonce
+	_, _, err = c.updateState(ctx, b, send(2, 100))
+	require.Equal(t, ErrInsufficientBalance, err)
+	require.EqualValues(t, 1, nonce())
+
+	// the drained client keeps its nonce
+	_, _, err = c.updateState(ctx, b, send(2, 9))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, nonce())
+	_, _, err = c.updateState(ctx, b, send(1, 1))
+	require.Equal(t, ErrOldNonce, err)
+
+	// the transactions without a nonce aren't checked
+	_, _, err = c.updateState(ctx, b, &transaction.Transaction{
+		HashIDField:     datastore.HashIDField{Hash: encryption.Hash("data")},
+		ClientID:        from,
+		TransactionType: transaction.TxnTypeData,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, nonce())
+}
+
+func TestChain_Receipts(t *testing.T) {
+	var (
+		from = encryption.Hash("from client")
+		to   = encryption.Hash("to client")
+		c    = NewChainFromConfig()
+		ctx  = context.Background()
+	)
+
+	newBlock := func() *block.Block {
+		b := block.NewBlock("", 1)
+		b.PrevBlock = block.NewBlock("", 0)
+		b.CreateState(util.NewMemoryNodeDB(), nil)
+		s := &state.State{Balance: 10}
+		require.NoError(t, s.SetTxnHash(encryption.Hash("genesis")))
+		_, err := b.ClientState.Insert(util.Path(from), s)
+		require.NoError(t, err)
+		return b
+	}
+	send := func(value int64) *transaction.Transaction {
+		return &transaction.Transaction{
+			HashIDField: datastore.HashIDField{
+				Hash: encryption.Hash(fmt.Sprintf("send %d", value))},
+			ClientID:        from,
+			ToClientID:      to,
+			Value:           value,
+			TransactionType: transaction.TxnTypeSend,
+		}
+	}
+
+	// the sequential execution
+	b := newBlock()
+	b.Txns = []*transaction.Transaction{send(1), send(2)}
+	for _, txn := range b.Txns {
+		_, _, err := c.updateState(ctx, b, txn)
+		require.NoError(t, err)
+	}
+	_, _, err := c.updateState(ctx, b, send(100))
+	require.Equal(t, ErrInsufficientBalance, err)
+	require.NoError(t, b.SetReceipts())
+	require.Len(t, b.Receipts, 2)
+	for i, r := range b.Receipts {
+		require.Equal(t, b.Txns[i].Hash, r.TxnHash)
+		require.Equal(t, transaction.TxnSuccess, r.Status)
+		require.Equal(t, []*state.Transfer{
+			state.NewTransfer(from, to, state.Balance(b.Txns[i].Value))}, r.Transfers)
+	}
+
+	// the parallel execution has the same receipts
+	pb := newBlock()
+	pb.Txns = b.Txns
+	for _, txn := range pb.Txns {
+		ts, err := c.ExecuteTransaction(ctx, pb, txn)
+		require.NoError(t, err)
+		require.NoError(t, c.MergeTransaction(pb, ts))
+	}
+	require.NoError(t, pb.SetReceipts())
+	require.Equal(t, b.GetReceiptsMerkleTree().GetRoot(), pb.GetReceiptsMerkleTree().GetRoot())
+
+	// a failed execution has the error in its receipt
+	ts, err := c.ExecuteTransaction(ctx, pb, send(100))
+	require.Equal(t, ErrInsufficientBalance, err)
+	require.Equal(t, transaction.TxnFail, ts.Receipt.Status)
+	require.Equal(t, ErrInsufficientBalance.Error(), ts.Receipt.Error)
+}
+
+func TestChain_Cost(t *testing.T) {
+	var (
+		from = encryption.Hash("from client")
+		to   = encryption.Hash("to client")
+		c    = NewChainFromConfig()
+		ctx  = context.Background()
+	)
+
+	b := block.NewBlock("", 1)
+	b.PrevBlock = block.NewBlock("", 0)
+	b.CreateState(util.NewMemoryNodeDB(), nil)
+	s := &state.State{Balance: 10}
+	require.NoError(t, s.SetTxnHash(encryption.Hash("genesis")))
+	_, err := b.ClientState.Insert(util.Path(from), s)
+	require.NoError(t, err)
+
+	send := func(data string, maxCost int64) *transaction.Transaction {
+		return &transaction.Transaction{
+			HashIDField: datastore.HashIDField{
+				Hash: encryption.Hash(fmt.Sprintf("send %v %d", data, maxCost))},
+			ClientID:        from,
+			ToClientID:      to,
+			Value:           1,
+			TransactionData: data,
+			MaxCost:         maxCost,
+			TransactionType: transaction.TxnTypeSend,
+		}
+	}
+
+	cost := 4*bcstate.CostInputByte + bcstate.CostTransfer
+	ts, err := c.ExecuteTransaction(ctx, b, send("data", cost))
+	require.NoError(t, err)
+	require.Equal(t, cost, ts.Receipt.Cost)
+
+	ts, err = c.ExecuteTransaction(ctx, b, send("data", cost-1))
+	require.Equal(t, bcstate.ErrCostExceeded, err)
+	require.Equal(t, transaction.TxnFail, ts.Receipt.Status)
+	require.Empty(t, ts.Receipt.Transfers)
+
+	// the fee of the cost used
+	txn := send("", 1000)
+	txn.Fee = 500
+	require.Equal(t, state.Balance(500), txnFee(txn, 1000))
+	require.Equal(t, state.Balance(100), txnFee(txn, 200))
+	require.Equal(t, state.Balance(0), txnFee(txn, 1))
+	txn.MaxCost = 0
+	require.Equal(t, state.Balance(500), txnFee(txn, 200), "no max cost")
+}
+
+func TestChain_Bundle(t *testing.T) {
+	var (
+		from = encryption.Hash("from client")
+		to   = encryption.Hash("to client")
+		c    = NewChainFromConfig()
+		ctx  = context.Background()
+	)
+
+	b := block.NewBlock("", 1)
+	b.PrevBlock = block.NewBlock("", 0)
+	b.CreateState(util.NewMemoryNodeDB(), nil)
+	s := &state.State{Balance: 10}
+	require.NoError(t, s.SetTxnHash(encryption.Hash("genesis")))
+	_, err := b.ClientState.Insert(util.Path(from), s)
+	require.NoError(t, err)
+
+	bundle := func(value int64, values ...int64) *transaction.Transaction {
+		var bundle transaction.Bundle
+		for _, v := range values {
+			bundle.Operations = append(bundle.Operations, &transaction.BundleOperation{
+				ToClientID:      to,
+				Value:           v,
+				TransactionType: transaction.TxnTypeSend,
+			})
+		}
+		return &transaction.Transaction{
+			HashIDField: datastore.HashIDField{
+				Hash: encryption.Hash(fmt.Sprintf("bundle %d %v", value, values))},
+			ClientID:        from,
+			ToClientID:      to,
+			Value:           value,
+			TransactionData: bundle.Encode(),
+			TransactionType: transaction.TxnTypeBundle,
+		}
+	}
+	balance := func(id string) state.Balance {
+		s, err := c.getState(b.ClientState, id)
+		if err == util.ErrValueNotPresent {
+			return 0
+		}
+		require.NoError(t, err)
+		return s.Balance
+	}
+
+	// a failed operation rolls back the ones before it
+	_, _, err = c.updateState(ctx, b, bundle(12, 2, 10))
+	require.Error(t, err)
+	require.Equal(t, state.Balance(10), balance(from))
+	require.Equal(t, state.Balance(0), balance(to))
+
+	_, _, err = c.updateState(ctx, b, bundle(5, 2, 2))
+	require.Equal(t, ErrBundleValue, err)
+
+	ts, err := c.ExecuteTransaction(ctx, b, bundle(5, 2, 3))
+	require.NoError(t, err)
+	require.Equal(t, `["",""]`, ts.Txn.TransactionOutput)
+	require.Equal(t, []*state.Transfer{
+		state.NewTransfer(from, to, 2),
+		state.NewTransfer(from, to, 3),
+	}, ts.Receipt.Transfers)
+	require.NoError(t, c.MergeTransaction(b, ts))
+	require.Equal(t, state.Balance(5), balance(from))
+	require.Equal(t, state.Balance(5), balance(to))
+}
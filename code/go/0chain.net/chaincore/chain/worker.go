@@ -30,6 +30,9 @@ func (c *Chain) SetupWorkers(ctx context.Context) {
 	go c.SyncLFBStateWorker(ctx)
 	go c.blockFetcher.StartBlockFetchWorker(ctx, c)
 	go c.StartLFBTicketWorker(ctx, c.GetLatestFinalizedBlock())
+	if c.snapshotStore != nil {
+		go c.StateSnapshotWorker(ctx)
+	}
 	go node.Self.Underlying().MemoryUsage()
 }
 
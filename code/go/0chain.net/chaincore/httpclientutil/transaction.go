@@ -41,6 +41,9 @@ const (
 
 	//TxnTypeSmartContract A smart contract transaction type
 	TxnTypeSmartContract = 1000
+
+	//TxnTypeBundle A bundle of sends and smart contract calls applied all together or not at all
+	TxnTypeBundle = 1002
 )
 
 //SmartContractTxnData Smart Contract Txn Data
@@ -0,0 +1,492 @@
+// Package lightclient follows the chain from a trusted magic block with the
+// notarized block headers only. The magic blocks are walked back from the
+// latest finalized one and verified forward, each one notarized by the
+// miners of the previous one, and the state values are checked with the
+// state proofs against the client state hash of a notarized header. So a
+// single honest sharder is enough, the answers of the sharders don't have to
+// be compared.
+package lightclient
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/encryption"
+	"0chain.net/core/stateproof"
+	"0chain.net/core/util"
+)
+
+const (
+	// DefaultThreshold - the default percent of the miners of a magic block
+	// notarizing a block
+	DefaultThreshold = 66
+	// DefaultSignatureScheme - the default signature scheme of the miners
+	DefaultSignatureScheme = "bls0chain"
+	// DefaultTimeout - the default timeout of a request to a sharder
+	DefaultTimeout = 10 * time.Second
+)
+
+var (
+	// ErrNotNotarized - the block doesn't have enough valid verification
+	// tickets of the miners of its magic block
+	ErrNotNotarized = errors.New("block is not notarized")
+	// ErrInvalidHeader - the block header doesn't match its hash
+	ErrInvalidHeader = errors.New("invalid block header")
+	// ErrInvalidMagicBlock - the magic block doesn't follow the previous one
+	ErrInvalidMagicBlock = errors.New("invalid magic block")
+	// ErrStateNotCovered - the block hash of the header doesn't cover the
+	// client state hash, so the state can't be verified
+	ErrStateNotCovered = errors.New("block header doesn't cover the state")
+)
+
+// Config - the configuration of a light client
+type Config struct {
+	Sharders        []string      // the base URLs of the sharders, tried in order
+	Threshold       int           // the percent of the miners notarizing a block
+	SignatureScheme string        // the signature scheme of the miners
+	Timeout         time.Duration // the timeout of a request to a sharder
+}
+
+// Header - a block header with the verification tickets notarizing it
+type Header struct {
+	*block.BlockSummary
+	VerificationTickets []*block.VerificationTicket `json:"verification_tickets"`
+}
+
+// Client - a light client, it trusts a magic block only and verifies
+// everything else it gets from the sharders
+type Client struct {
+	conf   Config
+	client *http.Client
+
+	mutex sync.RWMutex
+	mbs   []*block.MagicBlock // the verified magic blocks by number
+}
+
+// NewClient - a light client trusting the magic block
+func NewClient(conf Config, trusted *block.MagicBlock) (*Client, error) {
+	if len(conf.Sharders) == 0 {
+		return nil, errors.New("no sharders")
+	}
+	if conf.Threshold == 0 {
+		conf.Threshold = DefaultThreshold
+	}
+	if conf.Threshold < 0 || conf.Threshold > 100 {
+		return nil, fmt.Errorf("invalid threshold: %v", conf.Threshold)
+	}
+	if conf.SignatureScheme == "" {
+		conf.SignatureScheme = DefaultSignatureScheme
+	}
+	if !encryption.IsValidSignatureScheme(conf.SignatureScheme) {
+		return nil, fmt.Errorf("invalid signature scheme: %v", conf.SignatureScheme)
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = DefaultTimeout
+	}
+	if trusted == nil {
+		return nil, fmt.Errorf("%w: no magic block", ErrInvalidMagicBlock)
+	}
+	hash, err := magicBlockHash(trusted)
+	if err != nil {
+		return nil, err
+	}
+	if trusted.Hash == "" {
+		trusted.Hash = hash
+	}
+	if trusted.Hash != hash {
+		return nil, fmt.Errorf("%w: hash mismatch", ErrInvalidMagicBlock)
+	}
+	if err := checkNodeIDs(trusted); err != nil {
+		return nil, err
+	}
+	return &Client{
+		conf:   conf,
+		client: &http.Client{Timeout: conf.Timeout},
+		mbs:    []*block.MagicBlock{trusted},
+	}, nil
+}
+
+// MagicBlock - the latest verified magic block
+func (c *Client) MagicBlock() *block.MagicBlock {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.mbs[len(c.mbs)-1]
+}
+
+// getMagicBlock - the verified magic block of the round, the latest one
+// starting at the round or before
+func (c *Client) getMagicBlock(round int64) *block.MagicBlock {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	i := sort.Search(len(c.mbs), func(i int) bool {
+		return c.mbs[i].StartingRound > round
+	})
+	if i == 0 {
+		return nil
+	}
+	return c.mbs[i-1]
+}
+
+// addMagicBlock - add the next verified magic block
+func (c *Client) addMagicBlock(mb *block.MagicBlock) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if last := c.mbs[len(c.mbs)-1]; mb.MagicBlockNumber == last.MagicBlockNumber+1 {
+		c.mbs = append(c.mbs, mb)
+	}
+}
+
+// SyncMagicBlocks - follow the magic blocks up to the latest finalized one,
+// every magic block is verified against the previous one
+func (c *Client) SyncMagicBlocks(ctx context.Context) error {
+	return c.query(ctx, func(sharder string) error {
+		var lfmb struct {
+			Hash string `json:"hash"`
+		}
+		err := c.get(ctx, sharder, "/v1/block/get/latest_finalized_magic_block", nil, &lfmb)
+		if err != nil {
+			return err
+		}
+
+		// walk back to the magic block following the latest verified one
+		var (
+			latest  = c.MagicBlock()
+			headers []*Header
+			number  int64
+		)
+		for hash := lfmb.Hash; ; {
+			h, err := c.getHeader(ctx, sharder, url.Values{"block": {hash}})
+			if err != nil {
+				return err
+			}
+			if h.MagicBlock == nil {
+				return fmt.Errorf("%w: block %v has no magic block", ErrInvalidMagicBlock, hash)
+			}
+			n := h.MagicBlock.MagicBlockNumber
+			if n <= latest.MagicBlockNumber {
+				if len(headers) == 0 {
+					return nil // up to date
+				}
+				return fmt.Errorf("%w: magic block %v isn't linked", ErrInvalidMagicBlock, number)
+			}
+			if len(headers) > 0 && n >= number {
+				return fmt.Errorf("%w: magic block %v follows %v", ErrInvalidMagicBlock, n, number)
+			}
+			headers, number = append(headers, h), n
+			if n == latest.MagicBlockNumber+1 {
+				break
+			}
+			hash = h.LatestFinalizedMagicBlockHash
+		}
+
+		for i := len(headers) - 1; i >= 0; i-- {
+			if err := c.verifyMagicBlock(headers[i], c.MagicBlock()); err != nil {
+				return err
+			}
+			c.addMagicBlock(headers[i].MagicBlock)
+		}
+		return nil
+	})
+}
+
+// verifyMagicBlock - check the header of the block of the next magic block,
+// notarized by the miners of the previous magic block
+func (c *Client) verifyMagicBlock(h *Header, prev *block.MagicBlock) error {
+	mb := h.MagicBlock
+	hash, err := magicBlockHash(mb)
+	if err != nil {
+		return err
+	}
+	switch {
+	case mb.Hash != hash:
+		return fmt.Errorf("%w: hash mismatch of magic block %v", ErrInvalidMagicBlock, mb.MagicBlockNumber)
+	case mb.MagicBlockNumber != prev.MagicBlockNumber+1:
+		return fmt.Errorf("%w: magic block %v follows %v", ErrInvalidMagicBlock, mb.MagicBlockNumber, prev.MagicBlockNumber)
+	case mb.PreviousMagicBlockHash != prev.Hash:
+		return fmt.Errorf("%w: previous hash mismatch of magic block %v", ErrInvalidMagicBlock, mb.MagicBlockNumber)
+	case h.Round < prev.StartingRound || mb.StartingRound <= h.Round:
+		return fmt.Errorf("%w: magic block %v of round %v starts at %v", ErrInvalidMagicBlock, mb.MagicBlockNumber, h.Round, mb.StartingRound)
+	}
+	if err := checkNodeIDs(mb); err != nil {
+		return err
+	}
+	return c.verifyNotarization(h, prev)
+}
+
+// VerifyHeader - check the header is notarized by the miners of the magic
+// block of its round
+func (c *Client) VerifyHeader(h *Header) error {
+	mb := c.getMagicBlock(h.Round)
+	if mb == nil {
+		return fmt.Errorf("no magic block of round %v", h.Round)
+	}
+	return c.verifyNotarization(h, mb)
+}
+
+// verifyNotarization - check the header hash and that enough miners of the
+// magic block signed it
+func (c *Client) verifyNotarization(h *Header, mb *block.MagicBlock) error {
+	if h.BlockSummary == nil {
+		return ErrInvalidHeader
+	}
+	if h.MagicBlock != nil {
+		if _, err := magicBlockHash(h.MagicBlock); err != nil {
+			return err
+		}
+	}
+	if h.Hash != h.ComputeHash() {
+		return ErrInvalidHeader
+	}
+	var (
+		threshold = c.threshold(mb.Miners.MapSize())
+		signed    = make(map[string]bool, len(h.VerificationTickets))
+	)
+	for _, vt := range h.VerificationTickets {
+		if vt == nil || signed[vt.VerifierID] {
+			continue
+		}
+		miner := mb.Miners.GetNode(vt.VerifierID)
+		if miner == nil {
+			continue
+		}
+		ss := encryption.GetSignatureScheme(c.conf.SignatureScheme)
+		if err := ss.SetPublicKey(miner.PublicKey); err != nil {
+			continue
+		}
+		if ok, err := ss.Verify(vt.Signature, h.Hash); err != nil || !ok {
+			continue
+		}
+		signed[vt.VerifierID] = true
+	}
+	if len(signed) < threshold {
+		return fmt.Errorf("%w: %v of %v signatures of round %v", ErrNotNotarized,
+			len(signed), threshold, h.Round)
+	}
+	return nil
+}
+
+// threshold - the number of signatures notarizing a block out of the miners
+func (c *Client) threshold(miners int) int {
+	t := (miners*c.conf.Threshold + 99) / 100
+	if t < 1 {
+		t = 1
+	}
+	return t
+}
+
+// GetHeader - the verified header of the block finalized in the round, the
+// latest finalized round if the round isn't positive
+func (c *Client) GetHeader(ctx context.Context, round int64) (*Header, error) {
+	var h *Header
+	err := c.query(ctx, func(sharder string) error {
+		r := round
+		if r <= 0 {
+			var lfb block.BlockSummary
+			err := c.get(ctx, sharder, "/v1/block/get/latest_finalized", nil, &lfb)
+			if err != nil {
+				return err
+			}
+			r = lfb.Round
+		}
+		hr, err := c.getHeader(ctx, sharder, url.Values{"round": {strconv.FormatInt(r, 10)}})
+		if err != nil {
+			return err
+		}
+		if hr.Round != r {
+			return fmt.Errorf("%w: round %v instead of %v", ErrInvalidHeader, hr.Round, r)
+		}
+		if hr.LatestFinalizedMagicBlockRound > c.MagicBlock().StartingRound {
+			// the block might be of a magic block not synced yet
+			if err := c.SyncMagicBlocks(ctx); err != nil {
+				return err
+			}
+		}
+		if err := c.VerifyHeader(hr); err != nil {
+			return err
+		}
+		h = hr
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// GetStateValue - the encoded value of the state path at the round, nil if
+// the path has no value, verified against the header of the round
+func (c *Client) GetStateValue(ctx context.Context, path util.Path,
+	params url.Values, round int64) ([]byte, *Header, error) {
+
+	h, err := c.GetHeader(ctx, round)
+	if err != nil {
+		return nil, nil, err
+	}
+	if h.HeaderVersion < 1 {
+		return nil, h, ErrStateNotCovered
+	}
+	query := url.Values{"round": {strconv.FormatInt(h.Round, 10)}}
+	for k, v := range params {
+		query[k] = v
+	}
+	var value []byte
+	err = c.query(ctx, func(sharder string) error {
+		var resp struct {
+			BlockHash string `json:"block_hash"`
+			stateproof.Proof
+		}
+		if err := c.get(ctx, sharder, "/v1/state/proof", query, &resp); err != nil {
+			return err
+		}
+		if resp.BlockHash != h.Hash {
+			return fmt.Errorf("%w: proof of block %v instead of %v",
+				stateproof.ErrInvalidProof, resp.BlockHash, h.Hash)
+		}
+		v, err := resp.Proof.Verify(h.ClientStateHash, path)
+		if err != nil {
+			return err
+		}
+		value = v
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return value, h, nil
+}
+
+// GetClientState - the verified state of the client at the round, the
+// latest finalized round if the round isn't positive
+func (c *Client) GetClientState(ctx context.Context, clientID string,
+	round int64) (*state.State, *Header, error) {
+
+	value, h, err := c.GetStateValue(ctx, util.Path(clientID),
+		url.Values{"client_id": {clientID}}, round)
+	if err != nil {
+		return nil, nil, err
+	}
+	if value == nil {
+		return nil, h, util.ErrValueNotPresent
+	}
+	s := &state.State{}
+	if err := s.Decode(value); err != nil {
+		return nil, nil, err
+	}
+	return s, h, nil
+}
+
+// GetSCState - the verified encoded value of the key of the smart contract
+// at the round, the latest finalized round if the round isn't positive
+func (c *Client) GetSCState(ctx context.Context, scAddress, key string,
+	round int64) ([]byte, *Header, error) {
+
+	path := util.Path(encryption.Hash(scAddress + key))
+	value, h, err := c.GetStateValue(ctx, path,
+		url.Values{"sc_address": {scAddress}, "key": {key}}, round)
+	if err != nil {
+		return nil, nil, err
+	}
+	if value == nil {
+		return nil, h, util.ErrValueNotPresent
+	}
+	return value, h, nil
+}
+
+// getHeader - the unverified header of the block with its verification
+// tickets from the sharder
+func (c *Client) getHeader(ctx context.Context, sharder string,
+	params url.Values) (*Header, error) {
+
+	params.Set("content", "header,verification_tickets")
+	var resp struct {
+		Header  *block.BlockSummary         `json:"header"`
+		Tickets []*block.VerificationTicket `json:"verification_tickets"`
+	}
+	if err := c.get(ctx, sharder, "/v1/block/get", params, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Header == nil {
+		return nil, fmt.Errorf("%w: no header", ErrInvalidHeader)
+	}
+	return &Header{BlockSummary: resp.Header, VerificationTickets: resp.Tickets}, nil
+}
+
+// query - run the query against the sharders in order until one succeeds,
+// a sharder failing the verification is skipped
+func (c *Client) query(ctx context.Context, fn func(sharder string) error) (
+	err error) {
+
+	for _, sharder := range c.conf.Sharders {
+		if err = fn(sharder); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// get - the JSON response of the sharder
+func (c *Client) get(ctx context.Context, sharder, path string,
+	params url.Values, result interface{}) error {
+
+	u := strings.TrimRight(sharder, "/") + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%v: %v: %s", u, resp.Status, body)
+	}
+	return json.Unmarshal(body, result)
+}
+
+// magicBlockHash - the hash of an untrusted magic block, the magic block
+// might miss the parts of the hash
+func magicBlockHash(mb *block.MagicBlock) (string, error) {
+	if mb.Miners == nil || mb.Sharders == nil || mb.Mpks == nil ||
+		mb.GetShareOrSigns() == nil {
+		return "", fmt.Errorf("%w: magic block %v is incomplete",
+			ErrInvalidMagicBlock, mb.MagicBlockNumber)
+	}
+	return mb.GetHash(), nil
+}
+
+// checkNodeIDs - the miners of the magic block have the ids of their public
+// keys, so a ticket can't be signed with another key
+func checkNodeIDs(mb *block.MagicBlock) error {
+	for _, n := range mb.Miners.CopyNodesMap() {
+		pk, err := hex.DecodeString(n.PublicKey)
+		if err != nil || n.ID != encryption.Hash(pk) {
+			return fmt.Errorf("%w: miner %v doesn't match its public key",
+				ErrInvalidMagicBlock, n.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package lightclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/encryption"
+	"0chain.net/core/stateproof"
+	"0chain.net/core/util"
+)
+
+var testClientID = encryption.Hash("client")
+
+func testMiners(t *testing.T, n int) []encryption.SignatureScheme {
+	miners := make([]encryption.SignatureScheme, n)
+	for i := range miners {
+		ss := encryption.NewED25519Scheme()
+		require.NoError(t, ss.GenerateKeys())
+		miners[i] = ss
+	}
+	return miners
+}
+
+func testMagicBlock(number, startingRound int64, prevHash string,
+	miners []encryption.SignatureScheme) *block.MagicBlock {
+
+	mb := block.NewMagicBlock()
+	mb.MagicBlockNumber = number
+	mb.StartingRound = startingRound
+	mb.PreviousMagicBlockHash = prevHash
+	mb.Miners = node.NewPool(node.NodeTypeMiner)
+	mb.Sharders = node.NewPool(node.NodeTypeSharder)
+	for _, ss := range miners {
+		n := &node.Node{Type: node.NodeTypeMiner}
+		n.SetPublicKey(ss.GetPublicKey())
+		mb.Miners.AddNode(n)
+	}
+	mb.Hash = mb.GetHash()
+	return mb
+}
+
+// testHeader - the header notarized by the miners
+func testHeader(t *testing.T, bs *block.BlockSummary,
+	miners []encryption.SignatureScheme) *Header {
+
+	bs.Hash = bs.ComputeHash()
+	h := &Header{BlockSummary: bs}
+	for _, ss := range miners {
+		var n node.Node
+		n.SetPublicKey(ss.GetPublicKey())
+		sig, err := ss.Sign(bs.Hash)
+		require.NoError(t, err)
+		h.VerificationTickets = append(h.VerificationTickets,
+			&block.VerificationTicket{VerifierID: n.ID, Signature: sig})
+	}
+	return h
+}
+
+// testSharder - a sharder serving the headers, the magic blocks and the
+// state proofs of a chain
+type testSharder struct {
+	headers   map[string]*Header
+	rounds    map[int64]string
+	lfmb      string
+	lfb       int64
+	mpt       util.MerklePatriciaTrieI
+	stateHash string // the block of the state
+}
+
+func (ts *testSharder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var resp interface{}
+	switch r.URL.Path {
+	case "/v1/block/get/latest_finalized_magic_block":
+		resp = map[string]string{"hash": ts.lfmb}
+	case "/v1/block/get/latest_finalized":
+		resp = ts.headers[ts.rounds[ts.lfb]].BlockSummary
+	case "/v1/block/get":
+		hash := r.FormValue("block")
+		if rs := r.FormValue("round"); rs != "" {
+			round, _ := strconv.ParseInt(rs, 10, 64)
+			hash = ts.rounds[round]
+		}
+		h, ok := ts.headers[hash]
+		if !ok {
+			http.Error(w, "not found", http.StatusBadRequest)
+			return
+		}
+		resp = map[string]interface{}{
+			"header":               h.BlockSummary,
+			"verification_tickets": h.VerificationTickets,
+		}
+	case "/v1/state/proof":
+		p, err := stateproof.New(ts.mpt.GetRoot(), util.Path(r.FormValue("client_id")),
+			util.EncodedNodeGetter(ts.mpt.GetNodeDB()))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp = map[string]interface{}{
+			"block_hash": ts.stateHash,
+			"root":       p.Root,
+			"path":       p.Path,
+			"nodes":      p.Nodes,
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// testChain - the trusted magic block 1 starting at round 0, the block of
+// round 5 with the magic block 2 starting at round 10 and the block of round
+// 12 with the state of the client
+func testChain(t *testing.T) (*block.MagicBlock, *testSharder) {
+	var (
+		miners1 = testMiners(t, 3)
+		miners2 = testMiners(t, 4)
+		mb1     = testMagicBlock(1, 0, "", miners1)
+		mb2     = testMagicBlock(2, 10, mb1.Hash, miners2)
+		genesis = encryption.Hash("genesis")
+	)
+
+	mpt := util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 1, nil)
+	s := &state.State{Balance: 100}
+	require.NoError(t, s.SetTxnHash(encryption.Hash("txn")))
+	_, err := mpt.Insert(util.Path(testClientID), s)
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		_, err = mpt.Insert(util.Path(encryption.Hash(strconv.Itoa(i))), s)
+		require.NoError(t, err)
+	}
+
+	mbh := testHeader(t, &block.BlockSummary{
+		Round:                         5,
+		PrevHash:                      encryption.Hash("4"),
+		MagicBlock:                    mb2,
+		LatestFinalizedMagicBlockHash: genesis,
+	}, miners1)
+	sh := testHeader(t, &block.BlockSummary{
+		Round:                          12,
+		PrevHash:                       encryption.Hash("11"),
+		ClientStateHash:                mpt.GetRoot(),
+		HeaderVersion:                  block.HeaderVersion,
+		LatestFinalizedMagicBlockHash:  mbh.Hash,
+		LatestFinalizedMagicBlockRound: 10,
+	}, miners2)
+
+	return mb1, &testSharder{
+		headers:   map[string]*Header{mbh.Hash: mbh, sh.Hash: sh},
+		rounds:    map[int64]string{5: mbh.Hash, 12: sh.Hash},
+		lfmb:      mbh.Hash,
+		lfb:       12,
+		mpt:       mpt,
+		stateHash: sh.Hash,
+	}
+}
+
+func newTestClient(t *testing.T, mb *block.MagicBlock,
+	sharders ...http.Handler) *Client {
+
+	urls := make([]string, len(sharders))
+	for i, s := range sharders {
+		server := httptest.NewServer(s)
+		t.Cleanup(server.Close)
+		urls[i] = server.URL
+	}
+	c, err := NewClient(Config{Sharders: urls, SignatureScheme: "ed25519"}, mb)
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient_GetClientState(t *testing.T) {
+	mb, sharder := testChain(t)
+	c := newTestClient(t, mb, sharder)
+	ctx := context.Background()
+
+	require.NoError(t, c.SyncMagicBlocks(ctx))
+	require.Equal(t, int64(2), c.MagicBlock().MagicBlockNumber)
+	require.NoError(t, c.SyncMagicBlocks(ctx), "up to date")
+	require.Equal(t, int64(2), c.MagicBlock().MagicBlockNumber)
+
+	for _, round := range []int64{0, 12} {
+		s, h, err := c.GetClientState(ctx, testClientID, round)
+		require.NoError(t, err)
+		assert.Equal(t, state.Balance(100), s.Balance)
+		assert.Equal(t, int64(12), h.Round)
+	}
+
+	_, _, err := c.GetClientState(ctx, encryption.Hash("missing"), 12)
+	assert.Equal(t, util.ErrValueNotPresent, err)
+
+	// the header of the magic block round verified against the first one
+	h, err := c.GetHeader(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), h.MagicBlock.MagicBlockNumber)
+
+	// the headers syncing the magic blocks on their own
+	c = newTestClient(t, mb, sharder)
+	_, _, err = c.GetClientState(ctx, testClientID, 12)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c.MagicBlock().MagicBlockNumber)
+}
+
+func TestClient_Tampered(t *testing.T) {
+	ctx := context.Background()
+	tests := map[string]struct {
+		tamper func(ts *testSharder)
+		err    error
+	}{
+		"state": {
+			tamper: func(ts *testSharder) {
+				s := &state.State{Balance: 1000}
+				require.NoError(t, s.SetTxnHash(encryption.Hash("txn")))
+				_, err := ts.mpt.Insert(util.Path(testClientID), s)
+				require.NoError(t, err)
+			},
+			err: stateproof.ErrInvalidProof,
+		},
+		"state_hash": {
+			tamper: func(ts *testSharder) {
+				h := ts.headers[ts.rounds[12]]
+				h.ClientStateHash = util.Key(encryption.RawHash("state"))
+			},
+			err: ErrInvalidHeader,
+		},
+		"tickets": {
+			tamper: func(ts *testSharder) {
+				h := ts.headers[ts.rounds[12]]
+				h.VerificationTickets = h.VerificationTickets[:2]
+			},
+			err: ErrNotNotarized,
+		},
+		"duplicate_tickets": {
+			tamper: func(ts *testSharder) {
+				h := ts.headers[ts.rounds[12]]
+				vts := h.VerificationTickets
+				h.VerificationTickets = []*block.VerificationTicket{vts[0], vts[0], vts[1], vts[1]}
+			},
+			err: ErrNotNotarized,
+		},
+		"signature": {
+			tamper: func(ts *testSharder) {
+				h := ts.headers[ts.rounds[12]]
+				for _, vt := range h.VerificationTickets[1:] {
+					vt.Signature = h.VerificationTickets[0].Signature
+				}
+			},
+			err: ErrNotNotarized,
+		},
+		"magic_block_miners": {
+			tamper: func(ts *testSharder) {
+				// a magic block of other miners notarized by themselves
+				miners := testMiners(t, 4)
+				h := ts.headers[ts.lfmb]
+				mb := testMagicBlock(2, 10, h.MagicBlock.PreviousMagicBlockHash, miners)
+				fake := testHeader(t, &block.BlockSummary{
+					Round:                         5,
+					MagicBlock:                    mb,
+					LatestFinalizedMagicBlockHash: h.LatestFinalizedMagicBlockHash,
+				}, miners)
+				ts.headers[fake.Hash], ts.lfmb = fake, fake.Hash
+			},
+			err: ErrNotNotarized,
+		},
+		"magic_block_link": {
+			tamper: func(ts *testSharder) {
+				h := ts.headers[ts.lfmb]
+				mb := block.NewMagicBlock()
+				require.NoError(t, mb.Decode(h.MagicBlock.Encode()))
+				mb.PreviousMagicBlockHash = encryption.Hash("other")
+				mb.Hash = mb.GetHash()
+				h.MagicBlock = mb
+			},
+			err: ErrInvalidMagicBlock,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mb, good := testChain(t)
+			_, bad := testChain(t) // the same state with other headers
+			bad.rounds, bad.lfmb, bad.stateHash = good.rounds, good.lfmb, good.stateHash
+
+			// the copies of the headers of the good sharder
+			bad.headers = make(map[string]*Header)
+			for hash, h := range good.headers {
+				bs := *h.BlockSummary
+				vts := make([]*block.VerificationTicket, len(h.VerificationTickets))
+				for i, vt := range h.VerificationTickets {
+					vts[i] = vt.Copy()
+				}
+				bad.headers[hash] = &Header{BlockSummary: &bs, VerificationTickets: vts}
+			}
+			tt.tamper(bad)
+
+			_, _, err := newTestClient(t, mb, bad).GetClientState(ctx, testClientID, 12)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tt.err), "%v", err)
+
+			// the next sharder is asked when the verification fails
+			s, _, err := newTestClient(t, mb, bad, good).GetClientState(ctx, testClientID, 12)
+			require.NoError(t, err)
+			assert.Equal(t, state.Balance(100), s.Balance)
+		})
+	}
+}
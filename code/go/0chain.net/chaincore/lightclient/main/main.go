@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/lightclient"
+	"0chain.net/chaincore/state"
+	"0chain.net/core/util"
+)
+
+type output struct {
+	Round            int64          `json:"round"`
+	BlockHash        string         `json:"block_hash"`
+	StateHash        string         `json:"state_hash,omitempty"`
+	MagicBlockNumber int64          `json:"magic_block_number"`
+	Balance          *state.Balance `json:"balance,omitempty"`
+	Value            string         `json:"value,omitempty"`
+}
+
+func main() {
+	magicBlockFile := flag.String("magic_block", "", "the trusted magic block file, the genesis magic block usually")
+	sharders := flag.String("sharders", "", "the comma separated base URLs of the sharders")
+	threshold := flag.Int("threshold", lightclient.DefaultThreshold, "the percent of the miners notarizing a block")
+	scheme := flag.String("signature_scheme", lightclient.DefaultSignatureScheme, "the signature scheme of the miners")
+	timeout := flag.Duration("timeout", lightclient.DefaultTimeout, "the timeout of a request to a sharder")
+	round := flag.Int64("round", 0, "the round to query, the latest finalized round if not positive")
+	clientID := flag.String("client_id", "", "the client to get the verified balance of")
+	scAddress := flag.String("sc_address", "", "the smart contract to get the verified value of the key of")
+	key := flag.String("key", "", "the smart contract key")
+	flag.Parse()
+
+	if *magicBlockFile == "" || *sharders == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	buf, err := ioutil.ReadFile(*magicBlockFile)
+	if err != nil {
+		fatal("read magic block: %v", err)
+	}
+	mb := block.NewMagicBlock()
+	if err := mb.Decode(buf); err != nil {
+		fatal("decode magic block: %v", err)
+	}
+
+	c, err := lightclient.NewClient(lightclient.Config{
+		Sharders:        strings.Split(*sharders, ","),
+		Threshold:       *threshold,
+		SignatureScheme: *scheme,
+		Timeout:         *timeout,
+	}, mb)
+	if err != nil {
+		fatal("create light client: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := c.SyncMagicBlocks(ctx); err != nil {
+		fatal("sync magic blocks: %v", err)
+	}
+
+	var (
+		out = output{MagicBlockNumber: c.MagicBlock().MagicBlockNumber}
+		h   *lightclient.Header
+	)
+	switch {
+	case *clientID != "":
+		var s *state.State
+		s, h, err = c.GetClientState(ctx, *clientID, *round)
+		if err == util.ErrValueNotPresent {
+			s, err = &state.State{}, nil
+		}
+		if s != nil {
+			out.Balance = &s.Balance
+		}
+	case *scAddress != "":
+		var value []byte
+		value, h, err = c.GetSCState(ctx, *scAddress, *key, *round)
+		out.Value = string(value)
+	default:
+		h, err = c.GetHeader(ctx, *round)
+	}
+	if err != nil {
+		fatal("query: %v", err)
+	}
+
+	out.Round, out.BlockHash = h.Round, h.Hash
+	if h.HeaderVersion > 0 {
+		out.StateHash = util.ToHex(h.ClientStateHash)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		fatal("print: %v", err)
+	}
+}
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -31,6 +31,42 @@ func init() {
 	compDecomp = common.NewZStdCompDe()
 }
 
+//SetCompression - compress the entities sent with the encoding given, zstd,
+//zlib, snappy or zstddict with the zstd dictionary file given; the entities
+//received are decompressed with any of them, so all the nodes should have
+//the dictionary before any of them sends with zstddict. The dictionary
+//entities are sent as zstddict-<dictionary id>, the nodes without the
+//dictionary reject them as of an unknown encoding.
+func SetCompression(encoding, dictionaryFile string) error {
+	var dictCompDe common.CompDe
+	if dictionaryFile != "" {
+		dict, err := ioutil.ReadFile(dictionaryFile)
+		if err != nil {
+			return err
+		}
+		if common.ZStdDictID(dict) == 0 {
+			return common.NewErrorf("n2n_compression",
+				"not a trained zstd dictionary: %v", dictionaryFile)
+		}
+		cd, err := common.NewZStdCompDeWithDict(dict)
+		if err != nil {
+			return err
+		}
+		common.RegisterCompDe(cd)
+		dictCompDe = cd
+	}
+	if encoding == common.ZStdDictEncoding && dictCompDe != nil {
+		compDecomp = dictCompDe
+		return nil
+	}
+	cd := common.GetCompDe(encoding)
+	if cd == nil {
+		return common.NewErrorf("n2n_compression", "unknown encoding: %v", encoding)
+	}
+	compDecomp = cd
+	return nil
+}
+
 //SetTimeoutSmallMessage - set the timeout for small message
 func SetTimeoutSmallMessage(ts time.Duration) {
 	TimeoutSmallMessage = ts
@@ -158,6 +194,19 @@ func getHashData(clientID datastore.Key, ts common.Timestamp, key datastore.Key)
 
 var NoDataErr = common.NewError("no_data", "No data")
 
+//getCompDe - the CompDe of the content encoding, an error for an encoding
+//unknown to the node, such as a zstddict-<id> of a dictionary it doesn't have
+func getCompDe(encoding string) (common.CompDe, error) {
+	if encoding == "" {
+		return nil, nil
+	}
+	cd := common.GetCompDe(encoding)
+	if cd == nil {
+		return nil, common.NewErrorf("unknown_encoding", "unknown content encoding: %v", encoding)
+	}
+	return cd, nil
+}
+
 func readAndClose(reader io.ReadCloser) {
 	io.Copy(ioutil.Discard, reader)
 	reader.Close()
@@ -173,16 +222,20 @@ func getDataAndClose(reader io.ReadCloser) []byte {
 func getRequestEntity(r *http.Request, entityMetadata datastore.EntityMetadata) (datastore.Entity, error) {
 	defer r.Body.Close()
 	var buffer io.Reader = r.Body
-	if r.Header.Get("Content-Encoding") == compDecomp.Encoding() {
+	cd, err := getCompDe(r.Header.Get("Content-Encoding"))
+	if err != nil {
+		return nil, err
+	}
+	if cd != nil {
 		cbuffer := new(bytes.Buffer)
 		cbuffer.ReadFrom(r.Body)
 		cbytes := cbuffer.Bytes()
 		if len(cbytes) == 0 {
 			return nil, NoDataErr
 		}
-		cbytes, err := compDecomp.Decompress(cbytes)
+		cbytes, err := cd.Decompress(cbytes)
 		if err != nil {
-			logging.N2n.Error("decoding", zap.String("encoding", compDecomp.Encoding()), zap.Error(err))
+			logging.N2n.Error("decoding", zap.String("encoding", cd.Encoding()), zap.Error(err))
 			return nil, err
 		}
 		buffer = bytes.NewReader(cbytes)
@@ -194,13 +247,17 @@ func getResponseEntity(resp *http.Response, entityMetadata datastore.EntityMetad
 	defer resp.Body.Close()
 	var buffer io.Reader = resp.Body
 	var size int
-	if resp.Header.Get("Content-Encoding") == compDecomp.Encoding() {
+	cd, err := getCompDe(resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return size, nil, err
+	}
+	if cd != nil {
 		cbuffer := new(bytes.Buffer)
 		cbuffer.ReadFrom(resp.Body)
 		size = cbuffer.Len()
-		cbytes, err := compDecomp.Decompress(cbuffer.Bytes())
+		cbytes, err := cd.Decompress(cbuffer.Bytes())
 		if err != nil {
-			logging.N2n.Error("decoding", zap.String("encoding", compDecomp.Encoding()), zap.Error(err))
+			logging.N2n.Error("decoding", zap.String("encoding", cd.Encoding()), zap.Error(err))
 			return size, nil, err
 		}
 		buffer = bytes.NewReader(cbytes)
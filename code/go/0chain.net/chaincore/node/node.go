@@ -577,6 +577,10 @@ func ReadConfig() {
 	SetTimeoutLargeMessage(viper.GetDuration("network.timeout.large_message") * time.Millisecond)
 	SetMaxConcurrentRequests(viper.GetInt("network.max_concurrent_requests"))
 	SetLargeMessageThresholdSize(viper.GetInt("network.large_message_th_size"))
+	if err := SetCompression(viper.GetString("network.compression.codec"),
+		viper.GetString("network.compression.dictionary")); err != nil {
+		panic(err)
+	}
 }
 
 //SetID - set the id of the node
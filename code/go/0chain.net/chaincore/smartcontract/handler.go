@@ -92,3 +92,21 @@ func ExecuteSmartContract(ctx context.Context, t *transaction.Transaction, balan
 	}
 	return "", common.NewError("invalid_smart_contract_address", "Invalid Smart Contract address")
 }
+
+// GetAccessHints - the access hints of the smart contract function called by
+// the transaction, nil if the smart contract doesn't declare them
+func GetAccessHints(t *transaction.Transaction) *sci.AccessHints {
+	contractObj := getSmartContract(t.ToClientID)
+	if contractObj == nil {
+		return nil
+	}
+	hinter, ok := contractObj.(sci.AccessHinter)
+	if !ok {
+		return nil
+	}
+	var smartContractData sci.SmartContractTransactionData
+	if err := json.Unmarshal([]byte(t.TransactionData), &smartContractData); err != nil {
+		return nil
+	}
+	return hinter.GetAccessHints(t, smartContractData.FunctionName, smartContractData.InputData)
+}
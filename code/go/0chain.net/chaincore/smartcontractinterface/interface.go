@@ -7,6 +7,7 @@ import (
 
 	c_state "0chain.net/chaincore/chain/state"
 	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
 )
 
 const Seperator = ":"
@@ -39,6 +40,32 @@ type SmartContractInterface interface {
 	GetAddress() string
 }
 
+// AccessHints - the trie nodes and the client accounts a smart contract
+// function may access, declared from the transaction before executing it.
+// The node keys are the ones the function passes to the trie node functions
+// of StateContextI.
+type AccessHints struct {
+	Reads  []datastore.Key
+	Writes []datastore.Key
+	// the accounts the transfers and the mints of the function may change,
+	// besides the client of the transaction and the smart contract getting
+	// the value of the transaction
+	Clients []datastore.Key
+	// Serial - the function touches global nodes of the smart contract it
+	// can't list, so it is grouped apart from every other transaction of
+	// the block, not only the ones of the smart contract
+	Serial bool
+}
+
+// AccessHinter - an optional interface of a smart contract declaring what
+// its functions access. The hints are only used to group the transactions
+// before executing them, wrong hints don't change the resulting state but
+// cost re-executions. A function that can't tell what it accesses from its
+// input alone gets nil hints.
+type AccessHinter interface {
+	GetAccessHints(t *transaction.Transaction, funcName string, input []byte) *AccessHints
+}
+
 /*BCContextI interface for smart contracts to access blockchain.
 These functions should not modify blockchain states in anyway.
 */
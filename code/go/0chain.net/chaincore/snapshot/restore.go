@@ -0,0 +1,119 @@
+package snapshot
+
+import (
+	"context"
+	"sync"
+
+	"0chain.net/core/common"
+	"0chain.net/core/util"
+)
+
+// DefaultParallel - the number of chunks downloaded at once unless
+// configured
+const DefaultParallel = 8
+
+// FetchFunc - get the data of the chunk from the network
+type FetchFunc func(ctx context.Context, hash string) ([]byte, error)
+
+// Restore - download the chunks of the snapshot in parallel and put their
+// nodes into the node db, then check the nodes make up the state of the
+// manifest. A node is stored by the hash of its own encoding, so a wrong
+// node of a chunk can't take the place of another one, and the state is
+// verified once every node of the state root is found.
+func Restore(ctx context.Context, m *Manifest, fetch FetchFunc, parallel int,
+	ndb util.NodeDB) error {
+
+	if parallel <= 0 {
+		parallel = DefaultParallel
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		hashes = make(chan string)
+		errs   = make(chan error, parallel)
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for hash := range hashes {
+				if err := restoreChunk(cctx, hash, fetch, ndb); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+loop:
+	for _, hash := range m.Chunks {
+		select {
+		case hashes <- hash:
+		case <-cctx.Done():
+			break loop
+		}
+	}
+	close(hashes)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return Verify(ctx, m, ndb)
+}
+
+func restoreChunk(ctx context.Context, hash string, fetch FetchFunc,
+	ndb util.NodeDB) error {
+
+	data, err := fetch(ctx, hash)
+	if err != nil {
+		return err
+	}
+	if err = VerifyChunk(hash, data); err != nil {
+		return err
+	}
+	nodes, err := DecodeChunk(data)
+	if err != nil {
+		return err
+	}
+	keys := make([]util.Key, len(nodes))
+	for i, node := range nodes {
+		keys[i] = node.GetHashBytes()
+	}
+	return ndb.MultiPutNode(keys, nodes)
+}
+
+// Verify - check every node of the state of the manifest is in the node db
+func Verify(ctx context.Context, m *Manifest, ndb util.NodeDB) error {
+	root, err := m.StateRoot()
+	if err != nil || len(root) == 0 {
+		return ErrInvalidManifest
+	}
+	var count int64
+	handler := func(ctx context.Context, path util.Path, key util.Key,
+		node util.Node) error {
+
+		if node == nil {
+			return common.NewErrorf(ErrIncomplete.Code,
+				"missing node %v", util.ToHex(key))
+		}
+		count++
+		return nil
+	}
+	mpt := util.NewMerklePatriciaTrie(ndb, util.Sequence(m.Round), root)
+	if err := mpt.Iterate(ctx, handler, StateNodeTypes); err != nil {
+		return err
+	}
+	if count != m.Nodes {
+		return common.NewErrorf(ErrIncomplete.Code,
+			"state has %v nodes, the manifest %v", count, m.Nodes)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+// Package snapshot exports the client state of a finalized round as a state
+// snapshot: the MPT nodes in content addressed chunks listed by a manifest
+// signed by the node taking the snapshot. The export walks the trie in a
+// fixed order, so the nodes taking a snapshot of the same round with the
+// same chunk size have the same chunks and any of them can serve any chunk.
+// A node missing the state downloads the chunks in parallel and verifies
+// the restored trie against the state hash of the block of the round.
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+// DefaultChunkSize - the size of the chunks unless configured, in bytes
+const DefaultChunkSize = 1 << 20
+
+var (
+	// ErrInvalidChunk - the chunk data doesn't match the chunk hash
+	ErrInvalidChunk = common.NewError("invalid_snapshot_chunk",
+		"snapshot chunk doesn't match its hash")
+	// ErrInvalidManifest - the manifest hash or signature is wrong
+	ErrInvalidManifest = common.NewError("invalid_snapshot_manifest",
+		"snapshot manifest hash or signature is invalid")
+	// ErrIncomplete - the restored nodes don't make up the state of the
+	// manifest
+	ErrIncomplete = common.NewError("incomplete_snapshot",
+		"snapshot doesn't cover the state")
+	// ErrNotFound - no snapshot is kept for the round
+	ErrNotFound = common.NewError("snapshot_not_found", "no snapshot found")
+)
+
+// StateNodeTypes - the node types stored in the state db, the value nodes
+// are part of the leaf and the full nodes
+const StateNodeTypes = util.NodeTypeLeafNode | util.NodeTypeFullNode |
+	util.NodeTypeExtensionNode
+
+// Manifest - the state snapshot of a finalized round, the ID is the hash
+type Manifest struct {
+	datastore.IDField
+	Round     int64    `json:"round"`
+	BlockHash string   `json:"block_hash"`
+	StateHash string   `json:"state_hash"` // hex of the root key
+	Nodes     int64    `json:"nodes"`
+	Size      int64    `json:"size"`   // the size of all the chunks
+	Chunks    []string `json:"chunks"` // the hashes of the chunks in order
+	NodeID    string   `json:"node_id"`
+	Signature string   `json:"signature"`
+}
+
+// NewManifest - a new manifest entity
+func NewManifest() *Manifest {
+	return datastore.GetEntityMetadata("state_snapshot").Instance().(*Manifest)
+}
+
+var manifestEntityMetadata *datastore.EntityMetadataImpl
+
+// ManifestProvider - a manifest instance provider
+func ManifestProvider() datastore.Entity {
+	return &Manifest{}
+}
+
+// GetEntityMetadata - implement interface
+func (m *Manifest) GetEntityMetadata() datastore.EntityMetadata {
+	return manifestEntityMetadata
+}
+
+func (m *Manifest) getHashData() string {
+	return fmt.Sprintf("%v:%v:%v:%v:%v:%v", m.Round, m.BlockHash,
+		m.StateHash, m.Nodes, m.Size, strings.Join(m.Chunks, ","))
+}
+
+// ComputeHash - the hash of the content of the manifest, the nodes taking
+// the same snapshot have the same hash
+func (m *Manifest) ComputeHash() string {
+	return encryption.Hash(m.getHashData())
+}
+
+// Sign - set the hash and sign it by the node
+func (m *Manifest) Sign(nodeID string, sign func(hash string) (string, error)) (
+	err error) {
+
+	m.ID = m.ComputeHash()
+	m.NodeID = nodeID
+	m.Signature, err = sign(m.ID)
+	return
+}
+
+// Verify - check the hash and the signature of the manifest by the node
+// with the verify function, and that the chunks are addressed by hashes
+func (m *Manifest) Verify(verify func(signature, hash string) (bool, error)) error {
+	if m.ID != m.ComputeHash() || len(m.Chunks) == 0 {
+		return ErrInvalidManifest
+	}
+	for _, hash := range m.Chunks {
+		if !encryption.IsHash(hash) {
+			return ErrInvalidManifest
+		}
+	}
+	if ok, err := verify(m.Signature, m.ID); err != nil || !ok {
+		return ErrInvalidManifest
+	}
+	return nil
+}
+
+// StateRoot - the root key of the state of the manifest
+func (m *Manifest) StateRoot() (util.Key, error) {
+	return hex.DecodeString(m.StateHash)
+}
+
+// Chunk - the data of a chunk of a snapshot, the ID is the hash
+type Chunk struct {
+	datastore.IDField
+	Data []byte `json:"data"`
+}
+
+// NewChunk - a new chunk entity
+func NewChunk(hash string, data []byte) *Chunk {
+	c := datastore.GetEntityMetadata("state_snapshot_chunk").Instance().(*Chunk)
+	c.ID, c.Data = hash, data
+	return c
+}
+
+var chunkEntityMetadata *datastore.EntityMetadataImpl
+
+// ChunkProvider - a chunk instance provider
+func ChunkProvider() datastore.Entity {
+	return &Chunk{}
+}
+
+// GetEntityMetadata - implement interface
+func (c *Chunk) GetEntityMetadata() datastore.EntityMetadata {
+	return chunkEntityMetadata
+}
+
+// SetupEntity - setup the snapshot manifest and chunk entities
+func SetupEntity(store datastore.Store) {
+	manifestEntityMetadata = datastore.MetadataProvider()
+	manifestEntityMetadata.Name = "state_snapshot"
+	manifestEntityMetadata.Provider = ManifestProvider
+	manifestEntityMetadata.Store = store
+	manifestEntityMetadata.IDColumnName = "id"
+	datastore.RegisterEntityMetadata("state_snapshot", manifestEntityMetadata)
+
+	chunkEntityMetadata = datastore.MetadataProvider()
+	chunkEntityMetadata.Name = "state_snapshot_chunk"
+	chunkEntityMetadata.Provider = ChunkProvider
+	chunkEntityMetadata.Store = store
+	chunkEntityMetadata.IDColumnName = "id"
+	datastore.RegisterEntityMetadata("state_snapshot_chunk", chunkEntityMetadata)
+}
+
+// ChunkHash - the hash addressing the chunk data
+func ChunkHash(data []byte) string {
+	return encryption.Hash(data)
+}
+
+// VerifyChunk - check the data matches the chunk hash
+func VerifyChunk(hash string, data []byte) error {
+	if ChunkHash(data) != hash {
+		return ErrInvalidChunk
+	}
+	return nil
+}
+
+// encodeNode - append the length prefixed encoding of the node
+func encodeNode(buf *bytes.Buffer, node util.Node) {
+	data := node.Encode()
+	var l [binary.MaxVarintLen64]byte
+	buf.Write(l[:binary.PutUvarint(l[:], uint64(len(data)))])
+	buf.Write(data)
+}
+
+// DecodeChunk - the nodes of the chunk data
+func DecodeChunk(data []byte) ([]util.Node, error) {
+	var (
+		r     = bytes.NewReader(data)
+		nodes []util.Node
+	)
+	for r.Len() > 0 {
+		l, err := binary.ReadUvarint(r)
+		if err != nil || l > uint64(r.Len()) {
+			return nil, ErrInvalidChunk
+		}
+		nd := make([]byte, l)
+		r.Read(nd)
+		node, err := decodeNode(nd)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// decodeNode - decode a node of a chunk, the chunks come from the peers and
+// a malformed node makes util.CreateNode panic
+func decodeNode(buf []byte) (node util.Node, err error) {
+	if len(buf) == 0 {
+		return nil, ErrInvalidChunk
+	}
+	switch buf[0] & util.NodeTypesAll {
+	case util.NodeTypeLeafNode, util.NodeTypeFullNode, util.NodeTypeExtensionNode:
+	default:
+		return nil, ErrInvalidChunk
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			node, err = nil, fmt.Errorf("%w: %v", ErrInvalidChunk, r)
+		}
+	}()
+	if node, err = util.CreateNode(bytes.NewReader(buf)); err != nil {
+		return nil, ErrInvalidChunk
+	}
+	return node, nil
+}
+
+// Export - walk the state trie and put its nodes into the chunks of the
+// store, the manifest has to be signed and saved after
+func Export(ctx context.Context, mpt util.MerklePatriciaTrieI, chunkSize int,
+	store *Store) (*Manifest, error) {
+
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	var (
+		m   = &Manifest{StateHash: util.ToHex(mpt.GetRoot())}
+		buf bytes.Buffer
+	)
+	flush := func() error {
+		hash, err := store.PutChunk(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		m.Chunks = append(m.Chunks, hash)
+		m.Size += int64(buf.Len())
+		buf.Reset()
+		return nil
+	}
+	handler := func(ctx context.Context, path util.Path, key util.Key,
+		node util.Node) error {
+
+		if node == nil {
+			return common.NewErrorf("snapshot_export",
+				"missing node %v", util.ToHex(key))
+		}
+		encodeNode(&buf, node)
+		m.Nodes++
+		if buf.Len() >= chunkSize {
+			return flush()
+		}
+		return nil
+	}
+	if err := mpt.Iterate(ctx, handler, StateNodeTypes); err != nil {
+		return nil, err
+	}
+	if buf.Len() > 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,245 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+	"0chain.net/core/util"
+)
+
+func init() {
+	logging.Logger = zap.NewNop()
+}
+
+const testNodes = 300
+
+func testState(t *testing.T) util.MerklePatriciaTrieI {
+	mpt := util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 1, nil)
+	for i := 0; i < testNodes; i++ {
+		v := &util.SecureSerializableValue{Buffer: []byte(strconv.Itoa(i))}
+		_, err := mpt.Insert(util.Path(encryption.Hash(strconv.Itoa(i))), v)
+		require.NoError(t, err)
+	}
+	return mpt
+}
+
+func testStore(t *testing.T) *Store {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	s, err := NewStore(dir)
+	require.NoError(t, err)
+	return s
+}
+
+// testSnapshot - a snapshot of the test state in small chunks
+func testSnapshot(t *testing.T) (util.MerklePatriciaTrieI, *Store, *Manifest) {
+	mpt := testState(t)
+	s := testStore(t)
+	m, err := Export(context.Background(), mpt, 1024, s)
+	require.NoError(t, err)
+	m.Round, m.BlockHash = 10, encryption.Hash("block")
+	require.True(t, len(m.Chunks) > 1)
+	return mpt, s, m
+}
+
+func TestExport_Deterministic(t *testing.T) {
+	mpt := testState(t)
+	m1, err := Export(context.Background(), mpt, 1024, testStore(t))
+	require.NoError(t, err)
+	m2, err := Export(context.Background(), mpt, 1024, testStore(t))
+	require.NoError(t, err)
+	assert.Equal(t, m1.Chunks, m2.Chunks)
+	assert.Equal(t, m1.ComputeHash(), m2.ComputeHash())
+	assert.Equal(t, util.ToHex(mpt.GetRoot()), m1.StateHash)
+}
+
+func TestRestore(t *testing.T) {
+	mpt, s, m := testSnapshot(t)
+	ctx := context.Background()
+	fetch := func(ctx context.Context, hash string) ([]byte, error) {
+		return s.GetChunk(hash)
+	}
+
+	ndb := util.NewMemoryNodeDB()
+	require.NoError(t, Restore(ctx, m, fetch, 4, ndb))
+	restored := util.NewMerklePatriciaTrie(ndb, 1, mpt.GetRoot())
+	for _, i := range []int{0, testNodes / 2, testNodes - 1} {
+		v, err := restored.GetNodeValue(util.Path(encryption.Hash(strconv.Itoa(i))))
+		require.NoError(t, err)
+		assert.Equal(t, []byte(strconv.Itoa(i)), v.Encode())
+	}
+}
+
+func TestRestore_Invalid(t *testing.T) {
+	ctx := context.Background()
+	tests := map[string]struct {
+		tamper func(m *Manifest, fetch FetchFunc) FetchFunc
+		err    error
+	}{
+		"chunk": {
+			tamper: func(m *Manifest, fetch FetchFunc) FetchFunc {
+				return func(ctx context.Context, hash string) ([]byte, error) {
+					data, err := fetch(ctx, hash)
+					if hash == m.Chunks[1] {
+						data[len(data)-1]++
+					}
+					return data, err
+				}
+			},
+			err: ErrInvalidChunk,
+		},
+		"missing_chunk": {
+			tamper: func(m *Manifest, fetch FetchFunc) FetchFunc {
+				m.Chunks = m.Chunks[1:]
+				return fetch
+			},
+			err: ErrIncomplete,
+		},
+		"state_hash": {
+			tamper: func(m *Manifest, fetch FetchFunc) FetchFunc {
+				m.StateHash = encryption.Hash("state")
+				return fetch
+			},
+			err: ErrIncomplete,
+		},
+		"fetch": {
+			tamper: func(m *Manifest, fetch FetchFunc) FetchFunc {
+				return func(ctx context.Context, hash string) ([]byte, error) {
+					return nil, ErrNotFound
+				}
+			},
+			err: ErrNotFound,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, s, m := testSnapshot(t)
+			fetch := tt.tamper(m, func(ctx context.Context, hash string) ([]byte, error) {
+				return s.GetChunk(hash)
+			})
+			err := Restore(ctx, m, fetch, 4, util.NewMemoryNodeDB())
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tt.err), "%v", err)
+		})
+	}
+}
+
+func TestDecodeChunk_Malformed(t *testing.T) {
+	chunk := func(nodes ...[]byte) []byte {
+		var buf []byte
+		for _, nd := range nodes {
+			buf = append(buf, byte(len(nd)))
+			buf = append(buf, nd...)
+		}
+		return buf
+	}
+	var (
+		leaf = util.NewLeafNode(nil, util.Path("0123"), 1,
+			&util.SecureSerializableValue{Buffer: []byte("value")}).Encode()
+		full = util.NewFullNode(&util.SecureSerializableValue{Buffer: []byte("value")}).Encode()
+	)
+	nodes, err := DecodeChunk(chunk(leaf, full))
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+
+	for name, data := range map[string][]byte{
+		"truncated_leaf": chunk(leaf[:len(leaf)/2]),
+		// the node type and the origin tracker, then a prefix without a path
+		"leaf_no_path": chunk(append(append([]byte{util.NodeTypeLeafNode}, make([]byte, 16)...),
+			"0a:1b"...)),
+		"truncated_full": chunk(full[:2]),
+		"garbage":        chunk([]byte{util.NodeTypeFullNode, 0xff, 0xfe, 0x01}),
+		"unknown_type":   chunk([]byte{0xf0, 0x01}),
+		"empty_node":     chunk([]byte{}),
+		"length":         {0x7f, util.NodeTypeLeafNode},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var err error
+			require.NotPanics(t, func() { _, err = DecodeChunk(data) })
+			assert.True(t, errors.Is(err, ErrInvalidChunk), "%v", err)
+		})
+	}
+}
+
+func TestManifest_Verify(t *testing.T) {
+	_, s, m := testSnapshot(t)
+	ss := encryption.NewED25519Scheme()
+	require.NoError(t, ss.GenerateKeys())
+	require.NoError(t, m.Sign("node", func(hash string) (string, error) {
+		return ss.Sign(hash)
+	}))
+	require.NoError(t, m.Verify(ss.Verify))
+	require.NoError(t, s.SaveManifest(m))
+
+	got, err := s.GetManifest(0)
+	require.NoError(t, err)
+	require.NoError(t, got.Verify(ss.Verify))
+	assert.Equal(t, m.Chunks, got.Chunks)
+
+	got.Round++
+	assert.Equal(t, ErrInvalidManifest, got.Verify(ss.Verify))
+
+	// signed, but with a chunk not addressed by a hash
+	bad := *m
+	bad.Chunks = append([]string{""}, m.Chunks[1:]...)
+	require.NoError(t, bad.Sign("node", func(hash string) (string, error) {
+		return ss.Sign(hash)
+	}))
+	assert.Equal(t, ErrInvalidManifest, bad.Verify(ss.Verify))
+
+	other := encryption.NewED25519Scheme()
+	require.NoError(t, other.GenerateKeys())
+	assert.Equal(t, ErrInvalidManifest, m.Verify(other.Verify))
+}
+
+func TestStore_Prune(t *testing.T) {
+	mpt := testState(t)
+	s := testStore(t)
+	ctx := context.Background()
+
+	var manifests []*Manifest
+	for round := int64(1); round <= 3; round++ {
+		v := &util.SecureSerializableValue{Buffer: []byte(strconv.FormatInt(round, 10))}
+		_, err := mpt.Insert(util.Path(encryption.Hash("round")), v)
+		require.NoError(t, err)
+		m, err := Export(ctx, mpt, 1024, s)
+		require.NoError(t, err)
+		m.Round = round * 10
+		require.NoError(t, s.SaveManifest(m))
+		manifests = append(manifests, m)
+	}
+
+	m, err := s.GetManifest(25)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), m.Round)
+	_, err = s.GetManifest(5)
+	assert.Equal(t, ErrNotFound, err)
+
+	require.NoError(t, s.Prune(2))
+	_, err = s.GetManifest(15)
+	assert.Equal(t, ErrNotFound, err)
+	for _, m := range manifests[1:] {
+		for _, hash := range m.Chunks {
+			_, err := s.GetChunk(hash)
+			require.NoError(t, err, "chunk of a kept snapshot")
+		}
+	}
+	var removed int
+	for _, hash := range manifests[0].Chunks {
+		if _, err := s.GetChunk(hash); err == ErrNotFound {
+			removed++
+		}
+	}
+	assert.True(t, removed > 0, "chunks of the removed snapshot only")
+}
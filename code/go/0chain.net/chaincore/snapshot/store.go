@@ -0,0 +1,195 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+const (
+	manifestsDir = "manifests"
+	chunksDir    = "chunks"
+)
+
+// Store - the snapshots kept on the disk, a chunk file is named by its hash
+// and shared by the snapshots having it, a manifest file is named by its
+// round and written after all its chunks
+type Store struct {
+	dir   string
+	mutex sync.RWMutex
+}
+
+// NewStore - the store of the directory, created if missing
+func NewStore(dir string) (*Store, error) {
+	for _, d := range []string{manifestsDir, chunksDir} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0700); err != nil {
+			return nil, err
+		}
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) chunkPath(hash string) string {
+	return filepath.Join(s.dir, chunksDir, hash)
+}
+
+func (s *Store) manifestPath(round int64) string {
+	return filepath.Join(s.dir, manifestsDir,
+		strconv.FormatInt(round, 10)+".json")
+}
+
+// writeFile - write the file through a temporary one, so a file is either
+// missing or complete
+func writeFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// PutChunk - store the chunk data, the hash of the chunk
+func (s *Store) PutChunk(data []byte) (string, error) {
+	hash := ChunkHash(data)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	path := s.chunkPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+	return hash, writeFile(path, data)
+}
+
+// GetChunk - the data of the chunk
+func (s *Store) GetChunk(hash string) ([]byte, error) {
+	if !encryption.IsHash(hash) {
+		return nil, common.InvalidRequest("invalid chunk hash")
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	data, err := ioutil.ReadFile(s.chunkPath(hash))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// SaveManifest - store the manifest of the snapshot, the chunks have to be
+// stored already
+func (s *Store) SaveManifest(m *Manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return writeFile(s.manifestPath(m.Round), data)
+}
+
+// rounds - the rounds of the manifests stored, in order
+func (s *Store) rounds() ([]int64, error) {
+	files, err := ioutil.ReadDir(filepath.Join(s.dir, manifestsDir))
+	if err != nil {
+		return nil, err
+	}
+	var rounds []int64
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		round, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+		rounds = append(rounds, round)
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+	return rounds, nil
+}
+
+func (s *Store) readManifest(round int64) (*Manifest, error) {
+	data, err := ioutil.ReadFile(s.manifestPath(round))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GetManifest - the manifest of the latest snapshot at or before the round,
+// the latest snapshot if the round isn't positive
+func (s *Store) GetManifest(round int64) (*Manifest, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	rounds, err := s.rounds()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(rounds) - 1; i >= 0; i-- {
+		if round <= 0 || rounds[i] <= round {
+			return s.readManifest(rounds[i])
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Prune - keep the latest snapshots only, the chunks of the removed ones
+// not shared with the kept ones are removed too, so it can't run during an
+// export
+func (s *Store) Prune(keep int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rounds, err := s.rounds()
+	if err != nil {
+		return err
+	}
+	if keep < 1 {
+		keep = 1
+	}
+	if len(rounds) <= keep {
+		return nil
+	}
+	for _, round := range rounds[:len(rounds)-keep] {
+		if err := os.Remove(s.manifestPath(round)); err != nil {
+			return err
+		}
+	}
+	kept := make(map[string]bool)
+	for _, round := range rounds[len(rounds)-keep:] {
+		m, err := s.readManifest(round)
+		if err != nil {
+			return err
+		}
+		for _, hash := range m.Chunks {
+			kept[hash] = true
+		}
+	}
+	files, err := ioutil.ReadDir(filepath.Join(s.dir, chunksDir))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if kept[f.Name()] {
+			continue
+		}
+		if err := os.Remove(s.chunkPath(f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
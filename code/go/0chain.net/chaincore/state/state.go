@@ -24,6 +24,9 @@ type State struct {
 	TxnHashBytes []byte  `json:"-" msgpack:"t"`
 	Round        int64   `json:"round" msgpack:"r"`
 	Balance      Balance `json:"balance" msgpack:"b"`
+	// the nonce of the last transaction of the client with a nonce, the
+	// next one must have the nonce after it
+	Nonce int64 `json:"nonce" msgpack:"n"`
 }
 
 /*GetHash - implement SecureSerializableValueI interface */
@@ -46,6 +49,11 @@ func (s *State) Encode() []byte {
 	buf.Write(s.TxnHashBytes)
 	binary.Write(buf, binary.LittleEndian, s.Round)
 	binary.Write(buf, binary.LittleEndian, s.Balance)
+	// the states of the clients without nonces are encoded as before, so
+	// their hashes don't change
+	if s.Nonce != 0 {
+		binary.Write(buf, binary.LittleEndian, s.Nonce)
+	}
 	return buf.Bytes()
 }
 
@@ -62,6 +70,12 @@ func (s *State) Decode(data []byte) error {
 	binary.Read(buf, binary.LittleEndian, &balance)
 	s.Round = origin
 	s.Balance = Balance(balance)
+	s.Nonce = 0
+	if buf.Len() > 0 {
+		if err := binary.Read(buf, binary.LittleEndian, &s.Nonce); err != nil {
+			return errors.New("invalid state")
+		}
+	}
 	return nil
 }
 
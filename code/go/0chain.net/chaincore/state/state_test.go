@@ -33,6 +33,7 @@ func TestState_GetHash(t *testing.T) {
 		TxnHashBytes []byte
 		Round        int64
 		Balance      Balance
+		Nonce        int64
 	}
 	tests := []struct {
 		name   string
@@ -73,6 +74,7 @@ func TestState_GetHashBytes(t *testing.T) {
 		TxnHashBytes []byte
 		Round        int64
 		Balance      Balance
+		Nonce        int64
 	}
 	tests := []struct {
 		name   string
@@ -113,6 +115,7 @@ func TestState_Encode(t *testing.T) {
 		TxnHashBytes []byte
 		Round        int64
 		Balance      Balance
+		Nonce        int64
 	}
 	tests := []struct {
 		name   string
@@ -166,6 +169,7 @@ func TestState_Decode(t *testing.T) {
 		TxnHashBytes []byte
 		Round        int64
 		Balance      Balance
+		Nonce        int64
 	}
 	type args struct {
 		data []byte
@@ -213,6 +217,7 @@ func TestState_ComputeProperties(t *testing.T) {
 		TxnHashBytes []byte
 		Round        int64
 		Balance      Balance
+		Nonce        int64
 	}
 	tests := []struct {
 		name   string
@@ -257,6 +262,7 @@ func TestState_Set(t *testing.T) {
 		TxnHashBytes []byte
 		Round        int64
 		Balance      Balance
+		Nonce        int64
 	}
 	type args struct {
 		round   int64
@@ -340,3 +346,24 @@ func TestDeserializer_Deserialize(t *testing.T) {
 		})
 	}
 }
+
+func TestState_Nonce(t *testing.T) {
+	t.Parallel()
+
+	st := makeTestState()
+	st.TxnHash = ""
+	withoutNonce := st.Encode()
+	assert.Len(t, withoutNonce, 48, "encoded as before the nonces")
+
+	st.Nonce = 7
+	withNonce := st.Encode()
+	assert.Len(t, withNonce, 56)
+
+	s := &State{Nonce: 3}
+	assert.NoError(t, s.Decode(withNonce))
+	assert.Equal(t, st, s)
+	assert.NoError(t, s.Decode(withoutNonce))
+	assert.Zero(t, s.Nonce)
+
+	assert.Error(t, s.Decode(withNonce[:50]))
+}
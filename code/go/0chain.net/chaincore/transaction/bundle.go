@@ -0,0 +1,85 @@
+package transaction
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+// MaxBundleOperations - the max number of operations of a transaction bundle
+const MaxBundleOperations = 16
+
+// ErrInvalidBundle - the data of a bundle transaction isn't a valid bundle
+var ErrInvalidBundle = common.NewError("invalid_bundle", "invalid transaction bundle")
+
+// BundleOperation - a send or a smart contract call of a transaction bundle
+type BundleOperation struct {
+	ToClientID      string `json:"to_client_id"`
+	Value           int64  `json:"transaction_value,omitempty"`
+	TransactionType int    `json:"transaction_type"`
+	TransactionData string `json:"transaction_data,omitempty"`
+}
+
+// Bundle - the data of a bundle transaction, the operations are applied in
+// order and a failed operation fails the whole bundle
+type Bundle struct {
+	Operations []*BundleOperation `json:"operations"`
+}
+
+// DecodeBundle - decode and validate the bundle of the transaction data
+func DecodeBundle(data string) (*Bundle, error) {
+	var b Bundle
+	if err := json.Unmarshal([]byte(data), &b); err != nil {
+		return nil, common.NewErrorf("invalid_bundle", "decoding bundle: %v", err)
+	}
+	if len(b.Operations) == 0 || len(b.Operations) > MaxBundleOperations {
+		return nil, common.NewErrorf("invalid_bundle",
+			"number of operations must be between 1 and %v", MaxBundleOperations)
+	}
+	for i, op := range b.Operations {
+		if op == nil {
+			return nil, ErrInvalidBundle
+		}
+		if op.TransactionType != TxnTypeSend && op.TransactionType != TxnTypeSmartContract {
+			return nil, common.NewErrorf("invalid_bundle",
+				"operation %v: only sends and smart contract calls allowed", i)
+		}
+		if op.Value < 0 {
+			return nil, common.NewErrorf("invalid_bundle",
+				"operation %v: negative value", i)
+		}
+		if !encryption.IsHash(op.ToClientID) {
+			return nil, common.NewErrorf("invalid_bundle",
+				"operation %v: to client id must be a hexadecimal hash", i)
+		}
+	}
+	return &b, nil
+}
+
+// Encode - the transaction data of the bundle
+func (b *Bundle) Encode() string {
+	buff, _ := json.Marshal(b)
+	return string(buff)
+}
+
+// BundleOperation - the transaction of the operation of the bundle at the
+// index, its hash is derived from the hash of the bundle and the index, so
+// the entities a smart contract creates by the transaction hash don't collide,
+// its max cost is the one of the whole bundle
+func (t *Transaction) BundleOperation(idx int, op *BundleOperation) *Transaction {
+	ot := &Transaction{
+		ClientID:        t.ClientID,
+		PublicKey:       t.PublicKey,
+		ToClientID:      op.ToClientID,
+		ChainID:         t.ChainID,
+		TransactionData: op.TransactionData,
+		Value:           op.Value,
+		CreationDate:    t.CreationDate,
+		MaxCost:         t.MaxCost,
+		TransactionType: op.TransactionType,
+	}
+	ot.Hash = encryption.Hash(t.Hash + ":" + strconv.Itoa(idx))
+	return ot
+}
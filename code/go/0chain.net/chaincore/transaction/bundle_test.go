@@ -0,0 +1,67 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/encryption"
+)
+
+func TestDecodeBundle(t *testing.T) {
+	var (
+		to   = encryption.Hash("to")
+		send = &BundleOperation{ToClientID: to, Value: 1, TransactionType: TxnTypeSend}
+		sc   = &BundleOperation{ToClientID: to, TransactionType: TxnTypeSmartContract,
+			TransactionData: `{"name":"lock"}`}
+	)
+
+	b, err := DecodeBundle((&Bundle{Operations: []*BundleOperation{send, sc}}).Encode())
+	require.NoError(t, err)
+	assert.Equal(t, []*BundleOperation{send, sc}, b.Operations)
+
+	tooMany := make([]*BundleOperation, MaxBundleOperations+1)
+	for i := range tooMany {
+		tooMany[i] = send
+	}
+	for name, data := range map[string]string{
+		"json":      "{",
+		"empty":     (&Bundle{}).Encode(),
+		"too_many":  (&Bundle{Operations: tooMany}).Encode(),
+		"nil":       `{"operations":[null]}`,
+		"data_type": (&Bundle{Operations: []*BundleOperation{{ToClientID: to, TransactionType: TxnTypeData}}}).Encode(),
+		"negative":  (&Bundle{Operations: []*BundleOperation{{ToClientID: to, Value: -1, TransactionType: TxnTypeSend}}}).Encode(),
+		"to_client": (&Bundle{Operations: []*BundleOperation{{ToClientID: "to", TransactionType: TxnTypeSend}}}).Encode(),
+	} {
+		_, err := DecodeBundle(data)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestTransaction_BundleOperation(t *testing.T) {
+	txn := &Transaction{
+		ClientID:        encryption.Hash("from"),
+		PublicKey:       "public key",
+		ChainID:         "chain",
+		CreationDate:    10,
+		MaxCost:         100,
+		TransactionType: TxnTypeBundle,
+	}
+	txn.Hash = encryption.Hash("bundle")
+	op := &BundleOperation{ToClientID: encryption.Hash("to"), Value: 5, TransactionType: TxnTypeSend}
+
+	first, second := txn.BundleOperation(0, op), txn.BundleOperation(1, op)
+	assert.NotEqual(t, txn.Hash, first.Hash)
+	assert.NotEqual(t, first.Hash, second.Hash)
+	assert.Equal(t, first.Hash, txn.BundleOperation(0, op).Hash, "deterministic hash")
+
+	assert.Equal(t, txn.ClientID, first.ClientID)
+	assert.Equal(t, txn.PublicKey, first.PublicKey)
+	assert.Equal(t, txn.ChainID, first.ChainID)
+	assert.Equal(t, txn.CreationDate, first.CreationDate)
+	assert.Equal(t, txn.MaxCost, first.MaxCost)
+	assert.Equal(t, op.ToClientID, first.ToClientID)
+	assert.Equal(t, op.Value, first.Value)
+	assert.Equal(t, TxnTypeSend, first.TransactionType)
+}
@@ -0,0 +1,115 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+
+	"0chain.net/core/datastore"
+)
+
+// the direction of a transaction in the history of a client
+const (
+	ClientTxnSent     = "sent"
+	ClientTxnReceived = "received"
+)
+
+// ClientTxn - a transaction in the history of a client stored by the
+// sharders, the client of a smart contract call is the smart contract, the
+// transactions of a client are ordered by round, newest first
+type ClientTxn struct {
+	datastore.NOIDField
+	ClientID  string `json:"client_id"`
+	Round     int64  `json:"round"`
+	TxnHash   string `json:"txn_hash"`
+	Direction string `json:"direction"`
+	Type      int    `json:"type"`
+	Value     int64  `json:"value"`
+	Status    int    `json:"status"`
+}
+
+var clientTxnEntityMetadata *datastore.EntityMetadataImpl
+
+// ClientTxnProvider - factory method
+func ClientTxnProvider() datastore.Entity {
+	return &ClientTxn{}
+}
+
+func newClientTxn(clientID string, round int64, t *Transaction,
+	direction string, value int64) *ClientTxn {
+
+	ct := datastore.GetEntityMetadata("client_txn").Instance().(*ClientTxn)
+	ct.ClientID = clientID
+	ct.Round = round
+	ct.TxnHash = t.Hash
+	ct.Direction = direction
+	ct.Type = t.TransactionType
+	ct.Value = value
+	ct.Status = t.Status
+	return ct
+}
+
+// GetClientTxns - the entries of the transaction in the histories of its
+// sender and its recipients, a bundle has an entry for every recipient of
+// its operations with the sum of the values sent to it
+func (t *Transaction) GetClientTxns(round int64) []*ClientTxn {
+	if t.TransactionType != TxnTypeBundle {
+		cts := []*ClientTxn{newClientTxn(t.ClientID, round, t, ClientTxnSent, t.Value)}
+		if t.ToClientID != "" {
+			cts = append(cts, newClientTxn(t.ToClientID, round, t, ClientTxnReceived, t.Value))
+		}
+		return cts
+	}
+
+	b, err := DecodeBundle(t.TransactionData)
+	if err != nil {
+		return []*ClientTxn{newClientTxn(t.ClientID, round, t, ClientTxnSent, t.Value)}
+	}
+	var (
+		sent     int64
+		to       []string
+		received = make(map[string]int64)
+	)
+	for _, op := range b.Operations {
+		sent += op.Value
+		if _, ok := received[op.ToClientID]; !ok {
+			to = append(to, op.ToClientID)
+		}
+		received[op.ToClientID] += op.Value
+	}
+	cts := []*ClientTxn{newClientTxn(t.ClientID, round, t, ClientTxnSent, sent)}
+	for _, id := range to {
+		cts = append(cts, newClientTxn(id, round, t, ClientTxnReceived, received[id]))
+	}
+	return cts
+}
+
+// GetEntityMetadata - implement interface
+func (ct *ClientTxn) GetEntityMetadata() datastore.EntityMetadata {
+	return clientTxnEntityMetadata
+}
+
+// GetKey - implement interface
+func (ct *ClientTxn) GetKey() datastore.Key {
+	return datastore.ToKey(fmt.Sprintf("%v:%v:%v:%v", ct.ClientID, ct.Round,
+		ct.TxnHash, ct.Direction))
+}
+
+/*GetScore - score for write*/
+func (ct *ClientTxn) GetScore() int64 {
+	return ct.Round
+}
+
+/*Write - store write */
+func (ct *ClientTxn) Write(ctx context.Context) error {
+	return ct.GetEntityMetadata().GetStore().Write(ctx, ct)
+}
+
+/*SetupClientTxnEntity - setup the client transaction history entity */
+func SetupClientTxnEntity(store datastore.Store) {
+	clientTxnEntityMetadata = datastore.MetadataProvider()
+	clientTxnEntityMetadata.Name = "client_txn"
+	clientTxnEntityMetadata.Provider = ClientTxnProvider
+	clientTxnEntityMetadata.Store = store
+	clientTxnEntityMetadata.IDColumnName = "client_id"
+	datastore.RegisterEntityMetadata("client_txn", clientTxnEntityMetadata)
+}
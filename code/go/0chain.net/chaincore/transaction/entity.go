@@ -53,6 +53,12 @@ type Transaction struct {
 	Signature       string           `json:"signature" msgpack:"s"`
 	CreationDate    common.Timestamp `json:"creation_date" msgpack:"ts"`
 	Fee             int64            `json:"transaction_fee" msgpack:"f"`
+	// the nonce of the transaction of the client, one more than the nonce of
+	// the client state, none for the transactions replay protected by time
+	Nonce int64 `json:"transaction_nonce,omitempty" msgpack:"n,omitempty"`
+	// the max cost of the execution of the transaction, the execution fails
+	// once it's exceeded and the fee is taken in proportion of the cost used
+	MaxCost int64 `json:"max_cost,omitempty" msgpack:"mc,omitempty"`
 
 	TransactionType   int    `json:"transaction_type" msgpack:"tt"`
 	TransactionOutput string `json:"transaction_output,omitempty" msgpack:"o,omitempty"`
@@ -143,6 +149,12 @@ func (t *Transaction) ValidateWrtTimeForBlock(ctx context.Context, ts common.Tim
 	if t.Value < 0 {
 		return common.InvalidRequest("value must be greater than or equal to zero")
 	}
+	if t.Nonce < 0 {
+		return common.InvalidRequest("nonce must be greater than or equal to zero")
+	}
+	if t.MaxCost < 0 {
+		return common.InvalidRequest("max cost must be greater than or equal to zero")
+	}
 	if !encryption.IsHash(t.ToClientID) && t.ToClientID != "" {
 		return common.InvalidRequest("to client id must be a hexadecimal hash")
 	}
@@ -239,6 +251,13 @@ func (t *Transaction) GetClient(ctx context.Context) (*client.Client, error) {
 /*HashData - data used to hash the transaction */
 func (t *Transaction) HashData() string {
 	hashdata := common.TimeToString(t.CreationDate) + ":" + t.ClientID + ":" + t.ToClientID + ":" + strconv.FormatInt(t.Value, 10) + ":" + encryption.Hash(t.TransactionData)
+	if t.Nonce != 0 || t.MaxCost != 0 {
+		// signed along, so the nonce can't be changed to replay the transaction
+		hashdata += ":" + strconv.FormatInt(t.Nonce, 10)
+	}
+	if t.MaxCost != 0 {
+		hashdata += ":" + strconv.FormatInt(t.MaxCost, 10)
+	}
 	return hashdata
 }
 
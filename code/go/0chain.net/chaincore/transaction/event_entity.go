@@ -0,0 +1,77 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+
+	"0chain.net/core/datastore"
+)
+
+// Event - a log of a transaction receipt stored by the sharders with the
+// transaction it belongs to, the events of a round are ordered by index
+type Event struct {
+	datastore.NOIDField
+	Round     int64  `json:"round"`
+	Index     int    `json:"idx"`
+	BlockHash string `json:"block_hash"`
+	TxnHash   string `json:"txn_hash"`
+	Contract  string `json:"contract"`
+	ClientID  string `json:"client_id"`
+	Type      string `json:"type"`
+	Tag       string `json:"tag,omitempty"`
+	Data      string `json:"data,omitempty"`
+}
+
+var eventEntityMetadata *datastore.EntityMetadataImpl
+
+// EventProvider - factory method
+func EventProvider() datastore.Entity {
+	return &Event{}
+}
+
+// NewEvent - the event of the log of a transaction in a block
+func NewEvent(round int64, blockHash string, idx int, txn *Transaction, l *Log) *Event {
+	e := datastore.GetEntityMetadata("txn_event").Instance().(*Event)
+	e.Round = round
+	e.Index = idx
+	e.BlockHash = blockHash
+	e.TxnHash = txn.Hash
+	if e.Contract = l.Contract; e.Contract == "" {
+		e.Contract = txn.ToClientID
+	}
+	e.ClientID = txn.ClientID
+	e.Type = l.Type
+	e.Tag = l.Tag
+	e.Data = l.Data
+	return e
+}
+
+// GetEntityMetadata - implement interface
+func (e *Event) GetEntityMetadata() datastore.EntityMetadata {
+	return eventEntityMetadata
+}
+
+// GetKey - implement interface
+func (e *Event) GetKey() datastore.Key {
+	return datastore.ToKey(fmt.Sprintf("%v:%v", e.Round, e.Index))
+}
+
+/*GetScore - score for write*/
+func (e *Event) GetScore() int64 {
+	return e.Round
+}
+
+/*Write - store write */
+func (e *Event) Write(ctx context.Context) error {
+	return e.GetEntityMetadata().GetStore().Write(ctx, e)
+}
+
+/*SetupEventEntity - setup the txn event entity */
+func SetupEventEntity(store datastore.Store) {
+	eventEntityMetadata = datastore.MetadataProvider()
+	eventEntityMetadata.Name = "txn_event"
+	eventEntityMetadata.Provider = EventProvider
+	eventEntityMetadata.Store = store
+	eventEntityMetadata.IDColumnName = "round"
+	datastore.RegisterEntityMetadata("txn_event", eventEntityMetadata)
+}
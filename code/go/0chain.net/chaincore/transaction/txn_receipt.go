@@ -1,23 +1,71 @@
 package transaction
 
-import "0chain.net/core/util"
+import (
+	"encoding/json"
 
-//TxnReceipt - a transaction receipt is a processed transaction that contains the output
+	"0chain.net/chaincore/state"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+//Log - a structured event log emitted by a smart contract while executing the transaction,
+//the contract is the one of the bundle operation emitting it for a bundle transaction
+type Log struct {
+	Contract string `json:"contract,omitempty"`
+	Type     string `json:"type"`
+	Tag      string `json:"tag,omitempty"`
+	Data     string `json:"data,omitempty"`
+}
+
+//TxnReceipt - a transaction receipt is the result of processing a transaction, everything
+//the transaction produced besides the state changes
 type TxnReceipt struct {
-	Transaction *Transaction
+	TxnHash         string                  `json:"txn_hash"`
+	Status          int                     `json:"status"`
+	Error           string                  `json:"error,omitempty"`
+	Output          string                  `json:"output,omitempty"`
+	Cost            int64                   `json:"cost,omitempty"`
+	Transfers       []*state.Transfer       `json:"transfers,omitempty"`
+	SignedTransfers []*state.SignedTransfer `json:"signed_transfers,omitempty"`
+	Mints           []*state.Mint           `json:"mints,omitempty"`
+	Logs            []*Log                  `json:"logs,omitempty"`
+}
+
+//NewTransactionReceipt - create a new transaction receipt with the status and the output of the transaction
+func NewTransactionReceipt(t *Transaction) *TxnReceipt {
+	return &TxnReceipt{TxnHash: t.Hash, Status: t.Status, Output: t.TransactionOutput}
+}
+
+//Encode - the encoding of the receipt the hash commits to
+func (r *TxnReceipt) Encode() []byte {
+	buff, _ := json.Marshal(r)
+	return buff
 }
 
 //GetHash - implement interface
-func (rh *TxnReceipt) GetHash() string {
-	return rh.Transaction.OutputHash
+func (r *TxnReceipt) GetHash() string {
+	return encryption.Hash(r.Encode())
 }
 
 /*GetHashBytes - implement Hashable interface */
-func (rh *TxnReceipt) GetHashBytes() []byte {
-	return util.HashStringToBytes(rh.Transaction.OutputHash)
+func (r *TxnReceipt) GetHashBytes() []byte {
+	return encryption.RawHash(r.Encode())
 }
 
-//NewTransactionReceipt - create a new transaction receipt
-func NewTransactionReceipt(t *Transaction) *TxnReceipt {
-	return &TxnReceipt{Transaction: t}
+//ReceiptConfirmation - a transaction receipt with the proof of its presence in the receipts of a block
+type ReceiptConfirmation struct {
+	Hash                  string       `json:"hash"`
+	BlockHash             string       `json:"block_hash"`
+	Round                 int64        `json:"round"`
+	Receipt               *TxnReceipt  `json:"receipt"`
+	ReceiptMerkleTreeRoot string       `json:"receipt_merkle_tree_root"`
+	ReceiptMerkleTreePath *util.MTPath `json:"receipt_merkle_tree_path"`
+}
+
+//Verify - verify the receipt is in the receipts Merkle tree
+func (rc *ReceiptConfirmation) Verify() bool {
+	if rc.Receipt == nil || rc.ReceiptMerkleTreePath == nil || rc.Receipt.TxnHash != rc.Hash {
+		return false
+	}
+	return util.VerifyMerklePath(rc.Receipt.GetHash(), rc.ReceiptMerkleTreePath, rc.ReceiptMerkleTreeRoot)
 }
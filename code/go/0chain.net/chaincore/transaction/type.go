@@ -12,4 +12,6 @@ const (
 	TxnTypeData = 10 // A transaction to just store a piece of data on the block chain
 
 	TxnTypeSmartContract = 1000 // A smart contract transaction type
+
+	TxnTypeBundle = 1002 // A bundle of sends and smart contract calls applied all together or not at all
 )
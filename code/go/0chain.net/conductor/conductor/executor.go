@@ -397,6 +397,18 @@ func (r *Runner) WrongBlockHash(wbh *config.Bad) (err error) {
 	return
 }
 
+func (r *Runner) WrongAccessMap(wam *config.Bad) (err error) {
+	r.verbosePrintByGoodBad("wrong access map", wam)
+
+	err = r.server.UpdateStates(wam.By, func(state *conductrpc.State) {
+		state.WrongAccessMap = wam
+	})
+	if err != nil {
+		return fmt.Errorf("setting 'wrong access map': %v", err)
+	}
+	return
+}
+
 func (r *Runner) VerificationTicketGroup(vtg *config.Bad) (err error) {
 	r.verbosePrintByGoodBad("verification ticket group", vtg)
 
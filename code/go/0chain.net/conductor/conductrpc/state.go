@@ -28,6 +28,7 @@ type State struct {
 	WrongBlockSignHash          *config.Bad
 	WrongBlockSignKey           *config.Bad
 	WrongBlockHash              *config.Bad
+	WrongAccessMap              *config.Bad
 	VerificationTicketGroup     *config.Bad
 	WrongVerificationTicketHash *config.Bad
 	WrongVerificationTicketKey  *config.Bad
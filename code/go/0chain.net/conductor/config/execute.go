@@ -57,6 +57,7 @@ type Executor interface {
 	WrongBlockSignHash(wbsh *Bad) (err error)
 	WrongBlockSignKey(wbsk *Bad) (err error)
 	WrongBlockHash(wbh *Bad) (err error)
+	WrongAccessMap(wam *Bad) (err error)
 	VerificationTicketGroup(vtg *Bad) (err error)
 	WrongVerificationTicketHash(wvth *Bad) (err error)
 	WrongVerificationTicketKey(wvtk *Bad) (err error)
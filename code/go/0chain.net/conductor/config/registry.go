@@ -178,6 +178,15 @@ func init() {
 		return ex.WrongBlockHash(&wbh)
 	})
 
+	register("wrong_access_map", func(name string,
+		ex Executor, val interface{}, tm time.Duration) (err error) {
+		var wam Bad
+		if err = wam.Unmarshal(name, val); err != nil {
+			return
+		}
+		return ex.WrongAccessMap(&wam)
+	})
+
 	register("verification_ticket_group", func(name string,
 		ex Executor, val interface{}, tm time.Duration) (err error) {
 		var vtg Bad
@@ -3,7 +3,10 @@ package common
 import (
 	"bytes"
 	"compress/zlib"
+	"encoding/binary"
+	"fmt"
 	"io"
+	"sync"
 
 	"github.com/golang/snappy"
 	"github.com/valyala/gozstd"
@@ -76,8 +79,20 @@ func (zstd *ZStdCompDe) Encoding() string {
 
 //ZStdDictCompDe - a CompDe using dictionary based on zstandard
 type ZStdDictCompDe struct {
-	cdict *gozstd.CDict
-	ddict *gozstd.DDict
+	cdict  *gozstd.CDict
+	ddict  *gozstd.DDict
+	dictID uint32
+}
+
+// the magic number starting a zstd dictionary, followed by its id
+const zstdDictMagic = 0xEC30A437
+
+//ZStdDictID - the id of a trained zstd dictionary, 0 for raw content
+func ZStdDictID(dict []byte) uint32 {
+	if len(dict) < 8 || binary.LittleEndian.Uint32(dict) != zstdDictMagic {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(dict[4:])
 }
 
 //NewZStdCompDeWithDict - create a new ZStdDictCompDe
@@ -93,9 +108,15 @@ func NewZStdCompDeWithDict(dict []byte) (*ZStdDictCompDe, error) {
 	cd := &ZStdDictCompDe{}
 	cd.cdict = cdict
 	cd.ddict = ddict
+	cd.dictID = ZStdDictID(dict)
 	return cd, nil
 }
 
+//DictID - the id of the dictionary
+func (zstd *ZStdDictCompDe) DictID() uint32 {
+	return zstd.dictID
+}
+
 //Compress - implement interface
 func (zstd *ZStdDictCompDe) Compress(data []byte) []byte {
 	return gozstd.CompressDict(nil, data, zstd.cdict)
@@ -106,9 +127,16 @@ func (zstd *ZStdDictCompDe) Decompress(data []byte) ([]byte, error) {
 	return gozstd.DecompressDict(nil, data, zstd.ddict)
 }
 
-//Encoding - implement interface
+//ZStdDictEncoding - the encoding of the zstd dictionary CompDe, naming the
+//dictionary id so that a peer without the dictionary doesn't decode with another
+const ZStdDictEncoding = "zstddict"
+
+//Encoding - implement interface, zstddict-<id> for a trained dictionary
 func (zstd *ZStdDictCompDe) Encoding() string {
-	return "zstddict"
+	if zstd.dictID == 0 {
+		return ZStdDictEncoding
+	}
+	return fmt.Sprintf("%v-%d", ZStdDictEncoding, zstd.dictID)
 }
 
 //ZLibCompDe - a CompDe based on zlib
@@ -148,3 +176,28 @@ func (zlibcd *ZLibCompDe) Decompress(data []byte) ([]byte, error) {
 func (zlibcd *ZLibCompDe) Encoding() string {
 	return "zlib"
 }
+
+var compDes = struct {
+	sync.RWMutex
+	m map[string]CompDe
+}{m: make(map[string]CompDe)}
+
+func init() {
+	RegisterCompDe(NewSnappyCompDe())
+	RegisterCompDe(NewZStdCompDe())
+	RegisterCompDe(NewZLibCompDe())
+}
+
+//RegisterCompDe - make the CompDe available by its encoding, replacing the one of the same encoding
+func RegisterCompDe(cd CompDe) {
+	compDes.Lock()
+	defer compDes.Unlock()
+	compDes.m[cd.Encoding()] = cd
+}
+
+//GetCompDe - the CompDe registered for the encoding, nil if there's none
+func GetCompDe(encoding string) CompDe {
+	compDes.RLock()
+	defer compDes.RUnlock()
+	return compDes.m[encoding]
+}
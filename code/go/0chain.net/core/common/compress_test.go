@@ -485,8 +485,9 @@ func TestZStdDictCompDe_Encoding(t *testing.T) {
 	t.Parallel()
 
 	type fields struct {
-		cdict *gozstd.CDict
-		ddict *gozstd.DDict
+		cdict  *gozstd.CDict
+		ddict  *gozstd.DDict
+		dictID uint32
 	}
 	tests := []struct {
 		name   string
@@ -497,6 +498,11 @@ func TestZStdDictCompDe_Encoding(t *testing.T) {
 			name: "Test_ZStdDictCompDe_Encoding_OK",
 			want: "zstddict",
 		},
+		{
+			name:   "Test_ZStdDictCompDe_Encoding_Dict_ID_OK",
+			fields: fields{dictID: 7},
+			want:   "zstddict-7",
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -504,8 +510,9 @@ func TestZStdDictCompDe_Encoding(t *testing.T) {
 			t.Parallel()
 
 			zstd := &ZStdDictCompDe{
-				cdict: tt.fields.cdict,
-				ddict: tt.fields.ddict,
+				cdict:  tt.fields.cdict,
+				ddict:  tt.fields.ddict,
+				dictID: tt.fields.dictID,
 			}
 			if got := zstd.Encoding(); got != tt.want {
 				t.Errorf("Encoding() = %v, want %v", got, tt.want)
@@ -654,3 +661,23 @@ func TestZLibCompDe_Encoding(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCompDe(t *testing.T) {
+	for _, encoding := range []string{"snappy", "zstd", "zlib"} {
+		cd := GetCompDe(encoding)
+		if cd == nil || cd.Encoding() != encoding {
+			t.Errorf("GetCompDe(%v) = %v", encoding, cd)
+		}
+	}
+	if cd := GetCompDe("lz4"); cd != nil {
+		t.Errorf("GetCompDe(lz4) = %v, want nil", cd)
+	}
+
+	dict := []byte{0x37, 0xa4, 0x30, 0xec, 7, 0, 0, 0}
+	if id := ZStdDictID(dict); id != 7 {
+		t.Errorf("ZStdDictID() = %v, want 7", id)
+	}
+	if id := ZStdDictID([]byte("abcdefgh")); id != 0 {
+		t.Errorf("ZStdDictID() = %v, want 0", id)
+	}
+}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+)
+
+// LocalhostOnly - serve the handler to the requests from the loopback
+// interface only, for the diagnostics too costly to expose to the users. The
+// forwarding headers are not trusted.
+func LocalhostOnly(handler ReqRespHandlerf) ReqRespHandlerf {
+	handler = Recover(handler)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopback(r.RemoteAddr) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": "localhost only",
+				"code":  "forbidden",
+			})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalhostOnly(t *testing.T) {
+	t.Parallel()
+
+	handler := LocalhostOnly(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       int
+	}{
+		{name: "ipv4_loopback", remoteAddr: "127.0.0.1:5050", want: http.StatusOK},
+		{name: "ipv6_loopback", remoteAddr: "[::1]:5050", want: http.StatusOK},
+		{name: "remote", remoteAddr: "10.0.0.7:5050", want: http.StatusForbidden},
+		{name: "forwarded_not_trusted", remoteAddr: "10.0.0.7:5050",
+			forwarded: "127.0.0.1", want: http.StatusForbidden},
+		{name: "invalid", remoteAddr: "localhost", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "/_audit", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
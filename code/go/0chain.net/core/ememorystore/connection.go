@@ -4,10 +4,9 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/0chain/gorocksdb"
-
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
+	"0chain.net/core/kvdb"
 )
 
 func panicf(format string, args ...interface{}) {
@@ -17,15 +16,12 @@ func panicf(format string, args ...interface{}) {
 type dbpool struct {
 	ID     string
 	CtxKey common.ContextKey
-	Pool   *gorocksdb.TransactionDB
+	Pool   kvdb.DB
 }
 
 /*Connection - a struct that manages an underlying connection */
 type Connection struct {
-	Conn               *gorocksdb.Transaction
-	ReadOptions        *gorocksdb.ReadOptions
-	WriteOptions       *gorocksdb.WriteOptions
-	TransactionOptions *gorocksdb.TransactionOptions
+	Conn kvdb.Txn
 }
 
 /*Commit - delegates the commit call to underlying connection */
@@ -33,20 +29,13 @@ func (c *Connection) Commit() error {
 	return c.Conn.Commit()
 }
 
-/*CreateDB - create a database */
-func CreateDB(dataDir string) (*gorocksdb.TransactionDB, error) {
-	bbto := gorocksdb.NewDefaultBlockBasedTableOptions()
-	bbto.SetBlockCache(gorocksdb.NewLRUCache(3 << 30))
-	opts := gorocksdb.NewDefaultOptions()
-	opts.SetKeepLogFileNum(5)
-	opts.SetBlockBasedTableFactory(bbto)
-	opts.SetCreateIfMissing(true)
-	tdbopts := gorocksdb.NewDefaultTransactionDBOptions()
-	return gorocksdb.OpenTransactionDb(opts, tdbopts, dataDir)
+/*CreateDB - create a database on the default database backend */
+func CreateDB(dataDir string) (kvdb.DB, error) {
+	return kvdb.Open(kvdb.DefaultBackend(), dataDir, nil)
 }
 
 //DefaultPool - default db pool
-var DefaultPool *gorocksdb.TransactionDB
+var DefaultPool kvdb.DB
 
 func init1() {
 	dp, err := CreateDB("data/rocksdb")
@@ -67,7 +56,7 @@ func getConnectionCtxKey(dbid string) common.ContextKey {
 }
 
 /*AddPool - add a database pool to the repository of db pools */
-func AddPool(dbid string, db *gorocksdb.TransactionDB) *dbpool {
+func AddPool(dbid string, db kvdb.DB) *dbpool {
 	dbpool := &dbpool{ID: dbid, CtxKey: getConnectionCtxKey(dbid), Pool: db}
 	pools[dbid] = dbpool
 	return dbpool
@@ -91,14 +80,8 @@ func GetConnection() *Connection {
 }
 
 /*GetTransaction - get the transaction object associated with this db */
-func GetTransaction(db *gorocksdb.TransactionDB) *Connection {
-	ro := gorocksdb.NewDefaultReadOptions()
-	wo := gorocksdb.NewDefaultWriteOptions()
-	to := gorocksdb.NewDefaultTransactionOptions()
-
-	t := db.TransactionBegin(wo, to, nil)
-	conn := &Connection{Conn: t, ReadOptions: ro, WriteOptions: wo, TransactionOptions: to}
-	return conn
+func GetTransaction(db kvdb.DB) *Connection {
+	return &Connection{Conn: db.Begin()}
 }
 
 /*GetEntityConnection - returns a connection from the pool configured for the entity */
@@ -220,10 +203,6 @@ func Close(ctx context.Context) {
 		panicf("invalid setup, type of connection is %T", c)
 	}
 	for _, con := range cMap {
-		con.ReadOptions.Destroy()
-		con.WriteOptions.Destroy()
-		con.TransactionOptions.Destroy()
 		con.Conn.Rollback() // commit is expected to be done by the caller of the get connection
-		con.Conn.Destroy()
 	}
 }
@@ -7,10 +7,9 @@ import (
 	"reflect"
 	"testing"
 
-	"github.com/0chain/gorocksdb"
-
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
+	"0chain.net/core/kvdb"
 )
 
 const dataDir = "data"
@@ -39,30 +38,12 @@ func TestCreateDB(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	bbto := gorocksdb.NewDefaultBlockBasedTableOptions()
-	bbto.SetBlockCache(gorocksdb.NewLRUCache(3 << 30))
-	opts := gorocksdb.NewDefaultOptions()
-	opts.SetKeepLogFileNum(5)
-	opts.SetBlockBasedTableFactory(bbto)
-	opts.SetCreateIfMissing(true)
-	tdbopts := gorocksdb.NewDefaultTransactionDBOptions()
-	tDB, err := gorocksdb.OpenTransactionDb(opts, tdbopts, dataDir)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	tDB.Close()
-	if err := cleanUp(); err != nil {
-		t.Fatal(err)
-	}
-
 	type args struct {
 		dataDir string
 	}
 	tests := []struct {
 		name    string
 		args    args
-		want    *gorocksdb.TransactionDB
 		wantErr bool
 	}{
 		{
@@ -70,7 +51,6 @@ func TestCreateDB(t *testing.T) {
 			args: args{
 				dataDir: dataDir,
 			},
-			want:    tDB,
 			wantErr: false,
 		},
 	}
@@ -80,15 +60,16 @@ func TestCreateDB(t *testing.T) {
 			t.Parallel()
 
 			got, err := CreateDB(tt.args.dataDir)
-			if got != nil {
-				got.Close()
-			}
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateDB() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("CreateDB() got = %#v, want %#v", got, tt.want)
+			defer got.Close()
+			if err := got.Put([]byte("key"), []byte("value")); err != nil {
+				t.Fatal(err)
+			}
+			if value, err := got.Get([]byte("key")); err != nil || string(value) != "value" {
+				t.Errorf("CreateDB() got value = %q, err = %v", value, err)
 			}
 		})
 	}
@@ -107,7 +88,7 @@ func TestAddPool(t *testing.T) {
 
 	type args struct {
 		dbid string
-		db   *gorocksdb.TransactionDB
+		db   kvdb.DB
 	}
 	tests := []struct {
 		name string
@@ -179,7 +160,7 @@ func TestGetTransaction(t *testing.T) {
 	defer db.Close()
 
 	type args struct {
-		db *gorocksdb.TransactionDB
+		db kvdb.DB
 	}
 	tests := []struct {
 		name string
@@ -189,15 +170,7 @@ func TestGetTransaction(t *testing.T) {
 		{
 			name: "Test_GetTransaction_OK",
 			args: args{db: db},
-			want: func() *Connection {
-				ro := gorocksdb.NewDefaultReadOptions()
-				wo := gorocksdb.NewDefaultWriteOptions()
-				to := gorocksdb.NewDefaultTransactionOptions()
-
-				t := db.TransactionBegin(wo, to, nil)
-
-				return &Connection{Conn: t, ReadOptions: ro, WriteOptions: wo, TransactionOptions: to}
-			}(),
+			want: &Connection{Conn: db.Begin()},
 		},
 	}
 	for _, tt := range tests {
@@ -238,15 +211,7 @@ func TestGetEntityConnection(t *testing.T) {
 		{
 			name: "TestGetEntityConnection_OK",
 			args: args{entityMetadata: &em},
-			want: func() *Connection {
-				ro := gorocksdb.NewDefaultReadOptions()
-				wo := gorocksdb.NewDefaultWriteOptions()
-				to := gorocksdb.NewDefaultTransactionOptions()
-
-				t := db.TransactionBegin(wo, to, nil)
-
-				return &Connection{Conn: t, ReadOptions: ro, WriteOptions: wo, TransactionOptions: to}
-			}(),
+			want: &Connection{Conn: db.Begin()},
 		},
 		{
 			name: "TestGetEntityConnection_Empty_DB_OK",
@@ -359,10 +324,12 @@ func TestGetEntityCon(t *testing.T) {
 		entityMetadata datastore.EntityMetadata
 	}
 	tests := []struct {
-		name      string
-		args      args
-		want      *Connection
-		wantPanic bool
+		name string
+		args args
+		want *Connection
+		// the connection is a new one registered in the context under the key
+		wantCtxKey common.ContextKey
+		wantPanic  bool
 	}{
 		{
 			name: "Test_GetEntityCon_Nil_Ctx_OK",
@@ -402,7 +369,7 @@ func TestGetEntityCon(t *testing.T) {
 					entityMetadata: &datastore.EntityMetadataImpl{DB: em.DB},
 				}
 			}(),
-			want: GetConnection(),
+			wantCtxKey: "ctxKey",
 		},
 	}
 	for _, tt := range tests {
@@ -413,7 +380,15 @@ func TestGetEntityCon(t *testing.T) {
 					t.Errorf("GetEntityCon() want panic  = %v, but got = %v", tt.wantPanic, got)
 				}
 			}()
-			if got := GetEntityCon(tt.args.ctx, tt.args.entityMetadata); !reflect.DeepEqual(got, tt.want) {
+			got := GetEntityCon(tt.args.ctx, tt.args.entityMetadata)
+			if tt.wantCtxKey != "" {
+				cMap := tt.args.ctx.Value(CONNECTION).(connections)
+				if got == nil || got.Conn == nil || cMap[tt.wantCtxKey] != got {
+					t.Errorf("GetEntityCon() = %v, not registered under %v", got, tt.wantCtxKey)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetEntityCon() = %v, want %v", got, tt.want)
 			}
 		})
@@ -727,10 +702,7 @@ func TestConnection_Commit(t *testing.T) {
 	conn := GetTransaction(db)
 
 	type fields struct {
-		Conn               *gorocksdb.Transaction
-		ReadOptions        *gorocksdb.ReadOptions
-		WriteOptions       *gorocksdb.WriteOptions
-		TransactionOptions *gorocksdb.TransactionOptions
+		Conn kvdb.Txn
 	}
 	tests := []struct {
 		name    string
@@ -740,10 +712,7 @@ func TestConnection_Commit(t *testing.T) {
 		{
 			name: "TestConnection_Commit_OK",
 			fields: fields{
-				Conn:               conn.Conn,
-				ReadOptions:        conn.ReadOptions,
-				WriteOptions:       conn.WriteOptions,
-				TransactionOptions: conn.TransactionOptions,
+				Conn: conn.Conn,
 			},
 			wantErr: false,
 		},
@@ -751,10 +720,7 @@ func TestConnection_Commit(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Connection{
-				Conn:               tt.fields.Conn,
-				ReadOptions:        tt.fields.ReadOptions,
-				WriteOptions:       tt.fields.WriteOptions,
-				TransactionOptions: tt.fields.TransactionOptions,
+				Conn: tt.fields.Conn,
 			}
 			if err := c.Commit(); (err != nil) != tt.wantErr {
 				t.Errorf("Commit() error = %v, wantErr %v", err, tt.wantErr)
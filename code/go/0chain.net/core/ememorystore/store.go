@@ -5,8 +5,6 @@ import (
 	"encoding/binary"
 	"strconv"
 
-	"github.com/0chain/gorocksdb"
-
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 )
@@ -26,7 +24,7 @@ func (ems *Store) Read(ctx context.Context, key datastore.Key, entity datastore.
 	entity.SetKey(key)
 	emd := entity.GetEntityMetadata()
 	c := GetEntityCon(ctx, emd)
-	var data *gorocksdb.Slice
+	var data []byte
 	var err error
 	if emd.GetName() == "round" {
 		rNumber, err := strconv.ParseInt(datastore.ToString(entity.GetKey()), 10, 64)
@@ -35,18 +33,17 @@ func (ems *Store) Read(ctx context.Context, key datastore.Key, entity datastore.
 		}
 		key := make([]byte, 8)
 		binary.BigEndian.PutUint64(key, uint64(rNumber))
-		data, err = c.Conn.Get(c.ReadOptions, key)
+		data, err = c.Conn.Get(key)
 		if err != nil {
 			return err
 		}
 	} else {
-		data, err = c.Conn.Get(c.ReadOptions, []byte(key))
+		data, err = c.Conn.Get([]byte(key))
 		if err != nil {
 			return err
 		}
 	}
-	defer data.Free()
-	err = datastore.FromJSON(data.Data(), entity)
+	err = datastore.FromJSON(data, entity)
 	if err != nil {
 		return err
 	}
@@ -78,7 +75,7 @@ func (ems *Store) Write(ctx context.Context, entity datastore.Entity) error {
 func (ems *Store) InsertIfNE(ctx context.Context, entity datastore.Entity) error {
 	emd := entity.GetEntityMetadata()
 	c := GetEntityCon(ctx, emd)
-	_, err := c.Conn.Get(c.ReadOptions, []byte(datastore.ToString(entity.GetKey())))
+	_, err := c.Conn.Get([]byte(datastore.ToString(entity.GetKey())))
 	if err == nil {
 		return common.NewError("entity_already_exists", "Entity already exists")
 	}
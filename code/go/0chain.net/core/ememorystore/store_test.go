@@ -9,7 +9,6 @@ import (
 	"reflect"
 	"testing"
 
-	"github.com/0chain/gorocksdb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -18,6 +17,7 @@ import (
 	"0chain.net/core/datastore"
 	"0chain.net/core/ememorystore"
 	"0chain.net/core/encryption"
+	"0chain.net/core/kvdb"
 )
 
 const dataDir = "data"
@@ -36,7 +36,7 @@ var connections = map[string]string{
 	"block": "block",
 }
 
-var pools = make(map[string]*gorocksdb.TransactionDB)
+var pools = make(map[string]kvdb.DB)
 
 func initDBs() error {
 	for entityName, dbName := range connections {
@@ -66,7 +66,7 @@ func closeTestPools() {
 	for _, pool := range pools {
 		pool.Close()
 	}
-	pools = make(map[string]*gorocksdb.TransactionDB)
+	pools = make(map[string]kvdb.DB)
 }
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,215 @@
+package kvdb
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	boltFile = "kv.db"
+	// boltIteratorChunk - the number of entries an iterator reads at once,
+	// the read transactions are kept short so the writes of a handler of
+	// an iteration don't wait for the iteration
+	boltIteratorChunk = 1024
+)
+
+var boltBucket = []byte("kv")
+
+func init() {
+	Register(BackendBolt, openBolt)
+}
+
+// boltDB - a database of a single bbolt file of the directory
+type boltDB struct {
+	db *bolt.DB
+}
+
+func openBolt(dir string, _ *Options) (DB, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(filepath.Join(dir, boltFile), 0600,
+		&bolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltDB{db: db}, nil
+}
+
+func (b *boltDB) Get(key []byte) (value []byte, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		value = copyBytes(tx.Bucket(boltBucket).Get(key))
+		return nil
+	})
+	return
+}
+
+func (b *boltDB) Put(key, value []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put(key, value)
+	})
+}
+
+func (b *boltDB) Delete(key []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete(key)
+	})
+}
+
+func (b *boltDB) Write(batch *Batch) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		for _, op := range batch.ops {
+			var err error
+			if op.delete {
+				err = bucket.Delete(op.key)
+			} else {
+				err = bucket.Put(op.key, op.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *boltDB) NewIterator() Iterator {
+	return &boltIterator{db: b.db}
+}
+
+func (b *boltDB) Begin() Txn {
+	return newBufferedTxn(b)
+}
+
+func (b *boltDB) Flush() error {
+	return b.db.Sync()
+}
+
+func (b *boltDB) Close() error {
+	return b.db.Close()
+}
+
+type boltEntry struct {
+	key, value []byte
+}
+
+// boltIterator - an iterator reading the entries in chunks, each chunk in a
+// read transaction of its own
+type boltIterator struct {
+	db      *bolt.DB
+	entries []boltEntry // the chunk, in the key order
+	pos     int
+	err     error
+}
+
+func (it *boltIterator) SeekToFirst() {
+	it.loadForward(nil, false)
+}
+
+func (it *boltIterator) SeekToLast() {
+	it.loadBackward(nil)
+}
+
+func (it *boltIterator) Seek(key []byte) {
+	it.loadForward(copyBytes(key), false)
+}
+
+func (it *boltIterator) Valid() bool {
+	return it.err == nil && it.pos >= 0 && it.pos < len(it.entries)
+}
+
+func (it *boltIterator) Next() {
+	if !it.Valid() {
+		return
+	}
+	if it.pos++; it.pos == len(it.entries) {
+		it.loadForward(it.entries[len(it.entries)-1].key, true)
+	}
+}
+
+func (it *boltIterator) Prev() {
+	if !it.Valid() {
+		return
+	}
+	if it.pos--; it.pos < 0 {
+		it.loadBackward(it.entries[0].key)
+	}
+}
+
+func (it *boltIterator) Key() []byte {
+	return it.entries[it.pos].key
+}
+
+func (it *boltIterator) Value() []byte {
+	return it.entries[it.pos].value
+}
+
+func (it *boltIterator) Err() error {
+	return it.err
+}
+
+func (it *boltIterator) Close() {
+	it.entries = nil
+}
+
+// loadForward - load the chunk starting at the key, or after the key, from
+// the first key if nil
+func (it *boltIterator) loadForward(from []byte, after bool) {
+	var entries []boltEntry
+	it.err = it.db.View(func(tx *bolt.Tx) error {
+		var (
+			c    = tx.Bucket(boltBucket).Cursor()
+			k, v []byte
+		)
+		if from == nil {
+			k, v = c.First()
+		} else if k, v = c.Seek(from); after && bytes.Equal(k, from) {
+			k, v = c.Next()
+		}
+		for ; k != nil && len(entries) < boltIteratorChunk; k, v = c.Next() {
+			entries = append(entries, boltEntry{copyBytes(k), copyBytes(v)})
+		}
+		return nil
+	})
+	it.entries, it.pos = entries, 0
+}
+
+// loadBackward - load the chunk ending before the key, at the last key if
+// nil
+func (it *boltIterator) loadBackward(before []byte) {
+	var entries []boltEntry
+	it.err = it.db.View(func(tx *bolt.Tx) error {
+		var (
+			c    = tx.Bucket(boltBucket).Cursor()
+			k, v []byte
+		)
+		if before == nil {
+			k, v = c.Last()
+		} else if k, _ = c.Seek(before); k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+		for ; k != nil && len(entries) < boltIteratorChunk; k, v = c.Prev() {
+			entries = append(entries, boltEntry{copyBytes(k), copyBytes(v)})
+		}
+		return nil
+	})
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	it.entries, it.pos = entries, len(entries)-1
+}
@@ -0,0 +1,231 @@
+// Package kvdb provides the key value databases behind the persistent node
+// db of the state and the entity stores, so the storage engine is a backend
+// chosen by name. The rocksdb backend needs cgo and is left out with the
+// norocksdb build tag; the bbolt backend is pure Go.
+package kvdb
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	// BackendRocksDB - the rocksdb backend, cgo
+	BackendRocksDB = "rocksdb"
+	// BackendBolt - the bbolt backend, pure Go
+	BackendBolt = "bbolt"
+)
+
+var (
+	// ErrUnknownBackend - no backend registered with the name
+	ErrUnknownBackend = errors.New("unknown database backend")
+	// ErrTxnClosed - the transaction has been committed or rolled back
+	ErrTxnClosed = errors.New("transaction is closed")
+)
+
+// DB - a key value database with the keys in the byte order
+type DB interface {
+	// Get - the value of the key, empty if the key is missing
+	Get(key []byte) ([]byte, error)
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	// Write - apply the writes of the batch at once
+	Write(batch *Batch) error
+	// NewIterator - an iterator of the keys of the database, it has to be
+	// closed
+	NewIterator() Iterator
+	// Begin - a transaction, it has to be committed or rolled back
+	Begin() Txn
+	// Flush - persist the writes kept in memory, if any
+	Flush() error
+	Close() error
+}
+
+// Txn - a transaction, the writes are applied at once on commit and the
+// reads see the writes of the transaction. There is no isolation beyond
+// that: the reads see the writes committed meanwhile, and the transactions
+// of the backends without transactions of their own (bolt, plain rocksdb)
+// don't detect conflicts, the last commit wins. The callers writing the
+// same keys concurrently have to serialize their transactions.
+type Txn interface {
+	Get(key []byte) ([]byte, error)
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	// NewIterator - an iterator of the committed keys, whether it sees the
+	// writes of the transaction depends on the backend
+	NewIterator() Iterator
+	Commit() error
+	// Rollback - discard the writes and release the transaction, it can't
+	// be used afterwards, even if committed
+	Rollback() error
+}
+
+// Iterator - iterate the keys in the byte order, the key and the value are
+// valid until the iterator moves
+type Iterator interface {
+	SeekToFirst()
+	SeekToLast()
+	// Seek - move to the first key at or after the key
+	Seek(key []byte)
+	Valid() bool
+	Next()
+	Prev()
+	Key() []byte
+	Value() []byte
+	Err() error
+	Close()
+}
+
+// Options - the tuning of a database, a backend ignores what it doesn't
+// support
+type Options struct {
+	LogDir      string // the directory of the logs of the database
+	PointLookup bool   // tuned for the point lookups, as the state nodes
+	PlainTable  bool   // the plain table format of the point lookups
+}
+
+type batchOp struct {
+	key, value []byte
+	delete     bool
+}
+
+// Batch - the writes to apply at once
+type Batch struct {
+	ops []batchOp
+}
+
+// NewBatch - a new empty batch
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+// Put - add the put of the key to the batch
+func (b *Batch) Put(key, value []byte) {
+	b.ops = append(b.ops, batchOp{key: key, value: value})
+}
+
+// Delete - add the delete of the key to the batch
+func (b *Batch) Delete(key []byte) {
+	b.ops = append(b.ops, batchOp{key: key, delete: true})
+}
+
+// Len - the number of writes of the batch
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+// Reset - clear the batch for reuse
+func (b *Batch) Reset() {
+	b.ops = b.ops[:0]
+}
+
+// OpenFunc - open the database of the directory, created if missing
+type OpenFunc func(dir string, opts *Options) (DB, error)
+
+var (
+	backendsMutex  sync.RWMutex
+	backends       = make(map[string]OpenFunc)
+	defaultBackend string
+)
+
+// Register - register the backend with the name
+func Register(name string, open OpenFunc) {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+	backends[name] = open
+}
+
+// Backends - the names of the registered backends
+func Backends() []string {
+	backendsMutex.RLock()
+	defer backendsMutex.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetDefaultBackend - set the backend of the databases opened by the node
+func SetDefaultBackend(name string) error {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+	if _, ok := backends[name]; !ok {
+		return fmt.Errorf("%w: %v", ErrUnknownBackend, name)
+	}
+	defaultBackend = name
+	return nil
+}
+
+// DefaultBackend - the backend of the databases opened by the node, rocksdb
+// unless set or left out of the build
+func DefaultBackend() string {
+	backendsMutex.RLock()
+	defer backendsMutex.RUnlock()
+	if defaultBackend != "" {
+		return defaultBackend
+	}
+	if _, ok := backends[BackendRocksDB]; ok {
+		return BackendRocksDB
+	}
+	return BackendBolt
+}
+
+// Open - open the database of the directory with the backend
+func Open(backend, dir string, opts *Options) (DB, error) {
+	backendsMutex.RLock()
+	open, ok := backends[backend]
+	backendsMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownBackend, backend)
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	return open(dir, opts)
+}
+
+// Copy - copy the keys of the source database to the destination one in
+// batches of the size, the number of keys copied
+func Copy(dst, src DB, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	it := src.NewIterator()
+	defer it.Close()
+	var (
+		batch = NewBatch()
+		count int64
+	)
+	for it.SeekToFirst(); it.Valid(); it.Next() {
+		batch.Put(copyBytes(it.Key()), copyBytes(it.Value()))
+		count++
+		if batch.Len() == batchSize {
+			if err := dst.Write(batch); err != nil {
+				return count, err
+			}
+			batch = NewBatch()
+		}
+	}
+	if err := it.Err(); err != nil {
+		return count, err
+	}
+	if batch.Len() > 0 {
+		if err := dst.Write(batch); err != nil {
+			return count, err
+		}
+	}
+	return count, dst.Flush()
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
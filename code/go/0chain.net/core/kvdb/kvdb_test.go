@@ -0,0 +1,285 @@
+package kvdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOptions - the options the databases of the node are opened with
+var testOptions = map[string]*Options{
+	"entity": nil,
+	"state":  {PointLookup: true},
+}
+
+// forEachBackend - run the test against a new database of each backend
+// opened with each of the options
+func forEachBackend(t *testing.T, test func(t *testing.T, open func() DB)) {
+	for _, backend := range Backends() {
+		for name, opts := range testOptions {
+			backend, opts := backend, opts
+			t.Run(backend+"/"+name, func(t *testing.T) {
+				dir, err := ioutil.TempDir("", "kvdb")
+				require.NoError(t, err)
+				var db DB
+				t.Cleanup(func() {
+					if db != nil {
+						db.Close()
+					}
+					os.RemoveAll(dir)
+				})
+				open := func() DB {
+					if db != nil {
+						require.NoError(t, db.Close())
+					}
+					db, err = Open(backend, dir, opts)
+					require.NoError(t, err)
+					return db
+				}
+				test(t, open)
+			})
+		}
+	}
+}
+
+func testKey(i int) []byte {
+	return []byte(fmt.Sprintf("key%06d", i))
+}
+
+func testValue(i int) []byte {
+	return []byte(fmt.Sprintf("value%d", i))
+}
+
+// keys - the keys of the iterator from its position on
+func keys(it Iterator, forward bool) (keys []string) {
+	for it.Valid() {
+		keys = append(keys, string(it.Key()))
+		if forward {
+			it.Next()
+		} else {
+			it.Prev()
+		}
+	}
+	return
+}
+
+func TestOpen_UnknownBackend(t *testing.T) {
+	_, err := Open("unknown", "", nil)
+	assert.ErrorIs(t, err, ErrUnknownBackend)
+	assert.ErrorIs(t, SetDefaultBackend("unknown"), ErrUnknownBackend)
+	assert.Contains(t, Backends(), DefaultBackend())
+}
+
+func TestDB_GetPutDelete(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, open func() DB) {
+		db := open()
+
+		value, err := db.Get([]byte("missing"))
+		require.NoError(t, err)
+		assert.Empty(t, value)
+
+		require.NoError(t, db.Put([]byte("key"), []byte("value")))
+		value, err = db.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), value)
+
+		require.NoError(t, db.Put([]byte("key"), []byte("other")))
+		value, err = db.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("other"), value)
+
+		require.NoError(t, db.Delete([]byte("key")))
+		value, err = db.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Empty(t, value)
+		require.NoError(t, db.Delete([]byte("key")), "delete of a missing key")
+	})
+}
+
+func TestDB_Write(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, open func() DB) {
+		db := open()
+		require.NoError(t, db.Put([]byte("deleted"), []byte("value")))
+
+		batch := NewBatch()
+		batch.Put([]byte("a"), []byte("1"))
+		batch.Put([]byte("b"), []byte("2"))
+		batch.Delete([]byte("deleted"))
+		require.Equal(t, 3, batch.Len())
+		require.NoError(t, db.Write(batch))
+
+		for key, want := range map[string]string{"a": "1", "b": "2", "deleted": ""} {
+			value, err := db.Get([]byte(key))
+			require.NoError(t, err)
+			assert.Equal(t, want, string(value), key)
+		}
+
+		batch.Reset()
+		require.Equal(t, 0, batch.Len())
+		require.NoError(t, db.Write(batch), "empty batch")
+	})
+}
+
+func TestDB_Iterator(t *testing.T) {
+	// more keys than a chunk of the bbolt iterator
+	const n = 2*boltIteratorChunk + 10
+	forEachBackend(t, func(t *testing.T, open func() DB) {
+		db := open()
+		batch := NewBatch()
+		var want []string
+		for i := 0; i < n; i++ {
+			batch.Put(testKey(i), testValue(i))
+			want = append(want, string(testKey(i)))
+		}
+		require.NoError(t, db.Write(batch))
+
+		it := db.NewIterator()
+		defer it.Close()
+
+		it.SeekToFirst()
+		require.True(t, it.Valid())
+		assert.Equal(t, testValue(0), it.Value())
+		assert.Equal(t, want, keys(it, true))
+		require.NoError(t, it.Err())
+
+		it.SeekToLast()
+		var reversed []string
+		for i := len(want) - 1; i >= 0; i-- {
+			reversed = append(reversed, want[i])
+		}
+		assert.Equal(t, reversed, keys(it, false))
+
+		it.Seek(testKey(n - 5))
+		assert.Equal(t, want[n-5:], keys(it, true))
+
+		it.Seek([]byte("key"))
+		require.True(t, it.Valid(), "seek before the first key")
+		assert.Equal(t, testKey(0), it.Key())
+
+		it.Seek([]byte("z"))
+		assert.False(t, it.Valid(), "seek after the last key")
+
+		// a chunk boundary crossed backward and then forward
+		it.Seek(testKey(boltIteratorChunk + 1))
+		for i := 0; i < 3; i++ {
+			it.Prev()
+		}
+		require.True(t, it.Valid())
+		assert.Equal(t, testKey(boltIteratorChunk-2), it.Key())
+		it.Next()
+		require.True(t, it.Valid())
+		assert.Equal(t, testKey(boltIteratorChunk-1), it.Key())
+	})
+}
+
+func TestDB_IteratorEmpty(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, open func() DB) {
+		it := open().NewIterator()
+		defer it.Close()
+		it.SeekToFirst()
+		assert.False(t, it.Valid())
+		it.SeekToLast()
+		assert.False(t, it.Valid())
+		require.NoError(t, it.Err())
+	})
+}
+
+func TestTxn(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, open func() DB) {
+		db := open()
+		require.NoError(t, db.Put([]byte("deleted"), []byte("value")))
+
+		txn := db.Begin()
+		require.NoError(t, txn.Put([]byte("key"), []byte("value")))
+		require.NoError(t, txn.Delete([]byte("deleted")))
+
+		// the transaction reads its own writes, the database doesn't
+		value, err := txn.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), value)
+		value, err = txn.Get([]byte("deleted"))
+		require.NoError(t, err)
+		assert.Empty(t, value)
+		value, err = db.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Empty(t, value)
+
+		require.NoError(t, txn.Commit())
+		require.NoError(t, txn.Rollback(), "release of a committed transaction")
+		value, err = db.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), value)
+		value, err = db.Get([]byte("deleted"))
+		require.NoError(t, err)
+		assert.Empty(t, value)
+		assert.ErrorIs(t, txn.Commit(), ErrTxnClosed)
+
+		txn = db.Begin()
+		require.NoError(t, txn.Put([]byte("rolled back"), []byte("value")))
+		require.NoError(t, txn.Rollback())
+		value, err = db.Get([]byte("rolled back"))
+		require.NoError(t, err)
+		assert.Empty(t, value)
+	})
+}
+
+func TestTxn_Iterator(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, open func() DB) {
+		db := open()
+		for i := 0; i < 3; i++ {
+			require.NoError(t, db.Put(testKey(i), testValue(i)))
+		}
+		txn := db.Begin()
+		defer txn.Rollback()
+		it := txn.NewIterator()
+		defer it.Close()
+		it.SeekToLast()
+		assert.Equal(t, []string{"key000002", "key000001", "key000000"}, keys(it, false))
+	})
+}
+
+func TestDB_Reopen(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, open func() DB) {
+		db := open()
+		require.NoError(t, db.Put([]byte("key"), []byte("value")))
+		require.NoError(t, db.Flush())
+
+		db = open()
+		value, err := db.Get([]byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), value)
+	})
+}
+
+func TestCopy(t *testing.T) {
+	const n = 100
+	forEachBackend(t, func(t *testing.T, open func() DB) {
+		src := open()
+		for i := 0; i < n; i++ {
+			require.NoError(t, src.Put(testKey(i), testValue(i)))
+		}
+		for _, backend := range Backends() {
+			t.Run(backend, func(t *testing.T) {
+				dir, err := ioutil.TempDir("", "kvdb-copy")
+				require.NoError(t, err)
+				defer os.RemoveAll(dir)
+				dst, err := Open(backend, dir, nil)
+				require.NoError(t, err)
+				defer dst.Close()
+
+				count, err := Copy(dst, src, 7)
+				require.NoError(t, err)
+				assert.Equal(t, int64(n), count)
+				for i := 0; i < n; i++ {
+					value, err := dst.Get(testKey(i))
+					require.NoError(t, err)
+					assert.Equal(t, testValue(i), value)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"0chain.net/core/kvdb"
+)
+
+func main() {
+	from := flag.String("from", "", "the directory of the database to copy, as data/rocksdb/state")
+	fromBackend := flag.String("from_backend", kvdb.BackendRocksDB, "the backend of the database to copy")
+	to := flag.String("to", "", "the directory of the new database")
+	toBackend := flag.String("to_backend", kvdb.BackendBolt, "the backend of the new database")
+	state := flag.Bool("state", false, "the database is the state node db, not an entity store")
+	plainTable := flag.Bool("plain_table", false, "the state node db uses the rocksdb plain table format")
+	batchSize := flag.Int("batch", 10000, "the number of keys written at once")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"Copy a database to another backend, the backends are %v.\n\nUsage of %s:\n",
+			strings.Join(kvdb.Backends(), ", "), os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *from == "" || *to == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if _, err := os.Stat(*from); err != nil {
+		fatal("source database: %v", err)
+	}
+	opts := &kvdb.Options{PointLookup: *state, PlainTable: *plainTable}
+
+	src, err := kvdb.Open(*fromBackend, *from, opts)
+	if err != nil {
+		fatal("open source database: %v", err)
+	}
+	defer src.Close()
+	dst, err := kvdb.Open(*toBackend, *to, opts)
+	if err != nil {
+		fatal("open new database: %v", err)
+	}
+	defer dst.Close()
+
+	it := dst.NewIterator()
+	it.SeekToFirst()
+	empty := !it.Valid()
+	it.Close()
+	if !empty {
+		fatal("the new database %v is not empty", *to)
+	}
+
+	count, err := kvdb.Copy(dst, src, *batchSize)
+	if err != nil {
+		fatal("copy after %d keys: %v", count, err)
+	}
+	fmt.Printf("copied %d keys from %v (%v) to %v (%v)\n",
+		count, *from, *fromBackend, *to, *toBackend)
+}
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,293 @@
+// +build !norocksdb
+
+package kvdb
+
+import (
+	"sync"
+
+	"github.com/0chain/gorocksdb"
+)
+
+func init() {
+	Register(BackendRocksDB, openRocksDB)
+}
+
+func openRocksDB(dir string, opts *Options) (DB, error) {
+	if opts.PointLookup {
+		return openRocksPlainDB(dir, opts)
+	}
+	return openRocksTxnDB(dir, opts)
+}
+
+// rocksPlainDB - a rocksdb database tuned for the point lookups, without
+// the transactions of rocksdb
+type rocksPlainDB struct {
+	db *gorocksdb.DB
+	ro *gorocksdb.ReadOptions
+	wo *gorocksdb.WriteOptions
+	fo *gorocksdb.FlushOptions
+}
+
+func openRocksPlainDB(dir string, o *Options) (DB, error) {
+	opts := gorocksdb.NewDefaultOptions()
+	opts.SetCreateIfMissing(true)
+	opts.SetCompression(gorocksdb.LZ4Compression)
+	if o.PlainTable {
+		opts.SetAllowMmapReads(true)
+		opts.SetPrefixExtractor(gorocksdb.NewFixedPrefixTransform(6))
+		opts.SetPlainTableFactory(32, 10, 0.75, 16)
+	} else {
+		opts.OptimizeForPointLookup(64)
+		opts.SetAllowMmapReads(true)
+		opts.SetPrefixExtractor(gorocksdb.NewFixedPrefixTransform(6))
+	}
+	opts.IncreaseParallelism(2)          // pruning and saving happen in parallel
+	opts.SetSkipLogErrorOnRecovery(true) // do sync if necessary
+	opts.SetDbLogDir(o.LogDir)
+	opts.EnableStatistics()
+	opts.OptimizeUniversalStyleCompaction(64 * 1024 * 1024)
+	db, err := gorocksdb.OpenDb(opts, dir)
+	if err != nil {
+		return nil, err
+	}
+	wo := gorocksdb.NewDefaultWriteOptions()
+	wo.SetSync(false)
+	return &rocksPlainDB{
+		db: db,
+		ro: gorocksdb.NewDefaultReadOptions(),
+		wo: wo,
+		fo: gorocksdb.NewDefaultFlushOptions(),
+	}, nil
+}
+
+func (r *rocksPlainDB) Get(key []byte) ([]byte, error) {
+	data, err := r.db.Get(r.ro, key)
+	if err != nil {
+		return nil, err
+	}
+	defer data.Free()
+	return copyBytes(data.Data()), nil
+}
+
+func (r *rocksPlainDB) Put(key, value []byte) error {
+	return r.db.Put(r.wo, key, value)
+}
+
+func (r *rocksPlainDB) Delete(key []byte) error {
+	return r.db.Delete(r.wo, key)
+}
+
+func (r *rocksPlainDB) Write(batch *Batch) error {
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+	for _, op := range batch.ops {
+		if op.delete {
+			wb.Delete(op.key)
+		} else {
+			wb.Put(op.key, op.value)
+		}
+	}
+	return r.db.Write(r.wo, wb)
+}
+
+func (r *rocksPlainDB) NewIterator() Iterator {
+	ro := gorocksdb.NewDefaultReadOptions()
+	ro.SetFillCache(false)
+	return &rocksIterator{it: r.db.NewIterator(ro), ro: ro}
+}
+
+func (r *rocksPlainDB) Begin() Txn {
+	return newBufferedTxn(r)
+}
+
+func (r *rocksPlainDB) Flush() error {
+	return r.db.Flush(r.fo)
+}
+
+func (r *rocksPlainDB) Close() error {
+	r.db.Close()
+	return nil
+}
+
+// rocksTxnDB - a rocksdb transaction database
+type rocksTxnDB struct {
+	db *gorocksdb.TransactionDB
+	ro *gorocksdb.ReadOptions
+	wo *gorocksdb.WriteOptions
+	to *gorocksdb.TransactionOptions
+}
+
+func openRocksTxnDB(dir string, _ *Options) (DB, error) {
+	bbto := gorocksdb.NewDefaultBlockBasedTableOptions()
+	bbto.SetBlockCache(gorocksdb.NewLRUCache(3 << 30))
+	opts := gorocksdb.NewDefaultOptions()
+	opts.SetKeepLogFileNum(5)
+	opts.SetBlockBasedTableFactory(bbto)
+	opts.SetCreateIfMissing(true)
+	tdbopts := gorocksdb.NewDefaultTransactionDBOptions()
+	db, err := gorocksdb.OpenTransactionDb(opts, tdbopts, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &rocksTxnDB{
+		db: db,
+		ro: gorocksdb.NewDefaultReadOptions(),
+		wo: gorocksdb.NewDefaultWriteOptions(),
+		to: gorocksdb.NewDefaultTransactionOptions(),
+	}, nil
+}
+
+func (r *rocksTxnDB) Get(key []byte) ([]byte, error) {
+	data, err := r.db.Get(r.ro, key)
+	if err != nil {
+		return nil, err
+	}
+	defer data.Free()
+	return copyBytes(data.Data()), nil
+}
+
+func (r *rocksTxnDB) Put(key, value []byte) error {
+	return r.db.Put(r.wo, key, value)
+}
+
+func (r *rocksTxnDB) Delete(key []byte) error {
+	return r.db.Delete(r.wo, key)
+}
+
+func (r *rocksTxnDB) Write(batch *Batch) error {
+	t := r.begin()
+	defer t.Rollback()
+	for _, op := range batch.ops {
+		var err error
+		if op.delete {
+			err = t.Delete(op.key)
+		} else {
+			err = t.Put(op.key, op.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return t.Commit()
+}
+
+// NewIterator - the iterator of a transaction of its own, a transaction
+// database has no iterator
+func (r *rocksTxnDB) NewIterator() Iterator {
+	t := r.begin()
+	it := t.NewIterator().(*rocksIterator)
+	it.release = func() { t.Rollback() }
+	return it
+}
+
+func (r *rocksTxnDB) Begin() Txn {
+	return r.begin()
+}
+
+func (r *rocksTxnDB) begin() *rocksTxn {
+	return &rocksTxn{t: r.db.TransactionBegin(r.wo, r.to, nil), ro: r.ro}
+}
+
+func (r *rocksTxnDB) Flush() error {
+	return nil
+}
+
+func (r *rocksTxnDB) Close() error {
+	r.db.Close()
+	return nil
+}
+
+// rocksTxn - a rocksdb transaction
+type rocksTxn struct {
+	t         *gorocksdb.Transaction
+	ro        *gorocksdb.ReadOptions
+	mutex     sync.Mutex
+	committed bool
+	closed    bool
+}
+
+func (t *rocksTxn) Get(key []byte) ([]byte, error) {
+	data, err := t.t.Get(t.ro, key)
+	if err != nil {
+		return nil, err
+	}
+	defer data.Free()
+	return copyBytes(data.Data()), nil
+}
+
+func (t *rocksTxn) Put(key, value []byte) error {
+	return t.t.Put(key, value)
+}
+
+func (t *rocksTxn) Delete(key []byte) error {
+	return t.t.Delete(key)
+}
+
+func (t *rocksTxn) NewIterator() Iterator {
+	return &rocksIterator{it: t.t.NewIterator(t.ro)}
+}
+
+func (t *rocksTxn) Commit() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.closed {
+		return ErrTxnClosed
+	}
+	if err := t.t.Commit(); err != nil {
+		return err
+	}
+	t.committed = true
+	return nil
+}
+
+func (t *rocksTxn) Rollback() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	var err error
+	if !t.committed {
+		err = t.t.Rollback()
+	}
+	t.t.Destroy()
+	return err
+}
+
+// rocksIterator - a rocksdb iterator with copies of the keys and the values
+type rocksIterator struct {
+	it      *gorocksdb.Iterator
+	ro      *gorocksdb.ReadOptions // the read options of the iterator, if any
+	release func()
+}
+
+func (it *rocksIterator) SeekToFirst()    { it.it.SeekToFirst() }
+func (it *rocksIterator) SeekToLast()     { it.it.SeekToLast() }
+func (it *rocksIterator) Seek(key []byte) { it.it.Seek(key) }
+func (it *rocksIterator) Valid() bool     { return it.it.Valid() }
+func (it *rocksIterator) Next()           { it.it.Next() }
+func (it *rocksIterator) Prev()           { it.it.Prev() }
+func (it *rocksIterator) Err() error      { return it.it.Err() }
+
+func (it *rocksIterator) Key() []byte {
+	key := it.it.Key()
+	defer key.Free()
+	return copyBytes(key.Data())
+}
+
+func (it *rocksIterator) Value() []byte {
+	value := it.it.Value()
+	defer value.Free()
+	return copyBytes(value.Data())
+}
+
+func (it *rocksIterator) Close() {
+	it.it.Close()
+	if it.ro != nil {
+		it.ro.Destroy()
+	}
+	if it.release != nil {
+		it.release()
+	}
+}
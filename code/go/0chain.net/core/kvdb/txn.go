@@ -0,0 +1,91 @@
+package kvdb
+
+import "sync"
+
+type txnWrite struct {
+	value  []byte
+	delete bool
+}
+
+// bufferedTxn - a transaction of a database without transactions of its
+// own, the writes are kept in memory and written as a batch on commit, the
+// last commit wins on conflicts
+type bufferedTxn struct {
+	db     DB
+	mutex  sync.Mutex
+	writes map[string]txnWrite
+	closed bool
+}
+
+func newBufferedTxn(db DB) *bufferedTxn {
+	return &bufferedTxn{db: db, writes: make(map[string]txnWrite)}
+}
+
+func (t *bufferedTxn) Get(key []byte) ([]byte, error) {
+	t.mutex.Lock()
+	w, ok := t.writes[string(key)]
+	closed := t.closed
+	t.mutex.Unlock()
+	switch {
+	case closed:
+		return nil, ErrTxnClosed
+	case !ok:
+		return t.db.Get(key)
+	case w.delete:
+		return nil, nil
+	}
+	return w.value, nil
+}
+
+func (t *bufferedTxn) Put(key, value []byte) error {
+	return t.write(key, txnWrite{value: copyBytes(value)})
+}
+
+func (t *bufferedTxn) Delete(key []byte) error {
+	return t.write(key, txnWrite{delete: true})
+}
+
+func (t *bufferedTxn) write(key []byte, w txnWrite) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.closed {
+		return ErrTxnClosed
+	}
+	t.writes[string(key)] = w
+	return nil
+}
+
+func (t *bufferedTxn) NewIterator() Iterator {
+	return t.db.NewIterator()
+}
+
+func (t *bufferedTxn) Commit() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.closed {
+		return ErrTxnClosed
+	}
+	if len(t.writes) == 0 {
+		return nil
+	}
+	batch := NewBatch()
+	for key, w := range t.writes {
+		if w.delete {
+			batch.Delete([]byte(key))
+		} else {
+			batch.Put([]byte(key), w.value)
+		}
+	}
+	if err := t.db.Write(batch); err != nil {
+		return err
+	}
+	t.writes = make(map[string]txnWrite)
+	return nil
+}
+
+func (t *bufferedTxn) Rollback() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.writes, t.closed = nil, true
+	return nil
+}
@@ -0,0 +1,128 @@
+package stateproof
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// The encoding of the state trie nodes, as of the nodes of core/util. It is
+// decoded here to keep the package free of the storage and the signature
+// scheme dependencies of core/util, so a light wallet can build it without
+// cgo.
+const (
+	nodeTypeValue     = 1
+	nodeTypeLeaf      = 2
+	nodeTypeFull      = 4
+	nodeTypeExtension = 8
+	nodeTypesAll      = nodeTypeValue | nodeTypeLeaf | nodeTypeFull | nodeTypeExtension
+
+	// nodeHeaderSize - the node type followed by the version and the origin
+	// of the node as little endian int64s
+	nodeHeaderSize = 1 + 8 + 8
+
+	separator    = ':'
+	pathElements = "0123456789abcdef"
+	hashSize     = 32
+)
+
+// node - a decoded leaf, full or extension node of the state trie
+type node struct {
+	typ byte
+	// the leaf node prefix, or the extension node path
+	prefix []byte
+	// the leaf node path
+	path []byte
+	// the full node children keys in the order of the path elements, or the
+	// extension node key in the first one
+	children [16][]byte
+	// the leaf or the full node value
+	value []byte
+	hash  []byte
+}
+
+// decodeNode - decode an untrusted encoded node, the hash is computed as the
+// nodes of core/util do
+func decodeNode(buf []byte) (*node, error) {
+	if len(buf) < nodeHeaderSize {
+		return nil, ErrInvalidProof
+	}
+	var (
+		n      = &node{typ: buf[0] & nodeTypesAll}
+		origin = buf[9:nodeHeaderSize]
+		body   = buf[nodeHeaderSize:]
+	)
+	switch n.typ {
+	case nodeTypeLeaf:
+		fields := bytes.SplitN(body, []byte{separator}, 3)
+		if len(fields) != 3 {
+			return nil, ErrInvalidProof
+		}
+		n.prefix, n.path, n.value = fields[0], fields[1], fields[2]
+		n.hash = rawHash(origin, body)
+	case nodeTypeFull:
+		rest := body
+		for i := range n.children {
+			idx := bytes.IndexByte(rest, separator)
+			if idx < 0 {
+				return nil, ErrInvalidProof
+			}
+			if idx > 0 {
+				// only the canonical encoding, so that the hash of the
+				// encoded node is the hash of the node
+				key := rest[:idx]
+				if len(key) != 2*hashSize || !isValidPath(key) {
+					return nil, ErrInvalidProof
+				}
+				n.children[i] = make([]byte, hashSize)
+				hex.Decode(n.children[i], key)
+			}
+			rest = rest[idx+1:]
+		}
+		n.value = rest
+		n.hash = rawHash(body)
+	case nodeTypeExtension:
+		idx := bytes.IndexByte(body, separator)
+		if idx < 0 {
+			return nil, ErrInvalidProof
+		}
+		n.prefix, n.children[0] = body[:idx], body[idx+1:]
+		n.hash = rawHash(body)
+	default:
+		return nil, ErrInvalidProof
+	}
+	return n, nil
+}
+
+// next - the key of the next node along the path and the rest of the path,
+// the key is nil at the end of the path
+func (n *node) next(path []byte) ([]byte, []byte, error) {
+	switch n.typ {
+	case nodeTypeFull:
+		if len(path) == 0 {
+			return nil, nil, ErrInvalidProof
+		}
+		idx := strings.IndexByte(pathElements, path[0])
+		if idx < 0 {
+			return nil, nil, ErrInvalidPath
+		}
+		return n.children[idx], path[1:], nil
+	case nodeTypeExtension:
+		if !bytes.HasPrefix(path, n.prefix) {
+			return nil, nil, nil
+		}
+		return n.children[0], path[len(n.prefix):], nil
+	default:
+		return nil, nil, nil
+	}
+}
+
+func rawHash(data ...[]byte) []byte {
+	hash := sha3.New256()
+	for _, d := range data {
+		hash.Write(d)
+	}
+	return hash.Sum(nil)
+}
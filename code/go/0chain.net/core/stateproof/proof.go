@@ -0,0 +1,127 @@
+// Package stateproof provides the Merkle proofs of the values of a state
+// merkle patricia trie, so a client can check a value returned by a single
+// node against a state root without trusting the node.
+package stateproof
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidProof - the proof doesn't match the state root and the path
+	ErrInvalidProof = errors.New("invalid state proof")
+	// ErrInvalidPath - the path isn't a path of the state trie
+	ErrInvalidPath = errors.New("invalid state path")
+)
+
+// Proof - the encoded nodes of the state trie from the root along the path,
+// the last node either holds the value of the path (inclusion) or shows the
+// path isn't in the trie (non-inclusion)
+type Proof struct {
+	Root  string   `json:"root"`
+	Path  string   `json:"path"`
+	Nodes []string `json:"nodes"`
+}
+
+// NodeGetter - get the encoded node of the key from the node db of a state
+// trie
+type NodeGetter func(key []byte) ([]byte, error)
+
+// New - the proof of the value, or of the absence of value, of the path in
+// the trie of the root
+func New(root, path []byte, getNode NodeGetter) (*Proof, error) {
+	if !isValidPath(path) {
+		return nil, ErrInvalidPath
+	}
+	var (
+		key = root
+		p   = &Proof{
+			Root:  hex.EncodeToString(root),
+			Path:  string(path),
+			Nodes: []string{},
+		}
+	)
+	for len(key) > 0 {
+		buf, err := getNode(key)
+		if err != nil {
+			return nil, err
+		}
+		node, err := decodeNode(buf)
+		if err != nil {
+			return nil, err
+		}
+		p.Nodes = append(p.Nodes, hex.EncodeToString(buf))
+		if key, path, err = node.next(path); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Verify - check the proof against the state root and the path, the value
+// is the encoded value of the path or nil for a non-inclusion proof
+func (p *Proof) Verify(root, path []byte) ([]byte, error) {
+	if p.Root != hex.EncodeToString(root) || p.Path != string(path) {
+		return nil, ErrInvalidProof
+	}
+	nodes := make([][]byte, len(p.Nodes))
+	for i, n := range p.Nodes {
+		var err error
+		if nodes[i], err = hex.DecodeString(n); err != nil {
+			return nil, ErrInvalidProof
+		}
+	}
+	return Verify(root, path, nodes)
+}
+
+// Verify - check the encoded nodes of a proof against the state root and
+// the path, the value is the encoded value of the path or nil for a
+// non-inclusion proof
+func Verify(root, path []byte, nodes [][]byte) ([]byte, error) {
+	if !isValidPath(path) {
+		return nil, ErrInvalidPath
+	}
+	key := root
+	for i, buf := range nodes {
+		if len(key) == 0 {
+			return nil, ErrInvalidProof // nodes past the end of the path
+		}
+		node, err := decodeNode(buf)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(node.hash, key) {
+			return nil, ErrInvalidProof
+		}
+		if i == len(nodes)-1 && node.typ == nodeTypeLeaf && bytes.Equal(node.path, path) {
+			if len(node.value) == 0 {
+				return nil, ErrInvalidProof
+			}
+			return node.value, nil
+		}
+		if key, path, err = node.next(path); err != nil {
+			return nil, err
+		}
+	}
+	if len(key) > 0 {
+		return nil, ErrInvalidProof // the path continues past the nodes
+	}
+	return nil, nil
+}
+
+// isValidPath - the path is a lower case hexadecimal string, as the paths
+// of the client states and the smart contract keys
+func isValidPath(path []byte) bool {
+	if len(path) == 0 {
+		return false
+	}
+	for _, c := range path {
+		if strings.IndexByte(pathElements, c) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package stateproof
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+func testTrie(t *testing.T, n int) (util.MerklePatriciaTrieI, []util.Path) {
+	mpt := util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 1, nil)
+	paths := make([]util.Path, n)
+	for i := range paths {
+		paths[i] = util.Path(encryption.Hash(fmt.Sprintf("client %d", i)))
+		value := &util.SecureSerializableValue{Buffer: []byte(fmt.Sprintf("value %d", i))}
+		_, err := mpt.Insert(paths[i], value)
+		require.NoError(t, err)
+	}
+	return mpt, paths
+}
+
+func newProof(mpt util.MerklePatriciaTrieI, path util.Path) (*Proof, error) {
+	return New(mpt.GetRoot(), path, util.EncodedNodeGetter(mpt.GetNodeDB()))
+}
+
+func TestProof_Inclusion(t *testing.T) {
+	mpt, paths := testTrie(t, 200)
+	for i, path := range paths {
+		p, err := newProof(mpt, path)
+		require.NoError(t, err)
+		require.NotEmpty(t, p.Nodes)
+		value, err := p.Verify(mpt.GetRoot(), path)
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value %d", i)), value)
+	}
+}
+
+func TestProof_NonInclusion(t *testing.T) {
+	mpt, _ := testTrie(t, 200)
+	for i := 0; i < 50; i++ {
+		path := util.Path(encryption.Hash(fmt.Sprintf("missing %d", i)))
+		p, err := newProof(mpt, path)
+		require.NoError(t, err)
+		value, err := p.Verify(mpt.GetRoot(), path)
+		require.NoError(t, err)
+		require.Nil(t, value)
+	}
+
+	// an empty trie
+	empty := util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 1, nil)
+	path := util.Path(encryption.Hash("client"))
+	p, err := newProof(empty, path)
+	require.NoError(t, err)
+	require.Empty(t, p.Nodes)
+	value, err := p.Verify(empty.GetRoot(), path)
+	require.NoError(t, err)
+	require.Nil(t, value)
+}
+
+func TestProof_Invalid(t *testing.T) {
+	mpt, paths := testTrie(t, 200)
+	root := mpt.GetRoot()
+	path := paths[0]
+	proof := func() [][]byte {
+		p, err := newProof(mpt, path)
+		require.NoError(t, err)
+		nodes := make([][]byte, len(p.Nodes))
+		for i, n := range p.Nodes {
+			nodes[i], err = hex.DecodeString(n)
+			require.NoError(t, err)
+		}
+		return nodes
+	}
+	require.True(t, len(proof()) > 1)
+
+	_, err := Verify(root, path, proof())
+	require.NoError(t, err)
+
+	// the proof of another path or against another root
+	_, err = Verify(root, paths[1], proof())
+	assert.Error(t, err)
+	_, err = Verify(util.Key(encryption.RawHash("root")), path, proof())
+	assert.Error(t, err)
+
+	// a changed value
+	nodes := proof()
+	last := nodes[len(nodes)-1]
+	last[len(last)-1]++
+	_, err = Verify(root, path, nodes)
+	assert.Error(t, err)
+
+	// the missing or the additional nodes
+	nodes = proof()
+	_, err = Verify(root, path, nodes[:len(nodes)-1])
+	assert.Error(t, err)
+	_, err = Verify(root, path, nodes[1:])
+	assert.Error(t, err)
+	_, err = Verify(root, path, append(nodes, nodes[len(nodes)-1]))
+	assert.Error(t, err)
+	_, err = Verify(root, path, nil)
+	assert.Error(t, err)
+
+	// the malformed nodes
+	for _, node := range [][]byte{nil, {0}, {3}, {util.NodeTypeLeafNode}, {util.NodeTypeFullNode, 1, 2}} {
+		nodes = proof()
+		nodes[len(nodes)-1] = node
+		_, err = Verify(root, path, nodes)
+		assert.Error(t, err)
+	}
+
+	// the invalid paths
+	for _, path := range []util.Path{nil, util.Path("XYZ"), util.Path("ABCDEF")} {
+		_, err = Verify(root, path, proof())
+		assert.Equal(t, ErrInvalidPath, err)
+		_, err = newProof(mpt, path)
+		assert.Equal(t, ErrInvalidPath, err)
+	}
+}
+
+func TestDecodeNode(t *testing.T) {
+	mpt, _ := testTrie(t, 200)
+	types := make(map[byte]int)
+	err := mpt.GetNodeDB().Iterate(context.Background(),
+		func(_ context.Context, key util.Key, node util.Node) error {
+			n, err := decodeNode(node.Encode())
+			require.NoError(t, err)
+			require.Equal(t, []byte(key), n.hash, "the hash of core/util")
+			require.Equal(t, node.GetHashBytes(), n.hash)
+			types[n.typ]++
+			return nil
+		})
+	require.NoError(t, err)
+	require.Len(t, types, 3, "leaf, full and extension nodes")
+
+	// a non canonical encoding of a full node child
+	fn := util.NewFullNode(nil)
+	fn.PutChild('a', encryption.RawHash("child"))
+	buf := fn.Encode()
+	_, err = decodeNode(buf)
+	require.NoError(t, err)
+	upper := bytes.Replace(buf, []byte(util.ToHex(encryption.RawHash("child"))),
+		[]byte(util.ToUpperHex(encryption.RawHash("child"))), 1)
+	_, err = decodeNode(upper)
+	assert.Equal(t, ErrInvalidProof, err)
+}
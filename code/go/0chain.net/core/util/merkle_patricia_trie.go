@@ -630,7 +630,9 @@ func (mpt *MerklePatriciaTrie) deleteAtNode(node Node, prefix, path Path) (Node,
 			nnode := cnode.Clone().(*LeafNode)
 			nnode.SetOrigin(mpt.Version)
 			nnode.Prefix = concat(prefix)
-			nnode.Path = append(nodeImpl.Path, cnodeImpl.Path...)
+			// concat, the extension path may share its array with the
+			// path of another node, appending would overwrite it
+			nnode.Path = concat(nodeImpl.Path, cnodeImpl.Path...)
 			nnode.SetValue(cnodeImpl.GetValue())
 			mpt.deleteNode(cnode)
 			return mpt.insertNode(node, nnode)
@@ -641,7 +643,7 @@ func (mpt *MerklePatriciaTrie) deleteAtNode(node Node, prefix, path Path) (Node,
 		case *ExtensionNode:
 			// if extension child changes from full node to extension node, merge the extensions
 			nnode := nodeImpl.Clone().(*ExtensionNode)
-			nnode.Path = append(nnode.Path, cnodeImpl.Path...)
+			nnode.Path = concat(nnode.Path, cnodeImpl.Path...)
 			nnode.NodeKey = cnodeImpl.NodeKey
 			mpt.deleteNode(cnode)
 			return mpt.insertNode(node, nnode)
@@ -15,7 +15,6 @@ import (
 	"sync"
 	"testing"
 
-	"github.com/0chain/gorocksdb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -968,6 +967,31 @@ func TestAddTwiceDeleteOnce(t *testing.T) {
 	//doDelete("delete a leaf node", mpt2, "223556782", true)
 }
 
+/*
+  merging the extension on delete must not change the nodes of the previous
+  versions sharing the path with it
+*/
+func TestDeleteKeepsPreviousVersion(t *testing.T) {
+	mndb := NewMemoryNodeDB()
+	mpt := NewMerklePatriciaTrie(mndb, Sequence(0), nil)
+
+	doStrValInsert(t, mpt, "123456", "x")
+	doStrValInsert(t, mpt, "123489", "y")
+
+	db := NewLevelNodeDB(NewMemoryNodeDB(), mndb, false)
+	mpt2 := NewMerklePatriciaTrie(db, Sequence(1), mpt.GetRoot())
+	doDelete(t, mpt2, "123489", nil)
+	doGetStrValue(t, mpt2, "123456", "x")
+
+	doGetStrValue(t, mpt, "123456", "x")
+	doGetStrValue(t, mpt, "123489", "y")
+	for key, node := range mndb.Nodes {
+		if node.GetHash() != ToHex([]byte(key)) {
+			t.Fatalf("node changed in place: %v", ToHex([]byte(key)))
+		}
+	}
+}
+
 func TestWithPruneStats(t *testing.T) {
 	t.Parallel()
 
@@ -1252,9 +1276,7 @@ func TestMerklePatriciaTrie_Insert(t *testing.T) {
 	db, cleanup := newPNodeDB(t)
 	defer cleanup()
 
-	db.wo = gorocksdb.NewDefaultWriteOptions()
-	db.wo.SetSync(true)
-	db.wo.DisableWAL(true)
+	db.db = &failingWriteDB{DB: db.db}
 
 	type fields struct {
 		mutex           *sync.RWMutex
@@ -1658,9 +1680,8 @@ func TestMerklePatriciaTrie_insert(t *testing.T) {
 func TestMerklePatriciaTrie_insertAtNode(t *testing.T) {
 	db, cleanup := newPNodeDB(t)
 	defer cleanup()
-	db.wo = gorocksdb.NewDefaultWriteOptions()
-	db.wo.SetSync(true)
-	db.wo.DisableWAL(true)
+
+	db.db = &failingWriteDB{DB: db.db}
 
 	path := Path("path")
 
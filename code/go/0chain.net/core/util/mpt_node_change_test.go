@@ -6,7 +6,6 @@ import (
 	"reflect"
 	"testing"
 
-	"github.com/0chain/gorocksdb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -75,9 +74,7 @@ func TestChangeCollector_UpdateChanges(t *testing.T) {
 	pndb, cleanup := newPNodeDB(t)
 	defer cleanup()
 
-	pndb.wo = gorocksdb.NewDefaultWriteOptions()
-	pndb.wo.DisableWAL(true)
-	pndb.wo.SetSync(true)
+	pndb.db = &failingWriteDB{DB: pndb.db}
 
 	type fields struct {
 		Changes map[string]*NodeChange
@@ -59,6 +59,18 @@ type NodeDB interface {
 	GetDBVersions() []int64
 }
 
+// EncodedNodeGetter - get the encoded nodes of the node db, as the state
+// proofs read them
+func EncodedNodeGetter(ndb NodeDB) func(key []byte) ([]byte, error) {
+	return func(key []byte) ([]byte, error) {
+		node, err := ndb.GetNode(key)
+		if err != nil {
+			return nil, err
+		}
+		return node.Encode(), nil
+	}
+}
+
 // StrKey - data type for the key used to store the node into some storage
 // (this is needed as hashmap keys can't be []byte.
 type StrKey string
@@ -9,7 +9,6 @@ import (
 	"sync"
 	"testing"
 
-	"github.com/0chain/gorocksdb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -1169,9 +1168,7 @@ func TestLevelNodeDB_MultiPutNode(t *testing.T) {
 	current, cleanup := newPNodeDB(t)
 	defer cleanup()
 
-	current.wo = gorocksdb.NewDefaultWriteOptions()
-	current.wo.DisableWAL(true)
-	current.wo.SetSync(true)
+	current.db = &failingWriteDB{DB: current.db}
 
 	type fields struct {
 		mu               *sync.RWMutex
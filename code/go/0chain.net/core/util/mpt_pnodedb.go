@@ -5,8 +5,7 @@ import (
 	"context"
 	"sync"
 
-	"github.com/0chain/gorocksdb"
-
+	"0chain.net/core/kvdb"
 	"0chain.net/core/logging"
 	. "0chain.net/core/logging"
 	"go.uber.org/zap"
@@ -15,11 +14,7 @@ import (
 /*PNodeDB - a node db that is persisted */
 type PNodeDB struct {
 	dataDir  string
-	db       *gorocksdb.DB
-	ro       *gorocksdb.ReadOptions
-	wo       *gorocksdb.WriteOptions
-	to       *gorocksdb.TransactionOptions
-	fo       *gorocksdb.FlushOptions
+	db       kvdb.DB
 	mutex    sync.Mutex
 	version  int64
 	versions []int64
@@ -32,48 +27,31 @@ const (
 
 var sstType = SSTTypeBlockBasedTable
 
-/*NewPNodeDB - create a new PNodeDB */
+/*NewPNodeDB - create a new PNodeDB on the default database backend */
 func NewPNodeDB(dataDir string, logDir string) (*PNodeDB, error) {
-	opts := gorocksdb.NewDefaultOptions()
-	opts.SetCreateIfMissing(true)
-	opts.SetCompression(gorocksdb.LZ4Compression)
-	if sstType == SSTTypePlainTable {
-		opts.SetAllowMmapReads(true)
-		opts.SetPrefixExtractor(gorocksdb.NewFixedPrefixTransform(6))
-		opts.SetPlainTableFactory(32, 10, 0.75, 16)
-	} else {
-		opts.OptimizeForPointLookup(64)
-		opts.SetAllowMmapReads(true)
-		opts.SetPrefixExtractor(gorocksdb.NewFixedPrefixTransform(6))
-	}
-	opts.IncreaseParallelism(2)          // pruning and saving happen in parallel
-	opts.SetSkipLogErrorOnRecovery(true) // do sync if necessary
-	opts.SetDbLogDir(logDir)
-	opts.EnableStatistics()
-	opts.OptimizeUniversalStyleCompaction(64 * 1024 * 1024)
-	db, err := gorocksdb.OpenDb(opts, dataDir)
+	return NewPNodeDBBackend(kvdb.DefaultBackend(), dataDir, logDir)
+}
+
+/*NewPNodeDBBackend - create a new PNodeDB on the database backend */
+func NewPNodeDBBackend(backend, dataDir, logDir string) (*PNodeDB, error) {
+	db, err := kvdb.Open(backend, dataDir, &kvdb.Options{
+		LogDir:      logDir,
+		PointLookup: true,
+		PlainTable:  sstType == SSTTypePlainTable,
+	})
 	if err != nil {
 		return nil, err
 	}
-	pnodedb := &PNodeDB{db: db}
-	pnodedb.dataDir = dataDir
-	pnodedb.ro = gorocksdb.NewDefaultReadOptions()
-	pnodedb.wo = gorocksdb.NewDefaultWriteOptions()
-	pnodedb.wo.SetSync(false)
-	pnodedb.to = gorocksdb.NewDefaultTransactionOptions()
-	pnodedb.fo = gorocksdb.NewDefaultFlushOptions()
-	return pnodedb, nil
+	return &PNodeDB{dataDir: dataDir, db: db}, nil
 }
 
 /*GetNode - implement interface */
 func (pndb *PNodeDB) GetNode(key Key) (Node, error) {
-	data, err := pndb.db.Get(pndb.ro, key)
+	buf, err := pndb.db.Get(key)
 	if err != nil {
 		return nil, err
 	}
-	defer data.Free()
-	buf := data.Data()
-	if buf == nil || len(buf) == 0 {
+	if len(buf) == 0 {
 		return nil, ErrNodeNotFound
 	}
 	return CreateNode(bytes.NewReader(buf))
@@ -82,7 +60,7 @@ func (pndb *PNodeDB) GetNode(key Key) (Node, error) {
 /*PutNode - implement interface */
 func (pndb *PNodeDB) PutNode(key Key, node Node) error {
 	data := node.Encode()
-	err := pndb.db.Put(pndb.wo, key, data)
+	err := pndb.db.Put(key, data)
 	if DebugMPTNode {
 		logging.Logger.Debug("node put to PersistDB",
 			zap.String("key", ToHex(key)), zap.Error(err),
@@ -94,7 +72,7 @@ func (pndb *PNodeDB) PutNode(key Key, node Node) error {
 
 /*DeleteNode - implement interface */
 func (pndb *PNodeDB) DeleteNode(key Key) error {
-	err := pndb.db.Delete(pndb.wo, key)
+	err := pndb.db.Delete(key)
 	return err
 }
 
@@ -115,8 +93,7 @@ func (pndb *PNodeDB) MultiGetNode(keys []Key) ([]Node, error) {
 
 /*MultiPutNode - implement interface */
 func (pndb *PNodeDB) MultiPutNode(keys []Key, nodes []Node) error {
-	wb := gorocksdb.NewWriteBatch()
-	defer wb.Destroy()
+	wb := kvdb.NewBatch()
 	for idx, key := range keys {
 		wb.Put(key, nodes[idx].Encode())
 		if DebugMPTNode {
@@ -126,55 +103,45 @@ func (pndb *PNodeDB) MultiPutNode(keys []Key, nodes []Node) error {
 				zap.Int64("Version", int64(nodes[idx].GetVersion())))
 		}
 	}
-	err := pndb.db.Write(pndb.wo, wb)
+	err := pndb.db.Write(wb)
 	return err
 }
 
 /*MultiDeleteNode - implement interface */
 func (pndb *PNodeDB) MultiDeleteNode(keys []Key) error {
-	wb := gorocksdb.NewWriteBatch()
-	defer wb.Destroy()
+	wb := kvdb.NewBatch()
 	for _, key := range keys {
 		wb.Delete(key)
 	}
-	return pndb.db.Write(pndb.wo, wb)
+	return pndb.db.Write(wb)
 }
 
 /*Iterate - implement interface */
 func (pndb *PNodeDB) Iterate(ctx context.Context, handler NodeDBIteratorHandler) error {
-	ro := gorocksdb.NewDefaultReadOptions()
-	defer ro.Destroy()
-	ro.SetFillCache(false)
-	it := pndb.db.NewIterator(ro)
+	it := pndb.db.NewIterator()
 	defer it.Close()
 	for it.SeekToFirst(); it.Valid(); it.Next() {
-		key := it.Key()
-		value := it.Value()
-		kdata := key.Data()
-		vdata := value.Data()
+		kdata := it.Key()
+		vdata := it.Value()
 		node, err := CreateNode(bytes.NewReader(vdata))
 		if err != nil {
-			key.Free()
-			value.Free()
 			Logger.Error("iterate - create node", zap.String("key", ToHex(kdata)), zap.Error(err))
 			continue
 		}
 		err = handler(ctx, kdata, node)
 		if err != nil {
-			key.Free()
-			value.Free()
 			Logger.Error("iterate - create node handler error", zap.String("key", ToHex(kdata)), zap.Any("data", vdata), zap.Error(err))
 			return err
 		}
-		key.Free()
-		value.Free()
 	}
 	return nil
 }
 
 /*Flush - flush the db */
 func (pndb *PNodeDB) Flush() {
-	pndb.db.Flush(pndb.fo)
+	if err := pndb.db.Flush(); err != nil {
+		Logger.Error("flush", zap.Error(err))
+	}
 }
 
 /*PruneBelowVersion - prune the state below the given origin */
@@ -241,7 +208,7 @@ func (pndb *PNodeDB) Size(ctx context.Context) int64 {
 	return count
 }
 
-// Close close the rocksdb
+// Close close the database
 func (pndb *PNodeDB) Close() {
 	pndb.db.Close()
 }
@@ -2,18 +2,43 @@ package util
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"testing"
 
-	"github.com/0chain/gorocksdb"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"0chain.net/core/kvdb"
 )
 
 const dataDir = "tmp"
 
+var errWrite = errors.New("write failed")
+
+// failingWriteDB - a database failing the writes
+type failingWriteDB struct {
+	kvdb.DB
+}
+
+func (db *failingWriteDB) Put(_, _ []byte) error {
+	return errWrite
+}
+
+func (db *failingWriteDB) Delete([]byte) error {
+	return errWrite
+}
+
+func (db *failingWriteDB) Write(*kvdb.Batch) error {
+	return errWrite
+}
+
 func cleanUp() error {
 	if err := os.RemoveAll(dataDir); err != nil {
 		return err
@@ -85,13 +110,12 @@ func TestPNodeDB_Iterate(t *testing.T) {
 			pndb, cleanUp := newPNodeDB(t)
 			defer cleanUp()
 
-			wo := gorocksdb.NewDefaultWriteOptions()
 			for i := 0; i < 257; i++ {
 				r := rand.Int()
-				err := pndb.db.Put(wo, []byte(strconv.Itoa(r)), []byte{NodeTypeValueNode})
+				err := pndb.db.Put([]byte(strconv.Itoa(r)), []byte{NodeTypeValueNode})
 				require.NoError(t, err)
 			}
-			err := pndb.db.Put(wo, []byte("key"), make([]byte, 0))
+			err := pndb.db.Put([]byte("key"), make([]byte, 0))
 			require.NoError(t, err)
 
 			if err := pndb.Iterate(tt.args.ctx, tt.args.handler); (err != nil) != tt.wantErr {
@@ -106,8 +130,6 @@ func TestPNodeDB_PruneBelowVersion_Iterator_Err(t *testing.T) {
 
 	type fields struct {
 		dataDir  string
-		ro       *gorocksdb.ReadOptions
-		to       *gorocksdb.TransactionOptions
 		version  int64
 		versions []int64
 	}
@@ -137,23 +159,15 @@ func TestPNodeDB_PruneBelowVersion_Iterator_Err(t *testing.T) {
 
 			db, cleanUp := newPNodeDB(t)
 			defer cleanUp()
-			wo := gorocksdb.NewDefaultWriteOptions()
 			for i := 0; i < 257; i++ {
 				r := rand.Int()
-				err := db.db.Put(wo, []byte(strconv.Itoa(r)), []byte{NodeTypeValueNode})
+				err := db.db.Put([]byte(strconv.Itoa(r)), []byte{NodeTypeValueNode})
 				require.NoError(t, err)
 			}
-			wo.DisableWAL(true)
-			wo.SetSync(true)
-			fo := gorocksdb.NewDefaultFlushOptions()
 			ctx := context.WithValue(context.TODO(), PruneStatsKey, &PruneStats{})
 			pndb := &PNodeDB{
 				dataDir:  tt.fields.dataDir,
-				db:       db.db,
-				ro:       tt.fields.ro,
-				wo:       wo,
-				to:       tt.fields.to,
-				fo:       fo,
+				db:       &failingWriteDB{DB: db.db},
 				version:  tt.fields.version,
 				versions: tt.fields.versions,
 			}
@@ -169,8 +183,6 @@ func TestPNodeDB_PruneBelowVersion_Multi_Delete_Err(t *testing.T) {
 
 	type fields struct {
 		dataDir  string
-		ro       *gorocksdb.ReadOptions
-		to       *gorocksdb.TransactionOptions
 		version  int64
 		versions []int64
 	}
@@ -201,29 +213,19 @@ func TestPNodeDB_PruneBelowVersion_Multi_Delete_Err(t *testing.T) {
 			db, cleanUp := newPNodeDB(t)
 			defer cleanUp()
 
-			wo := gorocksdb.NewDefaultWriteOptions()
 			for i := 0; i < 257; i++ {
 				r := rand.Int()
-				err := db.db.Put(wo, []byte(strconv.Itoa(r)), []byte{NodeTypeValueNode})
+				err := db.db.Put([]byte(strconv.Itoa(r)), []byte{NodeTypeValueNode})
 				require.NoError(t, err)
 			}
 
 			n := NewValueNode()
-			err := db.db.Put(wo, []byte("key"), n.Encode())
+			err := db.db.Put([]byte("key"), n.Encode())
 			require.NoError(t, err)
 
-			wo.DisableWAL(true)
-			wo.SetSync(true)
-
-			fo := gorocksdb.NewDefaultFlushOptions()
-
 			pndb := &PNodeDB{
 				dataDir:  tt.fields.dataDir,
-				db:       db.db,
-				ro:       tt.fields.ro,
-				wo:       wo,
-				to:       tt.fields.to,
-				fo:       fo,
+				db:       &failingWriteDB{DB: db.db},
 				version:  tt.fields.version,
 				versions: tt.fields.versions,
 			}
@@ -241,11 +243,6 @@ func TestPNodeDB_GetDBVersions(t *testing.T) {
 
 	type fields struct {
 		dataDir  string
-		db       *gorocksdb.DB
-		ro       *gorocksdb.ReadOptions
-		wo       *gorocksdb.WriteOptions
-		to       *gorocksdb.TransactionOptions
-		fo       *gorocksdb.FlushOptions
 		version  int64
 		versions []int64
 	}
@@ -267,11 +264,6 @@ func TestPNodeDB_GetDBVersions(t *testing.T) {
 
 			pndb := &PNodeDB{
 				dataDir:  tt.fields.dataDir,
-				db:       tt.fields.db,
-				ro:       tt.fields.ro,
-				wo:       tt.fields.wo,
-				to:       tt.fields.to,
-				fo:       tt.fields.fo,
 				version:  tt.fields.version,
 				versions: tt.fields.versions,
 			}
@@ -291,11 +283,6 @@ func TestPNodeDB_TrackDBVersion(t *testing.T) {
 
 	type fields struct {
 		dataDir  string
-		db       *gorocksdb.DB
-		ro       *gorocksdb.ReadOptions
-		wo       *gorocksdb.WriteOptions
-		to       *gorocksdb.TransactionOptions
-		fo       *gorocksdb.FlushOptions
 		version  int64
 		versions []int64
 	}
@@ -321,11 +308,6 @@ func TestPNodeDB_TrackDBVersion(t *testing.T) {
 
 			pndb := &PNodeDB{
 				dataDir:  tt.fields.dataDir,
-				db:       tt.fields.db,
-				ro:       tt.fields.ro,
-				wo:       tt.fields.wo,
-				to:       tt.fields.to,
-				fo:       tt.fields.fo,
 				version:  tt.fields.version,
 				versions: tt.fields.versions,
 			}
@@ -338,3 +320,53 @@ func TestPNodeDB_TrackDBVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestPNodeDB_Backends(t *testing.T) {
+	const exp = "ae6a645401f35411371b9d498fa13c663909a7f6463b42a7f2a060db3ef0196b"
+	ctx := context.TODO()
+	for _, backend := range kvdb.Backends() {
+		backend := backend
+		t.Run(backend, func(t *testing.T) {
+			dirname, err := ioutil.TempDir("", "mpt-pndb-"+backend)
+			require.NoError(t, err)
+			defer os.RemoveAll(dirname)
+
+			open := func() *PNodeDB {
+				pndb, err := NewPNodeDBBackend(backend, filepath.Join(dirname, "mpt"),
+					filepath.Join(dirname, "log"))
+				require.NoError(t, err)
+				return pndb
+			}
+			sponge := func(mpt MerklePatriciaTrieI) string {
+				s := sha3.New256()
+				err := mpt.Iterate(ctx, iterSpongeHandler(s),
+					NodeTypeValueNode|NodeTypeLeafNode|NodeTypeFullNode|NodeTypeExtensionNode)
+				require.NoError(t, err)
+				return hex.EncodeToString(s.Sum(nil))
+			}
+
+			pndb := open()
+			mpt := NewMerklePatriciaTrie(NewLevelNodeDB(NewMemoryNodeDB(), pndb, false),
+				Sequence(2016), nil)
+			doStateValInsert(t, mpt, "123456", 100)
+			doStateValInsert(t, mpt, "123457", 1000)
+			doStateValInsert(t, mpt, "123458", 1000000)
+			doStateValInsert(t, mpt, "133458", 1000000000)
+			require.NoError(t, mpt.SaveChanges(ctx, pndb, false))
+			size := pndb.Size(ctx)
+			require.True(t, size > 0)
+			pndb.Close()
+
+			// the same trie read back after reopening
+			pndb = open()
+			defer pndb.Close()
+			require.Equal(t, size, pndb.Size(ctx))
+			require.Equal(t, exp, sponge(NewMerklePatriciaTrie(pndb, Sequence(2016), mpt.GetRoot())))
+
+			require.NoError(t, pndb.PruneBelowVersion(ctx, Sequence(2016)))
+			require.Equal(t, size, pndb.Size(ctx), "nothing below the version")
+			require.NoError(t, pndb.PruneBelowVersion(ctx, Sequence(2017)))
+			require.Equal(t, int64(0), pndb.Size(ctx))
+		})
+	}
+}
@@ -92,6 +92,14 @@ func ChainStatsWriter(w http.ResponseWriter, r *http.Request) {
 	diagnostics.WriteTimerStatistics(w, c, btvTimer, 1000000.0)
 	fmt.Fprintf(w, "</td></tr>")
 
+	fmt.Fprintf(w, "<tr><td>")
+	fmt.Fprintf(w, "<h3>Block Execution Statistics</h3>")
+	diagnostics.WriteTimerStatistics(w, c, bteTimer, 1000000.0)
+	fmt.Fprintf(w, "</td><td valign='top'>")
+	fmt.Fprintf(w, "<h3>Block Execution Wait on Verification Statistics</h3>")
+	diagnostics.WriteTimerStatistics(w, c, btwTimer, 1000000.0)
+	fmt.Fprintf(w, "</td></tr>")
+
 	fmt.Fprintf(w, "<tr><td>")
 	fmt.Fprintf(w, "<h3>Block Txns Statistics</h3>")
 	diagnostics.WriteHistogramStatistics(w, c, bsHistogram)
@@ -23,12 +23,14 @@ import (
 	"0chain.net/chaincore/diagnostics"
 	"0chain.net/chaincore/node"
 	"0chain.net/chaincore/round"
+	"0chain.net/chaincore/snapshot"
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/threshold/bls"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/build"
 	"0chain.net/core/common"
 	"0chain.net/core/ememorystore"
+	"0chain.net/core/kvdb"
 	"0chain.net/core/logging"
 	"0chain.net/core/memorystore"
 	"0chain.net/core/viper"
@@ -59,6 +61,12 @@ func main() {
 	transaction.SetTxnTimeout(int64(viper.GetInt("server_chain.transaction.timeout")))
 	transaction.SetTxnFee(viper.GetInt64("server_chain.transaction.min_fee"))
 
+	if backend := viper.GetString("server_chain.db.backend"); backend != "" {
+		if err := kvdb.SetDefaultBackend(backend); err != nil {
+			logging.Logger.Panic("database backend", zap.Error(err))
+		}
+	}
+
 	config.SetServerChainID(config.Configuration.ChainID)
 
 	common.SetupRootContext(node.GetNodeContext())
@@ -85,6 +93,17 @@ func main() {
 	mc.SetDiscoverClients(viper.GetBool("server_chain.client.discover"))
 	mc.SetGenerationTimeout(viper.GetInt("server_chain.block.generation.timeout"))
 	mc.SetSyncStateTimeout(viper.GetDuration("server_chain.state.sync.timeout") * time.Second)
+	if err := mc.SetupStateSnapshots(chain.StateSnapshotConfig{
+		Dir:          "data/snapshots",
+		Interval:     viper.GetInt64("server_chain.state.snapshot.interval"),
+		Keep:         viper.GetInt("server_chain.state.snapshot.keep"),
+		ChunkSize:    viper.GetInt("server_chain.state.snapshot.chunk_size"),
+		Sync:         viper.GetBool("server_chain.state.snapshot.sync"),
+		SyncParallel: viper.GetInt("server_chain.state.snapshot.sync_parallel"),
+		SyncTimeout:  viper.GetDuration("server_chain.state.snapshot.sync_timeout") * time.Second,
+	}); err != nil {
+		logging.Logger.Panic("state snapshots", zap.Error(err))
+	}
 	mc.SetBCStuckCheckInterval(viper.GetDuration("server_chain.stuck.check_interval") * time.Second)
 	mc.SetBCStuckTimeThreshold(viper.GetDuration("server_chain.stuck.time_threshold") * time.Second)
 	mc.SetRetryWaitTime(viper.GetInt("server_chain.block.generation.retry_wait_time"))
@@ -367,6 +386,7 @@ func initEntities() {
 	block.SetupStateChange(memoryStorage)
 	state.SetupPartialState(memoryStorage)
 	state.SetupStateNodes(memoryStorage)
+	snapshot.SetupEntity(memoryStorage)
 	client.SetupEntity(memoryStorage)
 
 	transaction.SetupTransactionDB()
@@ -482,3 +482,28 @@ func setupSelf() func() {
 		s.Close()
 	}
 }
+
+func TestOrderByNonce(t *testing.T) {
+	txn := func(hash, client string, nonce int64) *transaction.Transaction {
+		return &transaction.Transaction{
+			HashIDField: datastore.HashIDField{Hash: hash},
+			ClientID:    client,
+			Nonce:       nonce,
+		}
+	}
+	txns := []*transaction.Transaction{
+		txn("a3", "a", 3),
+		txn("b2", "b", 2),
+		txn("x", "a", 0),
+		txn("a1", "a", 1),
+		txn("b1", "b", 1),
+		txn("a2", "a", 2),
+	}
+	orderByNonce(txns)
+
+	var got []string
+	for _, txn := range txns {
+		got = append(got, txn.Hash)
+	}
+	require.Equal(t, []string{"a1", "b1", "x", "a2", "b2", "a3"}, got)
+}
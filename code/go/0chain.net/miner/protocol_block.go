@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"runtime"
+	"sort"
 	"strconv"
 	"time"
 
@@ -35,6 +37,8 @@ var (
 	bgTimer     metrics.Timer // block generation timer
 	bpTimer     metrics.Timer // block processing timer (includes block verification)
 	btvTimer    metrics.Timer // block verification timer
+	bteTimer    metrics.Timer // block transactions execution timer
+	btwTimer    metrics.Timer // block execution wait on the transactions verification timer
 	bsHistogram metrics.Histogram
 )
 
@@ -42,6 +46,8 @@ func init() {
 	bgTimer = metrics.GetOrRegisterTimer("bg_time", nil)
 	bpTimer = metrics.GetOrRegisterTimer("bv_time", nil)
 	btvTimer = metrics.GetOrRegisterTimer("btv_time", nil)
+	bteTimer = metrics.GetOrRegisterTimer("bte_time", nil)
+	btwTimer = metrics.GetOrRegisterTimer("btw_time", nil)
 	bsHistogram = metrics.GetOrRegisterHistogram("bs_histogram", nil, metrics.NewUniformSample(1024))
 }
 
@@ -91,6 +97,33 @@ func (mc *Chain) createBlockRewardTxn(b *block.Block) *transaction.Transaction {
 	return brTxn
 }
 
+// orderByNonce - order the transactions with a nonce of each client by their
+// nonces, in the places of the transactions of the client, the others keep
+// their places
+func orderByNonce(txns []*transaction.Transaction) {
+	var places = make(map[datastore.Key][]int)
+	for i, txn := range txns {
+		if txn.Nonce != 0 {
+			places[txn.ClientID] = append(places[txn.ClientID], i)
+		}
+	}
+	for _, idxs := range places {
+		if len(idxs) < 2 {
+			continue
+		}
+		var ctxns = make([]*transaction.Transaction, len(idxs))
+		for i, idx := range idxs {
+			ctxns[i] = txns[idx]
+		}
+		sort.SliceStable(ctxns, func(i, j int) bool {
+			return ctxns[i].Nonce < ctxns[j].Nonce
+		})
+		for i, idx := range idxs {
+			txns[idx] = ctxns[i]
+		}
+	}
+}
+
 func (mc *Chain) txnToReuse(txn *transaction.Transaction) *transaction.Transaction {
 	ctxn := *txn
 	ctxn.OutputHash = ""
@@ -117,7 +150,8 @@ func (mc *Chain) UpdatePendingBlock(ctx context.Context, b *block.Block, txns []
 
 func (mc *Chain) verifySmartContracts(ctx context.Context, b *block.Block) error {
 	for _, txn := range b.Txns {
-		if txn.TransactionType == transaction.TxnTypeSmartContract {
+		if txn.TransactionType == transaction.TxnTypeSmartContract ||
+			txn.TransactionType == transaction.TxnTypeBundle {
 			err := txn.VerifyOutputHash(ctx)
 			if err != nil {
 				logging.Logger.Error("Smart contract output verification failed", zap.Any("error", err), zap.Any("output", txn.TransactionOutput))
@@ -248,11 +282,20 @@ func (mc *Chain) VerifyBlock(ctx context.Context, b *block.Block) (
 		return nil, block.ErrPreviousBlockUnavailable
 	}
 
-	if err = mc.ValidateTransactions(ctx, b); err != nil {
-		return
+	if mc.PipelinedValidation {
+		var verr error
+		if verr, err = mc.validateAndComputeState(ctx, b); verr != nil {
+			return nil, verr
+		}
+	} else {
+		if err = mc.ValidateTransactions(ctx, b); err != nil {
+			return
+		}
+		ts := time.Now()
+		err = mc.ComputeState(ctx, b)
+		bteTimer.UpdateSince(ts)
 	}
-
-	if err = mc.ComputeState(ctx, b); err != nil {
+	if err != nil {
 		if err == context.Canceled {
 			logging.Logger.Warn("verify block canceled")
 			return
@@ -338,6 +381,10 @@ func (mc *Chain) ValidateTransactions(ctx context.Context, b *block.Block) error
 				}
 				aggregateSignatureScheme.Aggregate(sigScheme, start+idx, txn.Signature, txn.Hash)
 			}
+			if txn.Nonce != 0 {
+				// the nonce is checked against the client state
+				continue
+			}
 			ok, err := mc.ChainHasTransaction(ctx, b.PrevBlock, txn)
 			if ok || err != nil {
 				if err != nil {
@@ -384,6 +431,125 @@ func (mc *Chain) ValidateTransactions(ctx context.Context, b *block.Block) error
 	return nil
 }
 
+// validateAndComputeState - validate the transactions of the block on a
+// pool of workers while the block is executed, each transaction is executed
+// as soon as it's validated. With an aggregate signature scheme the
+// signatures are verified per batch and the transactions of a batch are
+// validated together. Returns the validation error first, the error
+// computing the state otherwise.
+func (mc *Chain) validateAndComputeState(ctx context.Context, b *block.Block) (verr, err error) {
+	var (
+		tv        = block.NewTxnVerification(b)
+		batchSize = 1
+		workers   = mc.ValidationWorkers
+	)
+	if encryption.GetAggregateSignatureScheme(mc.ClientSignatureScheme, 1, 1) != nil &&
+		mc.ValidationBatchSize > 1 {
+		batchSize = mc.ValidationBatchSize
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	vctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var batches = make(chan int, len(b.Txns)/batchSize+1)
+	for start := 0; start < len(b.Txns); start += batchSize {
+		batches <- start
+	}
+	close(batches)
+	for i := 0; i < workers && i*batchSize < len(b.Txns); i++ {
+		go func() {
+			for start := range batches {
+				select {
+				case <-tv.Done():
+					return
+				case <-vctx.Done():
+					tv.Fail(vctx.Err())
+					return
+				default:
+				}
+				end := start + batchSize
+				if end > len(b.Txns) {
+					end = len(b.Txns)
+				}
+				if err := mc.validateTransactionBatch(vctx, b, start, end); err != nil {
+					tv.Fail(err)
+					return
+				}
+				for idx := start; idx < end; idx++ {
+					tv.Verified(idx)
+				}
+			}
+		}()
+	}
+
+	ts := time.Now()
+	err = mc.ComputeState(block.WithTxnVerification(ctx, tv), b)
+	bteTimer.UpdateSince(ts)
+	if verr = tv.Wait(ctx); verr != nil {
+		return verr, err
+	}
+	btvTimer.Update(tv.Duration())
+	btwTimer.Update(tv.Waited())
+	if mc.discoverClients {
+		go mc.SaveClients(ctx, b.GetClients())
+	}
+	return nil, err
+}
+
+// validateTransactionBatch - validate the transactions of the block in the
+// given index range, the way ValidateTransactions does
+func (mc *Chain) validateTransactionBatch(ctx context.Context, b *block.Block, start, end int) error {
+	var aggregateSignatureScheme encryption.AggregateSignatureScheme
+	if end-start > 1 {
+		aggregateSignatureScheme = encryption.GetAggregateSignatureScheme(
+			mc.ClientSignatureScheme, end-start, end-start)
+	}
+	for idx, txn := range b.Txns[start:end] {
+		if mc.GetCurrentRound() > b.Round {
+			logging.Logger.Info("validate transactions (round mismatch)", zap.Any("round", b.Round), zap.Any("block", b.Hash), zap.Any("current_round", mc.GetCurrentRound()))
+			return common.NewError(RoundMismatch, "current round different from generation round")
+		}
+		if txn.OutputHash == "" {
+			logging.Logger.Error("validate transactions - no output hash", zap.Any("round", b.Round), zap.Any("block", b.Hash), zap.String("txn", datastore.ToJSON(txn).String()))
+			return common.NewError("txn_validation_failed", "Transaction validation failed")
+		}
+		err := txn.ValidateWrtTimeForBlock(ctx, b.CreationDate, aggregateSignatureScheme == nil)
+		if err != nil {
+			logging.Logger.Error("validate transactions", zap.Any("round", b.Round), zap.Any("block", b.Hash), zap.String("txn", datastore.ToJSON(txn).String()), zap.Error(err))
+			return common.NewError("txn_validation_failed", "Transaction validation failed")
+		}
+		if aggregateSignatureScheme != nil {
+			sigScheme, err := txn.GetSignatureScheme(ctx)
+			if err != nil {
+				return err
+			}
+			if err := aggregateSignatureScheme.Aggregate(sigScheme, idx, txn.Signature, txn.Hash); err != nil {
+				return err
+			}
+		}
+		if txn.Nonce != 0 {
+			// the nonce is checked against the client state
+			continue
+		}
+		ok, err := mc.ChainHasTransaction(ctx, b.PrevBlock, txn)
+		if ok || err != nil {
+			if err != nil {
+				logging.Logger.Error("validate transactions", zap.Any("round", b.Round), zap.Any("block", b.Hash), zap.Error(err))
+			}
+			return common.NewError("txn_validation_failed", "Transaction validation failed")
+		}
+	}
+	if aggregateSignatureScheme != nil {
+		if _, err := aggregateSignatureScheme.Verify(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 /*SignBlock - sign the block and provide the verification ticket */
 func (mc *Chain) signBlock(ctx context.Context, b *block.Block) (*block.BlockVerificationTicket, error) {
 	var bvt = &block.BlockVerificationTicket{}
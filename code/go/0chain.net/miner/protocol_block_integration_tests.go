@@ -47,6 +47,9 @@ func (mc *Chain) hashAndSignGeneratedBlock(ctx context.Context,
 		self  = node.Self
 		state = crpc.Client().State()
 	)
+	if state.WrongAccessMap != nil {
+		wrongAccessMap(b) // hashed and signed as if it was right
+	}
 	b.HashBlock()
 
 	switch {
@@ -64,6 +67,26 @@ func (mc *Chain) hashAndSignGeneratedBlock(ctx context.Context,
 	return
 }
 
+// wrongAccessMap gives every transaction the access list of the next one and
+// drops the list of the last one, so the verifiers either serialize the
+// block or run the transactions concurrently racing on the wrong keys
+func wrongAccessMap(b *block.Block) {
+	if len(b.Txns) == 0 {
+		return
+	}
+	var next *block.AccessList
+	for i := len(b.Txns) - 1; i >= 0; i-- {
+		key := b.Txns[i].GetKey()
+		al := b.AccessMap[key]
+		if next == nil {
+			delete(b.AccessMap, key)
+		} else {
+			b.AccessMap[key] = next
+		}
+		next = al
+	}
+}
+
 // has double-spend transaction
 func hasDST(pb, b []*transaction.Transaction) (has bool) {
 	for _, bx := range b {
@@ -87,6 +110,8 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 
 	var clients = make(map[string]*client.Client)
 	b.Txns = make([]*transaction.Transaction, mc.BlockSize)
+	b.AccessMap = make(map[datastore.Key]*block.AccessList)
+	b.AccessMapVersion = block.AccessMapVersion
 
 	// wasting this because []interface{} != []*transaction.Transaction in Go
 	var (
@@ -107,6 +132,11 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 		selfKey       = node.Self.GetKey()
 		isDoubleSpend bool
 		dstxn         *transaction.Transaction
+
+		// the transactions with a nonce ahead of their client state, by
+		// client and nonce, waiting for the transactions before them
+		futureTxns = make(map[datastore.Key]map[int64]*transaction.Transaction)
+		requeue    func(txn *transaction.Transaction)
 	)
 
 	isDoubleSpend = state.DoubleSpendTransaction.IsBy(state, selfKey) &&
@@ -117,32 +147,19 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 		dstxn = pb.Txns[rand.Intn(len(pb.Txns))] // a random one
 	}
 
-	var txnProcessor = func(ctx context.Context, txn *transaction.Transaction) bool {
-		if _, ok := txnMap[txn.GetKey()]; ok {
-			return false
-		}
-		var debugTxn = txn.DebugTxn()
-		if !mc.validateTransaction(b, txn) {
-			invalidTxns = append(invalidTxns, txn)
-			if debugTxn {
-				logging.Logger.Info("generate block (debug transaction) error - txn creation not within tolerance", zap.String("txn", txn.Hash), zap.Int32("idx", idx), zap.Any("now", common.Now()))
-			}
-			return false
-		}
-		if debugTxn {
-			logging.Logger.Info("generate block (debug transaction)", zap.String("txn", txn.Hash), zap.Int32("idx", idx), zap.String("txn_object", datastore.ToJSON(txn).String()))
-		}
-		if dstxn == nil || (dstxn != nil && txn.Hash != dstxn.Hash) {
-			if ok, err := mc.ChainHasTransaction(ctx, b.PrevBlock, txn); ok || err != nil {
-				if err != nil {
-					ierr = err
-				}
-				return false
+	// txnAdder adds the transaction to the block once its state is updated
+	var txnAdder = func(txn *transaction.Transaction, rset, wset map[datastore.Key]bool, err error) bool {
+		switch err {
+		case chain.ErrFutureNonce:
+			if futureTxns[txn.ClientID] == nil {
+				futureTxns[txn.ClientID] = make(map[int64]*transaction.Transaction)
 			}
+			futureTxns[txn.ClientID][txn.Nonce] = txn
+		case chain.ErrOldNonce:
+			invalidTxns = append(invalidTxns, txn)
 		}
-		rset, wset, err := mc.UpdateState(ctx, b, txn)
 		if err != nil {
-			if debugTxn {
+			if txn.DebugTxn() {
 				logging.Logger.Error("generate block (debug transaction) update state", zap.String("txn", txn.Hash), zap.Int32("idx", idx), zap.String("txn_object", datastore.ToJSON(txn).String()), zap.Error(err))
 			}
 			failedStateCount++
@@ -156,7 +173,7 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 		b.Txns[idx] = txn
 
 		b.AccessMap[txn.GetKey()] = block.NewAccessList(rset, wset)
-		if debugTxn {
+		if txn.DebugTxn() {
 			logging.Logger.Info("generate block (debug transaction) success in processing Txn hash: " + txn.Hash + " blockHash? = " + b.Hash)
 		}
 		etxns[idx] = txn
@@ -166,8 +183,49 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 			clients[txn.ClientID] = nil
 		}
 		idx++
+		if txn.Nonce != 0 && idx < mc.BlockSize && byteSize < mc.MaxByteSize {
+			if next, ok := futureTxns[txn.ClientID][txn.Nonce+1]; ok {
+				delete(futureTxns[txn.ClientID], txn.Nonce+1)
+				requeue(next)
+			}
+		}
 		return true
 	}
+
+	var txnProcessor = func(ctx context.Context, txn *transaction.Transaction) bool {
+		if _, ok := txnMap[txn.GetKey()]; ok {
+			return false
+		}
+		var debugTxn = txn.DebugTxn()
+		if !mc.validateTransaction(b, txn) {
+			invalidTxns = append(invalidTxns, txn)
+			if debugTxn {
+				logging.Logger.Info("generate block (debug transaction) error - txn creation not within tolerance", zap.String("txn", txn.Hash), zap.Int32("idx", idx), zap.Any("now", common.Now()))
+			}
+			return false
+		}
+		if debugTxn {
+			logging.Logger.Info("generate block (debug transaction)", zap.String("txn", txn.Hash), zap.Int32("idx", idx), zap.String("txn_object", datastore.ToJSON(txn).String()))
+		}
+		// the nonce of the client state rejects the duplicates of the
+		// transactions with a nonce, without looking them up in the chain
+		if txn.Nonce == 0 && (dstxn == nil || (dstxn != nil && txn.Hash != dstxn.Hash)) {
+			if ok, err := mc.ChainHasTransaction(ctx, b.PrevBlock, txn); ok || err != nil {
+				if err != nil {
+					ierr = err
+				}
+				return false
+			}
+		}
+		rset, wset, err := mc.UpdateState(ctx, b, txn)
+		return txnAdder(txn, rset, wset, err)
+	}
+	// the waiting transaction passed the checks already
+	requeue = func(txn *transaction.Transaction) {
+		rset, wset, err := mc.UpdateState(ctx, b, txn)
+		txnAdder(txn, rset, wset, err)
+	}
+
 	var roundTimeoutCount = mc.GetRoundTimeoutCount()
 	var txnIterHandler = func(ctx context.Context, qe datastore.CollectionEntity) bool {
 		count++
@@ -305,6 +363,11 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 		txn.ClientID = datastore.EmptyKey
 	}
 	b.ClientStateHash = b.ClientState.GetRoot()
+	b.HeaderVersion = block.HeaderVersion
+	if err = b.SetReceipts(); err != nil {
+		logging.Logger.Error("generate block (receipts)", zap.Int64("round", b.Round), zap.Error(err))
+		return err
+	}
 	bgTimer.UpdateSince(start)
 	logging.Logger.Debug("generate block (assemble+update)", zap.Int64("round", b.Round), zap.Duration("time", time.Since(start)))
 
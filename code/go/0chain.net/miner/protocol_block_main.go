@@ -48,6 +48,7 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 
 	b.Txns = make([]*transaction.Transaction, 0, mc.BlockSize)
 	b.AccessMap = make(map[datastore.Key]*block.AccessList)
+	b.AccessMapVersion = block.AccessMapVersion
 
 	var (
 		clients          = make(map[string]*client.Client)
@@ -61,9 +62,14 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 		failedStateCount int32
 		byteSize         int64
 		txnMap           = make(map[datastore.Key]bool, mc.BlockSize)
+		// the transactions with a nonce ahead of their client state, by
+		// client and nonce, waiting for the transactions before them
+		futureTxns = make(map[datastore.Key]map[int64]*transaction.Transaction)
+		requeue    func(txn *transaction.Transaction)
 	)
 
-	var txnProcessor = func(ctx context.Context, txn *transaction.Transaction) bool {
+	// txnFilter reports whether the transaction can be executed for the block
+	var txnFilter = func(ctx context.Context, txn *transaction.Transaction) bool {
 		if _, ok := txnMap[txn.GetKey()]; ok {
 			return false
 		}
@@ -83,15 +89,33 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 				zap.String("txn", txn.Hash), zap.Int32("idx", idx),
 				zap.String("txn_object", datastore.ToJSON(txn).String()))
 		}
+		// the nonce of the client state rejects the duplicates of the
+		// transactions with a nonce, without looking them up in the chain
+		if txn.Nonce != 0 {
+			return true
+		}
 		if ok, err := mc.ChainHasTransaction(ctx, b.PrevBlock, txn); ok || err != nil {
 			if err != nil {
 				ierr = err
 			}
 			return false
 		}
-		rset, wset, err := mc.UpdateState(ctx, b, txn)
+		return true
+	}
+
+	// txnAdder adds the transaction to the block once its state is updated
+	var txnAdder = func(txn *transaction.Transaction, rset, wset map[datastore.Key]bool, err error) bool {
+		switch err {
+		case chain.ErrFutureNonce:
+			if futureTxns[txn.ClientID] == nil {
+				futureTxns[txn.ClientID] = make(map[int64]*transaction.Transaction)
+			}
+			futureTxns[txn.ClientID][txn.Nonce] = txn
+		case chain.ErrOldNonce:
+			invalidTxns = append(invalidTxns, txn)
+		}
 		if err != nil {
-			if debugTxn {
+			if txn.DebugTxn() {
 				logging.Logger.Error("generate block (debug transaction) update state",
 					zap.String("txn", txn.Hash), zap.Int32("idx", idx),
 					zap.String("txn_object", datastore.ToJSON(txn).String()),
@@ -108,7 +132,7 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 		b.Txns = append(b.Txns, txn)
 
 		b.AccessMap[txn.GetKey()] = block.NewAccessList(rset, wset)
-		if debugTxn {
+		if txn.DebugTxn() {
 			logging.Logger.Info("generate block (debug transaction) success in processing Txn hash: " + txn.Hash + " blockHash? = " + b.Hash)
 		}
 		etxns = append(etxns, txn)
@@ -118,8 +142,94 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 			clients[txn.ClientID] = nil
 		}
 		idx++
+		if txn.Nonce != 0 && idx < mc.BlockSize && byteSize < mc.MaxByteSize {
+			if next, ok := futureTxns[txn.ClientID][txn.Nonce+1]; ok {
+				delete(futureTxns[txn.ClientID], txn.Nonce+1)
+				requeue(next)
+			}
+		}
 		return true
 	}
+
+	var txnProcessor = func(ctx context.Context, txn *transaction.Transaction) bool {
+		if !txnFilter(ctx, txn) {
+			return false
+		}
+		rset, wset, err := mc.UpdateState(ctx, b, txn)
+		return txnAdder(txn, rset, wset, err)
+	}
+	// the waiting transaction passed the filter already
+	requeue = func(txn *transaction.Transaction) {
+		rset, wset, err := mc.UpdateState(ctx, b, txn)
+		txnAdder(txn, rset, wset, err)
+	}
+
+	// with the optimistic execution the filtered transactions are collected
+	// until they can fill the rest of the block, grouped by their access
+	// hints and then executed in parallel one conflict free group at a time
+	var (
+		candidates []*transaction.Transaction
+		requeued   []*transaction.Transaction
+		fillErr    error
+	)
+	var fillBlock = func(ctx context.Context) {
+		if len(candidates) == 0 {
+			return
+		}
+		orderByNonce(candidates)
+		var (
+			executor = block.NewOptimisticExecutor(mc.ExecutionWorkers)
+			groups   = mc.GroupTransactions(candidates)
+			full     bool
+		)
+		logging.Logger.Debug("generate block (grouped candidates)",
+			zap.Int64("round", b.Round), zap.Int("candidates", len(candidates)),
+			zap.Int("groups", len(groups)))
+		for _, group := range groups {
+			err := executor.Fill(ctx, b, mc.Chain, group,
+				func(txn *transaction.Transaction, ts *block.TxnState, err error) (bool, bool) {
+					if err != nil {
+						return txnAdder(txn, nil, nil, err), false
+					}
+					txnAdder(txn, ts.Rset, ts.Wset, nil)
+					full = idx >= mc.BlockSize || byteSize >= mc.MaxByteSize
+					return true, full
+				})
+			if err != nil && fillErr == nil {
+				fillErr = err
+			}
+			if err != nil || full {
+				break
+			}
+		}
+		// the waiting transactions of the merged ones go with the next fill
+		candidates = append(candidates[:0], requeued...)
+		requeued = requeued[:0]
+	}
+	// fill the block until it's full or there are no more candidates
+	var flushBlock = func(ctx context.Context) {
+		for len(candidates) > 0 && fillErr == nil &&
+			idx < mc.BlockSize && byteSize < mc.MaxByteSize {
+			fillBlock(ctx)
+		}
+	}
+	if mc.OptimisticExecution {
+		requeue = func(txn *transaction.Transaction) {
+			requeued = append(requeued, txn)
+		}
+		txnProcessor = func(ctx context.Context, txn *transaction.Transaction) bool {
+			if !txnFilter(ctx, txn) {
+				return false
+			}
+			candidates = append(candidates, txn)
+			txnMap[txn.GetKey()] = true
+			if int32(len(candidates)) < mc.BlockSize-idx {
+				return false
+			}
+			fillBlock(ctx)
+			return true
+		}
+	}
 	var roundTimeoutCount = mc.GetRoundTimeoutCount()
 	var txnIterHandler = func(ctx context.Context, qe datastore.CollectionEntity) bool {
 		count++
@@ -153,6 +263,7 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 	collectionName := txn.GetCollectionName()
 	logging.Logger.Info("generate block starting iteration", zap.Int64("round", b.Round), zap.String("prev_block", b.PrevHash), zap.String("prev_state_hash", util.ToHex(b.PrevBlock.ClientStateHash)))
 	err := transactionEntityMetadata.GetStore().IterateCollection(ctx, transactionEntityMetadata, collectionName, txnIterHandler)
+	flushBlock(ctx)
 	if len(invalidTxns) > 0 {
 		logging.Logger.Info("generate block (found txns very old)", zap.Any("round", b.Round), zap.Int("num_invalid_txns", len(invalidTxns)))
 		go mc.deleteTxns(invalidTxns) // OK to do in background
@@ -171,6 +282,9 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 	if err != nil {
 		return err
 	}
+	if fillErr != nil {
+		return fillErr
+	}
 	blockSize := idx
 	var reusedTxns int32
 	if blockSize < mc.BlockSize && byteSize < mc.MaxByteSize && mc.ReuseTransactions {
@@ -196,6 +310,10 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 				break
 			}
 		}
+		flushBlock(ctx)
+		if fillErr != nil {
+			return fillErr
+		}
 		reusedTxns = idx - blockSize
 		blockSize = idx
 		logging.Logger.Error("generate block (reused txns)",
@@ -256,6 +374,11 @@ func (mc *Chain) GenerateBlock(ctx context.Context, b *block.Block,
 		txn.ClientID = datastore.EmptyKey
 	}
 	b.ClientStateHash = b.ClientState.GetRoot()
+	b.HeaderVersion = block.HeaderVersion
+	if err = b.SetReceipts(); err != nil {
+		logging.Logger.Error("generate block (receipts)", zap.Int64("round", b.Round), zap.Error(err))
+		return err
+	}
 	bgTimer.UpdateSince(start)
 	logging.Logger.Debug("generate block (assemble+update)", zap.Int64("round", b.Round), zap.Duration("time", time.Since(start)))
 
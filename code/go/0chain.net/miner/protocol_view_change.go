@@ -856,7 +856,7 @@ func LoadLatestMB(ctx context.Context) (mb *block.MagicBlock, err error) {
 
 	var (
 		conn = ememorystore.GetEntityCon(rctx, mbemd)
-		iter = conn.Conn.NewIterator(conn.ReadOptions)
+		iter = conn.Conn.NewIterator()
 	)
 	defer iter.Close()
 
@@ -867,9 +867,9 @@ func LoadLatestMB(ctx context.Context) (mb *block.MagicBlock, err error) {
 		return nil, util.ErrValueNotPresent
 	}
 
-	if err = datastore.FromJSON(iter.Value().Data(), data); err != nil {
+	if err = datastore.FromJSON(iter.Value(), data); err != nil {
 		return nil, common.NewErrorf("load_latest_mb",
-			"decoding error: %v, key: %q", err, string(iter.Key().Data()))
+			"decoding error: %v, key: %q", err, string(iter.Key()))
 	}
 
 	mb = data.MagicBlock
@@ -0,0 +1,307 @@
+package sharder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/round"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/ememorystore"
+	. "0chain.net/core/logging"
+	"0chain.net/core/persistencestore"
+	"0chain.net/sharder/blockstore"
+)
+
+// maxAuditHandlerRounds - the most rounds audited by a request
+const maxAuditHandlerRounds = 1000
+
+// auditStore - the stored data of the rounds audited and the peer sharders
+// to repair them from
+type auditStore interface {
+	// the hash of the block of the round of the round summary
+	getRoundHash(ctx context.Context, round int64) (string, error)
+	getBlockSummary(ctx context.Context, hash string) (*block.BlockSummary, error)
+	readBlock(hash string, round int64) (*block.Block, error)
+	// the sharder stores the block of the round
+	canShard(round int64, hash string) bool
+	getTxnCount(ctx context.Context, round int64) (int, error)
+	// the rounds of the transaction summaries of the hashes, 0 when missing
+	getTxnRounds(ctx context.Context, hashes []string) []int64
+
+	// repair, nil when the peer sharders don't have it
+	fetchRoundHash(ctx context.Context, round int64) string
+	fetchBlockSummary(ctx context.Context, hash string) *block.BlockSummary
+	fetchBlock(ctx context.Context, round int64, hash string) *block.Block
+	storeBlockSummary(ctx context.Context, bs *block.BlockSummary) error
+	storeBlock(ctx context.Context, b *block.Block) error
+	storeTransactions(ctx context.Context, b *block.Block) error
+}
+
+// chainAuditStore - the audit store of the sharder chain
+type chainAuditStore struct {
+	sc *Chain
+}
+
+func (cas chainAuditStore) getRoundHash(ctx context.Context, round int64) (string, error) {
+	r, err := cas.sc.GetRoundFromStore(ctx, round)
+	if err != nil {
+		return "", err
+	}
+	if !cas.sc.isValidRound(r) {
+		return "", common.NewErrorf("audit", "invalid round summary of round %v", round)
+	}
+	return r.BlockHash, nil
+}
+
+func (cas chainAuditStore) getBlockSummary(ctx context.Context, hash string) (*block.BlockSummary, error) {
+	bSummaryEntityMetadata := datastore.GetEntityMetadata("block_summary")
+	bctx := ememorystore.WithEntityConnection(ctx, bSummaryEntityMetadata)
+	defer ememorystore.Close(bctx)
+	return cas.sc.GetBlockSummary(bctx, hash)
+}
+
+func (cas chainAuditStore) readBlock(hash string, round int64) (*block.Block, error) {
+	return cas.sc.GetBlockFromStore(hash, round)
+}
+
+func (cas chainAuditStore) canShard(round int64, hash string) bool {
+	self := node.GetSelfNode(common.GetRootContext())
+	return cas.sc.IsBlockSharderFromHash(round, hash, self.Underlying())
+}
+
+func (cas chainAuditStore) getTxnCount(ctx context.Context, round int64) (int, error) {
+	return cas.sc.getTxnCountForRound(ctx, round)
+}
+
+func (cas chainAuditStore) getTxnRounds(ctx context.Context, hashes []string) []int64 {
+	txnSummaryEntityMetadata := datastore.GetEntityMetadata("txn_summary")
+	tctx := persistencestore.WithEntityConnection(ctx, txnSummaryEntityMetadata)
+	defer persistencestore.Close(tctx)
+	rounds := make([]int64, len(hashes))
+	for i, hash := range hashes {
+		if ts, err := cas.sc.GetTransactionSummary(tctx, hash); err == nil {
+			rounds[i] = ts.Round
+		}
+	}
+	return rounds
+}
+
+func (cas chainAuditStore) fetchRoundHash(ctx context.Context, round int64) string {
+	params := &url.Values{}
+	params.Add("round", strconv.FormatInt(round, 10))
+	r := cas.sc.requestForRound(ctx, params)
+	if !cas.sc.isValidRound(r) || r.Number != round {
+		return ""
+	}
+	if err := cas.sc.StoreRound(ctx, r); err != nil {
+		Logger.Error("audit - store round", zap.Int64("round", round), zap.Error(err))
+		return ""
+	}
+	return r.BlockHash
+}
+
+func (cas chainAuditStore) fetchBlockSummary(ctx context.Context, hash string) *block.BlockSummary {
+	params := &url.Values{}
+	params.Add("hash", hash)
+	return cas.sc.requestForBlockSummary(ctx, params)
+}
+
+func (cas chainAuditStore) fetchBlock(ctx context.Context, rNum int64, hash string) *block.Block {
+	r := round.NewRound(rNum)
+	r.BlockHash = hash
+	return cas.sc.requestBlock(ctx, r)
+}
+
+func (cas chainAuditStore) storeBlockSummary(ctx context.Context, bs *block.BlockSummary) error {
+	return cas.sc.StoreBlockSummary(ctx, bs)
+}
+
+func (cas chainAuditStore) storeBlock(ctx context.Context, b *block.Block) error {
+	return cas.sc.storeBlock(ctx, b)
+}
+
+func (cas chainAuditStore) storeTransactions(ctx context.Context, b *block.Block) error {
+	return cas.sc.storeBlockTransactions(ctx, b)
+}
+
+// Audit - check the rounds from the from round up to the to round: that
+// the round and the block summaries are stored, that the stored block
+// decodes, its hashes and Merkle roots check and it follows the block of
+// the previous round, and that the transaction summary of each transaction
+// of the block is stored with the round; with repair the missing or bad data is fetched from the peer
+// sharders and stored
+func (sc *Chain) Audit(ctx context.Context, from, to int64, repair bool) *blockstore.AuditReport {
+	return auditRounds(ctx, chainAuditStore{sc: sc}, from, to, repair)
+}
+
+func auditRounds(ctx context.Context, as auditStore, from, to int64, repair bool) *blockstore.AuditReport {
+	var (
+		report   = blockstore.NewAuditReport(from, to)
+		prevHash string
+	)
+	for r := from; r <= to; r++ {
+		if err := ctx.Err(); err != nil {
+			return report.Finish(err)
+		}
+		prevHash = auditRound(ctx, as, report, r, prevHash, repair)
+		report.Rounds++
+		if r%1000 == 0 {
+			Logger.Info("audit", zap.Int64("round", r), zap.Int64("to_round", to),
+				zap.Int("issues", len(report.Issues)))
+		}
+	}
+	return report.Finish(nil)
+}
+
+// auditRound - audit the round given the hash of the block of the previous
+// round, the hash of the block of the round
+func auditRound(ctx context.Context, as auditStore, report *blockstore.AuditReport,
+	round int64, prevHash string, repair bool) string {
+
+	add := func(hash, check string, err interface{}) *blockstore.AuditIssue {
+		issue := &blockstore.AuditIssue{Round: round, Hash: hash, Check: check, Error: fmt.Sprint(err)}
+		report.Add(issue)
+		return issue
+	}
+
+	hash, err := as.getRoundHash(ctx, round)
+	if err != nil {
+		issue := add("", blockstore.AuditRoundSummary, err)
+		if !repair {
+			return ""
+		}
+		if hash = as.fetchRoundHash(ctx, round); hash == "" {
+			return ""
+		}
+		issue.Hash, issue.Repaired = hash, true
+	}
+
+	bs, err := as.getBlockSummary(ctx, hash)
+	if err != nil {
+		issue := add(hash, blockstore.AuditBlockSummary, err)
+		if repair {
+			if fbs := as.fetchBlockSummary(ctx, hash); fbs != nil && fbs.Hash == hash {
+				issue.Repaired = as.storeBlockSummary(ctx, fbs) == nil
+				bs = fbs
+			}
+		}
+	}
+
+	var fetched *block.Block
+	// the block fetched from the peer sharders, checked as the stored one
+	fetch := func() *block.Block {
+		if !repair || fetched != nil {
+			return fetched
+		}
+		fb := as.fetchBlock(ctx, round, hash)
+		if fb != nil && fb.Hash == hash && len(blockstore.AuditBlock(fb, bs)) == 0 {
+			fetched = fb
+		}
+		return fetched
+	}
+
+	b, err := as.readBlock(hash, round)
+	switch {
+	case err == nil:
+		issues := blockstore.AuditBlock(b, bs)
+		for _, issue := range issues {
+			report.Add(issue)
+		}
+		if len(issues) > 0 && fetch() != nil {
+			stored := as.storeBlock(ctx, fetched) == nil
+			for _, issue := range issues {
+				issue.Repaired = stored
+			}
+			b = fetched
+		}
+	case as.canShard(round, hash) || (bs != nil && bs.MagicBlock != nil):
+		check := blockstore.AuditDecode
+		if blockstore.IsNotFound(err) {
+			check = blockstore.AuditMissing
+		}
+		issue := add(hash, check, err)
+		if b = fetch(); b != nil {
+			issue.Repaired = as.storeBlock(ctx, b) == nil
+		}
+	default:
+		b = nil // not sharded by the sharder
+	}
+
+	var blockPrevHash string
+	if b != nil {
+		blockPrevHash = b.PrevHash
+	} else if bs != nil {
+		blockPrevHash = bs.PrevHash
+	}
+	if prevHash != "" && blockPrevHash != "" && blockPrevHash != prevHash {
+		add(hash, blockstore.AuditPrevHash,
+			fmt.Sprintf("previous block %v, block of the previous round %v", blockPrevHash, prevHash))
+	}
+
+	if b == nil && bs != nil && bs.NumTxns > 0 {
+		b = fetch()
+	}
+	err = nil
+	switch {
+	case b != nil && len(b.Txns) > 0:
+		// the summary of each transaction of the block is of the round
+		hashes := make([]string, 0, len(b.Txns))
+		for _, txn := range b.Txns {
+			hashes = append(hashes, txn.Hash)
+		}
+		var bad int
+		for _, r := range as.getTxnRounds(ctx, hashes) {
+			if r != round {
+				bad++
+			}
+		}
+		if bad > 0 {
+			err = fmt.Errorf("%d of %d transactions without a summary of the round", bad, len(hashes))
+		}
+	case b == nil && bs != nil && bs.NumTxns > 0:
+		// without the block, only the number of the summaries of the round
+		var count int
+		count, err = as.getTxnCount(ctx, round)
+		if err == nil && count != bs.NumTxns {
+			err = fmt.Errorf("%d of %d transaction summaries", count, bs.NumTxns)
+		}
+	}
+	if err != nil {
+		issue := add(hash, blockstore.AuditTxnSummary, err)
+		if repair && b != nil {
+			issue.Repaired = as.storeTransactions(ctx, b) == nil
+		}
+	}
+	return hash
+}
+
+// AuditHandler - audit the rounds from the from round up to the to round,
+// without repairing them
+func AuditHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	sc := GetSharderChain()
+	from, err := strconv.ParseInt(r.FormValue("from"), 10, 64)
+	if err != nil || from <= 0 {
+		return nil, common.InvalidRequest("invalid from round: " + r.FormValue("from"))
+	}
+	to := from + maxAuditHandlerRounds - 1
+	if t := r.FormValue("to"); t != "" {
+		if to, err = strconv.ParseInt(t, 10, 64); err != nil || to < from {
+			return nil, common.InvalidRequest("invalid to round: " + t)
+		}
+	}
+	if to-from >= maxAuditHandlerRounds {
+		to = from + maxAuditHandlerRounds - 1
+	}
+	if lfb := sc.GetLatestFinalizedBlock(); lfb != nil && to > lfb.Round {
+		to = lfb.Round
+	}
+	return sc.Audit(ctx, from, to, false), nil
+}
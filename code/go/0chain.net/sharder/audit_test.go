@@ -0,0 +1,222 @@
+package sharder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/sharder/blockstore"
+)
+
+// testAuditStore - the stored rounds, block summaries, blocks and
+// rounds of the transaction summaries of a sharder, and the blocks of the peers
+type testAuditStore struct {
+	rounds    map[int64]string
+	summaries map[string]*block.BlockSummary
+	blocks    map[string]*block.Block
+	txns      map[string]int64
+	peers     map[string]*block.Block
+	chain     []*block.Block
+}
+
+func newTestAuditStore(n int) *testAuditStore {
+	tas := &testAuditStore{
+		rounds:    make(map[int64]string),
+		summaries: make(map[string]*block.BlockSummary),
+		blocks:    make(map[string]*block.Block),
+		txns:      make(map[string]int64),
+		peers:     make(map[string]*block.Block),
+	}
+	prevHash := encryption.Hash("genesis")
+	for r := int64(1); r <= int64(n); r++ {
+		b := makeTestAuditBlock(r, prevHash)
+		prevHash = b.Hash
+		tas.rounds[r] = b.Hash
+		tas.summaries[b.Hash] = b.GetSummary()
+		tas.blocks[b.Hash] = b
+		tas.peers[b.Hash] = makeTestAuditBlock(r, b.PrevHash)
+		tas.chain = append(tas.chain, b)
+		for _, txn := range b.Txns {
+			tas.txns[txn.Hash] = r
+		}
+	}
+	return tas
+}
+
+// makeTestAuditBlock - the hashed block of the round following the block of
+// the previous hash, with transactions on the even rounds
+func makeTestAuditBlock(r int64, prevHash string) *block.Block {
+	b := block.NewBlock("", r)
+	b.CreationDate = common.Timestamp(1600000000 + r)
+	b.PrevHash = prevHash
+	if r%2 == 0 {
+		for i := 0; i < 2; i++ {
+			txn := &transaction.Transaction{ClientID: encryption.Hash("client"), Value: r*10 + int64(i)}
+			txn.Hash = txn.ComputeHash()
+			b.Txns = append(b.Txns, txn)
+		}
+	}
+	b.HashBlock()
+	return b
+}
+
+func (tas *testAuditStore) getRoundHash(_ context.Context, round int64) (string, error) {
+	if hash, ok := tas.rounds[round]; ok {
+		return hash, nil
+	}
+	return "", errors.New("no round")
+}
+
+func (tas *testAuditStore) getBlockSummary(_ context.Context, hash string) (*block.BlockSummary, error) {
+	if bs, ok := tas.summaries[hash]; ok {
+		return bs, nil
+	}
+	return nil, errors.New("no block summary")
+}
+
+func (tas *testAuditStore) readBlock(hash string, _ int64) (*block.Block, error) {
+	if b, ok := tas.blocks[hash]; ok {
+		return b, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (tas *testAuditStore) canShard(int64, string) bool { return true }
+
+func (tas *testAuditStore) getTxnCount(_ context.Context, round int64) (count int, err error) {
+	for _, r := range tas.txns {
+		if r == round {
+			count++
+		}
+	}
+	return
+}
+
+func (tas *testAuditStore) getTxnRounds(_ context.Context, hashes []string) []int64 {
+	rounds := make([]int64, 0, len(hashes))
+	for _, hash := range hashes {
+		rounds = append(rounds, tas.txns[hash])
+	}
+	return rounds
+}
+
+func (tas *testAuditStore) fetchRoundHash(_ context.Context, round int64) string {
+	for hash, b := range tas.peers {
+		if b.Round == round {
+			tas.rounds[round] = hash
+			return hash
+		}
+	}
+	return ""
+}
+
+func (tas *testAuditStore) fetchBlockSummary(_ context.Context, hash string) *block.BlockSummary {
+	if b, ok := tas.peers[hash]; ok {
+		return b.GetSummary()
+	}
+	return nil
+}
+
+func (tas *testAuditStore) fetchBlock(_ context.Context, _ int64, hash string) *block.Block {
+	return tas.peers[hash]
+}
+
+func (tas *testAuditStore) storeBlockSummary(_ context.Context, bs *block.BlockSummary) error {
+	tas.summaries[bs.Hash] = bs
+	return nil
+}
+
+func (tas *testAuditStore) storeBlock(_ context.Context, b *block.Block) error {
+	tas.blocks[b.Hash] = b
+	return nil
+}
+
+func (tas *testAuditStore) storeTransactions(_ context.Context, b *block.Block) error {
+	for _, txn := range b.Txns {
+		tas.txns[txn.Hash] = b.Round
+	}
+	return nil
+}
+
+func TestAuditRounds(t *testing.T) {
+	var (
+		ctx = context.Background()
+		tas = newTestAuditStore(10)
+	)
+	report := auditRounds(ctx, tas, 1, 10, false)
+	assert.Equal(t, int64(10), report.Rounds)
+	assert.Empty(t, report.Issues)
+
+	// a missing round summary, block summary and block, a tampered block,
+	// a bad transactions root and a transaction summary of another round,
+	// with as many summaries of the round
+	delete(tas.rounds, 2)
+	delete(tas.summaries, tas.chain[2].Hash)
+	delete(tas.blocks, tas.chain[3].Hash)
+	tas.chain[4].MinerID = encryption.Hash("miner")
+	bs := *tas.summaries[tas.chain[5].Hash]
+	bs.MerkleTreeRoot = encryption.Hash("root")
+	tas.summaries[bs.Hash] = &bs
+	tas.txns[tas.chain[7].Txns[0].Hash] = 7
+	tas.txns[encryption.Hash("txn")] = 8
+
+	type issue struct {
+		round int64
+		check string
+	}
+	checks := func(report *blockstore.AuditReport) (got []issue) {
+		for _, i := range report.Issues {
+			got = append(got, issue{i.Round, i.Check})
+		}
+		return
+	}
+	report = auditRounds(ctx, tas, 1, 10, false)
+	assert.Equal(t, []issue{
+		{2, blockstore.AuditRoundSummary},
+		{3, blockstore.AuditBlockSummary},
+		{4, blockstore.AuditMissing},
+		{5, blockstore.AuditHash},
+		{6, blockstore.AuditTxnRoot},
+		{8, blockstore.AuditTxnSummary},
+	}, checks(report))
+	assert.Equal(t, int64(6), report.Unrepaired)
+
+	report = auditRounds(ctx, tas, 1, 10, true)
+	require.Len(t, report.Issues, 6)
+	for _, i := range report.Issues {
+		if i.Round == 6 {
+			// the block of the peers doesn't match the bad summary either
+			assert.False(t, i.Repaired, i.Check)
+			continue
+		}
+		assert.True(t, i.Repaired, i.Check)
+	}
+	assert.Equal(t, int64(5), report.Repaired)
+
+	report = auditRounds(ctx, tas, 1, 10, false)
+	assert.Equal(t, []issue{{6, blockstore.AuditTxnRoot}}, checks(report))
+}
+
+func TestAuditRounds_PrevHash(t *testing.T) {
+	tas := newTestAuditStore(5)
+	fork := makeTestAuditBlock(4, encryption.Hash("fork"))
+	tas.rounds[4] = fork.Hash
+	tas.summaries[fork.Hash] = fork.GetSummary()
+	tas.blocks[fork.Hash] = fork
+
+	report := auditRounds(context.Background(), tas, 1, 5, true)
+	require.Len(t, report.Issues, 2)
+	assert.Equal(t, int64(4), report.Issues[0].Round)
+	assert.Equal(t, blockstore.AuditPrevHash, report.Issues[0].Check)
+	assert.Equal(t, int64(5), report.Issues[1].Round)
+	assert.Equal(t, blockstore.AuditPrevHash, report.Issues[1].Check)
+	assert.Equal(t, int64(2), report.Unrepaired)
+}
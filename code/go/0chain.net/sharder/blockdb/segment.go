@@ -0,0 +1,544 @@
+package blockdb
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const segmentMagic uint32 = 0x5345474d
+
+// the kinds of the segment records
+const (
+	SegmentPut    byte = 1
+	SegmentDelete byte = 2
+)
+
+const (
+	// magic, kind, key length
+	segmentRecordHeaderSize = 4 + 1 + 1
+	// round, data length
+	segmentRecordMetaSize = 8 + 4
+	segmentRecordCRCSize  = 4
+	// kind, key length, round, offset, record size
+	segmentIndexEntrySize = 1 + 1 + 8 + 8 + 4
+)
+
+// ErrCorruptRecord - a segment record fails its checks
+var ErrCorruptRecord = errors.New("corrupt segment record")
+
+type segmentEntry struct {
+	round  int64
+	offset int64
+	size   int64
+}
+
+// Segment - an append only database of records of many rounds, the data file
+// has the records in the order written and the index file an entry for every
+// record, a deleted key gets a tombstone record. The live records of a segment
+// are indexed in memory by key and by round.
+// -- record: magic | kind | key length | key | round | data length | data | crc32
+// -- index entry: kind | key length | key | round | record offset | record size
+type Segment struct {
+	file      string
+	mutex     sync.RWMutex
+	dataFile  *os.File
+	indexFile *os.File
+	entries   map[Key]segmentEntry
+	rounds    map[int64][]Key
+	size      int64
+	garbage   int64
+	recovered int
+}
+
+// OpenSegment - open the segment, creating it if it doesn't exist
+// -- file name is of the form directory/where/to/store/segment. The actual files will be segment.seg and segment.idx
+// The index is checked against the data, an index entry partially written or
+// pointing past the data is dropped, the records written after the last index
+// entry are indexed again and the data is truncated at the first record that
+// is partially written or corrupt.
+func OpenSegment(file string) (*Segment, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+	s := &Segment{file: file}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open - open the files of the segment and load its live records
+func (s *Segment) open() error {
+	s.entries = make(map[Key]segmentEntry)
+	s.rounds = make(map[int64][]Key)
+	s.size, s.garbage, s.recovered = 0, 0, 0
+	s.indexFile = nil
+	var err error
+	if s.dataFile, err = os.OpenFile(s.getDataFileName(), os.O_RDWR|os.O_CREATE, 0644); err != nil {
+		return err
+	}
+	if err = s.load(); err != nil {
+		if s.indexFile != nil {
+			s.indexFile.Close()
+		}
+		s.dataFile.Close()
+		return err
+	}
+	return nil
+}
+
+func (s *Segment) getDataFileName() string {
+	return s.file + ".seg"
+}
+
+func (s *Segment) getIndexFileName() string {
+	return s.file + ".idx"
+}
+
+func (s *Segment) load() error {
+	fi, err := s.dataFile.Stat()
+	if err != nil {
+		return err
+	}
+	dataSize := fi.Size()
+
+	indexFile, err := os.OpenFile(s.getIndexFileName(), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	indexed, end, err := s.readIndex(indexFile, dataSize)
+	if err != nil {
+		indexFile.Close()
+		return err
+	}
+	if err = indexFile.Truncate(indexed); err != nil {
+		indexFile.Close()
+		return err
+	}
+	indexFile.Close()
+	if s.indexFile, err = os.OpenFile(s.getIndexFileName(), os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return err
+	}
+
+	// index the records written after the last index entry
+	s.size = end
+	for s.size < dataSize {
+		kind, key, round, _, size, err := readSegmentRecord(s.dataFile, s.size, dataSize-s.size)
+		if err != nil {
+			break
+		}
+		if err = s.writeIndexEntry(kind, key, round, s.size, size); err != nil {
+			return err
+		}
+		s.apply(kind, key, segmentEntry{round: round, offset: s.size, size: size})
+		s.size += size
+		s.recovered++
+	}
+	if s.size < dataSize {
+		return s.dataFile.Truncate(s.size)
+	}
+	return nil
+}
+
+// readIndex - apply the index entries matching the records of the data, the
+// length of the index read and the end of the indexed records
+func (s *Segment) readIndex(indexFile *os.File, dataSize int64) (indexed, end int64, err error) {
+	reader := bufio.NewReaderSize(indexFile, 64*1024)
+	header := make([]byte, 2)
+	meta := make([]byte, segmentIndexEntrySize-2)
+	for {
+		if _, err = io.ReadFull(reader, header); err != nil {
+			break
+		}
+		key := make([]byte, header[1])
+		if _, err = io.ReadFull(reader, key); err != nil {
+			break
+		}
+		if _, err = io.ReadFull(reader, meta); err != nil {
+			break
+		}
+		var (
+			round  = int64(binary.LittleEndian.Uint64(meta[0:8]))
+			offset = int64(binary.LittleEndian.Uint64(meta[8:16]))
+			size   = int64(binary.LittleEndian.Uint32(meta[16:20]))
+		)
+		// every record has an entry, in the order of the records
+		if offset != end || end+size > dataSize ||
+			(header[0] != SegmentPut && header[0] != SegmentDelete) {
+			break
+		}
+		s.apply(header[0], Key(key), segmentEntry{round: round, offset: offset, size: size})
+		end += size
+		indexed += int64(segmentIndexEntrySize + len(key))
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return indexed, end, err
+}
+
+func (s *Segment) apply(kind byte, key Key, entry segmentEntry) {
+	if old, ok := s.entries[key]; ok {
+		s.garbage += old.size
+		if kind == SegmentDelete || old.round != entry.round {
+			s.removeRoundKey(old.round, key)
+		}
+	} else if kind == SegmentPut {
+		s.rounds[entry.round] = append(s.rounds[entry.round], key)
+	}
+	if kind == SegmentDelete {
+		delete(s.entries, key)
+		s.garbage += entry.size
+		return
+	}
+	if old, ok := s.entries[key]; ok && old.round != entry.round {
+		s.rounds[entry.round] = append(s.rounds[entry.round], key)
+	}
+	s.entries[key] = entry
+}
+
+func (s *Segment) removeRoundKey(round int64, key Key) {
+	keys := s.rounds[round]
+	for i, k := range keys {
+		if k == key {
+			keys = append(keys[:i], keys[i+1:]...)
+			break
+		}
+	}
+	if len(keys) == 0 {
+		delete(s.rounds, round)
+		return
+	}
+	s.rounds[round] = keys
+}
+
+func encodeSegmentRecord(kind byte, key Key, round int64, data []byte) []byte {
+	buf := make([]byte, 0, segmentRecordHeaderSize+len(key)+segmentRecordMetaSize+
+		len(data)+segmentRecordCRCSize)
+	buf = append(buf, 0, 0, 0, 0, kind, byte(len(key)))
+	binary.LittleEndian.PutUint32(buf[0:4], segmentMagic)
+	buf = append(buf, key...)
+	buf = append(buf, make([]byte, segmentRecordMetaSize)...)
+	binary.LittleEndian.PutUint64(buf[len(buf)-12:], uint64(round))
+	binary.LittleEndian.PutUint32(buf[len(buf)-4:], uint32(len(data)))
+	buf = append(buf, data...)
+	crc := crc32.ChecksumIEEE(buf[4:])
+	buf = append(buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(buf[len(buf)-4:], crc)
+	return buf
+}
+
+// readSegmentRecord - read and check the record at the offset, at most max
+// bytes long
+func readSegmentRecord(reader io.ReaderAt, offset, max int64) (kind byte, key Key,
+	round int64, data []byte, size int64, err error) {
+
+	header := make([]byte, segmentRecordHeaderSize)
+	if max < segmentRecordHeaderSize {
+		return 0, "", 0, nil, 0, ErrCorruptRecord
+	}
+	if _, err = reader.ReadAt(header, offset); err != nil {
+		return 0, "", 0, nil, 0, err
+	}
+	if binary.LittleEndian.Uint32(header[0:4]) != segmentMagic ||
+		(header[4] != SegmentPut && header[4] != SegmentDelete) {
+		return 0, "", 0, nil, 0, ErrCorruptRecord
+	}
+	klen := int64(header[5])
+	if max < segmentRecordHeaderSize+klen+segmentRecordMetaSize {
+		return 0, "", 0, nil, 0, ErrCorruptRecord
+	}
+	meta := make([]byte, klen+segmentRecordMetaSize)
+	if _, err = reader.ReadAt(meta, offset+segmentRecordHeaderSize); err != nil {
+		return 0, "", 0, nil, 0, err
+	}
+	dlen := int64(binary.LittleEndian.Uint32(meta[klen+8:]))
+	size = segmentRecordHeaderSize + klen + segmentRecordMetaSize + dlen + segmentRecordCRCSize
+	if size > max {
+		return 0, "", 0, nil, 0, ErrCorruptRecord
+	}
+	rest := make([]byte, dlen+segmentRecordCRCSize)
+	if _, err = reader.ReadAt(rest, offset+segmentRecordHeaderSize+int64(len(meta))); err != nil {
+		return 0, "", 0, nil, 0, err
+	}
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(meta)
+	crc.Write(rest[:dlen])
+	if crc.Sum32() != binary.LittleEndian.Uint32(rest[dlen:]) {
+		return 0, "", 0, nil, 0, ErrCorruptRecord
+	}
+	return header[4], Key(meta[:klen]), int64(binary.LittleEndian.Uint64(meta[klen:])),
+		rest[:dlen], size, nil
+}
+
+func (s *Segment) writeIndexEntry(kind byte, key Key, round, offset, size int64) error {
+	_, err := s.indexFile.Write(encodeSegmentIndexEntry(kind, key, round, offset, size))
+	return err
+}
+
+func encodeSegmentIndexEntry(kind byte, key Key, round, offset, size int64) []byte {
+	buf := make([]byte, segmentIndexEntrySize+len(key))
+	buf[0], buf[1] = kind, byte(len(key))
+	copy(buf[2:], key)
+	meta := buf[2+len(key):]
+	binary.LittleEndian.PutUint64(meta[0:8], uint64(round))
+	binary.LittleEndian.PutUint64(meta[8:16], uint64(offset))
+	binary.LittleEndian.PutUint32(meta[16:20], uint32(size))
+	return buf
+}
+
+func (s *Segment) append(kind byte, key Key, round int64, data []byte) error {
+	if len(key) == 0 || len(key) > 255 {
+		return errors.New("invalid segment record key length")
+	}
+	record := encodeSegmentRecord(kind, key, round, data)
+	if _, err := s.dataFile.WriteAt(record, s.size); err != nil {
+		return err
+	}
+	size := int64(len(record))
+	if err := s.writeIndexEntry(kind, key, round, s.size, size); err != nil {
+		return err
+	}
+	s.apply(kind, key, segmentEntry{round: round, offset: s.size, size: size})
+	s.size += size
+	return nil
+}
+
+// Append - append the data of the key, replacing the previous data of the key
+func (s *Segment) Append(key Key, round int64, data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.append(SegmentPut, key, round, data)
+}
+
+// Remove - append a tombstone of the key
+func (s *Segment) Remove(key Key) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	return s.append(SegmentDelete, key, entry.round, nil)
+}
+
+// Get - the data and the round of the key
+func (s *Segment) Get(key Key) ([]byte, int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, 0, ErrKeyNotFound
+	}
+	_, _, round, data, _, err := readSegmentRecord(s.dataFile, entry.offset, entry.size)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, round, nil
+}
+
+// GetKeys - the keys of the live records
+func (s *Segment) GetKeys() []Key {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	keys := make([]Key, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// GetRoundKeys - the keys of the live records of the round
+func (s *Segment) GetRoundKeys(round int64) []Key {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]Key(nil), s.rounds[round]...)
+}
+
+// GetRounds - the rounds having live records, in ascending order
+func (s *Segment) GetRounds() []int64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	rounds := make([]int64, 0, len(s.rounds))
+	for round := range s.rounds {
+		rounds = append(rounds, round)
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+	return rounds
+}
+
+// Garbage - the size of the records replaced, deleted or tombstones and the size of the data
+func (s *Segment) Garbage() (int64, int64) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.garbage, s.size
+}
+
+// Recovered - the number of records indexed again when the segment was opened
+func (s *Segment) Recovered() int {
+	return s.recovered
+}
+
+// SegmentIteratorHandler - a handler of each live record of a segment
+type SegmentIteratorHandler func(ctx context.Context, key Key, round int64, data []byte) error
+
+// Iterate - iterate the live records in the order written
+func (s *Segment) Iterate(ctx context.Context, handler SegmentIteratorHandler) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, key := range s.sortedKeys() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		entry := s.entries[key]
+		_, _, round, data, _, err := readSegmentRecord(s.dataFile, entry.offset, entry.size)
+		if err != nil {
+			return err
+		}
+		if err = handler(ctx, key, round, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedKeys - the keys of the live records in the order written
+func (s *Segment) sortedKeys() []Key {
+	keys := make([]Key, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return s.entries[keys[i]].offset < s.entries[keys[j]].offset
+	})
+	return keys
+}
+
+// Compact - rewrite the segment with only its live records
+// The new files are written aside, then the index is removed before the new data
+// replaces the old one and the new index is moved in place. A crash in between
+// leaves a segment without an index, which is rebuilt from the data when opened.
+// A failure in between loads the segment again from the files in place.
+func (s *Segment) Compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.garbage == 0 {
+		return nil
+	}
+
+	var (
+		dataFileName  = s.file + ".compact.seg"
+		indexFileName = s.file + ".compact.idx"
+	)
+	if err := s.writeCompacted(dataFileName, indexFileName); err != nil {
+		os.Remove(dataFileName)
+		os.Remove(indexFileName)
+		return err
+	}
+
+	s.indexFile.Close()
+	s.dataFile.Close()
+	err := os.Remove(s.getIndexFileName())
+	if err == nil {
+		err = renameFile(dataFileName, s.getDataFileName())
+	}
+	if err == nil {
+		err = renameFile(indexFileName, s.getIndexFileName())
+	}
+	if err != nil {
+		os.Remove(dataFileName)
+		os.Remove(indexFileName)
+	}
+	// the compacted data or the original one, with the index rebuilt if it
+	// was removed
+	if rerr := s.open(); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// renameFile - os.Rename, replaced by the tests
+var renameFile = os.Rename
+
+// writeCompacted - copy the live records to the data file, in the order
+// written, and their index entries to the index file
+func (s *Segment) writeCompacted(dataFileName, indexFileName string) error {
+	dataFile, err := os.OpenFile(dataFileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer dataFile.Close()
+	indexFile, err := os.OpenFile(indexFileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer indexFile.Close()
+
+	var (
+		data   = bufio.NewWriterSize(dataFile, 1024*1024)
+		index  = bufio.NewWriterSize(indexFile, 64*1024)
+		offset int64
+	)
+	for _, key := range s.sortedKeys() {
+		entry := s.entries[key]
+		record := io.NewSectionReader(s.dataFile, entry.offset, entry.size)
+		if _, err = io.CopyN(data, record, entry.size); err != nil {
+			return err
+		}
+		if _, err = index.Write(encodeSegmentIndexEntry(SegmentPut, key, entry.round, offset, entry.size)); err != nil {
+			return err
+		}
+		offset += entry.size
+	}
+	if err = data.Flush(); err != nil {
+		return err
+	}
+	if err = index.Flush(); err != nil {
+		return err
+	}
+	if err = dataFile.Sync(); err != nil {
+		return err
+	}
+	return indexFile.Sync()
+}
+
+// Sync - flush the segment files to the disk
+func (s *Segment) Sync() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.dataFile.Sync(); err != nil {
+		return err
+	}
+	return s.indexFile.Sync()
+}
+
+// Close - close the segment
+func (s *Segment) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	err := s.indexFile.Close()
+	if derr := s.dataFile.Close(); err == nil {
+		err = derr
+	}
+	return err
+}
+
+// Delete - close and remove the segment files
+func (s *Segment) Delete() error {
+	s.Close()
+	if err := os.Remove(s.getIndexFileName()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Remove(s.getDataFileName())
+}
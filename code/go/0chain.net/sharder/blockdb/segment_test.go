@@ -0,0 +1,193 @@
+package blockdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestSegment(t *testing.T, records int) (*Segment, string) {
+	dir, err := ioutil.TempDir("", "segment")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	file := filepath.Join(dir, "000001")
+	s, err := OpenSegment(file)
+	require.NoError(t, err)
+	for i := 0; i < records; i++ {
+		require.NoError(t, s.Append(Key(fmt.Sprintf("key%d", i)), int64(i/2),
+			[]byte(fmt.Sprintf("data%d", i))))
+	}
+	return s, file
+}
+
+func TestSegment_AppendRemove(t *testing.T) {
+	s, file := makeTestSegment(t, 10)
+
+	data, round, err := s.Get("key3")
+	require.NoError(t, err)
+	assert.Equal(t, "data3", string(data))
+	assert.Equal(t, int64(1), round)
+	assert.ElementsMatch(t, []Key{"key2", "key3"}, s.GetRoundKeys(1))
+
+	require.NoError(t, s.Append("key3", 1, []byte("new")))
+	require.NoError(t, s.Remove("key2"))
+	assert.Equal(t, ErrKeyNotFound, s.Remove("key2"))
+	_, _, err = s.Get("key2")
+	assert.Equal(t, ErrKeyNotFound, err)
+	assert.Equal(t, []Key{"key3"}, s.GetRoundKeys(1))
+	garbage, _ := s.Garbage()
+	assert.True(t, garbage > 0)
+	require.NoError(t, s.Close())
+
+	// the index file has the replaced and the deleted keys
+	s, err = OpenSegment(file)
+	require.NoError(t, err)
+	assert.Equal(t, 0, s.Recovered())
+	data, _, err = s.Get("key3")
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	assert.Len(t, s.GetKeys(), 9)
+	assert.Equal(t, []int64{0, 1, 2, 3, 4}, s.GetRounds())
+
+	require.NoError(t, s.Compact())
+	garbage, size := s.Garbage()
+	assert.Equal(t, int64(0), garbage)
+	var iterated []Key
+	require.NoError(t, s.Iterate(context.Background(),
+		func(_ context.Context, key Key, _ int64, data []byte) error {
+			iterated = append(iterated, key)
+			return nil
+		}))
+	assert.Len(t, iterated, 9)
+	assert.Equal(t, Key("key3"), iterated[len(iterated)-1])
+	require.NoError(t, s.Close())
+
+	s, err = OpenSegment(file)
+	require.NoError(t, err)
+	defer s.Close()
+	_, reopened := s.Garbage()
+	assert.Equal(t, size, reopened)
+	data, _, err = s.Get("key9")
+	require.NoError(t, err)
+	assert.Equal(t, "data9", string(data))
+}
+
+func TestSegment_Recovery(t *testing.T) {
+	tests := []struct {
+		name          string
+		damage        func(t *testing.T, file string)
+		wantKeys      int
+		wantRecovered int
+	}{
+		{
+			name: "missing_index",
+			damage: func(t *testing.T, file string) {
+				require.NoError(t, os.Remove(file+".idx"))
+			},
+			wantKeys:      10,
+			wantRecovered: 10,
+		},
+		{
+			name: "partial_index_entry",
+			damage: func(t *testing.T, file string) {
+				fi, err := os.Stat(file + ".idx")
+				require.NoError(t, err)
+				require.NoError(t, os.Truncate(file+".idx", fi.Size()-3))
+			},
+			wantKeys:      10,
+			wantRecovered: 1,
+		},
+		{
+			name: "partial_record",
+			damage: func(t *testing.T, file string) {
+				fi, err := os.Stat(file + ".seg")
+				require.NoError(t, err)
+				require.NoError(t, os.Truncate(file+".seg", fi.Size()-3))
+			},
+			wantKeys: 9,
+		},
+		{
+			name: "corrupt_record",
+			damage: func(t *testing.T, file string) {
+				require.NoError(t, os.Remove(file+".idx"))
+				f, err := os.OpenFile(file+".seg", os.O_RDWR, 0644)
+				require.NoError(t, err)
+				fi, err := f.Stat()
+				require.NoError(t, err)
+				_, err = f.WriteAt([]byte{0xff}, fi.Size()-6)
+				require.NoError(t, err)
+				require.NoError(t, f.Close())
+			},
+			wantKeys:      9,
+			wantRecovered: 9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, file := makeTestSegment(t, 10)
+			require.NoError(t, s.Close())
+			tt.damage(t, file)
+
+			s, err := OpenSegment(file)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRecovered, s.Recovered())
+			assert.Len(t, s.GetKeys(), tt.wantKeys)
+			for i := 0; i < tt.wantKeys; i++ {
+				data, _, err := s.Get(Key(fmt.Sprintf("key%d", i)))
+				require.NoError(t, err)
+				assert.Equal(t, fmt.Sprintf("data%d", i), string(data))
+			}
+
+			// appended after the recovered records
+			require.NoError(t, s.Append("next", 5, []byte("next")))
+			require.NoError(t, s.Close())
+			s, err = OpenSegment(file)
+			require.NoError(t, err)
+			defer s.Close()
+			assert.Equal(t, 0, s.Recovered())
+			assert.Len(t, s.GetKeys(), tt.wantKeys+1)
+		})
+	}
+}
+
+func TestSegment_CompactFailure(t *testing.T) {
+	defer func() { renameFile = os.Rename }()
+	for _, failed := range []string{".seg", ".idx"} {
+		t.Run(failed, func(t *testing.T) {
+			s, file := makeTestSegment(t, 10)
+			require.NoError(t, s.Remove("key0"))
+			renameFile = func(from, to string) error {
+				if filepath.Ext(to) == failed {
+					return errors.New("rename failed")
+				}
+				return os.Rename(from, to)
+			}
+			require.Error(t, s.Compact())
+			renameFile = os.Rename
+
+			// loaded again from the files in place, and usable
+			assert.Len(t, s.GetKeys(), 9)
+			data, _, err := s.Get("key9")
+			require.NoError(t, err)
+			assert.Equal(t, "data9", string(data))
+			require.NoError(t, s.Append("next", 5, []byte("next")))
+			require.NoError(t, s.Compact())
+			require.NoError(t, s.Close())
+
+			_, err = os.Stat(file + ".compact.seg")
+			assert.True(t, os.IsNotExist(err))
+			s, err = OpenSegment(file)
+			require.NoError(t, err)
+			defer s.Close()
+			assert.Equal(t, 0, s.Recovered())
+			assert.Len(t, s.GetKeys(), 10)
+		})
+	}
+}
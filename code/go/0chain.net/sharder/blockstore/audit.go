@@ -0,0 +1,202 @@
+package blockstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/datastore"
+)
+
+// the checks of the audit of the stored blocks
+const (
+	AuditRoundSummary = "round_summary"
+	AuditBlockSummary = "block_summary"
+	AuditMissing      = "missing"
+	AuditDecode       = "decode"
+	AuditHash         = "hash"
+	AuditTxnHash      = "txn_hash"
+	AuditTxnRoot      = "txn_root"
+	AuditReceiptRoot  = "receipt_root"
+	AuditPrevHash     = "prev_hash"
+	AuditTxnSummary   = "txn_summary"
+)
+
+// AuditIssue - a failed check of a round
+type AuditIssue struct {
+	Round    int64  `json:"round"`
+	Hash     string `json:"hash,omitempty"`
+	Check    string `json:"check"`
+	Error    string `json:"error"`
+	Repaired bool   `json:"repaired"`
+}
+
+// AuditReport - the failed checks of the rounds audited
+type AuditReport struct {
+	FromRound  int64         `json:"from_round"`
+	ToRound    int64         `json:"to_round"`
+	Rounds     int64         `json:"rounds"`
+	Blocks     int64         `json:"blocks"`
+	Issues     []*AuditIssue `json:"issues"`
+	Repaired   int64         `json:"repaired"`
+	Unrepaired int64         `json:"unrepaired"`
+	StartedAt  time.Time     `json:"started_at"`
+	Duration   string        `json:"duration"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// NewAuditReport - a report of the audit of the rounds starting now
+func NewAuditReport(from, to int64) *AuditReport {
+	return &AuditReport{
+		FromRound: from,
+		ToRound:   to,
+		Issues:    make([]*AuditIssue, 0),
+		StartedAt: time.Now().UTC(),
+	}
+}
+
+// Add - add the failed check
+func (ar *AuditReport) Add(issue *AuditIssue) {
+	ar.Issues = append(ar.Issues, issue)
+}
+
+// Finish - count the repaired issues and set the duration and the error
+// stopping the audit, if any
+func (ar *AuditReport) Finish(err error) *AuditReport {
+	ar.Repaired, ar.Unrepaired = 0, 0
+	for _, issue := range ar.Issues {
+		if issue.Repaired {
+			ar.Repaired++
+		} else {
+			ar.Unrepaired++
+		}
+	}
+	ar.Duration = time.Since(ar.StartedAt).String()
+	if err != nil {
+		ar.Error = err.Error()
+	}
+	return ar
+}
+
+// AuditBlock - check the hash of the block and of its transactions, and
+// the Merkle roots of the transactions and the receipts against the block
+// summary when given
+func AuditBlock(b *block.Block, bs *block.BlockSummary) []*AuditIssue {
+	var issues []*AuditIssue
+	add := func(check, format string, args ...interface{}) {
+		issues = append(issues, &AuditIssue{Round: b.Round, Hash: b.Hash, Check: check,
+			Error: fmt.Sprintf(format, args...)})
+	}
+
+	if hash := b.ComputeHash(); hash != b.Hash {
+		add(AuditHash, "computed hash %v", hash)
+	}
+	var bad int
+	for _, txn := range b.Txns {
+		if txn.Hash != txn.ComputeHash() {
+			bad++
+		}
+	}
+	if bad > 0 {
+		add(AuditTxnHash, "%d of %d transactions with a wrong hash", bad, len(b.Txns))
+	}
+	if bs == nil {
+		return issues
+	}
+	if root := b.GetMerkleTree().GetRoot(); root != bs.MerkleTreeRoot {
+		add(AuditTxnRoot, "transactions root %v, block summary root %v", root, bs.MerkleTreeRoot)
+	}
+	if root := b.GetReceiptsMerkleTree().GetRoot(); root != bs.ReceiptMerkleTreeRoot {
+		add(AuditReceiptRoot, "receipts root %v, block summary root %v", root, bs.ReceiptMerkleTreeRoot)
+	}
+	return issues
+}
+
+// AuditStoredBlock - the hash, the round and the encoded data of a block
+// of a block store
+type AuditStoredBlock func(hash string, round int64, data []byte) error
+
+// AuditBlockStore - audit the blocks of a block store without the round
+// and the block summaries: that each block decodes and its hashes check, and
+// that the block of a round follows a block of the previous round, when it
+// is stored; with gaps, that every round between the first and the last
+// has a block too, as for a store of every block of the chain
+func AuditBlockStore(ctx context.Context, iterate func(AuditStoredBlock) error, gaps bool) *AuditReport {
+	var (
+		report = NewAuditReport(0, 0)
+		rounds = make(map[int64][]auditedBlock)
+	)
+	err := iterate(func(hash string, round int64, data []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b := &block.Block{}
+		data, err := DecompressBlock(data)
+		if err == nil {
+			err = datastore.ReadJSON(bytes.NewReader(data), b)
+		}
+		if err != nil {
+			report.Add(&AuditIssue{Round: round, Hash: hash, Check: AuditDecode, Error: err.Error()})
+			return nil
+		}
+		if b.MagicBlock != nil && b.MagicBlock.Hash == hash && b.Hash != hash {
+			return nil // the copy of the block starting a magic block
+		}
+		report.Blocks++
+		if hash != b.Hash {
+			report.Add(&AuditIssue{Round: b.Round, Hash: hash, Check: AuditHash,
+				Error: fmt.Sprintf("stored as %v", hash)})
+		}
+		for _, issue := range AuditBlock(b, nil) {
+			report.Add(issue)
+		}
+		rounds[b.Round] = append(rounds[b.Round], auditedBlock{hash: b.Hash, prevHash: b.PrevHash})
+		return nil
+	})
+	if len(rounds) == 0 {
+		return report.Finish(err)
+	}
+
+	nums := make([]int64, 0, len(rounds))
+	for r := range rounds {
+		nums = append(nums, r)
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+	report.FromRound, report.ToRound = nums[0], nums[len(nums)-1]
+	report.Rounds = report.ToRound - report.FromRound + 1
+	for r := report.FromRound + 1; r <= report.ToRound; r++ {
+		prev, ok := rounds[r-1]
+		if !ok {
+			if gaps {
+				report.Add(&AuditIssue{Round: r - 1, Check: AuditMissing, Error: "no block"})
+			}
+			continue // a round the store doesn't keep
+		}
+		for _, sb := range rounds[r] {
+			if !followsAny(sb.prevHash, prev) {
+				report.Add(&AuditIssue{Round: r, Hash: sb.hash, Check: AuditPrevHash,
+					Error: fmt.Sprintf("no block %v of the previous round", sb.prevHash)})
+			}
+		}
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return report.Issues[i].Round < report.Issues[j].Round
+	})
+	return report.Finish(err)
+}
+
+type auditedBlock struct {
+	hash, prevHash string
+}
+
+func followsAny(prevHash string, prev []auditedBlock) bool {
+	for _, sb := range prev {
+		if sb.hash == prevHash {
+			return true
+		}
+	}
+	return false
+}
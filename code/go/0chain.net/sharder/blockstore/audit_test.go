@@ -0,0 +1,105 @@
+package blockstore
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/encryption"
+)
+
+// makeTestChainBlocks - hashed blocks of the rounds, each following the
+// block of the previous round
+func makeTestChainBlocks(n int) []*block.Block {
+	var (
+		blocks   = make([]*block.Block, 0, n)
+		prevHash = encryption.Hash("genesis")
+	)
+	for r := int64(1); r <= int64(n); r++ {
+		b := block.NewBlock("", r)
+		b.PrevHash = prevHash
+		b.HashBlock()
+		prevHash = b.Hash
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+func TestAuditBlock(t *testing.T) {
+	b := makeTestChainBlocks(1)[0]
+	bs := b.GetSummary()
+	assert.Empty(t, AuditBlock(b, bs))
+
+	bs.MerkleTreeRoot = encryption.Hash("root")
+	issues := AuditBlock(b, bs)
+	require.Len(t, issues, 1)
+	assert.Equal(t, AuditTxnRoot, issues[0].Check)
+
+	b.PrevHash = encryption.Hash("other")
+	issues = AuditBlock(b, nil)
+	require.Len(t, issues, 1)
+	assert.Equal(t, AuditHash, issues[0].Check)
+}
+
+func TestAuditBlockStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blocks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	fbs := NewFSBlockStore(dir, &minioClientMock{})
+
+	blocks := makeTestChainBlocks(10)
+	blocks[6].PrevHash = encryption.Hash("fork") // round 7
+	blocks[6].HashBlock()
+	for i, b := range blocks {
+		if b.Round == 4 {
+			continue // a gap
+		}
+		require.NoError(t, fbs.Write(b), i)
+	}
+	// round 9 tampered and round 10 corrupt
+	blocks[8].MinerID = encryption.Hash("miner")
+	require.NoError(t, fbs.Write(blocks[8]))
+	require.NoError(t, ioutil.WriteFile(fbs.getFileName(blocks[9].Hash, blocks[9].Round),
+		[]byte("corrupt"), 0644))
+
+	audit := func(gaps bool) *AuditReport {
+		return AuditBlockStore(context.Background(), func(handler AuditStoredBlock) error {
+			return IterateFSBlocks(context.Background(), dir, func(hash string, data []byte) error {
+				return handler(hash, 0, data)
+			})
+		}, gaps)
+	}
+	report := audit(true)
+	assert.Empty(t, report.Error)
+	assert.Equal(t, int64(8), report.Blocks)
+	assert.Equal(t, int64(1), report.FromRound)
+	assert.Equal(t, int64(9), report.ToRound)
+
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.Check)
+		assert.False(t, issue.Repaired)
+	}
+	// the corrupt block has no round, the gap is round 4, the fork round 7
+	// and the block of round 8 follows the replaced one
+	assert.Equal(t, []string{AuditDecode, AuditMissing, AuditPrevHash, AuditPrevHash, AuditHash}, got)
+	assert.Equal(t, int64(4), report.Issues[1].Round)
+	assert.Equal(t, int64(7), report.Issues[2].Round)
+	assert.Equal(t, int64(8), report.Issues[3].Round)
+	assert.Equal(t, int64(9), report.Issues[4].Round)
+	assert.Equal(t, int64(5), report.Unrepaired)
+
+	// without the gaps, the round 4 not kept by the store isn't missing
+	report = audit(false)
+	got = got[:0]
+	for _, issue := range report.Issues {
+		got = append(got, issue.Check)
+	}
+	assert.Equal(t, []string{AuditDecode, AuditPrevHash, AuditPrevHash, AuditHash}, got)
+	assert.Equal(t, int64(4), report.Unrepaired)
+}
@@ -0,0 +1,185 @@
+package blockstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+)
+
+// the codecs of the stored blocks
+const (
+	CodecZLib     byte = 1
+	CodecZStd     byte = 2
+	CodecZStdDict byte = 3
+)
+
+// the names of the codecs of the stored blocks, as the n2n encodings
+var blockCodecNames = map[byte]string{
+	CodecZLib:     "zlib",
+	CodecZStd:     "zstd",
+	CodecZStdDict: "zstddict",
+}
+
+// blockCodecMagic - the start of the header of a stored block, a block
+// without it is a zlib stream, as written before the codecs
+var blockCodecMagic = []byte{0xb1, 0x0c}
+
+var blockCodecs = struct {
+	sync.RWMutex
+	codec  byte
+	dictID uint32
+	dicts  map[uint32]*common.ZStdDictCompDe
+}{codec: CodecZLib, dicts: make(map[uint32]*common.ZStdDictCompDe)}
+
+var (
+	zlibCompDe = common.NewZLibCompDe()
+	zstdCompDe = common.NewZStdCompDe()
+)
+
+// GetBlockCodec - the codec the blocks are written with
+func GetBlockCodec() string {
+	blockCodecs.RLock()
+	defer blockCodecs.RUnlock()
+	return blockCodecNames[blockCodecs.codec]
+}
+
+// SetBlockCodec - write the blocks with the codec of the name, zlib, zstd
+// or zstddict with the dictionary added last
+func SetBlockCodec(name string) error {
+	blockCodecs.Lock()
+	defer blockCodecs.Unlock()
+	for codec, n := range blockCodecNames {
+		if n != name {
+			continue
+		}
+		if codec == CodecZStdDict && blockCodecs.dicts[blockCodecs.dictID] == nil {
+			return common.NewError("block_codec", "no block dictionary")
+		}
+		blockCodecs.codec = codec
+		return nil
+	}
+	return common.NewErrorf("block_codec", "unknown block codec: %v", name)
+}
+
+// AddBlockDictionary - add a trained zstd dictionary to read the blocks
+// written with it, the blocks are written with the dictionary added last,
+// the id of the dictionary
+func AddBlockDictionary(dict []byte) (uint32, error) {
+	id := common.ZStdDictID(dict)
+	if id == 0 {
+		return 0, common.NewError("block_codec", "not a trained zstd dictionary")
+	}
+	cd, err := common.NewZStdCompDeWithDict(dict)
+	if err != nil {
+		return 0, err
+	}
+	blockCodecs.Lock()
+	defer blockCodecs.Unlock()
+	blockCodecs.dicts[id] = cd
+	blockCodecs.dictID = id
+	return id, nil
+}
+
+// CompressBlock - the header of the codec of the blocks followed by the
+// compressed data
+func CompressBlock(data []byte) []byte {
+	blockCodecs.RLock()
+	codec, dict := blockCodecs.codec, blockCodecs.dicts[blockCodecs.dictID]
+	blockCodecs.RUnlock()
+
+	header := append([]byte(nil), blockCodecMagic...)
+	header = append(header, codec)
+	switch codec {
+	case CodecZStd:
+		return append(header, zstdCompDe.Compress(data)...)
+	case CodecZStdDict:
+		header = append(header, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(header[len(header)-4:], dict.DictID())
+		return append(header, dict.Compress(data)...)
+	default:
+		return append(header, zlibCompDe.Compress(data)...)
+	}
+}
+
+// DecompressBlock - the data of the stored block, by the codec of its header
+func DecompressBlock(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, blockCodecMagic) {
+		return zlibCompDe.Decompress(data)
+	}
+	data = data[len(blockCodecMagic):]
+	if len(data) == 0 {
+		return nil, common.NewError("block_codec", "no codec")
+	}
+	codec, data := data[0], data[1:]
+	switch codec {
+	case CodecZLib:
+		return zlibCompDe.Decompress(data)
+	case CodecZStd:
+		return zstdCompDe.Decompress(data)
+	case CodecZStdDict:
+		if len(data) < 4 {
+			return nil, common.NewError("block_codec", "no dictionary id")
+		}
+		id := binary.LittleEndian.Uint32(data)
+		blockCodecs.RLock()
+		dict := blockCodecs.dicts[id]
+		blockCodecs.RUnlock()
+		if dict == nil {
+			return nil, common.NewErrorf("block_codec", "unknown block dictionary: %v", id)
+		}
+		return dict.Decompress(data[4:])
+	default:
+		return nil, common.NewErrorf("block_codec", "unknown block codec: %v", codec)
+	}
+}
+
+// getBlockCodec - the name of the codec of the stored block
+func getBlockCodec(data []byte) string {
+	if !bytes.HasPrefix(data, blockCodecMagic) || len(data) == len(blockCodecMagic) {
+		return blockCodecNames[CodecZLib]
+	}
+	return blockCodecNames[data[len(blockCodecMagic)]]
+}
+
+func encodeBlock(v datastore.Entity) ([]byte, error) {
+	return CompressBlock(datastore.ToJSON(v).Bytes()), nil
+}
+
+func decodeBlock(blockMetadataProvider datastore.EntityMetadata, data []byte) (*block.Block, error) {
+	data, err := DecompressBlock(data)
+	if err != nil {
+		return nil, err
+	}
+	b := blockMetadataProvider.Instance().(*block.Block)
+	if err = datastore.ReadJSON(bytes.NewReader(data), b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// getEncodedBlockInfo - the round of the encoded block and the hash of its
+// magic block when the block starts it
+func getEncodedBlockInfo(data []byte) (round int64, mbHash string, err error) {
+	if data, err = DecompressBlock(data); err != nil {
+		return 0, "", err
+	}
+	var b struct {
+		Round      int64 `json:"round"`
+		MagicBlock *struct {
+			Hash          string `json:"hash"`
+			StartingRound int64  `json:"starting_round"`
+		} `json:"magic_block"`
+	}
+	if err = json.Unmarshal(data, &b); err != nil {
+		return 0, "", err
+	}
+	if b.MagicBlock != nil && b.MagicBlock.StartingRound == b.Round {
+		mbHash = b.MagicBlock.Hash
+	}
+	return b.Round, mbHash, nil
+}
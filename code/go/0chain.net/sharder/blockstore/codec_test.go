@@ -0,0 +1,91 @@
+package blockstore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+func makeTestCodecBlocks(n int) []*block.Block {
+	blocks := make([]*block.Block, 0, n)
+	for r := int64(1); r <= int64(n); r++ {
+		b := makeTestRoundBlock(r)
+		b.PrevHash = encryption.Hash(fmt.Sprintf("block %d", r-1))
+		b.MinerID = encryption.Hash(fmt.Sprintf("miner %d", r%7))
+		b.ClientStateHash = []byte(encryption.Hash(fmt.Sprintf("state %d", r)))
+		b.CreationDate = common.Timestamp(1600000000 + r)
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+func setTestBlockCodec(t *testing.T, codec string) {
+	require.NoError(t, SetBlockCodec(codec))
+	t.Cleanup(func() { require.NoError(t, SetBlockCodec("zlib")) })
+}
+
+func TestBlockCodecs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blocks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	fbs := NewFSBlockStore(dir, &minioClientMock{})
+
+	var (
+		blocks  = makeTestCodecBlocks(400)
+		encoded = make([][]byte, 0, len(blocks))
+	)
+	for _, b := range blocks {
+		data, err := encodeBlock(b)
+		require.NoError(t, err)
+		encoded = append(encoded, data)
+	}
+	dict, err := TrainBlockDictionary(encoded, 4096)
+	require.NoError(t, err)
+	id, err := AddBlockDictionary(dict)
+	require.NoError(t, err)
+	assert.Equal(t, common.ZStdDictID(dict), id)
+
+	// the blocks written before the codecs, and with each codec
+	legacy := blocks[0]
+	require.NoError(t, os.MkdirAll(filepath.Dir(fbs.getFileName(legacy.Hash, legacy.Round)), 0755))
+	require.NoError(t, ioutil.WriteFile(fbs.getFileName(legacy.Hash, legacy.Round),
+		zlibCompDe.Compress([]byte(fmt.Sprintf(`{"hash":%q,"round":%d}`, legacy.Hash, legacy.Round))), 0644))
+	for i, codec := range []string{"zlib", "zstd", "zstddict"} {
+		setTestBlockCodec(t, codec)
+		assert.Equal(t, codec, GetBlockCodec())
+		b := blocks[i+1]
+		require.NoError(t, fbs.Write(b))
+		data, err := ioutil.ReadFile(fbs.getFileName(b.Hash, b.Round))
+		require.NoError(t, err)
+		assert.Equal(t, codec, getBlockCodec(data))
+	}
+	for _, b := range blocks[:4] {
+		got, err := fbs.Read(b.Hash, b.Round)
+		require.NoError(t, err, b.Round)
+		assert.Equal(t, b.Hash, got.Hash)
+		assert.Equal(t, b.Round, got.Round)
+	}
+
+	require.Error(t, SetBlockCodec("lz4"))
+	data := CompressBlock([]byte("{}")) // with the dictionary
+	data[len(blockCodecMagic)+1] ^= 0xff
+	_, err = DecompressBlock(data)
+	assert.Error(t, err)
+
+	report, err := CompressionReport(encoded[:10], dict)
+	require.NoError(t, err)
+	require.Len(t, report, 3)
+	for _, cr := range report {
+		assert.True(t, cr.Ratio > 1, cr.Codec)
+	}
+	assert.True(t, report[2].Compressed < report[1].Compressed, "the dictionary helps")
+}
@@ -0,0 +1,73 @@
+package blockstore
+
+import (
+	"github.com/valyala/gozstd"
+
+	"0chain.net/core/common"
+)
+
+// DefaultDictionarySize - the size of a trained block dictionary, as
+// recommended by zstd
+const DefaultDictionarySize = 112640
+
+// CodecRatio - the compression of the blocks with a codec
+type CodecRatio struct {
+	Codec      string  `json:"codec"`
+	Size       int64   `json:"size"`
+	Compressed int64   `json:"compressed"`
+	Ratio      float64 `json:"ratio"`
+}
+
+// TrainBlockDictionary - train a zstd dictionary of the size given on the
+// stored blocks
+func TrainBlockDictionary(blocks [][]byte, size int) ([]byte, error) {
+	if size <= 0 {
+		size = DefaultDictionarySize
+	}
+	samples := make([][]byte, 0, len(blocks))
+	for _, data := range blocks {
+		sample, err := DecompressBlock(data)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	dict := gozstd.BuildDict(samples, size)
+	if common.ZStdDictID(dict) == 0 {
+		return nil, common.NewError("train_dictionary", "not enough blocks to train a dictionary")
+	}
+	return dict, nil
+}
+
+// CompressionReport - the compression of the stored blocks with zlib, zstd
+// and zstd with the dictionary, when given
+func CompressionReport(blocks [][]byte, dict []byte) ([]*CodecRatio, error) {
+	codecs := []common.CompDe{zlibCompDe, zstdCompDe}
+	if len(dict) > 0 {
+		cd, err := common.NewZStdCompDeWithDict(dict)
+		if err != nil {
+			return nil, err
+		}
+		codecs = append(codecs, cd)
+	}
+	report := make([]*CodecRatio, 0, len(codecs))
+	for _, cd := range codecs {
+		report = append(report, &CodecRatio{Codec: cd.Encoding()})
+	}
+	for _, data := range blocks {
+		data, err := DecompressBlock(data)
+		if err != nil {
+			return nil, err
+		}
+		for i, cd := range codecs {
+			report[i].Size += int64(len(data))
+			report[i].Compressed += int64(len(cd.Compress(data)))
+		}
+	}
+	for _, cr := range report {
+		if cr.Compressed > 0 {
+			cr.Ratio = float64(cr.Size) / float64(cr.Compressed)
+		}
+	}
+	return report, nil
+}
@@ -1,8 +1,7 @@
 package blockstore
 
 import (
-	"bufio"
-	"compress/zlib"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -74,28 +73,11 @@ func (fbs *FSBlockStore) write(hash string, round int64, v datastore.Entity) err
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
-	f, err := os.OpenFile(fn, os.O_RDWR|os.O_CREATE, 0644)
+	data, err := encodeBlock(v)
 	if err != nil {
 		return err
 	}
-	bf := bufio.NewWriterSize(f, 64*1024)
-	w, err := zlib.NewWriterLevel(bf, zlib.BestCompression)
-	if err != nil {
-		return err
-	}
-	if err := datastore.WriteJSON(w, v); err != nil {
-		return err
-	}
-	if err = w.Close(); err != nil {
-		return err
-	}
-	if err = bf.Flush(); err != nil {
-		return err
-	}
-	if err = f.Close(); err != nil {
-		return err
-	}
-	return nil
+	return ioutil.WriteFile(fn, data, 0644)
 }
 
 // Write - write the block to the file system
@@ -177,7 +159,7 @@ func (fbs *FSBlockStore) read(hash string, round int64) (*block.Block, error) {
 		return nil, encryption.ErrInvalidHash
 	}
 	fileName := fbs.getFileName(hash, round)
-	f, err := os.Open(fileName)
+	data, err := ioutil.ReadFile(fileName)
 	if err != nil {
 		if os.IsNotExist(err) {
 			if viper.GetBool("minio.enabled") {
@@ -186,7 +168,7 @@ func (fbs *FSBlockStore) read(hash string, round int64) (*block.Block, error) {
 					return nil, err
 				}
 			}
-			f, err = os.Open(fileName)
+			data, err = ioutil.ReadFile(fileName)
 			if err != nil {
 				return nil, err
 
@@ -195,18 +177,7 @@ func (fbs *FSBlockStore) read(hash string, round int64) (*block.Block, error) {
 			return nil, err
 		}
 	}
-	defer f.Close()
-	r, err := zlib.NewReader(f)
-	if err != nil {
-		return nil, err
-	}
-	defer r.Close()
-	b := fbs.blockMetadataProvider.Instance().(*block.Block)
-	err = datastore.ReadJSON(r, b)
-	if err != nil {
-		return nil, err
-	}
-	return b, nil
+	return decodeBlock(fbs.blockMetadataProvider, data)
 }
 
 // Delete - delete from the hash of the block
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"0chain.net/core/common"
+	"0chain.net/core/logging"
+	"0chain.net/sharder/blockstore"
+)
+
+func main() {
+	from := flag.String("from", "", "the directory of the file system block store to copy, as data/blocks")
+	to := flag.String("to", "", "the directory of the new segment block store, as data/segments")
+	segmentRounds := flag.Int64("segment_rounds", blockstore.DefaultSegmentRounds,
+		"the number of rounds of a segment")
+	compact := flag.Float64("compact", 0,
+		"compact the segments of the -to store having at least the given ratio of garbage instead")
+	train := flag.String("train", "",
+		"train a zstd dictionary on the blocks of the -from store, file system or segment, to the given file instead")
+	dictSize := flag.Int("dict_size", blockstore.DefaultDictionarySize, "the size of the trained dictionary")
+	samples := flag.Int("samples", 10000, "the most blocks to train the dictionary on")
+	audit := flag.String("audit", "",
+		"audit the blocks of the -from store, file system or segment, to the given JSON report file, - for stdout, instead")
+	auditGaps := flag.Bool("audit_gaps", false,
+		"audit that every round between the first and the last has a block, for a store of every block")
+	dictionaries := flag.String("dictionaries", "", "the comma separated zstd dictionary files of the blocks")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"Copy the blocks of a file system block store to a new segment block store,\n"+
+				"compact a segment block store, train a block dictionary or audit a block store.\n\nUsage of %s:\n",
+			os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	logging.Logger = zap.NewNop()
+
+	if *dictionaries != "" {
+		for _, file := range strings.Split(*dictionaries, ",") {
+			dict, err := ioutil.ReadFile(file)
+			if err != nil {
+				fatal("read dictionary: %v", err)
+			}
+			if _, err = blockstore.AddBlockDictionary(dict); err != nil {
+				fatal("add dictionary %v: %v", file, err)
+			}
+		}
+	}
+	if *audit != "" {
+		if *from == "" {
+			flag.Usage()
+			os.Exit(2)
+		}
+		auditBlockStore(*from, *audit, *auditGaps)
+		return
+	}
+	if *train != "" {
+		if *from == "" {
+			flag.Usage()
+			os.Exit(2)
+		}
+		trainDictionary(*from, *train, *dictSize, *samples)
+		return
+	}
+	if *to == "" || (*from == "" && *compact <= 0) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	sbs := blockstore.NewSegmentBlockStore(*to, *segmentRounds)
+	defer sbs.Close()
+
+	if *compact > 0 {
+		count, err := sbs.Compact(context.Background(), *compact)
+		if err != nil {
+			fatal("compact after %d segments: %v", count, err)
+		}
+		fmt.Printf("compacted %d segments of %v\n", count, *to)
+		return
+	}
+
+	if _, err := os.Stat(*from); err != nil {
+		fatal("source block store: %v", err)
+	}
+	count, err := blockstore.MigrateFSBlockStore(context.Background(), *from, sbs)
+	if err != nil {
+		fatal("copy after %d blocks: %v", count, err)
+	}
+	fmt.Printf("copied %d blocks from %v to %v\n", count, *from, *to)
+}
+
+// trainDictionary - train the dictionary on the blocks of the store, every
+// tenth block is held out to report the compression ratios with it
+func trainDictionary(from, out string, size, samples int) {
+	var (
+		ctx            = context.Background()
+		train, holdout [][]byte
+		errEnough      = errors.New("enough blocks")
+	)
+	add := func(data []byte) error {
+		if len(train)+len(holdout) >= samples {
+			return errEnough
+		}
+		if (len(train)+len(holdout))%10 == 9 {
+			holdout = append(holdout, data)
+		} else {
+			train = append(train, data)
+		}
+		return nil
+	}
+
+	err := iterateBlocks(ctx, from, func(_ string, _ int64, data []byte) error {
+		return add(data)
+	})
+	if err != nil && err != errEnough {
+		fatal("read blocks: %v", err)
+	}
+
+	dict, err := blockstore.TrainBlockDictionary(train, size)
+	if err != nil {
+		fatal("train dictionary on %d blocks: %v", len(train), err)
+	}
+	if err = ioutil.WriteFile(out, dict, 0644); err != nil {
+		fatal("write dictionary: %v", err)
+	}
+	fmt.Printf("trained dictionary %d of %d bytes on %d blocks to %v\n",
+		common.ZStdDictID(dict), len(dict), len(train), out)
+
+	if len(holdout) == 0 {
+		holdout = train
+	}
+	report, err := blockstore.CompressionReport(holdout, dict)
+	if err != nil {
+		fatal("compression report: %v", err)
+	}
+	fmt.Printf("compression of %d blocks:\n", len(holdout))
+	for _, cr := range report {
+		fmt.Printf("  %-8s %12d -> %12d  ratio %.2f\n", cr.Codec, cr.Size, cr.Compressed, cr.Ratio)
+	}
+}
+
+// iterateBlocks - call the handler for each block of the file system or
+// segment block store, the round of a block of a file system store is 0
+func iterateBlocks(ctx context.Context, from string, handler blockstore.AuditStoredBlock) error {
+	segments, err := filepath.Glob(filepath.Join(from, "*.seg"))
+	if err != nil {
+		return err
+	}
+	if len(segments) > 0 {
+		sbs := blockstore.NewSegmentBlockStore(from, blockstore.DefaultSegmentRounds)
+		defer sbs.Close()
+		return sbs.Iterate(ctx, func(_ context.Context, hash string, round int64, data []byte) error {
+			return handler(hash, round, data)
+		})
+	}
+	if _, err = os.Stat(from); err != nil {
+		return err
+	}
+	return blockstore.IterateFSBlocks(ctx, from, func(hash string, data []byte) error {
+		return handler(hash, 0, data)
+	})
+}
+
+// auditBlockStore - audit the blocks of the store, and the gaps between
+// them when asked, and write the report, exit with 1 when the audit fails
+func auditBlockStore(from, out string, gaps bool) {
+	ctx := context.Background()
+	report := blockstore.AuditBlockStore(ctx, func(handler blockstore.AuditStoredBlock) error {
+		return iterateBlocks(ctx, from, handler)
+	}, gaps)
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fatal("audit report: %v", err)
+	}
+	if out == "-" {
+		fmt.Println(string(data))
+	} else if err = ioutil.WriteFile(out, data, 0644); err != nil {
+		fatal("write audit report: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "audited %d blocks of rounds %d to %d: %d issues\n",
+		report.Blocks, report.FromRound, report.ToRound, len(report.Issues))
+	if report.Error != "" || len(report.Issues) > 0 {
+		os.Exit(1)
+	}
+}
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
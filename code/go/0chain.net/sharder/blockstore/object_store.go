@@ -0,0 +1,147 @@
+package blockstore
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sync"
+
+	"github.com/minio/minio-go"
+
+	"0chain.net/core/common"
+)
+
+// ObjectStore - an object storage keeping the blocks of the cold tier, by
+// block hash.
+type ObjectStore interface {
+	PutObject(name string, data []byte) error
+	GetObject(name string) ([]byte, error)
+	RemoveObject(name string) error
+	// StatObject - the size of the object, ErrObjectNotFound if there's none
+	StatObject(name string) (int64, error)
+}
+
+// ErrObjectNotFound - no object of the name
+var ErrObjectNotFound = common.NewError("object_not_found", "object not found")
+
+type minioObjectStore struct {
+	client     *minio.Client
+	bucketName string
+}
+
+// NewMinioObjectStore - an object store on a bucket of a MinIO or S3 server.
+func NewMinioObjectStore(config MinioConfiguration) (ObjectStore, error) {
+	mc, err := minio.New(
+		config.StorageServiceURL,
+		config.AccessKeyID,
+		config.SecretAccessKey,
+		config.Secure,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &minioObjectStore{client: mc, bucketName: config.BucketName}, nil
+}
+
+// PutObject is a part of ObjectStore interface implementation.
+func (mos *minioObjectStore) PutObject(name string, data []byte) error {
+	_, err := mos.client.PutObject(mos.bucketName, name, bytes.NewReader(data),
+		int64(len(data)), minio.PutObjectOptions{})
+	return err
+}
+
+// GetObject is a part of ObjectStore interface implementation.
+func (mos *minioObjectStore) GetObject(name string) ([]byte, error) {
+	obj, err := mos.client.GetObject(mos.bucketName, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	data, err := ioutil.ReadAll(obj)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// RemoveObject is a part of ObjectStore interface implementation.
+func (mos *minioObjectStore) RemoveObject(name string) error {
+	return mos.client.RemoveObject(mos.bucketName, name)
+}
+
+// StatObject is a part of ObjectStore interface implementation.
+func (mos *minioObjectStore) StatObject(name string) (int64, error) {
+	info, err := mos.client.StatObject(mos.bucketName, name, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return 0, ErrObjectNotFound
+		}
+		return 0, err
+	}
+	return info.Size, nil
+}
+
+// MemoryObjectStore - an in-process object store standing for the object
+// storage in tests and local setups.
+type MemoryObjectStore struct {
+	mutex   sync.RWMutex
+	objects map[string][]byte
+}
+
+var (
+	// Make sure minioObjectStore and MemoryObjectStore implement ObjectStore.
+	_ ObjectStore = (*minioObjectStore)(nil)
+	_ ObjectStore = (*MemoryObjectStore)(nil)
+)
+
+// NewMemoryObjectStore - return a new empty in-process object store.
+func NewMemoryObjectStore() *MemoryObjectStore {
+	return &MemoryObjectStore{objects: make(map[string][]byte)}
+}
+
+// PutObject is a part of ObjectStore interface implementation.
+func (mos *MemoryObjectStore) PutObject(name string, data []byte) error {
+	mos.mutex.Lock()
+	defer mos.mutex.Unlock()
+	mos.objects[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// GetObject is a part of ObjectStore interface implementation.
+func (mos *MemoryObjectStore) GetObject(name string) ([]byte, error) {
+	mos.mutex.RLock()
+	defer mos.mutex.RUnlock()
+	data, ok := mos.objects[name]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// RemoveObject is a part of ObjectStore interface implementation.
+func (mos *MemoryObjectStore) RemoveObject(name string) error {
+	mos.mutex.Lock()
+	defer mos.mutex.Unlock()
+	delete(mos.objects, name)
+	return nil
+}
+
+// StatObject is a part of ObjectStore interface implementation.
+func (mos *MemoryObjectStore) StatObject(name string) (int64, error) {
+	mos.mutex.RLock()
+	defer mos.mutex.RUnlock()
+	data, ok := mos.objects[name]
+	if !ok {
+		return 0, ErrObjectNotFound
+	}
+	return int64(len(data)), nil
+}
+
+// Len - the number of the objects stored
+func (mos *MemoryObjectStore) Len() int {
+	mos.mutex.RLock()
+	defer mos.mutex.RUnlock()
+	return len(mos.objects)
+}
@@ -0,0 +1,84 @@
+package blockstore
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"0chain.net/core/common"
+)
+
+// getFSBlockHash - the hash of the block of a file of the FSBlockStore, the
+// file path is root/round range/hash[0:3]/hash[3:6]/hash[6:9]/hash[9:].dat.zlib
+func getFSBlockHash(root, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || !strings.HasSuffix(rel, fileExt) {
+		return "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(rel, fileExt), string(os.PathSeparator))
+	if len(parts) != 5 {
+		return "", false
+	}
+	hash := strings.Join(parts[1:], "")
+	if len(hash) != 64 {
+		return "", false
+	}
+	return hash, true
+}
+
+// FSBlockHandler - handle the hash and the stored data of a block
+type FSBlockHandler func(hash string, data []byte) error
+
+// IterateFSBlocks - call the handler for each block of the FSBlockStore in
+// the root directory
+func IterateFSBlocks(ctx context.Context, root string, handler FSBlockHandler) error {
+	return filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		hash, ok := getFSBlockHash(root, path)
+		if !ok {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return handler(hash, data)
+	})
+}
+
+// MigrateFSBlockStore - copy the blocks of the FSBlockStore in the root
+// directory to the segment block store, the number of the blocks copied
+func MigrateFSBlockStore(ctx context.Context, root string, sbs *SegmentBlockStore) (int64, error) {
+	ids, err := sbs.GetSegmentIDs()
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) > 0 {
+		return 0, common.NewErrorf("segment_migrate", "the segment block store %v is not empty",
+			sbs.RootDirectory)
+	}
+
+	var count int64
+	err = IterateFSBlocks(ctx, root, func(hash string, data []byte) error {
+		round, _, err := getEncodedBlockInfo(data)
+		if err != nil {
+			return common.NewErrorf("segment_migrate", "decode %v: %v", hash, err)
+		}
+		if err = sbs.WriteRaw(hash, round, data); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
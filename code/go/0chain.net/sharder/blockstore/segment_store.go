@@ -0,0 +1,386 @@
+package blockstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	. "0chain.net/core/logging"
+	"0chain.net/sharder/blockdb"
+)
+
+const (
+	// DefaultSegmentRounds - the default number of rounds of a segment
+	DefaultSegmentRounds = 10000
+	// the default max number of segments kept open
+	defaultMaxOpenSegments = 16
+)
+
+type openSegment struct {
+	*blockdb.Segment
+	id   int64
+	refs int
+	used int64
+}
+
+// SegmentBlockStore - a block store appending the blocks to a segment file per
+// round range, indexed by block hash and by round.
+type SegmentBlockStore struct {
+	RootDirectory         string
+	SegmentRounds         int64
+	MaxOpenSegments       int
+	blockMetadataProvider datastore.EntityMetadata
+
+	mutex    sync.Mutex
+	segments map[int64]*openSegment
+	used     int64
+}
+
+var (
+	// Make sure SegmentBlockStore implements BlockStore.
+	_ BlockStore = (*SegmentBlockStore)(nil)
+)
+
+// NewSegmentBlockStore - return a new segment block store.
+func NewSegmentBlockStore(rootDir string, segmentRounds int64) *SegmentBlockStore {
+	if segmentRounds <= 0 {
+		segmentRounds = DefaultSegmentRounds
+	}
+	return &SegmentBlockStore{
+		RootDirectory:         rootDir,
+		SegmentRounds:         segmentRounds,
+		MaxOpenSegments:       defaultMaxOpenSegments,
+		blockMetadataProvider: datastore.GetEntityMetadata("block"),
+		segments:              make(map[int64]*openSegment),
+	}
+}
+
+func (sbs *SegmentBlockStore) getSegmentFile(id int64) string {
+	return filepath.Join(sbs.RootDirectory, fmt.Sprintf("%012d", id))
+}
+
+func (sbs *SegmentBlockStore) getSegmentID(round int64) int64 {
+	return round / sbs.SegmentRounds
+}
+
+// acquire - the segment open and in use until released, a segment not
+// existing is created only if asked
+func (sbs *SegmentBlockStore) acquire(id int64, create bool) (*openSegment, error) {
+	sbs.mutex.Lock()
+	defer sbs.mutex.Unlock()
+	sbs.used++
+	if seg, ok := sbs.segments[id]; ok {
+		seg.refs++
+		seg.used = sbs.used
+		return seg, nil
+	}
+	file := sbs.getSegmentFile(id)
+	if !create {
+		if _, err := os.Stat(file + ".seg"); err != nil {
+			return nil, err
+		}
+	}
+	s, err := blockdb.OpenSegment(file)
+	if err != nil {
+		return nil, err
+	}
+	if s.Recovered() > 0 {
+		Logger.Info("segment block store - records indexed again",
+			zap.String("segment", file), zap.Int("records", s.Recovered()))
+	}
+	sbs.evict()
+	seg := &openSegment{Segment: s, id: id, refs: 1, used: sbs.used}
+	sbs.segments[id] = seg
+	return seg, nil
+}
+
+func (sbs *SegmentBlockStore) release(seg *openSegment) {
+	sbs.mutex.Lock()
+	defer sbs.mutex.Unlock()
+	seg.refs--
+}
+
+// discard - release the segment and close it when not in use, for the next
+// use to open it again from its files
+func (sbs *SegmentBlockStore) discard(seg *openSegment) {
+	sbs.mutex.Lock()
+	defer sbs.mutex.Unlock()
+	seg.refs--
+	if seg.refs > 0 || sbs.segments[seg.id] != seg {
+		return
+	}
+	if err := seg.Close(); err != nil {
+		Logger.Debug("segment block store - close discarded segment",
+			zap.Int64("segment", seg.id), zap.Error(err))
+	}
+	delete(sbs.segments, seg.id)
+}
+
+// evict - close the least recently used segments not in use to keep at
+// most MaxOpenSegments open with the one to open
+func (sbs *SegmentBlockStore) evict() {
+	for len(sbs.segments) >= sbs.MaxOpenSegments {
+		var lru *openSegment
+		for _, seg := range sbs.segments {
+			if seg.refs == 0 && (lru == nil || seg.used < lru.used) {
+				lru = seg
+			}
+		}
+		if lru == nil {
+			return
+		}
+		if err := lru.Close(); err != nil {
+			Logger.Error("segment block store - close segment",
+				zap.Int64("segment", lru.id), zap.Error(err))
+		}
+		delete(sbs.segments, lru.id)
+	}
+}
+
+// GetSegmentIDs - the ids of the segments stored, in ascending order
+func (sbs *SegmentBlockStore) GetSegmentIDs() ([]int64, error) {
+	files, err := filepath.Glob(filepath.Join(sbs.RootDirectory, "*.seg"))
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".seg")
+		id, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue // not a segment, as a compaction left over
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// WriteRaw - write the encoded block, as stored by the FSBlockStore, with
+// the hash and the round given
+func (sbs *SegmentBlockStore) WriteRaw(hash string, round int64, data []byte) error {
+	seg, err := sbs.acquire(sbs.getSegmentID(round), true)
+	if err != nil {
+		return err
+	}
+	defer sbs.release(seg)
+	return seg.Append(blockdb.Key(hash), round, data)
+}
+
+// Write - append the block to the segment of its round
+func (sbs *SegmentBlockStore) Write(b *block.Block) error {
+	data, err := encodeBlock(b)
+	if err != nil {
+		return err
+	}
+	if err = sbs.WriteRaw(b.Hash, b.Round, data); err != nil {
+		return err
+	}
+	if b.MagicBlock != nil && b.Round == b.MagicBlock.StartingRound {
+		return sbs.WriteRaw(b.MagicBlock.Hash, b.MagicBlock.StartingRound, data)
+	}
+	return nil
+}
+
+// ReadRaw - the encoded block of the round
+func (sbs *SegmentBlockStore) ReadRaw(hash string, round int64) ([]byte, error) {
+	if len(hash) != 64 {
+		return nil, encryption.ErrInvalidHash
+	}
+	seg, err := sbs.acquire(sbs.getSegmentID(round), false)
+	if err != nil {
+		return nil, err
+	}
+	defer sbs.release(seg)
+	data, _, err := seg.Get(blockdb.Key(hash))
+	return data, err
+}
+
+// Read - read the block of the round from its segment
+func (sbs *SegmentBlockStore) Read(hash string, round int64) (*block.Block, error) {
+	data, err := sbs.ReadRaw(hash, round)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBlock(sbs.blockMetadataProvider, data)
+}
+
+// ReadWithBlockSummary - read the block given the block summary
+func (sbs *SegmentBlockStore) ReadWithBlockSummary(bs *block.BlockSummary) (*block.Block, error) {
+	return sbs.Read(bs.Hash, bs.Round)
+}
+
+// GetRoundHashes - the hashes of the blocks stored for the round
+func (sbs *SegmentBlockStore) GetRoundHashes(round int64) ([]string, error) {
+	seg, err := sbs.acquire(sbs.getSegmentID(round), false)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer sbs.release(seg)
+	var hashes []string
+	for _, key := range seg.GetRoundKeys(round) {
+		hashes = append(hashes, string(key))
+	}
+	return hashes, nil
+}
+
+// Delete - delete from the hash of the block
+func (sbs *SegmentBlockStore) Delete(hash string) error {
+	return common.NewError("interface_not_implemented", "SegmentBlockStore cannot provide this interface")
+}
+
+// DeleteBlock - append a tombstone of the block to its segment, the space
+// is reclaimed by the compaction
+func (sbs *SegmentBlockStore) DeleteBlock(b *block.Block) error {
+	return sbs.DeleteRaw(b.Hash, b.Round)
+}
+
+// DeleteRaw - append a tombstone of the block of the hash and the round
+func (sbs *SegmentBlockStore) DeleteRaw(hash string, round int64) error {
+	seg, err := sbs.acquire(sbs.getSegmentID(round), false)
+	if err != nil {
+		return err
+	}
+	defer sbs.release(seg)
+	return seg.Remove(blockdb.Key(hash))
+}
+
+// Stats - the number and the size of the blocks stored, with the magic block
+// copies
+func (sbs *SegmentBlockStore) Stats() (blocks, size int64, err error) {
+	ids, err := sbs.GetSegmentIDs()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, id := range ids {
+		seg, err := sbs.acquire(id, false)
+		if err != nil {
+			return 0, 0, err
+		}
+		garbage, segSize := seg.Garbage()
+		blocks += int64(len(seg.GetKeys()))
+		size += segSize - garbage
+		sbs.release(seg)
+	}
+	return blocks, size, nil
+}
+
+// SegmentIteratorHandler - a handler of each block stored
+type SegmentIteratorHandler func(ctx context.Context, hash string, round int64, data []byte) error
+
+// Iterate - iterate the encoded blocks stored, segment by segment
+func (sbs *SegmentBlockStore) Iterate(ctx context.Context, handler SegmentIteratorHandler) error {
+	ids, err := sbs.GetSegmentIDs()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		seg, err := sbs.acquire(id, false)
+		if err != nil {
+			return err
+		}
+		err = seg.Iterate(ctx, func(ctx context.Context, key blockdb.Key, round int64, data []byte) error {
+			return handler(ctx, string(key), round, data)
+		})
+		sbs.release(seg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compact - rewrite the segments having at least the given ratio of their
+// size deleted or replaced, the number of the segments compacted
+func (sbs *SegmentBlockStore) Compact(ctx context.Context, ratio float64) (int, error) {
+	ids, err := sbs.GetSegmentIDs()
+	if err != nil {
+		return 0, err
+	}
+	var compacted int
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return compacted, err
+		}
+		seg, err := sbs.acquire(id, false)
+		if err != nil {
+			return compacted, err
+		}
+		garbage, size := seg.Garbage()
+		if garbage > 0 && float64(garbage) >= ratio*float64(size) {
+			err = seg.Compact()
+			if err == nil {
+				compacted++
+				Logger.Info("segment block store - compacted", zap.Int64("segment", id),
+					zap.Int64("size", size), zap.Int64("reclaimed", garbage))
+			}
+		}
+		if err != nil {
+			// not to keep using a segment left with its files closed
+			sbs.discard(seg)
+			return compacted, err
+		}
+		sbs.release(seg)
+	}
+	return compacted, nil
+}
+
+// CompactionWorker - compact the segments periodically
+func (sbs *SegmentBlockStore) CompactionWorker(ctx context.Context, interval time.Duration, ratio float64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := sbs.Compact(ctx, ratio); err != nil {
+				Logger.Error("segment block store - compaction", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Close - close the segments open
+func (sbs *SegmentBlockStore) Close() error {
+	sbs.mutex.Lock()
+	defer sbs.mutex.Unlock()
+	var err error
+	for id, seg := range sbs.segments {
+		if cerr := seg.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		delete(sbs.segments, id)
+	}
+	return err
+}
+
+// UploadToCloud - not supported by the segments
+func (sbs *SegmentBlockStore) UploadToCloud(hash string, round int64) error {
+	return common.NewError("interface_not_implemented", "SegmentBlockStore cannot provide this interface")
+}
+
+// DownloadFromCloud - not supported by the segments
+func (sbs *SegmentBlockStore) DownloadFromCloud(hash string, round int64) error {
+	return common.NewError("interface_not_implemented", "SegmentBlockStore cannot provide this interface")
+}
+
+// CloudObjectExists - not supported by the segments
+func (sbs *SegmentBlockStore) CloudObjectExists(hash string) bool {
+	return false
+}
@@ -0,0 +1,132 @@
+package blockstore
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/encryption"
+)
+
+func makeTestSegmentBlockStore(t *testing.T, segmentRounds int64) *SegmentBlockStore {
+	currDir, err := os.Getwd()
+	require.NoError(t, err)
+	dir := filepath.Join(currDir, "tmp", "segments", t.Name())
+	require.NoError(t, os.RemoveAll(dir))
+	sbs := NewSegmentBlockStore(dir, segmentRounds)
+	t.Cleanup(func() {
+		require.NoError(t, sbs.Close())
+		require.NoError(t, os.RemoveAll(dir))
+	})
+	return sbs
+}
+
+func makeTestRoundBlock(round int64) *block.Block {
+	b := block.NewBlock("", round)
+	b.Hash = encryption.Hash(fmt.Sprintf("block %d", round))
+	return b
+}
+
+func TestSegmentBlockStore_WriteRead(t *testing.T) {
+	sbs := makeTestSegmentBlockStore(t, 10)
+	sbs.MaxOpenSegments = 2
+
+	for r := int64(1); r <= 50; r++ {
+		require.NoError(t, sbs.Write(makeTestRoundBlock(r)))
+	}
+	ids, err := sbs.GetSegmentIDs()
+	require.NoError(t, err)
+	assert.Equal(t, []int64{0, 1, 2, 3, 4, 5}, ids)
+	assert.True(t, len(sbs.segments) <= sbs.MaxOpenSegments)
+
+	for _, r := range []int64{1, 25, 50, 9, 10} {
+		want := makeTestRoundBlock(r)
+		b, err := sbs.Read(want.Hash, r)
+		require.NoError(t, err)
+		assert.Equal(t, want.Hash, b.Hash)
+		assert.Equal(t, r, b.Round)
+		got, err := sbs.ReadWithBlockSummary(&block.BlockSummary{Hash: want.Hash, Round: r})
+		require.NoError(t, err)
+		assert.Equal(t, want.Hash, got.Hash)
+
+		hashes, err := sbs.GetRoundHashes(r)
+		require.NoError(t, err)
+		assert.Equal(t, []string{want.Hash}, hashes)
+	}
+
+	// the block is in the segment of its round only
+	_, err = sbs.Read(makeTestRoundBlock(1).Hash, 15)
+	assert.Error(t, err)
+	_, err = sbs.Read(makeTestRoundBlock(1).Hash, 1000)
+	assert.True(t, os.IsNotExist(err))
+	_, err = sbs.Read("short", 1)
+	assert.Error(t, err)
+}
+
+func TestSegmentBlockStore_DeleteCompact(t *testing.T) {
+	var (
+		ctx = context.Background()
+		sbs = makeTestSegmentBlockStore(t, 10)
+	)
+	for r := int64(0); r < 20; r++ {
+		require.NoError(t, sbs.Write(makeTestRoundBlock(r)))
+	}
+	for r := int64(0); r < 8; r++ {
+		require.NoError(t, sbs.DeleteBlock(makeTestRoundBlock(r)))
+	}
+	require.Error(t, sbs.DeleteBlock(makeTestRoundBlock(100)))
+	_, err := sbs.Read(makeTestRoundBlock(3).Hash, 3)
+	assert.Error(t, err)
+
+	compacted, err := sbs.Compact(ctx, 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, compacted)
+	compacted, err = sbs.Compact(ctx, 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, 0, compacted)
+
+	// reopened from the compacted files
+	require.NoError(t, sbs.Close())
+	var rounds []int64
+	require.NoError(t, sbs.Iterate(ctx, func(_ context.Context, hash string, round int64, data []byte) error {
+		assert.Equal(t, makeTestRoundBlock(round).Hash, hash)
+		rounds = append(rounds, round)
+		return nil
+	}))
+	assert.Equal(t, []int64{8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19}, rounds)
+}
+
+func TestMigrateFSBlockStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blocks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	fbs := NewFSBlockStore(dir, &minioClientMock{})
+
+	var blocks []*block.Block
+	for r := int64(1); r <= 5; r++ {
+		b := makeTestRoundBlock(r)
+		require.NoError(t, fbs.Write(b))
+		blocks = append(blocks, b)
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README"), nil, 0644))
+
+	sbs := makeTestSegmentBlockStore(t, 2)
+	count, err := MigrateFSBlockStore(context.Background(), dir, sbs)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(blocks)), count)
+	for _, want := range blocks {
+		b, err := sbs.Read(want.Hash, want.Round)
+		require.NoError(t, err)
+		assert.Equal(t, want.Hash, b.Hash)
+	}
+
+	_, err = MigrateFSBlockStore(context.Background(), dir, sbs)
+	assert.Error(t, err, "not empty")
+}
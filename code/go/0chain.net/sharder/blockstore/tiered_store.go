@@ -0,0 +1,578 @@
+package blockstore
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/cache"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	. "0chain.net/core/logging"
+	"0chain.net/sharder/blockdb"
+)
+
+const (
+	tieringStateFile = "tiering.json"
+	// the default interval of the moves between the tiers
+	defaultMoveInterval = 10 * time.Minute
+)
+
+// TieringPolicy - the tier of a block by its age in rounds: the blocks of
+// the last HotRounds rounds are in the hot tier, the blocks of the
+// WarmRounds rounds before in the warm tier and the older blocks in the cold
+// tier. The blocks older than RetentionRounds rounds are deleted, the magic
+// block copies are kept.
+type TieringPolicy struct {
+	HotRounds       int64
+	WarmRounds      int64
+	RetentionRounds int64 // 0 to keep the blocks
+	MoveInterval    time.Duration
+	MoveRate        int // max blocks moved a second, 0 for no limit
+	CacheSize       int // max blocks read from the cold tier kept in memory
+}
+
+// tieringState - the rounds up to which the blocks are moved out of the hot
+// and the warm tiers and deleted, saved with the hot tier
+type tieringState struct {
+	HotRound     int64 `json:"hot_round"`
+	WarmRound    int64 `json:"warm_round"`
+	DeletedRound int64 `json:"deleted_round"`
+	ColdBlocks   int64 `json:"cold_blocks"`
+	ColdSize     int64 `json:"cold_size"`
+}
+
+// TierStats - the number and the size of the blocks of the tiers, with the
+// magic block copies
+type TierStats struct {
+	HotBlocks   int64     `json:"hot_blocks"`
+	HotSize     int64     `json:"hot_size"`
+	WarmBlocks  int64     `json:"warm_blocks"`
+	WarmSize    int64     `json:"warm_size"`
+	ColdBlocks  int64     `json:"cold_blocks"`
+	ColdSize    int64     `json:"cold_size"`
+	Moved       int64     `json:"moved"`
+	Deleted     int64     `json:"deleted"`
+	CacheHits   int64     `json:"cache_hits"`
+	CacheMisses int64     `json:"cache_misses"`
+	LastMove    time.Time `json:"last_move"`
+}
+
+// BlockHashFunc - the hash of the block kept for the round, an empty hash
+// without an error when the round is known to have no block
+type BlockHashFunc func(ctx context.Context, round int64) (string, error)
+
+// TieredBlockStore - a block store writing the blocks to the hot tier and
+// moving them to the warm and the cold tiers as they get older. Reads look
+// for the block in the tiers in order and cache the blocks read from the
+// cold tier. The warm or the cold tier can be left out, a block stays in the
+// previous tier then.
+type TieredBlockStore struct {
+	Hot    *FSBlockStore
+	Warm   *SegmentBlockStore
+	Cold   ObjectStore
+	Policy TieringPolicy
+
+	blockMetadataProvider datastore.EntityMetadata
+	cache                 *cache.LRU
+	round                 int64 // the latest round written
+	state                 tieringState
+	moved                 int64
+	deleted               int64
+
+	statsMutex sync.Mutex
+	stats      TierStats
+}
+
+var (
+	// Make sure TieredBlockStore implements BlockStore.
+	_ BlockStore = (*TieredBlockStore)(nil)
+
+	hotBlocksGauge  = metrics.GetOrRegisterGauge("blockstore_hot_blocks", nil)
+	hotSizeGauge    = metrics.GetOrRegisterGauge("blockstore_hot_size", nil)
+	warmBlocksGauge = metrics.GetOrRegisterGauge("blockstore_warm_blocks", nil)
+	warmSizeGauge   = metrics.GetOrRegisterGauge("blockstore_warm_size", nil)
+	coldBlocksGauge = metrics.GetOrRegisterGauge("blockstore_cold_blocks", nil)
+	coldSizeGauge   = metrics.GetOrRegisterGauge("blockstore_cold_size", nil)
+)
+
+// NewTieredBlockStore - return a new tiered block store, resuming the moves
+// from the state saved with the hot tier.
+func NewTieredBlockStore(hot *FSBlockStore, warm *SegmentBlockStore, cold ObjectStore,
+	policy TieringPolicy) (*TieredBlockStore, error) {
+
+	if policy.MoveInterval <= 0 {
+		policy.MoveInterval = defaultMoveInterval
+	}
+	tbs := &TieredBlockStore{
+		Hot:                   hot,
+		Warm:                  warm,
+		Cold:                  cold,
+		Policy:                policy,
+		blockMetadataProvider: datastore.GetEntityMetadata("block"),
+	}
+	if policy.CacheSize > 0 {
+		tbs.cache = cache.NewLRUCache(policy.CacheSize)
+	}
+	data, err := ioutil.ReadFile(tbs.getStateFile())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err = json.Unmarshal(data, &tbs.state); err != nil {
+			return nil, common.NewErrorf("tiered_store", "invalid tiering state: %v", err)
+		}
+	}
+	tbs.round = tbs.state.HotRound
+	return tbs, nil
+}
+
+func (tbs *TieredBlockStore) getStateFile() string {
+	return filepath.Join(tbs.Hot.RootDirectory, tieringStateFile)
+}
+
+func (tbs *TieredBlockStore) saveState() error {
+	// the rounds are only changed by the moves, the cold tier counters by
+	// DeleteBlock too
+	state := tieringState{
+		HotRound:     tbs.state.HotRound,
+		WarmRound:    tbs.state.WarmRound,
+		DeletedRound: tbs.state.DeletedRound,
+		ColdBlocks:   atomic.LoadInt64(&tbs.state.ColdBlocks),
+		ColdSize:     atomic.LoadInt64(&tbs.state.ColdSize),
+	}
+	data, err := json.Marshal(&state)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(tbs.Hot.RootDirectory, 0755); err != nil {
+		return err
+	}
+	tmp := tbs.getStateFile() + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, tbs.getStateFile())
+}
+
+// Write - write the block to the hot tier
+func (tbs *TieredBlockStore) Write(b *block.Block) error {
+	if err := tbs.Hot.Write(b); err != nil {
+		return err
+	}
+	for {
+		round := atomic.LoadInt64(&tbs.round)
+		if b.Round <= round || atomic.CompareAndSwapInt64(&tbs.round, round, b.Round) {
+			return nil
+		}
+	}
+}
+
+// IsNotFound - the error of reading a block of a block store not having it
+func IsNotFound(err error) bool {
+	return os.IsNotExist(err) || err == blockdb.ErrKeyNotFound || err == ErrObjectNotFound
+}
+
+// ReadRaw - the encoded block from the first tier having it
+func (tbs *TieredBlockStore) ReadRaw(hash string, round int64) ([]byte, error) {
+	if len(hash) != 64 {
+		return nil, encryption.ErrInvalidHash
+	}
+	data, err := ioutil.ReadFile(tbs.Hot.getFileName(hash, round))
+	if err == nil || !IsNotFound(err) {
+		return data, err
+	}
+	if tbs.Warm != nil {
+		if data, err = tbs.Warm.ReadRaw(hash, round); err == nil || !IsNotFound(err) {
+			return data, err
+		}
+	}
+	if tbs.Cold != nil {
+		return tbs.readCold(hash)
+	}
+	return nil, err
+}
+
+func (tbs *TieredBlockStore) readCold(hash string) ([]byte, error) {
+	if tbs.cache != nil {
+		if data, err := tbs.cache.Get(hash); err == nil {
+			return data.([]byte), nil
+		}
+	}
+	data, err := tbs.Cold.GetObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	if tbs.cache != nil {
+		tbs.cache.Add(hash, data)
+	}
+	return data, nil
+}
+
+// Read - read the block from the first tier having it
+func (tbs *TieredBlockStore) Read(hash string, round int64) (*block.Block, error) {
+	data, err := tbs.ReadRaw(hash, round)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBlock(tbs.blockMetadataProvider, data)
+}
+
+// ReadWithBlockSummary - read the block given the block summary
+func (tbs *TieredBlockStore) ReadWithBlockSummary(bs *block.BlockSummary) (*block.Block, error) {
+	return tbs.Read(bs.Hash, bs.Round)
+}
+
+// Delete - delete from the hash of the block
+func (tbs *TieredBlockStore) Delete(hash string) error {
+	return common.NewError("interface_not_implemented", "TieredBlockStore cannot provide this interface")
+}
+
+// DeleteBlock - delete the block from all the tiers
+func (tbs *TieredBlockStore) DeleteBlock(b *block.Block) error {
+	_, err := tbs.deleteRaw(b.Hash, b.Round)
+	return err
+}
+
+// deleteRaw - delete the block of the hash from the tiers, whether any had it
+func (tbs *TieredBlockStore) deleteRaw(hash string, round int64) (bool, error) {
+	var deleted bool
+	if len(hash) != 64 {
+		return false, encryption.ErrInvalidHash
+	}
+	if err := os.Remove(tbs.Hot.getFileName(hash, round)); err == nil {
+		deleted = true
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+	if tbs.Warm != nil {
+		if err := tbs.Warm.DeleteRaw(hash, round); err == nil {
+			deleted = true
+		} else if !IsNotFound(err) {
+			return deleted, err
+		}
+	}
+	if tbs.Cold != nil {
+		size, err := tbs.Cold.StatObject(hash)
+		if err != nil && !IsNotFound(err) {
+			return deleted, err
+		}
+		if err == nil {
+			if err = tbs.Cold.RemoveObject(hash); err != nil {
+				return deleted, err
+			}
+			atomic.AddInt64(&tbs.state.ColdBlocks, -1)
+			atomic.AddInt64(&tbs.state.ColdSize, -size)
+			deleted = true
+		}
+	}
+	return deleted, nil
+}
+
+// putCold - put the encoded block in the cold tier
+func (tbs *TieredBlockStore) putCold(hash string, data []byte) error {
+	if err := tbs.Cold.PutObject(hash, data); err != nil {
+		return err
+	}
+	atomic.AddInt64(&tbs.state.ColdBlocks, 1)
+	atomic.AddInt64(&tbs.state.ColdSize, int64(len(data)))
+	return nil
+}
+
+// moveHot - move the block of the hash, and its magic block copy, out of the
+// hot tier, whether it was there
+func (tbs *TieredBlockStore) moveHot(hash string, round int64) (bool, error) {
+	file := tbs.Hot.getFileName(hash, round)
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if tbs.Warm != nil {
+		err = tbs.Warm.WriteRaw(hash, round, data)
+	} else {
+		err = tbs.putCold(hash, data)
+	}
+	if err != nil {
+		return false, err
+	}
+	if err = os.Remove(file); err != nil {
+		return false, err
+	}
+	if _, mbHash, err := getEncodedBlockInfo(data); err == nil && mbHash != "" && mbHash != hash {
+		if _, err = tbs.moveHot(mbHash, round); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// moveWarm - move the block of the hash, and its magic block copy, from the
+// warm to the cold tier, whether it was in the warm tier
+func (tbs *TieredBlockStore) moveWarm(hash string, round int64) (bool, error) {
+	data, err := tbs.Warm.ReadRaw(hash, round)
+	if err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err = tbs.putCold(hash, data); err != nil {
+		return false, err
+	}
+	if err = tbs.Warm.DeleteRaw(hash, round); err != nil {
+		return false, err
+	}
+	if _, mbHash, err := getEncodedBlockInfo(data); err == nil && mbHash != "" && mbHash != hash {
+		if _, err = tbs.moveWarm(mbHash, round); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// moveRounds - apply the move to the blocks of the rounds after the
+// watermark up to the last round, advancing the watermark, the moves of the
+// blocks are rate limited. The rounds the hash of the block can't be looked
+// up for stop the moves until the next ones, not to leave their blocks
+// behind.
+func (tbs *TieredBlockStore) moveRounds(ctx context.Context, watermark *int64, last int64,
+	getHash BlockHashFunc, limiter <-chan time.Time,
+	move func(hash string, round int64) (bool, error)) error {
+
+	for r := *watermark + 1; r <= last; r++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hash, err := getHash(ctx, r)
+		if err != nil {
+			Logger.Error("tiered store - get block hash", zap.Int64("round", r), zap.Error(err))
+			return nil
+		}
+		if hash == "" {
+			*watermark = r // no block of the round
+			continue
+		}
+		moved, err := move(hash, r)
+		if err != nil {
+			return err
+		}
+		*watermark = r
+		if !moved {
+			continue
+		}
+		if limiter != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-limiter:
+			}
+		}
+		if r%1000 == 0 {
+			if err = tbs.saveState(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Move - delete the blocks past the retention and move the blocks to the
+// tiers of their age, one round at a time from where the previous moves
+// stopped
+func (tbs *TieredBlockStore) Move(ctx context.Context, getHash BlockHashFunc) error {
+	var (
+		current = atomic.LoadInt64(&tbs.round)
+		policy  = tbs.Policy
+		limiter <-chan time.Time
+	)
+	if policy.MoveRate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(policy.MoveRate))
+		defer ticker.Stop()
+		limiter = ticker.C
+	}
+	defer func() {
+		if err := tbs.saveState(); err != nil {
+			Logger.Error("tiered store - save state", zap.Error(err))
+		}
+	}()
+
+	if policy.RetentionRounds > 0 {
+		err := tbs.moveRounds(ctx, &tbs.state.DeletedRound, current-policy.RetentionRounds,
+			getHash, nil, func(hash string, round int64) (bool, error) {
+				deleted, err := tbs.deleteRaw(hash, round)
+				if deleted {
+					atomic.AddInt64(&tbs.deleted, 1)
+				}
+				return deleted, err
+			})
+		if err != nil {
+			return err
+		}
+		if tbs.state.HotRound < tbs.state.DeletedRound {
+			tbs.state.HotRound = tbs.state.DeletedRound
+		}
+		if tbs.state.WarmRound < tbs.state.DeletedRound {
+			tbs.state.WarmRound = tbs.state.DeletedRound
+		}
+	}
+
+	if tbs.Warm != nil || tbs.Cold != nil {
+		err := tbs.moveRounds(ctx, &tbs.state.HotRound, current-policy.HotRounds,
+			getHash, limiter, func(hash string, round int64) (bool, error) {
+				moved, err := tbs.moveHot(hash, round)
+				if moved {
+					atomic.AddInt64(&tbs.moved, 1)
+				}
+				return moved, err
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	if tbs.Warm != nil && tbs.Cold != nil {
+		last := current - policy.HotRounds - policy.WarmRounds
+		if last > tbs.state.HotRound {
+			last = tbs.state.HotRound
+		}
+		err := tbs.moveRounds(ctx, &tbs.state.WarmRound, last,
+			getHash, limiter, func(hash string, round int64) (bool, error) {
+				moved, err := tbs.moveWarm(hash, round)
+				if moved {
+					atomic.AddInt64(&tbs.moved, 1)
+				}
+				return moved, err
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ComputeStats - walk the local tiers for the number and the size of their
+// blocks
+func (tbs *TieredBlockStore) ComputeStats() (*TierStats, error) {
+	stats := &TierStats{
+		ColdBlocks: atomic.LoadInt64(&tbs.state.ColdBlocks),
+		ColdSize:   atomic.LoadInt64(&tbs.state.ColdSize),
+		Moved:      atomic.LoadInt64(&tbs.moved),
+		Deleted:    atomic.LoadInt64(&tbs.deleted),
+		LastMove:   time.Now(),
+	}
+	err := filepath.Walk(tbs.Hot.RootDirectory, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !fi.IsDir() && strings.HasSuffix(path, fileExt) {
+			stats.HotBlocks++
+			stats.HotSize += fi.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tbs.Warm != nil {
+		if stats.WarmBlocks, stats.WarmSize, err = tbs.Warm.Stats(); err != nil {
+			return nil, err
+		}
+	}
+	if tbs.cache != nil {
+		stats.CacheHits, stats.CacheMisses = tbs.cache.GetHit(), tbs.cache.GetMiss()
+	}
+	return stats, nil
+}
+
+// GetStats - the tier stats computed after the last moves
+func (tbs *TieredBlockStore) GetStats() TierStats {
+	tbs.statsMutex.Lock()
+	defer tbs.statsMutex.Unlock()
+	return tbs.stats
+}
+
+func (tbs *TieredBlockStore) updateStats() {
+	stats, err := tbs.ComputeStats()
+	if err != nil {
+		Logger.Error("tiered store - stats", zap.Error(err))
+		return
+	}
+	tbs.statsMutex.Lock()
+	tbs.stats = *stats
+	tbs.statsMutex.Unlock()
+
+	hotBlocksGauge.Update(stats.HotBlocks)
+	hotSizeGauge.Update(stats.HotSize)
+	warmBlocksGauge.Update(stats.WarmBlocks)
+	warmSizeGauge.Update(stats.WarmSize)
+	coldBlocksGauge.Update(stats.ColdBlocks)
+	coldSizeGauge.Update(stats.ColdSize)
+}
+
+// MoveWorker - move the blocks between the tiers periodically
+func (tbs *TieredBlockStore) MoveWorker(ctx context.Context, getHash BlockHashFunc) {
+	ticker := time.NewTicker(tbs.Policy.MoveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := tbs.Move(ctx, getHash); err != nil {
+				Logger.Error("tiered store - move blocks", zap.Error(err))
+			}
+			tbs.updateStats()
+		}
+	}
+}
+
+// UploadToCloud - move the block to the cold tier now
+func (tbs *TieredBlockStore) UploadToCloud(hash string, round int64) error {
+	if tbs.Cold == nil {
+		return common.NewError("tiered_store", "no cold tier")
+	}
+	if tbs.Warm != nil {
+		if _, err := tbs.moveHot(hash, round); err != nil {
+			return err
+		}
+		_, err := tbs.moveWarm(hash, round)
+		return err
+	}
+	_, err := tbs.moveHot(hash, round)
+	return err
+}
+
+// DownloadFromCloud - cache the block of the cold tier
+func (tbs *TieredBlockStore) DownloadFromCloud(hash string, round int64) error {
+	if tbs.Cold == nil {
+		return common.NewError("tiered_store", "no cold tier")
+	}
+	_, err := tbs.readCold(hash)
+	return err
+}
+
+// CloudObjectExists - the block is in the cold tier
+func (tbs *TieredBlockStore) CloudObjectExists(hash string) bool {
+	if tbs.Cold == nil {
+		return false
+	}
+	_, err := tbs.Cold.StatObject(hash)
+	return err == nil
+}
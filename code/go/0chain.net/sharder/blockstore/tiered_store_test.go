@@ -0,0 +1,228 @@
+package blockstore
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/encryption"
+)
+
+func makeTestTieredBlockStore(t *testing.T, cold ObjectStore, policy TieringPolicy) *TieredBlockStore {
+	dir, err := ioutil.TempDir("", "blocks")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	tbs, err := NewTieredBlockStore(NewFSBlockStore(dir, &minioClientMock{}),
+		makeTestSegmentBlockStore(t, 10), cold, policy)
+	require.NoError(t, err)
+	return tbs
+}
+
+func testBlockHashes(blocks map[int64]*block.Block) BlockHashFunc {
+	return func(_ context.Context, round int64) (string, error) {
+		if b, ok := blocks[round]; ok {
+			return b.Hash, nil
+		}
+		return "", nil // no block of the round
+	}
+}
+
+func TestTieredBlockStore_Move(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		cold   = NewMemoryObjectStore()
+		policy = TieringPolicy{HotRounds: 5, WarmRounds: 10, RetentionRounds: 25, CacheSize: 20}
+		tbs    = makeTestTieredBlockStore(t, cold, policy)
+		blocks = make(map[int64]*block.Block)
+	)
+	for r := int64(1); r <= 40; r++ {
+		if r == 30 {
+			continue // a round without a block
+		}
+		b := makeTestRoundBlock(r)
+		if r == 20 {
+			b.MagicBlock = block.NewMagicBlock()
+			b.MagicBlock.Hash = encryption.Hash("magic block")
+			b.MagicBlock.StartingRound = r
+		}
+		require.NoError(t, tbs.Write(b))
+		blocks[r] = b
+	}
+	require.NoError(t, tbs.Move(ctx, testBlockHashes(blocks)))
+
+	// deleted up to round 15, cold up to 25, warm up to 35
+	for r, b := range blocks {
+		_, err := tbs.Read(b.Hash, r)
+		if r <= 15 {
+			assert.Error(t, err, r)
+			continue
+		}
+		require.NoError(t, err, r)
+		_, inCold := cold.StatObject(b.Hash)
+		assert.Equal(t, r <= 25, inCold == nil, r)
+		_, err = tbs.Warm.ReadRaw(b.Hash, r)
+		assert.Equal(t, r > 25 && r <= 35, err == nil, r)
+		_, err = os.Stat(tbs.Hot.getFileName(b.Hash, r))
+		assert.Equal(t, r > 35, err == nil, r)
+	}
+	mb, err := tbs.Read(blocks[20].MagicBlock.Hash, 20)
+	require.NoError(t, err)
+	assert.Equal(t, blocks[20].Hash, mb.Hash)
+	assert.Equal(t, 11, cold.Len())
+	_, err = tbs.Read(blocks[25].Hash, 25)
+	require.NoError(t, err)
+
+	stats, err := tbs.ComputeStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stats.HotBlocks)
+	assert.Equal(t, int64(9), stats.WarmBlocks)
+	assert.Equal(t, int64(11), stats.ColdBlocks)
+	assert.Equal(t, int64(15), stats.Deleted)
+	assert.True(t, stats.CacheHits > 0)
+
+	// resumed from the saved state
+	more := makeTestRoundBlock(41)
+	blocks[41] = more
+	tbs, err = NewTieredBlockStore(tbs.Hot, tbs.Warm, cold, policy)
+	require.NoError(t, err)
+	require.NoError(t, tbs.Write(more))
+	require.NoError(t, tbs.Move(ctx, testBlockHashes(blocks)))
+	assert.Equal(t, int64(36), tbs.state.HotRound)
+	assert.Equal(t, int64(26), tbs.state.WarmRound)
+	assert.Equal(t, int64(16), tbs.state.DeletedRound)
+	assert.Equal(t, int64(11), tbs.state.ColdBlocks)
+	_, err = tbs.Read(blocks[16].Hash, 16)
+	assert.Error(t, err)
+	_, err = tbs.Read(blocks[26].Hash, 26)
+	assert.NoError(t, err)
+}
+
+func TestTieredBlockStore_MoveRate(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		policy = TieringPolicy{HotRounds: 1, MoveRate: 50}
+		tbs    = makeTestTieredBlockStore(t, nil, policy)
+		blocks = make(map[int64]*block.Block)
+	)
+	for r := int64(1); r <= 11; r++ {
+		blocks[r] = makeTestRoundBlock(r)
+		require.NoError(t, tbs.Write(blocks[r]))
+	}
+	start := time.Now()
+	require.NoError(t, tbs.Move(ctx, testBlockHashes(blocks)))
+	assert.True(t, time.Since(start) >= 180*time.Millisecond, "10 moves at 50 a second")
+	stats, err := tbs.ComputeStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.HotBlocks)
+	assert.Equal(t, int64(10), stats.WarmBlocks)
+	assert.False(t, tbs.CloudObjectExists(blocks[1].Hash))
+}
+
+func TestTieredBlockStore_MoveHashError(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		policy = TieringPolicy{HotRounds: 1}
+		tbs    = makeTestTieredBlockStore(t, nil, policy)
+		blocks = make(map[int64]*block.Block)
+	)
+	for r := int64(1); r <= 6; r++ {
+		blocks[r] = makeTestRoundBlock(r)
+		require.NoError(t, tbs.Write(blocks[r]))
+	}
+	hashes := testBlockHashes(blocks)
+	failing := func(ctx context.Context, round int64) (string, error) {
+		if round == 3 {
+			return "", errors.New("lookup failed")
+		}
+		return hashes(ctx, round)
+	}
+
+	// the moves stop at the round without the hash of its block
+	require.NoError(t, tbs.Move(ctx, failing))
+	assert.Equal(t, int64(2), tbs.state.HotRound)
+	_, err := os.Stat(tbs.Hot.getFileName(blocks[3].Hash, 3))
+	assert.NoError(t, err)
+
+	require.NoError(t, tbs.Move(ctx, hashes))
+	assert.Equal(t, int64(5), tbs.state.HotRound)
+	for r := int64(1); r <= 5; r++ {
+		_, err = tbs.Warm.ReadRaw(blocks[r].Hash, r)
+		assert.NoError(t, err, r)
+	}
+}
+
+func TestTieredBlockStore_DeleteWhileMoving(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		cold   = NewMemoryObjectStore()
+		policy = TieringPolicy{HotRounds: 5, WarmRounds: 5}
+		tbs    = makeTestTieredBlockStore(t, cold, policy)
+		blocks = make(map[int64]*block.Block)
+	)
+	for r := int64(1); r <= 20; r++ {
+		blocks[r] = makeTestRoundBlock(r)
+		require.NoError(t, tbs.Write(blocks[r]))
+	}
+	require.NoError(t, tbs.Move(ctx, testBlockHashes(blocks)))
+	require.Equal(t, 10, cold.Len())
+
+	for r := int64(21); r <= 40; r++ {
+		blocks[r] = makeTestRoundBlock(r)
+		require.NoError(t, tbs.Write(blocks[r]))
+	}
+	done := make(chan error, 1)
+	go func() {
+		for r := int64(1); r <= 10; r++ {
+			if err := tbs.DeleteBlock(blocks[r]); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	require.NoError(t, tbs.Move(ctx, testBlockHashes(blocks)))
+	require.NoError(t, <-done)
+
+	// the cold tier counters of the saved state match the deletes and moves
+	require.NoError(t, tbs.saveState())
+	tbs, err := NewTieredBlockStore(tbs.Hot, tbs.Warm, cold, policy)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), tbs.state.ColdBlocks)
+	assert.Equal(t, 20, cold.Len())
+}
+
+// TestMinioObjectStore - run against a local MinIO server given by the
+// MINIO_TEST_ENDPOINT, MINIO_TEST_ACCESS_KEY, MINIO_TEST_SECRET_KEY and
+// MINIO_TEST_BUCKET environment variables
+func TestMinioObjectStore(t *testing.T) {
+	endpoint := os.Getenv("MINIO_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("no local MinIO server")
+	}
+	mos, err := NewMinioObjectStore(MinioConfiguration{
+		StorageServiceURL: endpoint,
+		AccessKeyID:       os.Getenv("MINIO_TEST_ACCESS_KEY"),
+		SecretAccessKey:   os.Getenv("MINIO_TEST_SECRET_KEY"),
+		BucketName:        os.Getenv("MINIO_TEST_BUCKET"),
+	})
+	require.NoError(t, err)
+
+	name := encryption.Hash(t.Name())
+	require.NoError(t, mos.PutObject(name, []byte("block")))
+	size, err := mos.StatObject(name)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), size)
+	data, err := mos.GetObject(name)
+	require.NoError(t, err)
+	assert.Equal(t, "block", string(data))
+	require.NoError(t, mos.RemoveObject(name))
+	_, err = mos.StatObject(name)
+	assert.Equal(t, ErrObjectNotFound, err)
+}
@@ -16,7 +16,7 @@ import (
 	"0chain.net/core/datastore"
 	"0chain.net/sharder/blockstore"
 
-	"github.com/0chain/gorocksdb"
+	"0chain.net/core/kvdb"
 
 	. "0chain.net/core/logging"
 	"go.uber.org/zap"
@@ -37,6 +37,7 @@ func SetupSharderChain(c *chain.Chain) {
 	c.SetViewChanger(sharderChain)
 	c.SetAfterFetcher(sharderChain)
 	c.SetMagicBlockSaver(sharderChain)
+	c.SetRoundSummaryGetter(sharderChain)
 	sharderChain.BlockSyncStats = &SyncStats{}
 	sharderChain.TieringStats = &MinioStats{}
 	c.RoundF = SharderRoundFactory{}
@@ -306,11 +307,11 @@ func (sc *Chain) loadHighestMagicBlock(ctx context.Context,
 	return // not found
 }
 
-func (sc *Chain) walkDownLookingForLFB(iter *gorocksdb.Iterator,
+func (sc *Chain) walkDownLookingForLFB(iter kvdb.Iterator,
 	r *round.Round) (lfb *block.Block, err error) {
 
 	for ; iter.Valid(); iter.Prev() {
-		if err = datastore.FromJSON(iter.Value().Data(), r); err != nil {
+		if err = datastore.FromJSON(iter.Value(), r); err != nil {
 			return nil, common.NewErrorf("load_lfb",
 				"decoding round info: %v", err) // critical
 		}
@@ -359,7 +360,7 @@ func (sc *Chain) iterateRoundsLookingForLFB(ctx context.Context) *blocksLoaded {
 
 	var (
 		conn = ememorystore.GetEntityCon(rctx, remd)
-		iter = conn.Conn.NewIterator(conn.ReadOptions)
+		iter = conn.Conn.NewIterator()
 	)
 	defer iter.Close()
 
@@ -0,0 +1,274 @@
+package sharder
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	. "0chain.net/core/logging"
+	"0chain.net/core/persistencestore"
+)
+
+const (
+	// the default and the max number of transactions returned by a request
+	defaultClientTxnsLimit = 20
+	maxClientTxnsLimit     = 100
+	// the max number of pages of the history scanned by a request with
+	// filters before returning the transactions found
+	maxClientTxnsPages = 10
+)
+
+// ClientTxnCursor - the position in the transaction history of a client of
+// the last transaction returned, the next page starts right after it
+type ClientTxnCursor struct {
+	Round     int64
+	TxnHash   string
+	Direction string
+}
+
+func (c ClientTxnCursor) String() string {
+	return fmt.Sprintf("%v:%v:%v", c.Round, c.TxnHash, c.Direction)
+}
+
+// ParseClientTxnCursor - parse a "round:txn_hash:direction" cursor
+func ParseClientTxnCursor(s string) (c ClientTxnCursor, err error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return c, common.InvalidRequest("invalid cursor: " + s)
+	}
+	if c.Round, err = strconv.ParseInt(parts[0], 10, 64); err != nil || c.Round < 0 {
+		return c, common.InvalidRequest("invalid cursor: " + s)
+	}
+	c.TxnHash, c.Direction = parts[1], parts[2]
+	return c, nil
+}
+
+// ClientTxnFilter - the transactions of the history of a client to return,
+// an empty field matches any value
+type ClientTxnFilter struct {
+	FromRound int64
+	ToRound   int64
+	Type      *int
+	Direction string
+}
+
+// Match - the transaction passes the filter
+func (f *ClientTxnFilter) Match(ct *transaction.ClientTxn) bool {
+	return (f.Type == nil || *f.Type == ct.Type) &&
+		(f.Direction == "" || f.Direction == ct.Direction)
+}
+
+// ClientTxnsResponse - a page of the transaction history of a client and the
+// cursor of the next page, empty on the last page
+type ClientTxnsResponse struct {
+	Transactions []*transaction.ClientTxn `json:"transactions"`
+	Cursor       string                   `json:"cursor"`
+}
+
+// clientTxnsPageGetter - at most n transactions of the client from the
+// round before the cursor, newest first
+type clientTxnsPageGetter func(ctx context.Context, clientID string,
+	from int64, before ClientTxnCursor, n int) ([]*transaction.ClientTxn, error)
+
+// collectClientTxns - the transactions of the client before the cursor
+// matching the filter, at most limit of them and from at most
+// maxClientTxnsPages pages of the history, with the cursor of the last
+// scanned transaction unless the history is scanned to its start
+func collectClientTxns(ctx context.Context, clientID string,
+	before ClientTxnCursor, filter *ClientTxnFilter, limit int,
+	getPage clientTxnsPageGetter) ([]*transaction.ClientTxn, string, error) {
+
+	var txns []*transaction.ClientTxn
+	for page := 0; page < maxClientTxnsPages; page++ {
+		res, err := getPage(ctx, clientID, filter.FromRound, before, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, ct := range res {
+			before = ClientTxnCursor{Round: ct.Round, TxnHash: ct.TxnHash,
+				Direction: ct.Direction}
+			if !filter.Match(ct) {
+				continue
+			}
+			txns = append(txns, ct)
+			if len(txns) == limit {
+				return txns, before.String(), nil
+			}
+		}
+		if len(res) < limit {
+			return txns, "", nil
+		}
+	}
+	return txns, before.String(), nil
+}
+
+// getBlockClientTxns - the entries of the transactions of the block in the
+// client histories
+func getBlockClientTxns(b *block.Block) []datastore.Entity {
+	var cts []datastore.Entity
+	for _, txn := range b.Txns {
+		for _, ct := range txn.GetClientTxns(b.Round) {
+			cts = append(cts, ct)
+		}
+	}
+	return cts
+}
+
+// StoreClientTxns - persists the entries of the transactions of the block
+// in the client histories
+func (sc *Chain) StoreClientTxns(ctx context.Context, b *block.Block) error {
+	cts := getBlockClientTxns(b)
+	if len(cts) == 0 {
+		return nil
+	}
+	ctMetadata := datastore.GetEntityMetadata("client_txn")
+	cctx := persistencestore.WithEntityConnection(ctx, ctMetadata)
+	defer persistencestore.Close(cctx)
+	return ctMetadata.GetStore().MultiWrite(cctx, ctMetadata, cts)
+}
+
+// GetClientTxnsPage - at most n stored transactions of the client from the
+// round before the cursor, newest first
+func (sc *Chain) GetClientTxnsPage(ctx context.Context, clientID string,
+	from int64, before ClientTxnCursor, n int) ([]*transaction.ClientTxn, error) {
+
+	ctMetadata := datastore.GetEntityMetadata("client_txn")
+	cctx := persistencestore.WithEntityConnection(ctx, ctMetadata)
+	defer persistencestore.Close(cctx)
+	c := persistencestore.GetCon(cctx)
+	iter := c.Query(fmt.Sprintf("SELECT JSON * FROM %v WHERE client_id = ? AND "+
+		"(round, txn_hash, direction) < (?, ?, ?) AND (round) >= (?) LIMIT ?",
+		ctMetadata.GetName()), clientID, before.Round, before.TxnHash,
+		before.Direction, from, n).Iter()
+	var (
+		json string
+		txns []*transaction.ClientTxn
+	)
+	for iter.Scan(&json) {
+		ct := ctMetadata.Instance().(*transaction.ClientTxn)
+		if err := datastore.FromJSON(json, ct); err != nil {
+			Logger.Error("get client transactions", zap.String("client", clientID),
+				zap.Error(err))
+			continue
+		}
+		txns = append(txns, ct)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
+
+// ReindexClientTxns - store the entries of the transactions of the blocks
+// kept by the sharder from the round up to the latest finalized round in
+// the client histories, the number of the blocks indexed
+func (sc *Chain) ReindexClientTxns(ctx context.Context, from int64) (int64, error) {
+	lfb := sc.GetLatestFinalizedBlock()
+	if lfb == nil {
+		return 0, nil
+	}
+	var indexed int64
+	for r := from; r <= lfb.Round; r++ {
+		if err := ctx.Err(); err != nil {
+			return indexed, err
+		}
+		hash, err := sc.GetBlockHash(ctx, r)
+		if err != nil {
+			Logger.Error("reindex client transactions - no block hash",
+				zap.Int64("round", r), zap.Error(err))
+			continue
+		}
+		b, err := sc.GetBlockFromStore(hash, r)
+		if err != nil {
+			Logger.Debug("reindex client transactions - block not stored",
+				zap.Int64("round", r), zap.String("block", hash), zap.Error(err))
+			continue
+		}
+		if err = sc.StoreClientTxns(ctx, b); err != nil {
+			return indexed, err
+		}
+		indexed++
+		if r%1000 == 0 {
+			Logger.Info("reindex client transactions", zap.Int64("round", r),
+				zap.Int64("last_round", lfb.Round), zap.Int64("blocks", indexed))
+		}
+	}
+	return indexed, nil
+}
+
+// ClientTxnsHandler - the transaction history of a client, or a smart
+// contract, newest first and page by page, filtered by round range,
+// transaction type and direction
+func ClientTxnsHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	var (
+		sc       = GetSharderChain()
+		clientID = r.FormValue("client_id")
+		filter   = &ClientTxnFilter{
+			ToRound:   math.MaxInt64 - 1,
+			Direction: r.FormValue("direction"),
+		}
+		limit = defaultClientTxnsLimit
+		err   error
+	)
+	if clientID == "" {
+		return nil, common.InvalidRequest("client id (parameter client_id) is required")
+	}
+	if filter.Direction != "" && filter.Direction != transaction.ClientTxnSent &&
+		filter.Direction != transaction.ClientTxnReceived {
+		return nil, common.InvalidRequest("invalid direction: " + filter.Direction)
+	}
+	if l := r.FormValue("limit"); l != "" {
+		if limit, err = strconv.Atoi(l); err != nil || limit <= 0 {
+			return nil, common.InvalidRequest("invalid limit: " + l)
+		}
+		if limit > maxClientTxnsLimit {
+			limit = maxClientTxnsLimit
+		}
+	}
+	if f := r.FormValue("from_round"); f != "" {
+		if filter.FromRound, err = strconv.ParseInt(f, 10, 64); err != nil || filter.FromRound < 0 {
+			return nil, common.InvalidRequest("invalid from_round: " + f)
+		}
+	}
+	if t := r.FormValue("to_round"); t != "" {
+		if filter.ToRound, err = strconv.ParseInt(t, 10, 64); err != nil ||
+			filter.ToRound < filter.FromRound || filter.ToRound >= math.MaxInt64 {
+			return nil, common.InvalidRequest("invalid to_round: " + t)
+		}
+	}
+	if t := r.FormValue("type"); t != "" {
+		txnType, err := strconv.Atoi(t)
+		if err != nil {
+			return nil, common.InvalidRequest("invalid type: " + t)
+		}
+		filter.Type = &txnType
+	}
+
+	// the transactions of the rounds up to the to round, the empty hash is
+	// before any hash
+	before := ClientTxnCursor{Round: filter.ToRound + 1}
+	if c := r.FormValue("cursor"); c != "" {
+		if before, err = ParseClientTxnCursor(c); err != nil {
+			return nil, err
+		}
+	}
+
+	txns, cursor, err := collectClientTxns(ctx, clientID, before, filter, limit,
+		sc.GetClientTxnsPage)
+	if err != nil {
+		return nil, err
+	}
+	if txns == nil {
+		txns = []*transaction.ClientTxn{}
+	}
+	return &ClientTxnsResponse{Transactions: txns, Cursor: cursor}, nil
+}
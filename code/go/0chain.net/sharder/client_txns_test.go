@@ -0,0 +1,204 @@
+package sharder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/encryption"
+)
+
+// testClientTxns - a page getter of the history of perRound transactions
+// sent by the client in every round from 1 to rounds, alternating types
+func testClientTxns(rounds, perRound int) clientTxnsPageGetter {
+	var history []*transaction.ClientTxn
+	for r := rounds; r >= 1; r-- {
+		var txns []*transaction.ClientTxn
+		for i := 0; i < perRound; i++ {
+			txns = append(txns, &transaction.ClientTxn{
+				ClientID:  "client",
+				Round:     int64(r),
+				TxnHash:   fmt.Sprintf("%02d", i),
+				Direction: transaction.ClientTxnSent,
+				Type:      i % 2,
+			})
+		}
+		sort.Slice(txns, func(i, j int) bool { return txns[i].TxnHash > txns[j].TxnHash })
+		history = append(history, txns...)
+	}
+	before := func(ct *transaction.ClientTxn, c ClientTxnCursor) bool {
+		if ct.Round != c.Round {
+			return ct.Round < c.Round
+		}
+		if ct.TxnHash != c.TxnHash {
+			return ct.TxnHash < c.TxnHash
+		}
+		return ct.Direction < c.Direction
+	}
+	return func(_ context.Context, _ string, from int64, c ClientTxnCursor,
+		n int) ([]*transaction.ClientTxn, error) {
+
+		var page []*transaction.ClientTxn
+		for _, ct := range history {
+			if len(page) == n || ct.Round < from {
+				break
+			}
+			if before(ct, c) {
+				page = append(page, ct)
+			}
+		}
+		return page, nil
+	}
+}
+
+func TestParseClientTxnCursor(t *testing.T) {
+	c, err := ParseClientTxnCursor("10:abc:sent")
+	require.NoError(t, err)
+	assert.Equal(t, ClientTxnCursor{Round: 10, TxnHash: "abc", Direction: "sent"}, c)
+	assert.Equal(t, "10:abc:sent", c.String())
+
+	for _, s := range []string{"", "10", "x:abc:sent", "-1:abc:sent", "10:abc"} {
+		_, err = ParseClientTxnCursor(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestCollectClientTxns(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		getter = testClientTxns(100, 4)
+		odd    = 1
+		start  = ClientTxnCursor{Round: 101}
+	)
+	tests := []struct {
+		name       string
+		before     ClientTxnCursor
+		filter     ClientTxnFilter
+		limit      int
+		wantTxns   int
+		wantFirst  string
+		wantCursor string
+	}{
+		{
+			name:       "first_page",
+			before:     start,
+			limit:      6,
+			wantTxns:   6,
+			wantFirst:  "100:03:sent",
+			wantCursor: "99:02:sent",
+		},
+		{
+			name:       "next_page",
+			before:     ClientTxnCursor{Round: 99, TxnHash: "02", Direction: "sent"},
+			limit:      3,
+			wantTxns:   3,
+			wantFirst:  "99:01:sent",
+			wantCursor: "98:03:sent",
+		},
+		{
+			name:       "to_round",
+			before:     ClientTxnCursor{Round: 51},
+			limit:      1,
+			wantTxns:   1,
+			wantFirst:  "50:03:sent",
+			wantCursor: "50:03:sent",
+		},
+		{
+			name:      "from_round",
+			before:    start,
+			filter:    ClientTxnFilter{FromRound: 99},
+			limit:     20,
+			wantTxns:  8,
+			wantFirst: "100:03:sent",
+		},
+		{
+			name:       "type",
+			before:     start,
+			filter:     ClientTxnFilter{Type: &odd},
+			limit:      4,
+			wantTxns:   4,
+			wantFirst:  "100:03:sent",
+			wantCursor: "99:01:sent",
+		},
+		{
+			name:       "max_pages",
+			before:     start,
+			filter:     ClientTxnFilter{Direction: transaction.ClientTxnReceived},
+			limit:      4,
+			wantCursor: fmt.Sprintf("%v:00:sent", 101-maxClientTxnsPages),
+		},
+		{
+			name:   "end",
+			before: ClientTxnCursor{Round: 1, TxnHash: "00", Direction: "sent"},
+			limit:  4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txns, cursor, err := collectClientTxns(ctx, "client", tt.before,
+				&tt.filter, tt.limit, getter)
+			require.NoError(t, err)
+			require.Len(t, txns, tt.wantTxns)
+			assert.Equal(t, tt.wantCursor, cursor)
+			if tt.wantTxns > 0 {
+				first := ClientTxnCursor{Round: txns[0].Round,
+					TxnHash: txns[0].TxnHash, Direction: txns[0].Direction}
+				assert.Equal(t, tt.wantFirst, first.String())
+			}
+			for _, ct := range txns {
+				assert.True(t, tt.filter.Match(ct))
+			}
+		})
+	}
+}
+
+func TestGetBlockClientTxns(t *testing.T) {
+	transaction.SetupClientTxnEntity(nil)
+
+	var (
+		client = encryption.Hash("client")
+		sc     = encryption.Hash("sc")
+		other  = encryption.Hash("other")
+	)
+	b := block.NewBlock("", 7)
+	send := &transaction.Transaction{ClientID: client, ToClientID: other, Value: 5,
+		TransactionType: transaction.TxnTypeSend, Status: transaction.TxnSuccess}
+	send.Hash = "send"
+	data := &transaction.Transaction{ClientID: client,
+		TransactionType: transaction.TxnTypeData}
+	data.Hash = "data"
+	bundle := &transaction.Transaction{ClientID: client,
+		TransactionType: transaction.TxnTypeBundle}
+	bundle.Hash = "bundle"
+	bundle.TransactionData = (&transaction.Bundle{Operations: []*transaction.BundleOperation{
+		{ToClientID: other, Value: 1, TransactionType: transaction.TxnTypeSend},
+		{ToClientID: sc, Value: 2, TransactionType: transaction.TxnTypeSmartContract},
+		{ToClientID: other, Value: 3, TransactionType: transaction.TxnTypeSend},
+	}}).Encode()
+	b.Txns = append(b.Txns, send, data, bundle)
+
+	type entry struct {
+		client, txn, direction string
+		value                  int64
+	}
+	var got []entry
+	for _, e := range getBlockClientTxns(b) {
+		ct := e.(*transaction.ClientTxn)
+		assert.Equal(t, int64(7), ct.Round)
+		got = append(got, entry{ct.ClientID, ct.TxnHash, ct.Direction, ct.Value})
+	}
+	assert.Equal(t, []entry{
+		{client, "send", transaction.ClientTxnSent, 5},
+		{other, "send", transaction.ClientTxnReceived, 5},
+		{client, "data", transaction.ClientTxnSent, 0},
+		{client, "bundle", transaction.ClientTxnSent, 6},
+		{other, "bundle", transaction.ClientTxnReceived, 4},
+		{sc, "bundle", transaction.ClientTxnReceived, 2},
+	}, got)
+}
@@ -0,0 +1,289 @@
+package sharder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	. "0chain.net/core/logging"
+	"0chain.net/core/persistencestore"
+)
+
+const (
+	// the max number of rounds scanned by an events request before
+	// returning or waiting for more rounds
+	maxEventRounds = 100
+	// the default and the max number of events returned by a request
+	defaultEventsLimit = 100
+	maxEventsLimit     = 1000
+	// the default and the max time an events request waits for new events,
+	// the response has to be written before the 30s write timeout of the
+	// sharder server
+	defaultEventsWait = 20 * time.Second
+	maxEventsWait     = 25 * time.Second
+)
+
+// EventCursor - the position in the events stream of the first event not
+// returned yet, the events of a round are ordered by their index
+type EventCursor struct {
+	Round int64
+	Index int
+}
+
+func (c EventCursor) String() string {
+	return fmt.Sprintf("%v:%v", c.Round, c.Index)
+}
+
+// ParseEventCursor - parse a "round:index" or a "round" cursor
+func ParseEventCursor(s string) (c EventCursor, err error) {
+	parts := strings.SplitN(s, ":", 2)
+	if c.Round, err = strconv.ParseInt(parts[0], 10, 64); err != nil || c.Round < 0 {
+		return c, common.InvalidRequest("invalid cursor: " + s)
+	}
+	if len(parts) == 2 {
+		if c.Index, err = strconv.Atoi(parts[1]); err != nil || c.Index < 0 {
+			return c, common.InvalidRequest("invalid cursor: " + s)
+		}
+	}
+	return c, nil
+}
+
+// EventFilter - the events to return, an empty field matches any value
+type EventFilter struct {
+	Contract string
+	Type     string
+	ClientID string
+	Tag      string
+}
+
+// Match - the event passes the filter
+func (f *EventFilter) Match(e *transaction.Event) bool {
+	return (f.Contract == "" || f.Contract == e.Contract) &&
+		(f.Type == "" || f.Type == e.Type) &&
+		(f.ClientID == "" || f.ClientID == e.ClientID) &&
+		(f.Tag == "" || f.Tag == e.Tag)
+}
+
+// EventsResponse - the events matching a request and the cursor of the
+// next request
+type EventsResponse struct {
+	Events []*transaction.Event `json:"events"`
+	Cursor string               `json:"cursor"`
+}
+
+type roundEventsGetter func(ctx context.Context, round int64) ([]*transaction.Event, error)
+
+// collectEvents - the events from the cursor up to the last round matching
+// the filter, at most limit of them and from at most maxEventRounds rounds,
+// with the cursor right after the last scanned event; the scan stops with
+// the events collected when the context is done
+func collectEvents(ctx context.Context, cursor EventCursor, last int64,
+	filter *EventFilter, limit int, getEvents roundEventsGetter) (
+	[]*transaction.Event, EventCursor, error) {
+
+	var (
+		events []*transaction.Event
+		start  = cursor.Round
+	)
+	for r := start; r <= last && r < start+maxEventRounds; r++ {
+		if ctx.Err() != nil {
+			break
+		}
+		res, err := getEvents(ctx, r)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return nil, cursor, err
+		}
+		from := 0
+		if r == cursor.Round {
+			from = cursor.Index
+		}
+		for _, e := range res {
+			if e.Index < from || !filter.Match(e) {
+				continue
+			}
+			events = append(events, e)
+			if len(events) == limit {
+				return events, EventCursor{Round: r, Index: e.Index + 1}, nil
+			}
+		}
+		cursor = EventCursor{Round: r + 1}
+	}
+	return events, cursor, nil
+}
+
+// eventsNotifier - wakes up the requests waiting for new events when the
+// events of a finalized round are stored; the rounds repaired or synced
+// later don't move it
+type eventsNotifier struct {
+	mutex sync.Mutex
+	ch    chan struct{}
+	round int64
+}
+
+var eventsNotify = &eventsNotifier{ch: make(chan struct{})}
+
+func (en *eventsNotifier) wait() (<-chan struct{}, int64) {
+	en.mutex.Lock()
+	defer en.mutex.Unlock()
+	return en.ch, en.round
+}
+
+func (en *eventsNotifier) notify(round int64) {
+	en.mutex.Lock()
+	defer en.mutex.Unlock()
+	if round > en.round {
+		en.round = round
+	}
+	close(en.ch)
+	en.ch = make(chan struct{})
+}
+
+// getBlockEvents - the events of the logs of the receipts of the block
+func getBlockEvents(b *block.Block) []datastore.Entity {
+	if b.ReceiptsVersion == 0 || len(b.Receipts) != len(b.Txns) {
+		return nil
+	}
+	var events []datastore.Entity
+	for idx, r := range b.Receipts {
+		for _, l := range r.Logs {
+			events = append(events, transaction.NewEvent(b.Round, b.Hash, len(events), b.Txns[idx], l))
+		}
+	}
+	return events
+}
+
+// StoreEvents - persists the events of the block
+func (sc *Chain) StoreEvents(ctx context.Context, b *block.Block) error {
+	events := getBlockEvents(b)
+	if len(events) == 0 {
+		return nil
+	}
+	eventMetadata := datastore.GetEntityMetadata("txn_event")
+	ectx := persistencestore.WithEntityConnection(ctx, eventMetadata)
+	defer persistencestore.Close(ectx)
+	return eventMetadata.GetStore().MultiWrite(ectx, eventMetadata, events)
+}
+
+// GetRoundEvents - the stored events of the round ordered by index
+func (sc *Chain) GetRoundEvents(ctx context.Context, round int64) ([]*transaction.Event, error) {
+	eventMetadata := datastore.GetEntityMetadata("txn_event")
+	ectx := persistencestore.WithEntityConnection(ctx, eventMetadata)
+	defer persistencestore.Close(ectx)
+	c := persistencestore.GetCon(ectx)
+	iter := c.Query(fmt.Sprintf("SELECT JSON * FROM %v WHERE round = ?", eventMetadata.GetName()), round).Iter()
+	var (
+		json   string
+		events []*transaction.Event
+	)
+	for iter.Scan(&json) {
+		e := eventMetadata.Instance().(*transaction.Event)
+		if err := datastore.FromJSON(json, e); err != nil {
+			Logger.Error("get round events", zap.Int64("round", round), zap.Error(err))
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// EventsHandler - a long-poll handler to stream the smart contract events
+// from the cursor, it waits for new events when there are none to return
+func EventsHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	var (
+		sc     = GetSharderChain()
+		filter = &EventFilter{
+			Contract: r.FormValue("contract"),
+			Type:     r.FormValue("type"),
+			ClientID: r.FormValue("client_id"),
+			Tag:      r.FormValue("tag"),
+		}
+		limit  = defaultEventsLimit
+		wait   = defaultEventsWait
+		cursor EventCursor
+		err    error
+	)
+	if l := r.FormValue("limit"); l != "" {
+		if limit, err = strconv.Atoi(l); err != nil || limit <= 0 {
+			return nil, common.InvalidRequest("invalid limit: " + l)
+		}
+		if limit > maxEventsLimit {
+			limit = maxEventsLimit
+		}
+	}
+	if w := r.FormValue("wait"); w != "" {
+		secs, err := strconv.Atoi(w)
+		if err != nil || secs < 0 {
+			return nil, common.InvalidRequest("invalid wait: " + w)
+		}
+		if wait = time.Duration(secs) * time.Second; wait > maxEventsWait {
+			wait = maxEventsWait
+		}
+	}
+
+	// the rounds with events stored, the latest finalized round until the
+	// events of a round are stored after a restart
+	var storedRound = func() (<-chan struct{}, int64) {
+		ch, round := eventsNotify.wait()
+		if lfb := sc.GetLatestFinalizedBlock(); round == 0 && lfb != nil {
+			round = lfb.Round
+		}
+		return ch, round
+	}
+
+	if c := r.FormValue("cursor"); c != "" {
+		if cursor, err = ParseEventCursor(c); err != nil {
+			return nil, err
+		}
+	} else {
+		// only the new events without a cursor
+		_, round := storedRound()
+		cursor = EventCursor{Round: round + 1}
+	}
+
+	// the scan of the stored rounds counts in the wait
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	for {
+		ch, last := storedRound()
+		events, next, err := collectEvents(waitCtx, cursor, last, filter, limit, sc.GetRoundEvents)
+		if err != nil {
+			return nil, err
+		}
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		cursor = next
+		// a cursor behind the stored rounds gets a batch of rounds scanned
+		// per request, with the events found if any
+		if len(events) > 0 || cursor.Round <= last || waitCtx.Err() != nil {
+			if events == nil {
+				events = []*transaction.Event{}
+			}
+			return &EventsResponse{Events: events, Cursor: cursor.String()}, nil
+		}
+		select {
+		case <-ch:
+		case <-waitCtx.Done():
+			if err = ctx.Err(); err != nil {
+				return nil, err
+			}
+			return &EventsResponse{Events: []*transaction.Event{}, Cursor: cursor.String()}, nil
+		}
+	}
+}
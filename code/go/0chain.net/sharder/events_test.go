@@ -0,0 +1,183 @@
+package sharder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+)
+
+func testRoundEvents(rounds, perRound int) roundEventsGetter {
+	types := []string{"lock", "unlock"}
+	return func(_ context.Context, round int64) ([]*transaction.Event, error) {
+		if round < 1 || round > int64(rounds) {
+			return nil, nil
+		}
+		events := make([]*transaction.Event, perRound)
+		for i := range events {
+			events[i] = &transaction.Event{
+				Round:    round,
+				Index:    i,
+				Contract: "sc",
+				ClientID: "client",
+				Type:     types[i%len(types)],
+			}
+		}
+		return events, nil
+	}
+}
+
+func TestParseEventCursor(t *testing.T) {
+	c, err := ParseEventCursor("10:3")
+	require.NoError(t, err)
+	assert.Equal(t, EventCursor{Round: 10, Index: 3}, c)
+	assert.Equal(t, "10:3", c.String())
+
+	c, err = ParseEventCursor("10")
+	require.NoError(t, err)
+	assert.Equal(t, EventCursor{Round: 10}, c)
+
+	for _, s := range []string{"", "x", "-1", "10:x", "10:-1"} {
+		_, err = ParseEventCursor(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestCollectEvents(t *testing.T) {
+	ctx := context.Background()
+	getter := testRoundEvents(300, 4)
+
+	tests := []struct {
+		name       string
+		cursor     EventCursor
+		last       int64
+		filter     EventFilter
+		limit      int
+		wantEvents int
+		wantCursor EventCursor
+	}{
+		{
+			name:       "all",
+			cursor:     EventCursor{Round: 1},
+			last:       3,
+			limit:      100,
+			wantEvents: 12,
+			wantCursor: EventCursor{Round: 4},
+		},
+		{
+			name:       "limit",
+			cursor:     EventCursor{Round: 1},
+			last:       3,
+			limit:      5,
+			wantEvents: 5,
+			wantCursor: EventCursor{Round: 2, Index: 1},
+		},
+		{
+			name:       "from_index",
+			cursor:     EventCursor{Round: 2, Index: 1},
+			last:       3,
+			limit:      100,
+			wantEvents: 7,
+			wantCursor: EventCursor{Round: 4},
+		},
+		{
+			name:       "filter",
+			cursor:     EventCursor{Round: 1},
+			last:       3,
+			filter:     EventFilter{Type: "unlock", ClientID: "client"},
+			limit:      100,
+			wantEvents: 6,
+			wantCursor: EventCursor{Round: 4},
+		},
+		{
+			name:       "no_match",
+			cursor:     EventCursor{Round: 1},
+			last:       3,
+			filter:     EventFilter{Contract: "other"},
+			limit:      100,
+			wantCursor: EventCursor{Round: 4},
+		},
+		{
+			name:       "max_rounds",
+			cursor:     EventCursor{Round: 1},
+			last:       300,
+			filter:     EventFilter{Contract: "other"},
+			limit:      100,
+			wantCursor: EventCursor{Round: 1 + maxEventRounds},
+		},
+		{
+			name:       "ahead",
+			cursor:     EventCursor{Round: 5},
+			last:       3,
+			limit:      100,
+			wantCursor: EventCursor{Round: 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, cursor, err := collectEvents(ctx, tt.cursor, tt.last, &tt.filter, tt.limit, getter)
+			require.NoError(t, err)
+			assert.Len(t, events, tt.wantEvents)
+			assert.Equal(t, tt.wantCursor, cursor)
+			for _, e := range events {
+				assert.True(t, tt.filter.Match(e))
+			}
+		})
+	}
+
+	// the scan stops with the events collected when the context is done
+	cctx, cancel := context.WithCancel(ctx)
+	stopping := func(ctx context.Context, round int64) ([]*transaction.Event, error) {
+		if round == 2 {
+			cancel()
+			return nil, ctx.Err()
+		}
+		return getter(ctx, round)
+	}
+	events, cursor, err := collectEvents(cctx, EventCursor{Round: 1}, 3, &EventFilter{}, 100, stopping)
+	require.NoError(t, err)
+	assert.Len(t, events, 4)
+	assert.Equal(t, EventCursor{Round: 2}, cursor)
+}
+
+func TestGetBlockEvents(t *testing.T) {
+	transaction.SetupEventEntity(nil)
+
+	b := block.NewBlock("", 7)
+	b.Hash = "block"
+	for i, logs := range []int{2, 0, 1} {
+		txn := &transaction.Transaction{ClientID: "client", ToClientID: "sc"}
+		txn.Hash = string(rune('a' + i))
+		r := &transaction.TxnReceipt{TxnHash: txn.Hash}
+		for j := 0; j < logs; j++ {
+			r.Logs = append(r.Logs, &transaction.Log{Type: "test"})
+		}
+		if i == 2 {
+			// emitted by an operation of a bundle
+			r.Logs[0].Contract = "op"
+		}
+		b.Txns = append(b.Txns, txn)
+		b.Receipts = append(b.Receipts, r)
+	}
+	assert.Empty(t, getBlockEvents(b), "no events without the receipts version")
+
+	b.ReceiptsVersion = block.ReceiptsVersion
+	events := getBlockEvents(b)
+	require.Len(t, events, 3)
+	for i, want := range []string{"a", "a", "c"} {
+		e := events[i].(*transaction.Event)
+		assert.Equal(t, int64(7), e.Round)
+		assert.Equal(t, i, e.Index)
+		assert.Equal(t, want, e.TxnHash)
+		if want == "c" {
+			assert.Equal(t, "op", e.Contract)
+		} else {
+			assert.Equal(t, "sc", e.Contract)
+		}
+		assert.Equal(t, "client", e.ClientID)
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"0chain.net/chaincore/chain"
 	"0chain.net/chaincore/diagnostics"
 	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/persistencestore"
 )
 
 /* SetupHandlers sets up the necessary API end points */
@@ -22,9 +24,13 @@ func SetupHandlers() {
 	http.HandleFunc("/v1/block/get", common.UserRateLimit(common.ToJSONResponse(BlockHandler)))
 	http.HandleFunc("/v1/block/magic/get", common.UserRateLimit(common.ToJSONResponse(MagicBlockHandler)))
 	http.HandleFunc("/v1/transaction/get/confirmation", common.UserRateLimit(common.ToJSONResponse(TransactionConfirmationHandler)))
+	http.HandleFunc("/v1/transaction/get/receipt", common.UserRateLimit(common.ToJSONResponse(TransactionReceiptHandler)))
+	http.HandleFunc("/v1/events", common.UserRateLimit(common.ToJSONResponse(EventsHandler)))
+	http.HandleFunc("/v1/client/transactions", common.UserRateLimit(common.ToJSONResponse(ClientTxnsHandler)))
 	http.HandleFunc("/v1/chain/get/stats", common.UserRateLimit(common.ToJSONResponse(ChainStatsHandler)))
 	http.HandleFunc("/_chain_stats", common.UserRateLimit(ChainStatsWriter))
 	http.HandleFunc("/_health_check", common.UserRateLimit(HealthCheckWriter))
+	http.HandleFunc("/_audit", common.LocalhostOnly(common.ToJSONResponse(AuditHandler)))
 	http.HandleFunc("/v1/sharder/get/stats", common.UserRateLimit(common.ToJSONResponse(SharderStatsHandler)))
 }
 
@@ -104,6 +110,18 @@ func MagicBlockHandler(ctx context.Context, r *http.Request) (interface{}, error
 	return b, nil
 }
 
+/*TransactionReceiptHandler - a handler to respond to transaction receipt queries with the receipt Merkle path */
+func TransactionReceiptHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	hash := r.FormValue("hash")
+	if hash == "" {
+		return nil, common.InvalidRequest("transaction hash (parameter hash) is required")
+	}
+	txnSummaryEntityMetadata := datastore.GetEntityMetadata("txn_summary")
+	ctx = persistencestore.WithEntityConnection(ctx, txnSummaryEntityMetadata)
+	defer persistencestore.Close(ctx)
+	return GetSharderChain().GetTransactionReceipt(ctx, hash)
+}
+
 /*ChainStatsHandler - a handler to provide block statistics */
 func ChainStatsHandler(ctx context.Context, r *http.Request) (interface{}, error) {
 	c := GetSharderChain().Chain
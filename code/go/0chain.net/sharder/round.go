@@ -115,11 +115,11 @@ func (sc *Chain) GetMostRecentRoundFromDB(ctx context.Context) (*round.Round, er
 	defer ememorystore.Close(rctx)
 	c := ememorystore.GetEntityCon(rctx, remd)
 	r := remd.Instance().(*round.Round)
-	iterator := c.Conn.NewIterator(c.ReadOptions)
+	iterator := c.Conn.NewIterator()
 	defer iterator.Close()
 	iterator.SeekToLast()
 	if iterator.Valid() {
-		datastore.FromJSON(iterator.Value().Data(), r)
+		datastore.FromJSON(iterator.Value(), r)
 	}
 	return r, iterator.Err()
 }
@@ -3,10 +3,12 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	_ "net/http/pprof"
@@ -24,12 +26,14 @@ import (
 	"0chain.net/chaincore/diagnostics"
 	"0chain.net/chaincore/node"
 	"0chain.net/chaincore/round"
+	"0chain.net/chaincore/snapshot"
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/build"
 	"0chain.net/core/common"
 	"0chain.net/core/ememorystore"
 	"0chain.net/core/encryption"
+	"0chain.net/core/kvdb"
 	"0chain.net/core/logging"
 	. "0chain.net/core/logging"
 	"0chain.net/core/memorystore"
@@ -89,6 +93,10 @@ func main() {
 	minioFile := flag.String("minio_file", "", "minio_file")
 	initialStatesFile := flag.String("initial_states", "", "initial_states")
 	flag.String("nodes_file", "", "nodes_file (deprecated)")
+	reindexClientTxns := flag.Int64("reindex_client_txns", -1, "re-index the client transactions history from the round up to the latest finalized round")
+	audit := flag.Int64("audit", -1, "audit the stored blocks from the round up to the latest finalized round")
+	auditRepair := flag.Bool("audit_repair", false, "repair the rounds failing the audit from the other sharders")
+	auditReport := flag.String("audit_report", "", "the file of the JSON report of the audit, logged if empty")
 	flag.Parse()
 	config.Configuration.DeploymentMode = byte(*deploymentMode)
 	config.SetupDefaultConfig()
@@ -120,6 +128,12 @@ func main() {
 		panic(err)
 	}
 
+	if backend := viper.GetString("server_chain.db.backend"); backend != "" {
+		if err := kvdb.SetDefaultBackend(backend); err != nil {
+			logging.Logger.Panic("database backend", zap.Error(err))
+		}
+	}
+
 	config.SetServerChainID(config.Configuration.ChainID)
 	common.SetupRootContext(node.GetNodeContext())
 	ctx := common.GetRootContext()
@@ -137,6 +151,18 @@ func main() {
 	sc := sharder.GetSharderChain()
 	sc.SetupConfigInfoDB()
 	sc.SetSyncStateTimeout(viper.GetDuration("server_chain.state.sync.timeout") * time.Second)
+	sc.SetStateArchive(viper.GetBool("server_chain.state.archive"))
+	if err := sc.SetupStateSnapshots(chain.StateSnapshotConfig{
+		Dir:          "data/snapshots",
+		Interval:     viper.GetInt64("server_chain.state.snapshot.interval"),
+		Keep:         viper.GetInt("server_chain.state.snapshot.keep"),
+		ChunkSize:    viper.GetInt("server_chain.state.snapshot.chunk_size"),
+		Sync:         viper.GetBool("server_chain.state.snapshot.sync"),
+		SyncParallel: viper.GetInt("server_chain.state.snapshot.sync_parallel"),
+		SyncTimeout:  viper.GetDuration("server_chain.state.snapshot.sync_timeout") * time.Second,
+	}); err != nil {
+		logging.Logger.Panic("state snapshots", zap.Error(err))
+	}
 	sc.SetBCStuckCheckInterval(viper.GetDuration("server_chain.stuck.check_interval") * time.Second)
 	sc.SetBCStuckTimeThreshold(viper.GetDuration("server_chain.stuck.time_threshold") * time.Second)
 	chain.SetServerChain(serverChain)
@@ -278,6 +304,21 @@ func main() {
 		go sc.SetupSC(ctx)
 	}
 
+	if *reindexClientTxns >= 0 {
+		go func() {
+			indexed, err := sc.ReindexClientTxns(ctx, *reindexClientTxns)
+			if err != nil {
+				Logger.Error("reindex client transactions", zap.Int64("blocks", indexed), zap.Error(err))
+				return
+			}
+			Logger.Info("reindex client transactions done", zap.Int64("blocks", indexed))
+		}()
+	}
+
+	if *audit >= 0 {
+		go runAudit(ctx, sc, *audit, *auditRepair, *auditReport)
+	}
+
 	// Do a deep scan from finalized block till DeepWindow
 	go sc.HealthCheckWorker(ctx, sharder.DeepScan) // 4) progressively checks the health for each round
 
@@ -405,6 +446,7 @@ func initEntities() {
 	block.SetupStateChange(memoryStorage)
 	state.SetupPartialState(memoryStorage)
 	state.SetupStateNodes(memoryStorage)
+	snapshot.SetupEntity(memoryStorage)
 	round.SetupEntity(ememoryStorage)
 	client.SetupEntity(memoryStorage)
 	transaction.SetupEntity(memoryStorage)
@@ -412,6 +454,8 @@ func initEntities() {
 	persistencestore.InitSession()
 	persistenceStorage := persistencestore.GetStorageProvider()
 	transaction.SetupTxnSummaryEntity(persistenceStorage)
+	transaction.SetupEventEntity(persistenceStorage)
+	transaction.SetupClientTxnEntity(persistenceStorage)
 	transaction.SetupTxnConfirmationEntity(persistenceStorage)
 	block.SetupMagicBlockMapEntity(persistenceStorage)
 
@@ -465,6 +509,21 @@ func setupBlockStorageProvider(mConf blockstore.MinioConfiguration) {
 		}
 	}
 
+	for _, file := range viper.GetStringSlice("server_chain.block.storage.dictionaries") {
+		dict, err := ioutil.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+		if _, err = blockstore.AddBlockDictionary(dict); err != nil {
+			panic(err)
+		}
+	}
+	if codec := viper.GetString("server_chain.block.storage.codec"); codec != "" {
+		if err := blockstore.SetBlockCodec(codec); err != nil {
+			panic(err)
+		}
+	}
+
 	fsbs := blockstore.NewFSBlockStore("data/blocks", mClient)
 	blockStorageProvider := viper.GetString("server_chain.block.storage.provider")
 	switch blockStorageProvider {
@@ -480,7 +539,71 @@ func setupBlockStorageProvider(mConf blockstore.MinioConfiguration) {
 			),
 		}
 		blockstore.SetupStore(blockstore.NewMultiBlockStore(bs))
+	case "blockstore.SegmentBlockStore":
+		sbs := blockstore.NewSegmentBlockStore("data/segments",
+			viper.GetInt64("server_chain.block.storage.segment.rounds"))
+		if interval := viper.GetDuration("server_chain.block.storage.segment.compaction_interval"); interval > 0 {
+			go sbs.CompactionWorker(common.GetRootContext(), interval,
+				viper.GetFloat64("server_chain.block.storage.segment.compaction_ratio"))
+		}
+		blockstore.SetupStore(sbs)
+	case "blockstore.TieredBlockStore":
+		var (
+			warm *blockstore.SegmentBlockStore
+			cold blockstore.ObjectStore
+		)
+		if viper.GetInt64("server_chain.block.storage.tiering.warm_rounds") > 0 {
+			warm = blockstore.NewSegmentBlockStore("data/segments",
+				viper.GetInt64("server_chain.block.storage.segment.rounds"))
+			if interval := viper.GetDuration("server_chain.block.storage.segment.compaction_interval"); interval > 0 {
+				go warm.CompactionWorker(common.GetRootContext(), interval,
+					viper.GetFloat64("server_chain.block.storage.segment.compaction_ratio"))
+			}
+		}
+		if viper.GetBool("minio.enabled") {
+			if cold, err = blockstore.NewMinioObjectStore(mConf); err != nil {
+				panic(err)
+			}
+		}
+		tbs, err := blockstore.NewTieredBlockStore(fsbs, warm, cold, blockstore.TieringPolicy{
+			HotRounds:       viper.GetInt64("server_chain.block.storage.tiering.hot_rounds"),
+			WarmRounds:      viper.GetInt64("server_chain.block.storage.tiering.warm_rounds"),
+			RetentionRounds: viper.GetInt64("server_chain.block.storage.tiering.retention_rounds"),
+			MoveInterval:    viper.GetDuration("server_chain.block.storage.tiering.move_interval"),
+			MoveRate:        viper.GetInt("server_chain.block.storage.tiering.move_rate"),
+			CacheSize:       viper.GetInt("server_chain.block.storage.tiering.cache_size"),
+		})
+		if err != nil {
+			panic(err)
+		}
+		blockstore.SetupStore(tbs)
 	default:
 		panic(fmt.Sprintf("uknown block store provider - %v", blockStorageProvider))
 	}
 }
+
+// runAudit - audit the stored blocks from the round up to the latest
+// finalized round and write the report to the file, or log it
+func runAudit(ctx context.Context, sc *sharder.Chain, from int64, repair bool, file string) {
+	lfb := sc.GetLatestFinalizedBlock()
+	if lfb == nil {
+		Logger.Error("audit - no latest finalized block")
+		return
+	}
+	report := sc.Audit(ctx, from, lfb.Round, repair)
+	Logger.Info("audit done", zap.Int64("from_round", report.FromRound), zap.Int64("to_round", report.ToRound),
+		zap.Int64("repaired", report.Repaired), zap.Int64("unrepaired", report.Unrepaired),
+		zap.String("error", report.Error))
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		Logger.Error("audit report", zap.Error(err))
+		return
+	}
+	if file == "" {
+		Logger.Info("audit report", zap.ByteString("report", data))
+		return
+	}
+	if err = ioutil.WriteFile(file, data, 0644); err != nil {
+		Logger.Error("audit report", zap.String("file", file), zap.Error(err))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"0chain.net/chaincore/diagnostics"
+	"0chain.net/sharder/blockstore"
 	"github.com/rcrowley/go-metrics"
 )
 
@@ -231,5 +232,15 @@ func (sc *Chain) WriteMinioStats(w http.ResponseWriter) {
 	fmt.Fprintf(w, "<tr><td>Total Rounds processed</td><td>%d</td></tr>", sc.TieringStats.TotalBlocksUploaded)
 	fmt.Fprintf(w, "<tr><td>Last Round processed</td><td>%d</td></tr>", sc.TieringStats.LastRoundUploaded)
 	fmt.Fprintf(w, "<tr><td>Last Upload time</td class='string'><td>%v</td></tr>", sc.TieringStats.LastUploadTime.Format(HealthCheckDateTimeFormat))
+	if tbs, ok := blockstore.GetStore().(*blockstore.TieredBlockStore); ok {
+		ts := tbs.GetStats()
+		fmt.Fprintf(w, "<tr><th class='sheader' colspan='2'>Block Tiers</th></tr>")
+		fmt.Fprintf(w, "<tr><td>Hot blocks / bytes</td><td>%d / %d</td></tr>", ts.HotBlocks, ts.HotSize)
+		fmt.Fprintf(w, "<tr><td>Warm blocks / bytes</td><td>%d / %d</td></tr>", ts.WarmBlocks, ts.WarmSize)
+		fmt.Fprintf(w, "<tr><td>Cold blocks / bytes</td><td>%d / %d</td></tr>", ts.ColdBlocks, ts.ColdSize)
+		fmt.Fprintf(w, "<tr><td>Moved / deleted blocks</td><td>%d / %d</td></tr>", ts.Moved, ts.Deleted)
+		fmt.Fprintf(w, "<tr><td>Cache hits / misses</td><td>%d / %d</td></tr>", ts.CacheHits, ts.CacheMisses)
+		fmt.Fprintf(w, "<tr><td>Last move time</td><td class='string'>%v</td></tr>", ts.LastMove.Format(HealthCheckDateTimeFormat))
+	}
 	fmt.Fprintf(w, "</table>")
 }
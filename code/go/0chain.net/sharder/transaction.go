@@ -90,11 +90,59 @@ func (sc *Chain) GetTransactionConfirmation(ctx context.Context, hash string) (*
 	confirmation.MerkleTreePath = mt.GetPath(confirmation)
 	rmt := b.GetReceiptsMerkleTree()
 	confirmation.ReceiptMerkleTreeRoot = rmt.GetRoot()
-	confirmation.ReceiptMerkleTreePath = rmt.GetPath(transaction.NewTransactionReceipt(txn))
+	confirmation.ReceiptMerkleTreePath = b.GetReceiptPath(txn)
 	confirmation.PreviousBlockHash = b.PrevHash
 	return confirmation, nil
 }
 
+/*GetTransactionReceipt - given a transaction return its receipt with the proof of the receipt's presence in the block */
+func (sc *Chain) GetTransactionReceipt(ctx context.Context, hash string) (*transaction.ReceiptConfirmation, error) {
+	var ts *transaction.TransactionSummary
+	t, err := sc.BlockTxnCache.Get(hash)
+	if err != nil {
+		ts, err = sc.GetTransactionSummary(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ts = t.(*transaction.TransactionSummary)
+	}
+	bhash, err := sc.GetBlockHash(ctx, ts.Round)
+	if err != nil {
+		return nil, err
+	}
+
+	var b *block.Block
+	bc, err := sc.BlockCache.Get(bhash)
+	if err != nil {
+		bSummaryEntityMetadata := datastore.GetEntityMetadata("block_summary")
+		bctx := ememorystore.WithEntityConnection(ctx, bSummaryEntityMetadata)
+		defer ememorystore.Close(bctx)
+		bs, err := sc.GetBlockSummary(bctx, bhash)
+		if err != nil {
+			return nil, err
+		}
+		if b, err = sc.GetBlockBySummary(ctx, bs); err != nil {
+			return nil, err
+		}
+	} else {
+		b = bc.(*block.Block)
+	}
+
+	r, path, err := b.GetReceipt(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &transaction.ReceiptConfirmation{
+		Hash:                  hash,
+		BlockHash:             b.Hash,
+		Round:                 b.Round,
+		Receipt:               r,
+		ReceiptMerkleTreeRoot: b.GetReceiptsMerkleTree().GetRoot(),
+		ReceiptMerkleTreePath: path,
+	}, nil
+}
+
 /*StoreTransactions - persists given list of transactions*/
 func (sc *Chain) StoreTransactions(ctx context.Context, b *block.Block) error {
 	var sTxns = make([]datastore.Entity, len(b.Txns))
@@ -119,6 +167,12 @@ func (sc *Chain) StoreTransactions(ctx context.Context, b *block.Block) error {
 			break
 		}
 	}
+	if err := sc.StoreEvents(ctx, b); err != nil {
+		Logger.Error("save events error", zap.Any("round", b.Round), zap.String("block", b.Hash), zap.Error(err))
+	}
+	if err := sc.StoreClientTxns(ctx, b); err != nil {
+		Logger.Error("save client transactions error", zap.Any("round", b.Round), zap.String("block", b.Hash), zap.Error(err))
+	}
 	duration := time.Since(ts)
 	txnSaveTimer.UpdateSince(ts)
 	p95 := txnSaveTimer.Percentile(.95)
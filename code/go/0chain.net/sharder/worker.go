@@ -40,8 +40,10 @@ func SetupWorkers(ctx context.Context) {
 		sc.MagicBlockStorage)
 	go sc.UpdateMagicBlockWorker(ctx)
 	go sc.RegisterSharderKeepWorker(ctx)
-	// Move old blocks to cloud
-	if viper.GetBool("minio.enabled") {
+	// Move old blocks to cloud, or between the tiers of the tiered store
+	if tbs, ok := blockstore.GetStore().(*blockstore.TieredBlockStore); ok {
+		go tbs.MoveWorker(ctx, sc.GetBlockHash)
+	} else if viper.GetBool("minio.enabled") {
 		go sc.MinioWorker(ctx)
 	}
 
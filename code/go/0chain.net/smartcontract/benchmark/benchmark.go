@@ -1,11 +1,14 @@
 package benchmark
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
 	"0chain.net/chaincore/chain/state"
+	sci "0chain.net/chaincore/smartcontractinterface"
 	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
 	"0chain.net/core/encryption"
 )
 
@@ -91,6 +94,7 @@ const (
 	FaucetSc       = "faucetsc."
 	InterestPoolSC = "interestpoolsc."
 	VestingSc      = "vestingsc."
+	Parallel       = "parallel."
 
 	Fas = "free_allocation_settings."
 
@@ -109,6 +113,13 @@ const (
 	OptionTestSuites   = Options + "test_suites"
 	OptionOmittedTests = Options + "omitted_tests"
 
+	ParallelBlocks        = Parallel + "blocks"
+	ParallelBlockSize     = Parallel + "block_size"
+	ParallelConflictRatio = Parallel + "conflict_ratio"
+	ParallelWorkers       = Parallel + "workers"
+	ParallelSeed          = Parallel + "seed"
+	ParallelMix           = Parallel + "mix."
+
 	MinerMaxDelegates = SmartContract + MinerSc + "max_delegates"
 	MinerMaxCharge    = SmartContract + MinerSc + "max_charge"
 	MinerMinStake     = SmartContract + MinerSc + "min_stake"
@@ -212,6 +223,45 @@ type BenchTestI interface {
 	Run(state.StateContextI, *testing.B)
 }
 
+// BlockTxn - a kind of transactions making up the mixed blocks of the
+// parallel execution benchmark. Build returns a transaction of the kind sent
+// by the given client and touching the fixtures of the client only, so that
+// the transactions built for different clients conflict on the smart
+// contract accounts and configurations at most.
+type BlockTxn struct {
+	Name  string
+	Build func(data BenchData, client int) *transaction.Transaction
+}
+
+// SmartContractTransaction - a transaction calling the given function of
+// the smart contract with the JSON encoded input
+func SmartContractTransaction(
+	clientID, scAddress, funcName string,
+	value int64,
+	creationDate common.Timestamp,
+	input interface{},
+) *transaction.Transaction {
+	inputBytes, err := json.Marshal(input)
+	if err != nil {
+		panic(err)
+	}
+	data, err := json.Marshal(&sci.SmartContractTransactionData{
+		FunctionName: funcName,
+		InputData:    inputBytes,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &transaction.Transaction{
+		ClientID:        clientID,
+		ToClientID:      scAddress,
+		Value:           value,
+		CreationDate:    creationDate,
+		TransactionData: string(data),
+		TransactionType: transaction.TxnTypeSmartContract,
+	}
+}
+
 type SignatureScheme interface {
 	encryption.SignatureScheme
 	SetPrivateKey(privateKey string)
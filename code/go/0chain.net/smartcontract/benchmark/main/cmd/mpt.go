@@ -42,9 +42,9 @@ func extractMpt(mpt *util.MerklePatriciaTrie, root util.Key) *util.MerklePatrici
 
 func getBalances(
 	txn *transaction.Transaction,
-	mpt *util.MerklePatriciaTrie,
+	mpt util.MerklePatriciaTrieI,
 	data benchmark.BenchData,
-) (*util.MerklePatriciaTrie, cstate.StateContextI) {
+) (util.MerklePatriciaTrieI, cstate.StateContextI) {
 	bk := &block.Block{
 		MagicBlock: &block.MagicBlock{
 			StartingRound: 0,
@@ -87,6 +87,8 @@ func setUpMpt(
 	log.Println("added clients")
 	faucetsc.FundMockFaucetSmartContract(pMpt)
 	log.Println("funded faucet")
+	vestingsc.FundMockVestingSmartContract(pMpt)
+	log.Println("funded vesting")
 	pMpt.GetNodeDB().(*util.PNodeDB).TrackDBVersion(1)
 
 	bk := &block.Block{}
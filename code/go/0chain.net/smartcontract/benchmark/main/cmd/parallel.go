@@ -0,0 +1,411 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/chain"
+	"0chain.net/chaincore/smartcontract"
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+	bk "0chain.net/smartcontract/benchmark"
+	"0chain.net/smartcontract/benchmark/main/cmd/log"
+	"0chain.net/smartcontract/minersc"
+	"0chain.net/smartcontract/storagesc"
+	"0chain.net/smartcontract/vestingsc"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// the sources of the transactions of the mixed blocks
+var blockTxnSources = []func() []bk.BlockTxn{
+	transferBlockTxns,
+	storagesc.BenchmarkBlockTxns,
+	vestingsc.BenchmarkBlockTxns,
+}
+
+// the smart contracts the mixed blocks call
+var blockSmartContracts = []func() sci.SmartContractInterface{
+	storagesc.NewStorageSmartContract,
+	vestingsc.NewVestingSmartContract,
+}
+
+func init() {
+	parallelCmd.Flags().Int("blocks", 0, "number of blocks")
+	parallelCmd.Flags().Int("block_size", 0, "number of transactions in a block")
+	parallelCmd.Flags().Float64("conflict_ratio", 0, "share of transactions sent by the same client")
+	parallelCmd.Flags().Int("workers", 0, "max number of transactions executed at a time, 0 is the number of CPUs")
+	parallelCmd.Flags().Int64("seed", 0, "random seed of the blocks")
+	rootCmd.AddCommand(parallelCmd)
+}
+
+var parallelCmd = &cobra.Command{
+	Use:   "parallel",
+	Short: "Benchmark parallel execution of mixed blocks",
+	Long: `Benchmark parallel execution of mixed blocks of transfers, storage,
+stake and vesting transactions built on the benchmark blockchain. Each block
+is applied sequentially and by the parallel executors, the throughputs and
+the final state roots are compared.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		GetViper("testdata/benchmark.yaml")
+		log.PrintSimSettings()
+
+		opts := setupParallelOptions(cmd.Flags())
+		log.Println("read in command line options")
+
+		mpt, root, data := setUpMpt("db")
+		log.Println("finished setting up blockchain")
+
+		results, err := runParallel(opts, mpt, root, data)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		printParallelResults(results)
+	},
+}
+
+type parallelOptions struct {
+	blocks        int
+	blockSize     int
+	conflictRatio float64
+	workers       int
+	seed          int64
+}
+
+func setupParallelOptions(flags *pflag.FlagSet) parallelOptions {
+	verbose := viper.GetBool(bk.OptionVerbose)
+	if flags.Changed("verbose") {
+		var err error
+		if verbose, err = flags.GetBool("verbose"); err != nil {
+			log.Fatal(err)
+		}
+	}
+	log.SetVerbose(verbose)
+
+	var (
+		opts = parallelOptions{
+			blocks:        viper.GetInt(bk.ParallelBlocks),
+			blockSize:     viper.GetInt(bk.ParallelBlockSize),
+			conflictRatio: viper.GetFloat64(bk.ParallelConflictRatio),
+			workers:       viper.GetInt(bk.ParallelWorkers),
+			seed:          viper.GetInt64(bk.ParallelSeed),
+		}
+		err error
+	)
+	if flags.Changed("blocks") {
+		if opts.blocks, err = flags.GetInt("blocks"); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if flags.Changed("block_size") {
+		if opts.blockSize, err = flags.GetInt("block_size"); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if flags.Changed("conflict_ratio") {
+		if opts.conflictRatio, err = flags.GetFloat64("conflict_ratio"); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if flags.Changed("workers") {
+		if opts.workers, err = flags.GetInt("workers"); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if flags.Changed("seed") {
+		if opts.seed, err = flags.GetInt64("seed"); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if opts.blocks <= 0 || opts.blockSize <= 0 {
+		log.Fatal(fmt.Errorf("number of blocks %d and block size %d must be greater than zero",
+			opts.blocks, opts.blockSize))
+	}
+	if opts.conflictRatio < 0 || opts.conflictRatio > 1 {
+		log.Fatal(fmt.Errorf("conflict ratio %f must be in [0; 1]", opts.conflictRatio))
+	}
+	return opts
+}
+
+// transferBlockTxns - a client sends tokens to a new account of its own
+func transferBlockTxns() []bk.BlockTxn {
+	return []bk.BlockTxn{
+		{
+			Name: "transfer",
+			Build: func(data bk.BenchData, client int) *transaction.Transaction {
+				return &transaction.Transaction{
+					ClientID:        data.Clients[client],
+					ToClientID:      encryption.Hash("transfer to " + data.Clients[client]),
+					Value:           1e8,
+					TransactionType: transaction.TxnTypeSend,
+				}
+			},
+		},
+	}
+}
+
+// blockTxnMix - the kinds of transactions of the mixed blocks along with the
+// cumulative weights of the kinds set in the configurations
+type blockTxnMix struct {
+	kinds   []bk.BlockTxn
+	weights []float64
+}
+
+func newBlockTxnMix() (mix blockTxnMix) {
+	var total float64
+	for _, source := range blockTxnSources {
+		for _, kind := range source() {
+			weight := viper.GetFloat64(bk.ParallelMix + kind.Name)
+			if weight <= 0 {
+				continue
+			}
+			total += weight
+			mix.kinds = append(mix.kinds, kind)
+			mix.weights = append(mix.weights, total)
+		}
+	}
+	if len(mix.kinds) == 0 {
+		log.Fatal(fmt.Errorf("no transactions in the block mix"))
+	}
+	return
+}
+
+func (mix blockTxnMix) pick(rnd *rand.Rand) bk.BlockTxn {
+	var (
+		total = mix.weights[len(mix.weights)-1]
+		w     = rnd.Float64() * total
+	)
+	return mix.kinds[sort.SearchFloat64s(mix.weights, w)]
+}
+
+// buildMixedBlock - the transactions of a mixed block. The given share of
+// the transactions is sent by the first client and conflicts with each
+// other, the rest is sent by the other clients in turn, conflicting only
+// when a client comes again or on the smart contract accounts.
+func buildMixedBlock(
+	rnd *rand.Rand,
+	mix blockTxnMix,
+	data bk.BenchData,
+	opts parallelOptions,
+	blockNum int,
+) []*transaction.Transaction {
+	var (
+		txns   = make([]*transaction.Transaction, 0, opts.blockSize)
+		others = len(data.Clients) - 1
+		next   int
+	)
+	for i := 0; i < opts.blockSize; i++ {
+		var (
+			kind   = mix.pick(rnd)
+			client int
+		)
+		if rnd.Float64() >= opts.conflictRatio && others > 0 {
+			client = 1 + next%others
+			next++
+		}
+		txn := kind.Build(data, client)
+		txn.Hash = encryption.Hash(kind.Name + ":" + strconv.Itoa(blockNum) + ":" + strconv.Itoa(i))
+		txns = append(txns, txn)
+	}
+	return txns
+}
+
+// parallelResults - the results of applying the mixed blocks
+type parallelResults struct {
+	workers    int
+	blocks     int
+	txns       int
+	excluded   int
+	sequential time.Duration
+	dag        time.Duration
+	optimistic time.Duration
+	// the number of transactions of the dependency graph levels, executed
+	// at a time by a batch executor
+	batches []int
+	// the number of transactions on the longest dependency chains
+	criticalPaths []int
+	// the number of transactions not waiting for any other of a block
+	roots      []int
+	mismatches int
+}
+
+func newBenchmarkChain(workers int) *chain.Chain {
+	c := chain.Provider().(*chain.Chain)
+	c.ExecutionWorkers = workers
+	c.SmartContractTimeout = time.Minute
+	c.SetSignatureScheme(viper.GetString(bk.InternalSignatureScheme))
+	for _, newSC := range blockSmartContracts {
+		sc := newSC()
+		smartcontract.ContractMap[sc.GetAddress()] = sc
+	}
+	return c
+}
+
+func newBenchmarkBlock(
+	mpt *util.MerklePatriciaTrie,
+	root util.Key,
+) *block.Block {
+	b := &block.Block{PrevBlock: &block.Block{}}
+	b.Round = 1
+	b.MinerID = minersc.GetMockNodeId(0, minersc.NodeTypeMiner)
+	b.CreateState(mpt.GetNodeDB(), root)
+	return b
+}
+
+// newParallelBlock - the benchmark block of the transactions with their
+// versioned access map, as a generator makes it
+func newParallelBlock(
+	mpt *util.MerklePatriciaTrie,
+	root util.Key,
+	txns []*transaction.Transaction,
+	accessMap map[datastore.Key]*block.AccessList,
+) *block.Block {
+	b := newBenchmarkBlock(mpt, root)
+	b.Txns, b.AccessMap = txns, accessMap
+	b.AccessMapVersion = block.AccessMapVersion
+	return b
+}
+
+// runParallel - apply each of the mixed blocks sequentially as a generator
+// does, keeping the successful transactions only, then by the dependency
+// graph and the optimistic executors, all on top of the benchmark state
+func runParallel(
+	opts parallelOptions,
+	mpt *util.MerklePatriciaTrie,
+	root util.Key,
+	data bk.BenchData,
+) (*parallelResults, error) {
+	var (
+		ctx     = context.Background()
+		c       = newBenchmarkChain(opts.workers)
+		rnd     = rand.New(rand.NewSource(opts.seed))
+		mix     = newBlockTxnMix()
+		results = &parallelResults{
+			workers: opts.workers,
+			blocks:  opts.blocks,
+		}
+	)
+	if results.workers <= 0 {
+		results.workers = runtime.NumCPU()
+	}
+	for i := 0; i < opts.blocks; i++ {
+		var (
+			txns      = buildMixedBlock(rnd, mix, data, opts, i)
+			included  []*transaction.Transaction
+			accessMap = make(map[datastore.Key]*block.AccessList)
+			seq       = newBenchmarkBlock(mpt, root)
+			start     = time.Now()
+		)
+		for _, txn := range txns {
+			rset, wset, err := c.UpdateState(ctx, seq, txn)
+			if err != nil {
+				continue
+			}
+			included = append(included, txn)
+			accessMap[txn.GetKey()] = block.NewAccessList(rset, wset)
+		}
+		results.sequential += time.Since(start)
+		results.txns += len(included)
+		results.excluded += len(txns) - len(included)
+
+		// the graph the DAG executor schedules the block by
+		g := block.NewBlockTxnGraph(newParallelBlock(mpt, root, included, accessMap))
+		for _, level := range g.Levels() {
+			results.batches = append(results.batches, len(level))
+		}
+		results.criticalPaths = append(results.criticalPaths, g.CriticalPath())
+		results.roots = append(results.roots, len(g.Roots()))
+
+		for _, run := range []struct {
+			executor block.Executor
+			taken    *time.Duration
+		}{
+			{block.NewDAGExecutor(opts.workers), &results.dag},
+			{block.NewOptimisticExecutor(opts.workers), &results.optimistic},
+		} {
+			b := newParallelBlock(mpt, root, included, accessMap)
+			start := time.Now()
+			if err := run.executor.Apply(ctx, b, c); err != nil {
+				return nil, fmt.Errorf("block %d: %v", i, err)
+			}
+			*run.taken += time.Since(start)
+			if !bytes.Equal(b.ClientState.GetRoot(), seq.ClientState.GetRoot()) {
+				results.mismatches++
+			}
+		}
+		log.Println("block", i, "done,", len(included), "of", len(txns), "transactions included")
+	}
+	return results, nil
+}
+
+func throughput(txns int, taken time.Duration) float64 {
+	if taken <= 0 {
+		return 0
+	}
+	return float64(txns) / taken.Seconds()
+}
+
+func speedup(sequential, parallel time.Duration) float64 {
+	if parallel <= 0 {
+		return 0
+	}
+	return float64(sequential) / float64(parallel)
+}
+
+// percentile of the sorted values
+func percentile(sorted []int, p float64) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(p*float64(len(sorted)-1))]
+}
+
+func average(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum int
+	for _, v := range values {
+		sum += v
+	}
+	return float64(sum) / float64(len(values))
+}
+
+func printParallelResults(results *parallelResults) {
+	if log.GetVerbose() {
+		fmt.Println("\nParallel execution results")
+	}
+	var batches = append([]int(nil), results.batches...)
+	sort.Ints(batches)
+
+	fmt.Printf("blocks,%d\n", results.blocks)
+	fmt.Printf("workers,%d\n", results.workers)
+	fmt.Printf("transactions,%d\n", results.txns)
+	fmt.Printf("excluded_transactions,%d\n", results.excluded)
+	fmt.Printf("sequential_tps,%f\n", throughput(results.txns, results.sequential))
+	fmt.Printf("dag_tps,%f\n", throughput(results.txns, results.dag))
+	fmt.Printf("dag_speedup,%f\n", speedup(results.sequential, results.dag))
+	fmt.Printf("optimistic_tps,%f\n", throughput(results.txns, results.optimistic))
+	fmt.Printf("optimistic_speedup,%f\n", speedup(results.sequential, results.optimistic))
+	fmt.Printf("batches,%d\n", len(batches))
+	fmt.Printf("batch_size_avg,%f\n", average(batches))
+	fmt.Printf("batch_size_p50,%d\n", percentile(batches, 0.5))
+	fmt.Printf("batch_size_p90,%d\n", percentile(batches, 0.9))
+	fmt.Printf("batch_size_max,%d\n", percentile(batches, 1))
+	fmt.Printf("critical_path_avg,%f\n", average(results.criticalPaths))
+	fmt.Printf("roots_avg,%f\n", average(results.roots))
+	fmt.Printf("state_roots_match,%t\n", results.mismatches == 0)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParallel applies mixed blocks sequentially and by the parallel
+// executors, all of them must end up with the same state.
+func TestParallel(t *testing.T) {
+	GetViper("testdata/benchmark.yaml")
+	mpt, root, data := setUpMpt(t.TempDir() + "/")
+
+	for _, ratio := range []float64{0, 0.5} {
+		results, err := runParallel(parallelOptions{
+			blocks:        2,
+			blockSize:     60,
+			conflictRatio: ratio,
+			workers:       4,
+			seed:          1,
+		}, mpt, root, data)
+		require.NoError(t, err)
+		require.Zero(t, results.mismatches, "state roots")
+		require.NotZero(t, results.txns)
+		require.Zero(t, results.excluded, "failed transactions")
+		require.Len(t, results.criticalPaths, 2)
+		// the blocks are scheduled by their access maps, not in order
+		for _, roots := range results.roots {
+			require.True(t, roots > 1, "independent transactions")
+		}
+	}
+}
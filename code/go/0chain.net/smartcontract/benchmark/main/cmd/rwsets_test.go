@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+	bk "0chain.net/smartcontract/benchmark"
+)
+
+// recordingMPT records every path the smart contracts touch in the
+// underlying MPT, bypassing the state context bookkeeping
+type recordingMPT struct {
+	util.MerklePatriciaTrieI
+	reads  map[datastore.Key]bool
+	writes map[datastore.Key]bool
+}
+
+func newRecordingMPT(mpt util.MerklePatriciaTrieI) *recordingMPT {
+	return &recordingMPT{
+		MerklePatriciaTrieI: mpt,
+		reads:               make(map[datastore.Key]bool),
+		writes:              make(map[datastore.Key]bool),
+	}
+}
+
+func (rm *recordingMPT) GetNodeValue(path util.Path) (util.Serializable, error) {
+	value, err := rm.MerklePatriciaTrieI.GetNodeValue(path)
+	if err == nil || err == util.ErrValueNotPresent {
+		rm.reads[datastore.Key(path)] = true
+	}
+	return value, err
+}
+
+func (rm *recordingMPT) Insert(path util.Path, value util.Serializable) (util.Key, error) {
+	key, err := rm.MerklePatriciaTrieI.Insert(path, value)
+	if err == nil {
+		rm.writes[datastore.Key(path)] = true
+	}
+	return key, err
+}
+
+func (rm *recordingMPT) Delete(path util.Path) (util.Key, error) {
+	key, err := rm.MerklePatriciaTrieI.Delete(path)
+	switch err {
+	case nil:
+		rm.writes[datastore.Key(path)] = true
+	case util.ErrValueNotPresent:
+		rm.reads[datastore.Key(path)] = true
+	}
+	return key, err
+}
+
+// TestRWSets runs every smart contract function of the benchmark suites and
+// checks the read and write sets collected by the state context against the
+// MPT paths the function actually touched.
+func TestRWSets(t *testing.T) {
+	GetViper("testdata/benchmark.yaml")
+	mpt, root, data := setUpMpt(t.TempDir() + "/")
+
+	omitted := make(map[string]bool)
+	for _, name := range viper.GetStringSlice(bk.OptionOmittedTests) {
+		omitted[name] = true
+	}
+
+	for source := bk.BenchmarkSource(0); source < bk.NumberOdfBenchmarkSources; source++ {
+		var (
+			suite  = benchmarkSources[source](data, &BLS0ChainScheme{})
+			isRest = strings.HasSuffix(bk.BenchmarkSourceNames[source], "_rest")
+		)
+		for _, bm := range suite.Benchmarks {
+			if omitted[bm.Name()] {
+				continue
+			}
+			bm := bm
+			t.Run(bm.Name(), func(t *testing.T) {
+				recorder := newRecordingMPT(extractMpt(mpt, root))
+				_, balances := getBalances(bm.Transaction(), recorder, data)
+				require.NotPanics(t, func() { bm.Run(balances, &testing.B{}) })
+
+				rset, wset := balances.GetRWSets()
+				require.Equal(t, recorder.reads, rset, "read set")
+				require.Equal(t, recorder.writes, wset, "write set")
+				if isRest {
+					require.Empty(t, wset, "rest endpoint writes")
+				}
+			})
+		}
+	}
+}
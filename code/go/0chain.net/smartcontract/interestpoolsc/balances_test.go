@@ -57,11 +57,15 @@ func (tb *testBalances) Validate() error                          { return nil }
 func (tb *testBalances) GetMints() []*state.Mint                  { return nil }
 func (tb *testBalances) SetStateContext(*state.State) error       { return nil }
 func (tb *testBalances) GetTransfers() []*state.Transfer          { return nil }
-func (tb *testBalances) AddSignedTransfer(_ *state.SignedTransfer) {
+func (tb *testBalances) AddSignedTransfer(_ *state.SignedTransfer) error {
+	return nil
 }
 func (tb *testBalances) GetSignedTransfers() []*state.SignedTransfer {
 	return nil
 }
+func (tb *testBalances) AddLog(*transaction.Log)               {}
+func (tb *testBalances) GetLogs() []*transaction.Log           { return nil }
+func (tb *testBalances) EmitEvent(string, string, interface{}) {}
 func (tb *testBalances) DeleteTrieNode(datastore.Key) (datastore.Key, error) {
 	return "", nil
 }
@@ -0,0 +1,24 @@
+package interestpoolsc
+
+import (
+	"time"
+
+	"0chain.net/chaincore/state"
+	"0chain.net/core/datastore"
+)
+
+// the types of the events emitted by the interest pool smart contract, the
+// tag of an event is the ID of the interest pool
+const (
+	EventLock   = "lock"
+	EventUnlock = "unlock"
+)
+
+// poolEvent - the payload of the interest pool events
+type poolEvent struct {
+	PoolID       datastore.Key `json:"pool_id"`
+	ClientID     datastore.Key `json:"client_id"`
+	Amount       state.Balance `json:"amount"`
+	TokensEarned state.Balance `json:"tokens_earned,omitempty"`
+	Duration     time.Duration `json:"duration,omitempty"`
+}
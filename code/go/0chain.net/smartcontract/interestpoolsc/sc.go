@@ -119,6 +119,13 @@ func (ip *InterestPoolSmartContract) lock(t *transaction.Transaction, un *UserNo
 			return "", err
 		}
 		balances.InsertTrieNode(un.getKey(gn.ID), un)
+		balances.EmitEvent(EventLock, pool.ID, &poolEvent{
+			PoolID:       pool.ID,
+			ClientID:     t.ClientID,
+			Amount:       transfer.Amount,
+			TokensEarned: pool.TokensEarned,
+			Duration:     npr.Duration,
+		})
 		return resp, nil
 	}
 	return "", err
@@ -143,6 +150,11 @@ func (ip *InterestPoolSmartContract) unlock(t *transaction.Transaction, un *User
 		}
 		balances.AddTransfer(transfer)
 		balances.InsertTrieNode(un.getKey(gn.ID), un)
+		balances.EmitEvent(EventUnlock, pool.ID, &poolEvent{
+			PoolID:   pool.ID,
+			ClientID: t.ClientID,
+			Amount:   transfer.Amount,
+		})
 		return response, nil
 	}
 	return "", common.NewError("failed to unlock tokens", fmt.Sprintf("pool (%v) doesn't exist", ps.ID))
@@ -360,9 +360,12 @@ func (sc *mockStateContext) GetMints() []*state.Mint
 func (sc *mockStateContext) Validate() error                                       { return nil }
 func (sc *mockStateContext) GetBlockSharders(_ *block.Block) []string              { return nil }
 func (sc *mockStateContext) GetSignatureScheme() encryption.SignatureScheme        { return nil }
-func (sc *mockStateContext) AddSignedTransfer(_ *state.SignedTransfer)             { return }
+func (sc *mockStateContext) AddSignedTransfer(_ *state.SignedTransfer) error       { return nil }
 func (sc *mockStateContext) DeleteTrieNode(_ datastore.Key) (datastore.Key, error) { return "", nil }
 func (sc *mockStateContext) GetChainCurrentMagicBlock() *block.MagicBlock          { return nil }
+func (sc *mockStateContext) AddLog(_ *transaction.Log)                             { return }
+func (sc *mockStateContext) GetLogs() []*transaction.Log                           { return nil }
+func (sc *mockStateContext) EmitEvent(_, _ string, _ interface{})                  { return }
 
 func (sc *mockStateContext) GetClientBalance(_ datastore.Key) (state.Balance, error) {
 	if sc.clientStartBalance == 0 {
@@ -0,0 +1,63 @@
+package minersc
+
+import (
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+)
+
+// the functions changing the global node or the magic block related nodes
+var serialFunctions = map[string]bool{
+	"delete_miner":         true,
+	"delete_sharder":       true,
+	"migrate_state_layout": true,
+	"payFees":              true,
+	"update_globals":       true,
+	"update_settings":      true,
+	"wait":                 true,
+}
+
+// GetAccessHints implements sci.AccessHinter. Every function reads the
+// global node and its counters, the ones executed under
+// lockSmartContractExecute or changing the global node are serial. The
+// hints describe the partitioned state layout.
+func (msc *MinerSmartContract) GetAccessHints(t *transaction.Transaction,
+	funcName string, input []byte) *sci.AccessHints {
+
+	if _, ok := lockSmartContractExecute[funcName]; ok || serialFunctions[funcName] {
+		return &sci.AccessHints{Serial: true}
+	}
+
+	var hints = &sci.AccessHints{
+		Reads: []datastore.Key{GlobalNodeKey, GlobalCountersKey},
+	}
+	switch funcName {
+	case "addToDelegatePool", "deleteFromDelegatePool":
+		var dp deletePool
+		if err := dp.Decode(input); err != nil {
+			return nil
+		}
+		// the miner node may be looked up in the list of all miners
+		hints.Reads = append(hints.Reads, AllMinersKey)
+		hints.Writes = []datastore.Key{
+			getMinerKey(dp.MinerID),
+			(&UserNode{ID: t.ClientID}).GetKey(),
+		}
+		// the stake is moved between the client and the smart contract
+		hints.Clients = []datastore.Key{ADDRESS}
+	case "update_miner_settings", "update_sharder_settings":
+		var update = NewMinerNode()
+		if err := update.Decode(input); err != nil {
+			return nil
+		}
+		hints.Reads = append(hints.Reads, AllMinersKey)
+		hints.Writes = []datastore.Key{update.GetKey()}
+	case "miner_health_check":
+		hints.Writes = []datastore.Key{getMinerKey(t.ClientID)}
+	case "sharder_health_check":
+		hints.Writes = []datastore.Key{GetSharderKey(t.ClientID)}
+	default:
+		return nil
+	}
+	return hints
+}
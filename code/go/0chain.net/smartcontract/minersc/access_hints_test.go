@@ -0,0 +1,181 @@
+package minersc
+
+import (
+	"testing"
+
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+// accessRecorder - the test balances recording the trie nodes and the
+// accounts a smart contract function accesses
+type accessRecorder struct {
+	*testBalances
+	reads, writes, clients map[datastore.Key]bool
+}
+
+func newAccessRecorder(tb *testBalances) *accessRecorder {
+	return &accessRecorder{
+		testBalances: tb,
+		reads:        make(map[datastore.Key]bool),
+		writes:       make(map[datastore.Key]bool),
+		clients:      make(map[datastore.Key]bool),
+	}
+}
+
+func (ar *accessRecorder) GetTrieNode(key datastore.Key) (util.Serializable, error) {
+	ar.reads[key] = true
+	return ar.testBalances.GetTrieNode(key)
+}
+
+func (ar *accessRecorder) InsertTrieNode(key datastore.Key, node util.Serializable) (datastore.Key, error) {
+	ar.writes[key] = true
+	return ar.testBalances.InsertTrieNode(key, node)
+}
+
+func (ar *accessRecorder) DeleteTrieNode(key datastore.Key) (datastore.Key, error) {
+	ar.writes[key] = true
+	return ar.testBalances.DeleteTrieNode(key)
+}
+
+func (ar *accessRecorder) GetClientBalance(clientID datastore.Key) (state.Balance, error) {
+	ar.clients[clientID] = true
+	return ar.testBalances.GetClientBalance(clientID)
+}
+
+func (ar *accessRecorder) AddTransfer(t *state.Transfer) error {
+	ar.clients[t.ClientID], ar.clients[t.ToClientID] = true, true
+	return ar.testBalances.AddTransfer(t)
+}
+
+func (ar *accessRecorder) AddMint(m *state.Mint) error {
+	ar.clients[m.ToClientID] = true
+	return ar.testBalances.AddMint(m)
+}
+
+// requireCovered - the hints declare everything the function accessed
+func (ar *accessRecorder) requireCovered(t *testing.T,
+	txn *transaction.Transaction, hints *sci.AccessHints) {
+
+	t.Helper()
+	var declared = func(lists ...[]datastore.Key) map[datastore.Key]bool {
+		set := make(map[datastore.Key]bool)
+		for _, list := range lists {
+			for _, k := range list {
+				set[k] = true
+			}
+		}
+		return set
+	}
+	var (
+		reads   = declared(hints.Reads, hints.Writes)
+		writes  = declared(hints.Writes)
+		clients = declared(hints.Clients, []datastore.Key{txn.ClientID})
+	)
+	if txn.Value > 0 {
+		clients[txn.ToClientID] = true
+	}
+	for k := range ar.reads {
+		require.True(t, reads[k], "undeclared read: %s", k)
+	}
+	for k := range ar.writes {
+		require.True(t, writes[k], "undeclared write: %s", k)
+	}
+	for k := range ar.clients {
+		require.True(t, clients[k], "undeclared client: %s", k)
+	}
+}
+
+func TestMinerSmartContract_GetAccessHints(t *testing.T) {
+	const stakeVal = 10e10
+
+	var (
+		balances = newTestBalances()
+		msc      = newTestMinerSC()
+		now      int64
+	)
+	msc.InitSmartContractFunctions()
+	var gn = setConfig(t, balances)
+	var mn = newMiner(t, msc, now, 1, stakeVal, balances)
+	var sh = newSharder(t, msc, now, 0, 0, balances)
+
+	// the hints describe the partitioned state layout
+	gn.StateLayout = partitionedStateLayout
+	require.NoError(t, gn.save(balances))
+
+	mnode, err := getMinerNode(mn.miner.id, balances)
+	require.NoError(t, err)
+
+	var (
+		staker  = mn.stakers[0]
+		stakeTx = newTransaction(staker.id, ADDRESS, stakeVal, now)
+	)
+	tests := []struct {
+		name     string
+		funcName string
+		txn      *transaction.Transaction
+		input    []byte
+	}{
+		{
+			name:     "add to delegate pool",
+			funcName: "addToDelegatePool",
+			txn:      stakeTx,
+			input:    staker.addToDelegatePoolRequest(t, mn.miner.id),
+		},
+		{
+			name:     "delete from delegate pool",
+			funcName: "deleteFromDelegatePool",
+			txn:      newTransaction(staker.id, ADDRESS, 0, now),
+			input: mustEncode(t, &deletePool{
+				MinerID: mn.miner.id,
+				PoolID:  stakeTx.Hash,
+			}),
+		},
+		{
+			name:     "update miner settings",
+			funcName: "update_miner_settings",
+			txn:      newTransaction(mn.delegate.id, ADDRESS, 0, now),
+			input:    mnode.Encode(),
+		},
+		{
+			name:     "miner health check",
+			funcName: "miner_health_check",
+			txn:      newTransaction(mn.miner.id, ADDRESS, 0, now),
+		},
+		{
+			name:     "sharder health check",
+			funcName: "sharder_health_check",
+			txn:      newTransaction(sh.sharder.id, ADDRESS, 0, now),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hints := msc.GetAccessHints(tt.txn, tt.funcName, tt.input)
+			require.NotNil(t, hints)
+			require.False(t, hints.Serial)
+
+			balances.txn = tt.txn
+			rec := newAccessRecorder(balances)
+			_, err := msc.Execute(tt.txn, tt.funcName, tt.input, rec)
+			require.NoError(t, err)
+			rec.requireCovered(t, tt.txn, hints)
+		})
+	}
+
+	t.Run("serial", func(t *testing.T) {
+		for funcName := range lockSmartContractExecute {
+			hints := msc.GetAccessHints(stakeTx, funcName, nil)
+			require.NotNil(t, hints, funcName)
+			require.True(t, hints.Serial, funcName)
+		}
+	})
+
+	t.Run("malformed input", func(t *testing.T) {
+		require.Nil(t, msc.GetAccessHints(stakeTx, "addToDelegatePool", []byte("}{")))
+	})
+}
@@ -202,6 +202,7 @@ func BenchmarkTests(
 					"server_chain.block.sharding.min_active_replicators": "25",
 					"server_chain.block.validation.batch_size":           "1000",
 					"server_chain.block.reuse_txns":                      "false",
+					"server_chain.block.execution.strict_access_map":     "false",
 					"server_chain.round_range":                           "10000000",
 					"server_chain.round_timeouts.softto_min":             "3000",
 					"server_chain.round_timeouts.softto_mult":            "3",
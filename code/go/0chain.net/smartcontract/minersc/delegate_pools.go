@@ -102,6 +102,13 @@ func (msc *MinerSmartContract) addToDelegatePool(t *transaction.Transaction,
 			"saving miner node: %v", err)
 	}
 
+	balances.EmitEvent(EventDelegatePoolAdd, t.Hash, &delegatePoolEvent{
+		NodeID:   mn.ID,
+		PoolID:   t.Hash,
+		ClientID: t.ClientID,
+		Amount:   state.Balance(t.Value),
+		Status:   PENDING,
+	})
 	resp = string(mn.Encode()) + string(transfer.Encode()) + string(un.Encode())
 	return
 }
@@ -158,6 +165,12 @@ func (msc *MinerSmartContract) deleteFromDelegatePool(
 			return "", common.NewError("delegate_pool_del", err.Error())
 		}
 
+		balances.EmitEvent(EventDelegatePoolDel, dp.PoolID, &delegatePoolEvent{
+			NodeID:   mn.ID,
+			PoolID:   dp.PoolID,
+			ClientID: t.ClientID,
+			Amount:   transfer.Amount,
+		})
 		return resp, nil
 	}
 
@@ -193,5 +206,11 @@ func (msc *MinerSmartContract) deleteFromDelegatePool(
 			"saving miner node: %v", err)
 	}
 
+	balances.EmitEvent(EventDelegatePoolDel, dp.PoolID, &delegatePoolEvent{
+		NodeID:   mn.ID,
+		PoolID:   dp.PoolID,
+		ClientID: t.ClientID,
+		Status:   DELETING,
+	})
 	return `{"action": "pool will be released next VC"}`, nil
 }
@@ -0,0 +1,41 @@
+package minersc
+
+import (
+	"0chain.net/chaincore/state"
+	"0chain.net/core/datastore"
+)
+
+// the types of the events emitted by the miner smart contract, the tag of
+// an event is the ID of the node or of the delegate pool it's about
+const (
+	EventAddMiner        = "add_miner"
+	EventAddSharder      = "add_sharder"
+	EventDeleteMiner     = "delete_miner"
+	EventDeleteSharder   = "delete_sharder"
+	EventDelegatePoolAdd = "delegate_pool_add"
+	EventDelegatePoolDel = "delegate_pool_del"
+)
+
+// nodeEvent - the payload of the node events
+type nodeEvent struct {
+	ID             datastore.Key `json:"id"`
+	N2NHost        string        `json:"n2n_host,omitempty"`
+	DelegateWallet string        `json:"delegate_wallet,omitempty"`
+}
+
+func newNodeEvent(mn *MinerNode) *nodeEvent {
+	return &nodeEvent{
+		ID:             mn.ID,
+		N2NHost:        mn.N2NHost,
+		DelegateWallet: mn.DelegateWallet,
+	}
+}
+
+// delegatePoolEvent - the payload of the delegate pool events
+type delegatePoolEvent struct {
+	NodeID   datastore.Key `json:"node_id"`
+	PoolID   datastore.Key `json:"pool_id"`
+	ClientID datastore.Key `json:"client_id"`
+	Amount   state.Balance `json:"amount,omitempty"`
+	Status   string        `json:"status,omitempty"`
+}
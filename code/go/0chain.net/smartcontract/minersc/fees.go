@@ -449,7 +449,7 @@ func (msc *MinerSmartContract) payFees(t *transaction.Transaction,
 	}
 
 	gn.setLastRound(mb.Round)
-	if err = gn.save(balances); err != nil {
+	if err = gn.saveCounters(balances); err != nil {
 		return "", common.NewErrorf("pay_fees",
 			"saving global node: %v", err)
 	}
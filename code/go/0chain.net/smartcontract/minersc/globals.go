@@ -90,6 +90,8 @@ const (
 	HealthCheckProximityScanRejportStatusMins  // todo restart worker
 	HealthCheckShowCounters                    // todo restart worker
 
+	BlockStrictAccessMap
+
 	NumOfGlobalSettings
 )
 
@@ -156,6 +158,7 @@ var GlobalSettingName = []string{
 	"server_chain.health_check.proximity_scan.repeat_interval_mins",
 	"server_chain.health_check.proximity_scan.report_status_mins",
 	"server_chain.health_check.show_counters",
+	"server_chain.block.execution.strict_access_map",
 }
 
 var GlobalSettingInfo = map[string]struct {
@@ -224,6 +227,7 @@ var GlobalSettingInfo = map[string]struct {
 	GlobalSettingName[HealthCheckProximityScanRepeatIntervalMins]: {smartcontract.Duration, false},
 	GlobalSettingName[HealthCheckProximityScanRejportStatusMins]:  {smartcontract.Duration, false},
 	GlobalSettingName[HealthCheckShowCounters]:                    {smartcontract.Boolean, false},
+	GlobalSettingName[BlockStrictAccessMap]:                       {smartcontract.Boolean, true},
 }
 
 var GLOBALS_KEY = datastore.Key(encryption.Hash("global_settings"))
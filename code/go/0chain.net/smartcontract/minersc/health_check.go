@@ -9,10 +9,13 @@ import (
 func (msc *MinerSmartContract) minerHealthCheck(t *transaction.Transaction,
 	inputData []byte, gn *GlobalNode, balances cstate.StateContextI) (
 	resp string, err error) {
-	all, err := getMinersList(balances)
-	if err != nil {
-		return "", common.NewError("miner_health_check_failed",
-			"Failed to get miner list: "+err.Error())
+	// the list keeps the health check time under the legacy layout only
+	var all *MinerNodes
+	if !gn.isPartitioned() {
+		if all, err = getMinersList(balances); err != nil {
+			return "", common.NewError("miner_health_check_failed",
+				"Failed to get miner list: "+err.Error())
+		}
 	}
 
 	var existingMiner *MinerNode
@@ -23,16 +26,18 @@ func (msc *MinerSmartContract) minerHealthCheck(t *transaction.Transaction,
 
 	existingMiner.LastHealthCheck = t.CreationDate
 
-	for _, nodes := range all.Nodes {
-		if nodes.ID == t.ClientID {
-			nodes.LastHealthCheck = t.CreationDate
-			break
+	if all != nil {
+		for _, nodes := range all.Nodes {
+			if nodes.ID == t.ClientID {
+				nodes.LastHealthCheck = t.CreationDate
+				break
+			}
 		}
-	}
 
-	if err = updateMinersList(balances, all); err != nil {
-		return "", common.NewError("miner_health_check_failed",
-			"can't save all miners list: "+err.Error())
+		if err = updateMinersList(balances, all); err != nil {
+			return "", common.NewError("miner_health_check_failed",
+				"can't save all miners list: "+err.Error())
+		}
 	}
 
 	err = existingMiner.save(balances)
@@ -47,10 +52,13 @@ func (msc *MinerSmartContract) minerHealthCheck(t *transaction.Transaction,
 func (msc *MinerSmartContract) sharderHealthCheck(t *transaction.Transaction,
 	inputData []byte, gn *GlobalNode, balances cstate.StateContextI) (
 	resp string, err error) {
-	all, err := getAllShardersList(balances)
-	if err != nil {
-		return "", common.NewError("sharder_health_check_failed",
-			"Failed to get sharder list: "+err.Error())
+	// the list keeps the health check time under the legacy layout only
+	var all *MinerNodes
+	if !gn.isPartitioned() {
+		if all, err = getAllShardersList(balances); err != nil {
+			return "", common.NewError("sharder_health_check_failed",
+				"Failed to get sharder list: "+err.Error())
+		}
 	}
 
 	var existingSharder *MinerNode
@@ -61,16 +69,18 @@ func (msc *MinerSmartContract) sharderHealthCheck(t *transaction.Transaction,
 
 	existingSharder.LastHealthCheck = t.CreationDate
 
-	for _, nodes := range all.Nodes {
-		if nodes.ID == t.ClientID {
-			nodes.LastHealthCheck = t.CreationDate
-			break
+	if all != nil {
+		for _, nodes := range all.Nodes {
+			if nodes.ID == t.ClientID {
+				nodes.LastHealthCheck = t.CreationDate
+				break
+			}
 		}
-	}
 
-	if err = updateAllShardersList(balances, all); err != nil {
-		return "", common.NewError("sharder_health_check_failed",
-			"can't save all sharders list: "+err.Error())
+		if err = updateAllShardersList(balances, all); err != nil {
+			return "", common.NewError("sharder_health_check_failed",
+				"can't save all sharders list: "+err.Error())
+		}
 	}
 
 	err = existingSharder.save(balances)
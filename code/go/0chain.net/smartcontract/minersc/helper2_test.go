@@ -32,7 +32,7 @@ func (sc *mockStateContext) GetTransaction() *transaction.Transaction
 func (sc *mockStateContext) GetSignedTransfers() []*state.SignedTransfer             { return nil }
 func (sc *mockStateContext) Validate() error                                         { return nil }
 func (sc *mockStateContext) GetSignatureScheme() encryption.SignatureScheme          { return nil }
-func (sc *mockStateContext) AddSignedTransfer(_ *state.SignedTransfer)               { return }
+func (sc *mockStateContext) AddSignedTransfer(_ *state.SignedTransfer) error         { return nil }
 func (sc *mockStateContext) DeleteTrieNode(_ datastore.Key) (datastore.Key, error)   { return "", nil }
 func (sc *mockStateContext) GetClientBalance(_ datastore.Key) (state.Balance, error) { return 0, nil }
 func (sc *mockStateContext) GetChainCurrentMagicBlock() *block.MagicBlock            { return nil }
@@ -45,6 +45,18 @@ func (sc *mockStateContext) GetMints() []*state.Mint {
 	return sc.ctx.GetMints()
 }
 
+func (sc *mockStateContext) AddLog(l *transaction.Log) {
+	sc.ctx.AddLog(l)
+}
+
+func (sc *mockStateContext) GetLogs() []*transaction.Log {
+	return sc.ctx.GetLogs()
+}
+
+func (sc *mockStateContext) EmitEvent(eventType, tag string, payload interface{}) {
+	sc.ctx.EmitEvent(eventType, tag, payload)
+}
+
 func (sc *mockStateContext) GetLastestFinalizedMagicBlock() *block.Block {
 	return sc.LastestFinalizedMagicBlock
 }
@@ -0,0 +1,129 @@
+package minersc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+// The miner SC state layouts. Under the legacy layout the global node keeps
+// the last round and the minted tokens, changed by every block, and the
+// health checks update the lists of all miners and all sharders, so that
+// the health checks of different nodes conflict with each other. Under the
+// partitioned layout the counters are kept apart from the read-mostly
+// global node and the health checks update the node only. The SC owner
+// moves the state to the partitioned layout by migrate_state_layout.
+const (
+	legacyStateLayout      = 0
+	partitionedStateLayout = 1
+)
+
+var GlobalCountersKey = globalKeyHash("global_counters")
+
+// globalCounters are the global node fields changed by every block, kept
+// apart from the global node under the partitioned layout.
+type globalCounters struct {
+	LastRound int64         `json:"last_round"`
+	Minted    state.Balance `json:"minted"`
+}
+
+func (gc *globalCounters) Encode() []byte {
+	buff, _ := json.Marshal(gc)
+	return buff
+}
+
+func (gc *globalCounters) Decode(input []byte) error {
+	return json.Unmarshal(input, gc)
+}
+
+func (gc *globalCounters) GetHash() string {
+	return util.ToHex(gc.GetHashBytes())
+}
+
+func (gc *globalCounters) GetHashBytes() []byte {
+	return encryption.RawHash(gc.Encode())
+}
+
+func (gn *GlobalNode) isPartitioned() bool {
+	return gn.StateLayout >= partitionedStateLayout
+}
+
+// loadCounters sets the counters kept apart under the partitioned layout
+func (gn *GlobalNode) loadCounters(balances cstate.StateContextI) (
+	err error) {
+
+	if !gn.isPartitioned() {
+		return
+	}
+	var (
+		gc  globalCounters
+		val util.Serializable
+	)
+	if val, err = balances.GetTrieNode(GlobalCountersKey); err != nil {
+		return fmt.Errorf("getting global counters: %v", err)
+	}
+	if err = gc.Decode(val.Encode()); err != nil {
+		return fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	gn.LastRound, gn.Minted = gc.LastRound, gc.Minted
+	return
+}
+
+// saveCounters saves the counters changed by a block, that's the whole
+// global node under the legacy layout or when anything but the counters has
+// changed, as the view change, the previous magic block or declined rates
+func (gn *GlobalNode) saveCounters(balances cstate.StateContextI) (
+	err error) {
+
+	if !gn.isPartitioned() || gn.withoutCounters().GetHash() != gn.storedHash {
+		return gn.save(balances)
+	}
+	return gn.insertCounters(balances)
+}
+
+// withoutCounters returns copy of the global node as it's saved under the
+// partitioned layout, the counters aren't kept in the global node
+func (gn *GlobalNode) withoutCounters() *GlobalNode {
+	var cp = *gn
+	cp.LastRound, cp.Minted = 0, 0
+	return &cp
+}
+
+func (gn *GlobalNode) insertCounters(balances cstate.StateContextI) (
+	err error) {
+
+	var gc = globalCounters{LastRound: gn.LastRound, Minted: gn.Minted}
+	if _, err = balances.InsertTrieNode(GlobalCountersKey, &gc); err != nil {
+		return fmt.Errorf("saving global counters: %v", err)
+	}
+	return
+}
+
+// migrateStateLayout moves the miner SC state to the partitioned layout
+func (msc *MinerSmartContract) migrateStateLayout(t *transaction.Transaction,
+	_ []byte, gn *GlobalNode, balances cstate.StateContextI) (
+	resp string, err error) {
+
+	if t.ClientID != owner {
+		return "", common.NewError("migrate_state_layout",
+			"unauthorized access - only the owner can migrate the state")
+	}
+
+	if gn.isPartitioned() {
+		return "", common.NewError("migrate_state_layout",
+			"state is already partitioned")
+	}
+
+	gn.StateLayout = partitionedStateLayout
+	if err = gn.save(balances); err != nil {
+		return "", common.NewError("migrate_state_layout", err.Error())
+	}
+
+	return "state migrated to the partitioned layout", nil
+}
@@ -0,0 +1,78 @@
+package minersc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinerSmartContract_migrateStateLayout(t *testing.T) {
+	var (
+		balances = newTestBalances()
+		msc      = newTestMinerSC()
+		now      int64
+	)
+	msc.InitSmartContractFunctions()
+	var gn = setConfig(t, balances)
+	gn.LastRound, gn.Minted = 10, 100
+	require.NoError(t, gn.save(balances))
+	var mn = newMiner(t, msc, now, 0, 0, balances)
+
+	var migrate = func(clientID string) error {
+		var tx = newTransaction(clientID, ADDRESS, 0, now)
+		balances.txn = tx
+		_, err := msc.Execute(tx, "migrate_state_layout", nil, balances)
+		return err
+	}
+
+	require.Error(t, migrate(mn.miner.id), "not the owner")
+	require.NoError(t, migrate(owner))
+	require.Error(t, migrate(owner), "already migrated")
+
+	// the counters are kept apart from the global node
+	var saved GlobalNode
+	require.NoError(t, saved.Decode(balances.tree[GlobalNodeKey].Encode()))
+	require.Equal(t, partitionedStateLayout, saved.StateLayout)
+	require.Zero(t, saved.LastRound)
+	require.Zero(t, saved.Minted)
+
+	gn, err := getGlobalNode(balances)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, gn.LastRound)
+	require.EqualValues(t, 100, gn.Minted)
+
+	// a block changes the counters only
+	var gnBytes = balances.tree[GlobalNodeKey].Encode()
+	gn.setLastRound(11)
+	gn.Minted += 5
+	require.NoError(t, gn.saveCounters(balances))
+	require.Equal(t, gnBytes, balances.tree[GlobalNodeKey].Encode())
+
+	gn, err = getGlobalNode(balances)
+	require.NoError(t, err)
+	require.EqualValues(t, 11, gn.LastRound)
+	require.EqualValues(t, 105, gn.Minted)
+
+	// a block changing the view change saves the global node
+	gn.setLastRound(12)
+	gn.ViewChange = 100
+	require.NoError(t, gn.saveCounters(balances))
+	require.NotEqual(t, gnBytes, balances.tree[GlobalNodeKey].Encode())
+
+	gn, err = getGlobalNode(balances)
+	require.NoError(t, err)
+	require.EqualValues(t, 12, gn.LastRound)
+	require.EqualValues(t, 100, gn.ViewChange)
+
+	// a health check doesn't change the list of all miners
+	var allBytes = balances.tree[AllMinersKey].Encode()
+	var tx = newTransaction(mn.miner.id, ADDRESS, 0, now+5)
+	balances.txn = tx
+	_, err = msc.Execute(tx, "miner_health_check", nil, balances)
+	require.NoError(t, err)
+	require.Equal(t, allBytes, balances.tree[AllMinersKey].Encode())
+
+	node, err := getMinerNode(mn.miner.id, balances)
+	require.NoError(t, err)
+	require.EqualValues(t, now+5, node.LastHealthCheck)
+}
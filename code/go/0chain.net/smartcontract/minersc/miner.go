@@ -149,6 +149,8 @@ func (msc *MinerSmartContract) AddMiner(t *transaction.Transaction,
 
 	if !update {
 		logging.Logger.Debug("Add miner already exists", zap.String("ID", newMiner.ID))
+	} else {
+		balances.EmitEvent(EventAddMiner, newMiner.ID, newNodeEvent(newMiner))
 	}
 
 	return string(newMiner.Encode()), nil
@@ -183,6 +185,7 @@ func (msc *MinerSmartContract) DeleteMiner(
 		return "", common.NewError("delete_miner", err.Error())
 	}
 
+	balances.EmitEvent(EventDeleteMiner, updatedMn.ID, newNodeEvent(updatedMn))
 	return "", nil
 }
 
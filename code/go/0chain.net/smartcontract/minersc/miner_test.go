@@ -86,6 +86,7 @@ func TestDeleteMiner(t *testing.T) {
 		mockBlock.Round = mockRoundNumber
 		balances.On("GetBlock").Return(mockBlock).Twice()
 		balances.On("GetTrieNode", DKGMinersKey).Return(nil, util.ErrValueNotPresent).Once()
+		balances.On("EmitEvent", EventDeleteMiner, mockDeletedMinerId, mock.Anything).Return().Once()
 
 		mnInput := &MinerNode{
 			SimpleNode: &SimpleNode{
@@ -48,6 +48,7 @@ func (msc *MinerSmartContract) InitSmartContractFunctions() {
 	msc.smartContractFunctions["wait"] = msc.wait
 	msc.smartContractFunctions["update_globals"] = msc.updateGlobals
 	msc.smartContractFunctions["update_settings"] = msc.updateSettings
+	msc.smartContractFunctions["migrate_state_layout"] = msc.migrateStateLayout
 	msc.smartContractFunctions["update_miner_settings"] = msc.UpdateMinerSettings
 	msc.smartContractFunctions["update_sharder_settings"] = msc.UpdateSharderSettings
 
@@ -241,6 +241,14 @@ type GlobalNode struct {
 
 	// If viewchange is false then this will be used to pay interests and rewards to miner/sharders.
 	RewardRoundFrequency int64 `json:"reward_round_frequency"`
+
+	// StateLayout is version of the SC state layout. The LastRound and the
+	// Minted are kept apart under the partitioned layout (see layout.go).
+	StateLayout int `json:"state_layout,omitempty"`
+
+	// storedHash is hash of the global node loaded without the counters
+	// kept apart, to save it when anything else has changed
+	storedHash string
 }
 
 func (gn *GlobalNode) readConfig() {
@@ -552,9 +560,20 @@ func (gn *GlobalNode) setLastRound(round int64) {
 }
 
 func (gn *GlobalNode) save(balances cstate.StateContextI) (err error) {
-	if _, err = balances.InsertTrieNode(GlobalNodeKey, gn); err != nil {
+	if !gn.isPartitioned() {
+		if _, err = balances.InsertTrieNode(GlobalNodeKey, gn); err != nil {
+			return fmt.Errorf("saving global node: %v", err)
+		}
+		return
+	}
+	if err = gn.insertCounters(balances); err != nil {
+		return
+	}
+	var node = gn.withoutCounters()
+	if _, err = balances.InsertTrieNode(GlobalNodeKey, node); err != nil {
 		return fmt.Errorf("saving global node: %v", err)
 	}
+	gn.storedHash = node.GetHash()
 	return
 }
 
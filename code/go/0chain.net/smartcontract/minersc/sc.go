@@ -205,6 +205,10 @@ func getGlobalNode(
 	if err = gn.Decode(p.Encode()); err != nil {
 		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
 	}
+	gn.storedHash = gn.withoutCounters().GetHash()
+	if err = gn.loadCounters(balances); err != nil {
+		return nil, err
+	}
 	return gn, nil
 }
 
@@ -188,6 +188,7 @@ func (msc *MinerSmartContract) AddSharder(
 
 	msc.verifyMinerState(balances, "checking all sharders list after insert")
 
+	balances.EmitEvent(EventAddSharder, newSharder.ID, newNodeEvent(newSharder))
 	return string(newSharder.Encode()), nil
 }
 
@@ -220,6 +221,7 @@ func (msc *MinerSmartContract) DeleteSharder(
 		return "", common.NewError("delete_sharder", err.Error())
 	}
 
+	balances.EmitEvent(EventDeleteSharder, updatedSn.ID, newNodeEvent(updatedSn))
 	return "", nil
 }
 
@@ -87,6 +87,7 @@ func TestDeleteSharder(t *testing.T) {
 		balances.On("GetBlock").Return(mockBlock).Twice()
 		balances.On("GetTrieNode", ShardersKeepKey).Return(nil, util.ErrValueNotPresent).Once()
 		balances.On("InsertTrieNode", ShardersKeepKey, &MinerNodes{}).Return("", nil).Once()
+		balances.On("EmitEvent", EventDeleteSharder, mockDeletedSharderId, mock.Anything).Return().Once()
 
 		mnInput := &MinerNode{
 			SimpleNode: &SimpleNode{
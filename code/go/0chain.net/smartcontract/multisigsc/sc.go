@@ -236,7 +236,9 @@ func (ms MultiSigSmartContract) vote(currentTxnHash, signingClientID string, now
 	// execute the transfer soon. If the signature is found to be invalid,
 	// this vote transaction will fail.
 	signedTransfer := w.makeSignedTransferForProposal(p)
-	balances.AddSignedTransfer(&signedTransfer)
+	if err = balances.AddSignedTransfer(&signedTransfer); err != nil {
+		return "", common.NewError("err_vote_transfer", "adding the signed transfer: "+err.Error())
+	}
 
 	// Save the proposal again.
 	p.ExecutedInTxnHash = currentTxnHash
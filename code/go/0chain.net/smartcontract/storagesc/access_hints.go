@@ -0,0 +1,92 @@
+package storagesc
+
+import (
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+)
+
+// the functions saving the SC configurations or minting tokens to the
+// delegates of a stake pool
+var serialFunctions = map[string]bool{
+	"migrate_state_layout":     true,
+	"stake_pool_lock":          true,
+	"stake_pool_unlock":        true,
+	"stake_pool_pay_interests": true,
+	"update_settings":          true,
+}
+
+// GetAccessHints implements sci.AccessHinter for the read and the write
+// pools functions and the blobbers health checks, the stake pools functions
+// are serial. The configurations are expected to be set up already,
+// otherwise they are saved on first use. The hints describe the partitioned
+// state layout.
+func (ssc *StorageSmartContract) GetAccessHints(t *transaction.Transaction,
+	funcName string, input []byte) *sci.AccessHints {
+
+	if serialFunctions[funcName] {
+		return &sci.AccessHints{Serial: true}
+	}
+
+	switch funcName {
+	case "blobber_health_check":
+		var blobber = &StorageNode{ID: t.ClientID}
+		return &sci.AccessHints{
+			Reads: []datastore.Key{scConfigKey(ssc.ID)},
+			Writes: []datastore.Key{
+				blobber.GetKey(ssc.ID),
+				allBlobbersPartitionKey(listPartition(t.ClientID)),
+			},
+		}
+
+	case "new_read_pool":
+		return &sci.AccessHints{
+			Writes: []datastore.Key{readPoolKey(ssc.ID, t.ClientID)},
+		}
+
+	case "read_pool_lock", "write_pool_lock":
+		var lr lockRequest
+		if err := lr.decode(input); err != nil {
+			return nil
+		}
+		if len(lr.TargetId) == 0 {
+			lr.TargetId = t.ClientID
+		}
+		var (
+			alloc = &StorageAllocation{ID: lr.AllocationID}
+			hints = &sci.AccessHints{
+				Reads:  []datastore.Key{scConfigKey(ssc.ID), alloc.GetKey(ssc.ID)},
+				Writes: []datastore.Key{fundedPoolsKey(ssc.ID, t.ClientID)},
+			}
+		)
+		if funcName == "read_pool_lock" {
+			hints.Reads = append(hints.Reads, readPoolKey(ssc.ID, lr.TargetId))
+			hints.Writes = append(hints.Writes, readPoolKey(ssc.ID, t.ClientID))
+			// the tokens may be minted to the smart contract
+			hints.Clients = []datastore.Key{ssc.ID}
+		} else {
+			hints.Reads = append(hints.Reads, writePoolKey(ssc.ID, lr.TargetId))
+			hints.Writes = append(hints.Writes, writePoolKey(ssc.ID, t.ClientID),
+				alloc.GetKey(ssc.ID))
+		}
+		return hints
+
+	case "read_pool_unlock":
+		var req unlockRequest
+		if err := req.decode(input); err != nil {
+			return nil
+		}
+		if len(req.PoolOwner) == 0 {
+			req.PoolOwner = t.ClientID
+		}
+		return &sci.AccessHints{
+			Reads: []datastore.Key{
+				fundedPoolsKey(ssc.ID, t.ClientID),
+				readPoolKey(ssc.ID, req.PoolOwner),
+			},
+			Writes:  []datastore.Key{readPoolKey(ssc.ID, t.ClientID)},
+			Clients: []datastore.Key{ssc.ID},
+		}
+	}
+	return nil
+}
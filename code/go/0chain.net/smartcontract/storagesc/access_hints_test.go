@@ -0,0 +1,174 @@
+package storagesc
+
+import (
+	"testing"
+	"time"
+
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+// accessRecorder - the test balances recording the trie nodes and the
+// accounts a smart contract function accesses
+type accessRecorder struct {
+	*testBalances
+	reads, writes, clients map[datastore.Key]bool
+}
+
+func newAccessRecorder(tb *testBalances) *accessRecorder {
+	return &accessRecorder{
+		testBalances: tb,
+		reads:        make(map[datastore.Key]bool),
+		writes:       make(map[datastore.Key]bool),
+		clients:      make(map[datastore.Key]bool),
+	}
+}
+
+func (ar *accessRecorder) GetTrieNode(key datastore.Key) (util.Serializable, error) {
+	ar.reads[key] = true
+	return ar.testBalances.GetTrieNode(key)
+}
+
+func (ar *accessRecorder) InsertTrieNode(key datastore.Key, node util.Serializable) (datastore.Key, error) {
+	ar.writes[key] = true
+	return ar.testBalances.InsertTrieNode(key, node)
+}
+
+func (ar *accessRecorder) DeleteTrieNode(key datastore.Key) (datastore.Key, error) {
+	ar.writes[key] = true
+	return ar.testBalances.DeleteTrieNode(key)
+}
+
+func (ar *accessRecorder) GetClientBalance(clientID datastore.Key) (state.Balance, error) {
+	ar.clients[clientID] = true
+	return ar.testBalances.GetClientBalance(clientID)
+}
+
+func (ar *accessRecorder) AddTransfer(t *state.Transfer) error {
+	ar.clients[t.ClientID], ar.clients[t.ToClientID] = true, true
+	return ar.testBalances.AddTransfer(t)
+}
+
+func (ar *accessRecorder) AddMint(m *state.Mint) error {
+	ar.clients[m.ToClientID] = true
+	return ar.testBalances.AddMint(m)
+}
+
+// requireCovered - the hints declare everything the function accessed
+func (ar *accessRecorder) requireCovered(t *testing.T,
+	txn *transaction.Transaction, hints *sci.AccessHints) {
+
+	t.Helper()
+	var declared = func(lists ...[]datastore.Key) map[datastore.Key]bool {
+		set := make(map[datastore.Key]bool)
+		for _, list := range lists {
+			for _, k := range list {
+				set[k] = true
+			}
+		}
+		return set
+	}
+	var (
+		reads   = declared(hints.Reads, hints.Writes)
+		writes  = declared(hints.Writes)
+		clients = declared(hints.Clients, []datastore.Key{txn.ClientID})
+	)
+	if txn.Value > 0 {
+		clients[txn.ToClientID] = true
+	}
+	for k := range ar.reads {
+		require.True(t, reads[k], "undeclared read: %s", k)
+	}
+	for k := range ar.writes {
+		require.True(t, writes[k], "undeclared write: %s", k)
+	}
+	for k := range ar.clients {
+		require.True(t, clients[k], "undeclared client: %s", k)
+	}
+}
+
+func TestStorageSmartContract_GetAccessHints(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		now      = int64(10)
+	)
+	allocID, blobs := addAllocation(t, ssc, client, now,
+		int64(toSeconds(time.Hour)), 0, balances)
+
+	// the hints describe the partitioned state layout
+	var migrateTx = newTransaction(owner, ssc.ID, 0, now)
+	balances.setTransaction(t, migrateTx)
+	_, err := ssc.migrateStateLayout(migrateTx, nil, balances)
+	require.NoError(t, err)
+
+	var lr = lockRequest{Duration: 10 * time.Second, AllocationID: allocID}
+	var lockTx = newTransaction(client.id, ssc.ID, 10*x10, now)
+	tests := []struct {
+		name     string
+		funcName string
+		txn      *transaction.Transaction
+		input    []byte
+	}{
+		{
+			name:     "new read pool",
+			funcName: "new_read_pool",
+			txn:      newTransaction(client.id, ssc.ID, 0, now),
+		},
+		{
+			name:     "read pool lock",
+			funcName: "read_pool_lock",
+			txn:      lockTx,
+			input:    mustEncode(t, &lr),
+		},
+		{
+			name:     "write pool lock",
+			funcName: "write_pool_lock",
+			txn:      newTransaction(client.id, ssc.ID, 10*x10, now),
+			input:    mustEncode(t, &lr),
+		},
+		{
+			name:     "read pool unlock",
+			funcName: "read_pool_unlock",
+			txn:      newTransaction(client.id, ssc.ID, 0, now+60),
+			input:    mustEncode(t, &unlockRequest{PoolID: lockTx.Hash}),
+		},
+		{
+			name:     "blobber health check",
+			funcName: "blobber_health_check",
+			txn:      newTransaction(blobs[0].id, ssc.ID, 0, now+60),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hints := ssc.GetAccessHints(tt.txn, tt.funcName, tt.input)
+			require.NotNil(t, hints)
+			require.False(t, hints.Serial)
+
+			balances.setTransaction(t, tt.txn)
+			rec := newAccessRecorder(balances)
+			_, err := ssc.Execute(tt.txn, tt.funcName, tt.input, rec)
+			require.NoError(t, err)
+			rec.requireCovered(t, tt.txn, hints)
+		})
+	}
+
+	t.Run("serial", func(t *testing.T) {
+		for funcName := range serialFunctions {
+			hints := ssc.GetAccessHints(lockTx, funcName, nil)
+			require.NotNil(t, hints, funcName)
+			require.True(t, hints.Serial, funcName)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		require.Nil(t, ssc.GetAccessHints(lockTx, "write_pool_unlock", nil))
+		require.Nil(t, ssc.GetAccessHints(lockTx, "read_pool_lock", []byte("}{")))
+	})
+}
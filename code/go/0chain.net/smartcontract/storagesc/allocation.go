@@ -13,6 +13,7 @@ import (
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
+	"0chain.net/core/datastore"
 	"0chain.net/core/util"
 )
 
@@ -53,9 +54,19 @@ func (sc *StorageSmartContract) getAllocationsList(clientID string,
 func (sc *StorageSmartContract) getAllAllocationsList(
 	balances chainstate.StateContextI) (*Allocations, error) {
 
+	layout, err := sc.getStateLayout(balances)
+	if err != nil {
+		return nil, err
+	}
+	return getAllAllocations(layout, balances)
+}
+
+func getAllocationsNode(key datastore.Key,
+	balances chainstate.StateContextI) (*Allocations, error) {
+
 	allocationList := &Allocations{}
 
-	allocationListBytes, err := balances.GetTrieNode(ALL_ALLOCATIONS_KEY)
+	allocationListBytes, err := balances.GetTrieNode(key)
 	if allocationListBytes == nil {
 		return allocationList, nil
 	}
@@ -121,14 +132,12 @@ func (sc *StorageSmartContract) addUserAllocation(
 	return nil
 }
 
-func (sc *StorageSmartContract) addAllocation(alloc *StorageAllocation,
-	balances chainstate.StateContextI) (string, error) {
-	var err error
-	if err != nil {
-		return "", common.NewErrorf("add_allocation_failed",
-			"Failed to get allocation list: %v", err)
-	}
-	all, err := sc.getAllAllocationsList(balances)
+func (sc *StorageSmartContract) addAllocation(conf *scConfig,
+	alloc *StorageAllocation, balances chainstate.StateContextI) (
+	string, error) {
+
+	all, err := getAllocationsPartitionsOf(conf.StateLayout, balances,
+		alloc.ID)
 	if err != nil {
 		return "", common.NewErrorf("add_allocation_failed",
 			"Failed to get allocation list: %v", err)
@@ -149,7 +158,8 @@ func (sc *StorageSmartContract) addAllocation(alloc *StorageAllocation,
 
 	all.List.add(alloc.ID)
 
-	if _, err = balances.InsertTrieNode(ALL_ALLOCATIONS_KEY, all); err != nil {
+	err = saveAllocationsPartitionsOf(conf.StateLayout, all, balances, alloc.ID)
+	if err != nil {
 		return "", common.NewErrorf("add_allocation_failed",
 			"saving all allocations list: %v", err)
 	}
@@ -232,8 +242,8 @@ func (sc *StorageSmartContract) addBlobbersOffers(sa *StorageAllocation,
 }
 
 // update blobbers list in the all blobbers list
-func updateBlobbersInAll(all *StorageNodes, update []*StorageNode,
-	balances chainstate.StateContextI) (err error) {
+func updateBlobbersInAll(layout int, all *StorageNodes,
+	update []*StorageNode, balances chainstate.StateContextI) (err error) {
 
 	// update the blobbers in all blobbers list
 	for _, b := range update {
@@ -244,7 +254,8 @@ func updateBlobbersInAll(all *StorageNodes, update []*StorageNode,
 	}
 
 	// save
-	_, err = balances.InsertTrieNode(ALL_BLOBBERS_KEY, all)
+	err = saveBlobbersPartitionsOf(layout, all, balances,
+		blobbersIDs(update)...)
 	if err != nil {
 		return fmt.Errorf("can't save all blobber list: %v", err)
 	}
@@ -310,7 +321,7 @@ func (sc *StorageSmartContract) newAllocationRequestInternal(
 	balances chainstate.StateContextI,
 ) (resp string, err error) {
 	var allBlobbersList *StorageNodes
-	allBlobbersList, err = sc.getBlobbersList(balances)
+	allBlobbersList, err = getAllBlobbers(conf.StateLayout, balances)
 	if err != nil {
 		return "", common.NewErrorf("allocation_creation_failed",
 			"getting blobber list: %v", err)
@@ -381,7 +392,8 @@ func (sc *StorageSmartContract) newAllocationRequestInternal(
 		return "", common.NewError("allocation_creation_failed", err.Error())
 	}
 
-	err = updateBlobbersInAll(allBlobbersList, blobberNodes, balances)
+	err = updateBlobbersInAll(conf.StateLayout, allBlobbersList, blobberNodes,
+		balances)
 	if err != nil {
 		return "", common.NewError("allocation_creation_failed", err.Error())
 	}
@@ -395,10 +407,11 @@ func (sc *StorageSmartContract) newAllocationRequestInternal(
 		return "", common.NewError("allocation_creation_failed", err.Error())
 	}
 
-	if resp, err = sc.addAllocation(sa, balances); err != nil {
+	if resp, err = sc.addAllocation(conf, sa, balances); err != nil {
 		return "", common.NewErrorf("allocation_creation_failed", "%v", err)
 	}
 
+	balances.EmitEvent(EventNewAllocation, sa.ID, newAllocationEvent(sa))
 	return resp, err
 }
 
@@ -581,12 +594,13 @@ func (sc *StorageSmartContract) closeAllocation(t *transaction.Transaction,
 	return string(alloc.Encode()), nil // closing
 }
 
-func (sc *StorageSmartContract) saveUpdatedAllocation(all *StorageNodes,
-	alloc *StorageAllocation, blobbers []*StorageNode,
+func (sc *StorageSmartContract) saveUpdatedAllocation(conf *scConfig,
+	all *StorageNodes, alloc *StorageAllocation, blobbers []*StorageNode,
 	balances chainstate.StateContextI) (err error) {
 
 	// save all
-	if err = updateBlobbersInAll(all, blobbers, balances); err != nil {
+	err = updateBlobbersInAll(conf.StateLayout, all, blobbers, balances)
+	if err != nil {
 		return
 	}
 
@@ -934,15 +948,26 @@ func (sc *StorageSmartContract) updateAllocationRequestInternal(
 	balances chainstate.StateContextI,
 ) (resp string, err error) {
 
-	var all *StorageNodes // all blobbers list
-	if all, err = sc.getBlobbersList(balances); err != nil {
-		return "", common.NewError("allocation_updating_failed",
-			"can't get all blobbers list: "+err.Error())
+	// all blobbers list; under the partitioned layout only the partitions of
+	// the blobbers of the allocation are read, once they are known
+	var all *StorageNodes
+	checkAll := func() error {
+		if err != nil {
+			return common.NewError("allocation_updating_failed",
+				"can't get all blobbers list: "+err.Error())
+		}
+		if len(all.Nodes) == 0 {
+			return common.NewError("allocation_updating_failed",
+				"empty blobbers list")
+		}
+		return nil
 	}
 
-	if len(all.Nodes) == 0 {
-		return "", common.NewError("allocation_updating_failed",
-			"empty blobbers list")
+	if conf.StateLayout < partitionedStateLayout {
+		all, err = getAllBlobbers(conf.StateLayout, balances)
+		if err = checkAll(); err != nil {
+			return "", err
+		}
 	}
 
 	if t.ClientID == "" {
@@ -995,6 +1020,14 @@ func (sc *StorageSmartContract) updateAllocationRequestInternal(
 			err.Error())
 	}
 
+	if all == nil {
+		all, err = getBlobbersPartitionsOf(conf.StateLayout, balances,
+			blobbersIDs(blobbers)...)
+		if err = checkAll(); err != nil {
+			return "", err
+		}
+	}
+
 	// adjust expiration
 	var newExpiration = alloc.Expiration + request.Expiration
 
@@ -1039,11 +1072,12 @@ func (sc *StorageSmartContract) updateAllocationRequestInternal(
 		alloc.IsImmutable = true
 	}
 
-	err = sc.saveUpdatedAllocation(all, alloc, blobbers, balances)
+	err = sc.saveUpdatedAllocation(conf, all, alloc, blobbers, balances)
 	if err != nil {
 		return "", common.NewErrorf("allocation_reducing_failed", "%v", err)
 	}
 
+	balances.EmitEvent(EventUpdateAllocation, alloc.ID, newAllocationEvent(alloc))
 	return string(alloc.Encode()), nil
 }
 
@@ -1269,6 +1303,7 @@ func (sc *StorageSmartContract) cancelAllocationRequest(
 			"saving allocation: "+err.Error())
 	}
 
+	balances.EmitEvent(EventCancelAllocation, alloc.ID, newAllocationEvent(alloc))
 	return "canceled", nil
 }
 
@@ -1343,6 +1378,7 @@ func (sc *StorageSmartContract) finalizeAllocation(
 			"saving allocation: "+err.Error())
 	}
 
+	balances.EmitEvent(EventFinalizeAllocation, alloc.ID, newAllocationEvent(alloc))
 	return "finalized", nil
 }
 
@@ -1359,6 +1395,10 @@ func (sc *StorageSmartContract) finishAllocation(
 		return common.NewError("fini_alloc_failed",
 			"can't get SC configurations: "+err.Error())
 	}
+	if err = sc.loadMinted(conf, balances); err != nil {
+		return common.NewError("fini_alloc_failed",
+			"can't get minted tokens: "+err.Error())
+	}
 
 	// write pool
 	var wp *writePool
@@ -1382,7 +1422,9 @@ func (sc *StorageSmartContract) finishAllocation(
 	}
 
 	var allb *StorageNodes
-	if allb, err = sc.getBlobbersList(balances); err != nil {
+	allb, err = getBlobbersPartitionsOf(conf.StateLayout, balances,
+		blobbersIDs(blobbers)...)
+	if err != nil {
 		return common.NewError("fini_alloc_failed",
 			"can't get all blobbers list: "+err.Error())
 	}
@@ -1455,7 +1497,8 @@ func (sc *StorageSmartContract) finishAllocation(
 	}
 
 	// save all blobbers list
-	_, err = balances.InsertTrieNode(ALL_BLOBBERS_KEY, allb)
+	err = saveBlobbersPartitionsOf(conf.StateLayout, allb, balances,
+		blobbersIDs(blobbers)...)
 	if err != nil {
 		return common.NewError("fini_alloc_failed",
 			"saving all blobbers list: "+err.Error())
@@ -1476,7 +1519,8 @@ func (sc *StorageSmartContract) finishAllocation(
 	alloc.Finalized = true
 
 	var all *Allocations
-	if all, err = sc.getAllAllocationsList(balances); err != nil {
+	all, err = getAllocationsPartitionsOf(conf.StateLayout, balances, alloc.ID)
+	if err != nil {
 		return common.NewError("fini_alloc_failed",
 			"getting all allocations list: "+err.Error())
 	}
@@ -1486,15 +1530,14 @@ func (sc *StorageSmartContract) finishAllocation(
 			"invalid state: allocation not found in all allocations list")
 	}
 
-	_, err = balances.InsertTrieNode(ALL_ALLOCATIONS_KEY, all)
+	err = saveAllocationsPartitionsOf(conf.StateLayout, all, balances, alloc.ID)
 	if err != nil {
 		return common.NewError("fini_alloc_failed",
 			"saving all allocations list: "+err.Error())
 	}
 
 	// save configuration (minted tokens)
-	_, err = balances.InsertTrieNode(scConfigKey(sc.ID), conf)
-	if err != nil {
+	if err = sc.saveMinted(conf, balances); err != nil {
 		return common.NewError("fini_alloc_failed",
 			"saving configurations: "+err.Error())
 	}
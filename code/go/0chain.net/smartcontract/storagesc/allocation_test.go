@@ -854,7 +854,8 @@ func Test_updateBlobbersInAll(t *testing.T) {
 	u1.ID, u2.ID = "b1", "b2"
 	u1.Capacity, u2.Capacity = 200, 200
 
-	err = updateBlobbersInAll(&all, []*StorageNode{&u1, &u2}, balances)
+	err = updateBlobbersInAll(legacyStateLayout, &all, []*StorageNode{&u1, &u2},
+		balances)
 	require.NoError(t, err)
 
 	var allSeri, ok = balances.tree[ALL_BLOBBERS_KEY]
@@ -0,0 +1,65 @@
+package storagesc
+
+import (
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	bk "0chain.net/smartcontract/benchmark"
+
+	"github.com/spf13/viper"
+)
+
+// BenchmarkBlockTxns - the storage transactions of the mixed blocks of the
+// parallel execution benchmark. A client locks tokens in the read and write
+// pools of the allocation with the same index and stakes the blobber with
+// the same index.
+func BenchmarkBlockTxns() []bk.BlockTxn {
+	return []bk.BlockTxn{
+		{
+			Name: "storage.read_pool_lock",
+			Build: func(data bk.BenchData, client int) *transaction.Transaction {
+				return bk.SmartContractTransaction(
+					data.Clients[client],
+					ADDRESS,
+					"read_pool_lock",
+					int64(viper.GetFloat64(bk.StorageReadPoolMinLock)*1e10),
+					common.Now(),
+					&lockRequest{
+						Duration:     viper.GetDuration(bk.StorageReadPoolMinLockPeriod),
+						AllocationID: getMockAllocationId(client),
+					},
+				)
+			},
+		},
+		{
+			Name: "storage.write_pool_lock",
+			Build: func(data bk.BenchData, client int) *transaction.Transaction {
+				return bk.SmartContractTransaction(
+					data.Clients[client],
+					ADDRESS,
+					"write_pool_lock",
+					int64(viper.GetFloat64(bk.StorageWritePoolMinLock)*1e10),
+					common.Now(),
+					&lockRequest{
+						Duration:     viper.GetDuration(bk.StorageWritePoolMinLockPeriod),
+						AllocationID: getMockAllocationId(client),
+					},
+				)
+			},
+		},
+		{
+			Name: "storage.stake_pool_lock",
+			Build: func(data bk.BenchData, client int) *transaction.Transaction {
+				return bk.SmartContractTransaction(
+					data.Clients[client],
+					ADDRESS,
+					"stake_pool_lock",
+					int64(viper.GetFloat64(bk.StorageStakePoolMinLock)*1e10),
+					common.Timestamp(viper.GetInt64(bk.Now)),
+					&stakePoolRequest{
+						BlobberID: getMockBlobberId(client % viper.GetInt(bk.NumBlobbers)),
+					},
+				)
+			},
+		},
+	}
+}
@@ -9,14 +9,23 @@ import (
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
+	"0chain.net/core/datastore"
 	"0chain.net/core/util"
 )
 
 const blobberHealthTime = 60 * 60 // 1 Hour
 
 func (sc *StorageSmartContract) getBlobbersList(balances cstate.StateContextI) (*StorageNodes, error) {
+	layout, err := sc.getStateLayout(balances)
+	if err != nil {
+		return nil, err
+	}
+	return getAllBlobbers(layout, balances)
+}
+
+func getBlobbersNode(key datastore.Key, balances cstate.StateContextI) (*StorageNodes, error) {
 	allBlobbersList := &StorageNodes{}
-	allBlobbersBytes, err := balances.GetTrieNode(ALL_BLOBBERS_KEY)
+	allBlobbersBytes, err := balances.GetTrieNode(key)
 	if allBlobbersBytes == nil {
 		return allBlobbersList, nil
 	}
@@ -174,7 +183,7 @@ func (sc *StorageSmartContract) addBlobber(t *transaction.Transaction,
 	}
 
 	// get registered blobbers
-	blobbers, err := sc.getBlobbersList(balances)
+	blobbers, err := getAllBlobbers(conf.StateLayout, balances)
 	if err != nil {
 		return "", common.NewError("add_or_update_blobber_failed",
 			"Failed to get blobber list: "+err.Error())
@@ -197,7 +206,8 @@ func (sc *StorageSmartContract) addBlobber(t *transaction.Transaction,
 	}
 
 	// save all the blobbers
-	_, err = balances.InsertTrieNode(ALL_BLOBBERS_KEY, blobbers)
+	err = saveBlobbersPartitionsOf(conf.StateLayout, blobbers, balances,
+		blobber.ID)
 	if err != nil {
 		return "", common.NewError("add_or_update_blobber_failed",
 			"saving all blobbers: "+err.Error())
@@ -224,18 +234,20 @@ func (sc *StorageSmartContract) updateBlobberSettings(t *transaction.Transaction
 			"can't get config: "+err.Error())
 	}
 
-	var blobbers *StorageNodes
-	if blobbers, err = sc.getBlobbersList(balances); err != nil {
-		return "", common.NewError("update_blobber_settings_failed",
-			"failed to get blobber list: "+err.Error())
-	}
-
 	var updatedBlobber = new(StorageNode)
 	if err = updatedBlobber.Decode(input); err != nil {
 		return "", common.NewError("update_blobber_settings_failed",
 			"malformed request: "+err.Error())
 	}
 
+	var blobbers *StorageNodes
+	blobbers, err = getBlobbersPartitionsOf(conf.StateLayout, balances,
+		updatedBlobber.ID)
+	if err != nil {
+		return "", common.NewError("update_blobber_settings_failed",
+			"failed to get blobber list: "+err.Error())
+	}
+
 	var blobber *StorageNode
 	if blobber, err = sc.getBlobber(updatedBlobber.ID, balances); err != nil {
 		return "", common.NewError("update_blobber_settings_failed",
@@ -266,7 +278,8 @@ func (sc *StorageSmartContract) updateBlobberSettings(t *transaction.Transaction
 	}
 
 	// save all the blobbers
-	_, err = balances.InsertTrieNode(ALL_BLOBBERS_KEY, blobbers)
+	err = saveBlobbersPartitionsOf(conf.StateLayout, blobbers, balances,
+		blobber.ID)
 	if err != nil {
 		return "", common.NewError("update_blobber_settings_failed",
 			"saving all blobbers: "+err.Error())
@@ -291,7 +304,13 @@ func filterHealthyBlobbers(now common.Timestamp) filterBlobberFunc {
 func (sc *StorageSmartContract) blobberHealthCheck(t *transaction.Transaction,
 	_ []byte, balances cstate.StateContextI,
 ) (string, error) {
-	all, err := sc.getBlobbersList(balances)
+	layout, err := sc.getStateLayout(balances)
+	if err != nil {
+		return "", common.NewError("blobber_health_check_failed",
+			"can't get state layout: "+err.Error())
+	}
+
+	all, err := getBlobbersPartitionsOf(layout, balances, t.ClientID)
 	if err != nil {
 		return "", common.NewError("blobber_health_check_failed",
 			"Failed to get blobber list: "+err.Error())
@@ -314,7 +333,8 @@ func (sc *StorageSmartContract) blobberHealthCheck(t *transaction.Transaction,
 	}
 	var found = all.Nodes[i]
 	found.LastHealthCheck = t.CreationDate
-	if _, err = balances.InsertTrieNode(ALL_BLOBBERS_KEY, all); err != nil {
+	err = saveBlobbersPartitionsOf(layout, all, balances, t.ClientID)
+	if err != nil {
 		return "", common.NewError("blobber_health_check_failed",
 			"can't save all blobbers list: "+err.Error())
 	}
@@ -20,7 +20,12 @@ func (ssc *StorageSmartContract) payBlobberBlockRewards(
 		return nil
 	}
 
-	allBlobbers, err := ssc.getBlobbersList(balances)
+	if err = ssc.loadMinted(conf, balances); err != nil {
+		return common.NewError("blobber_block_rewards_failed",
+			"cannot get minted tokens: "+err.Error())
+	}
+
+	allBlobbers, err := getAllBlobbers(conf.StateLayout, balances)
 	if err != nil {
 		return common.NewError("blobber_block_rewards_failed",
 			"cannot get all blobbers list: "+err.Error())
@@ -76,8 +81,7 @@ func (ssc *StorageSmartContract) payBlobberBlockRewards(
 	}
 
 	// save configuration (minted tokens)
-	_, err = balances.InsertTrieNode(scConfigKey(ssc.ID), conf)
-	if err != nil {
+	if err = ssc.saveMinted(conf, balances); err != nil {
 		return common.NewError("blobber_block_rewards_failed",
 			"saving configurations: "+err.Error())
 	}
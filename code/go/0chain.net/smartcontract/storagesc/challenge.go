@@ -450,6 +450,13 @@ func (sc *StorageSmartContract) verifyChallenge(t *transaction.Transaction,
 			return "", common.NewError("challenge_reward_error", err.Error())
 		}
 
+		balances.EmitEvent(EventChallengePassed, challReq.ID, &challengeEvent{
+			ChallengeID:  challReq.ID,
+			AllocationID: alloc.ID,
+			BlobberID:    t.ClientID,
+			Success:      success,
+			Failure:      failure,
+		})
 		if success < threshold {
 			return "challenge passed partially by blobber", nil
 		}
@@ -493,6 +500,13 @@ func (sc *StorageSmartContract) verifyChallenge(t *transaction.Transaction,
 			return "", common.NewError("challenge_reward_error", err.Error())
 		}
 
+		balances.EmitEvent(EventChallengeFailed, challReq.ID, &challengeEvent{
+			ChallengeID:  challReq.ID,
+			AllocationID: alloc.ID,
+			BlobberID:    t.ClientID,
+			Success:      success,
+			Failure:      failure,
+		})
 		if pass && !fresh {
 			return "late challenge (failed)", nil
 		}
@@ -584,7 +598,7 @@ func (sc *StorageSmartContract) generateChallenges(t *transaction.Transaction,
 	// select allocations for the challenges
 
 	var validators *ValidatorNodes
-	if validators, err = sc.getValidatorsList(balances); err != nil {
+	if validators, err = getAllValidators(conf.StateLayout, balances); err != nil {
 		return common.NewErrorf("adding_challenge_error",
 			"error getting the validators list: %v", err)
 	}
@@ -595,7 +609,7 @@ func (sc *StorageSmartContract) generateChallenges(t *transaction.Transaction,
 	}
 
 	var all *Allocations
-	if all, err = sc.getAllAllocationsList(balances); err != nil {
+	if all, err = getAllAllocations(conf.StateLayout, balances); err != nil {
 		return common.NewErrorf("adding_challenge_error",
 			"error getting the allocation list: %v", err)
 	}
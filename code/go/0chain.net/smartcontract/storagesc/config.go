@@ -160,6 +160,9 @@ type scConfig struct {
 
 	// Allow direct access to MPT
 	ExposeMpt bool `json:"expose_mpt"`
+	// StateLayout is version of the SC state layout. The Minted is kept
+	// apart from the configurations under the partitioned layout.
+	StateLayout int `json:"state_layout,omitempty"`
 }
 
 func (sc *scConfig) validate() (err error) {
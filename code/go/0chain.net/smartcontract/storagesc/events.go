@@ -0,0 +1,60 @@
+package storagesc
+
+import (
+	"0chain.net/chaincore/state"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+)
+
+// the types of the events emitted by the storage smart contract, the tag of
+// an event is the ID of the allocation, stake pool or challenge it's about
+const (
+	EventNewAllocation      = "new_allocation"
+	EventUpdateAllocation   = "update_allocation"
+	EventCancelAllocation   = "cancel_allocation"
+	EventFinalizeAllocation = "finalize_allocation"
+	EventStakePoolLock      = "stake_pool_lock"
+	EventStakePoolUnlock    = "stake_pool_unlock"
+	EventChallengePassed    = "challenge_passed"
+	EventChallengeFailed    = "challenge_failed"
+)
+
+// allocationEvent - the payload of the allocation events
+type allocationEvent struct {
+	ID         string           `json:"id"`
+	Owner      string           `json:"owner"`
+	Size       int64            `json:"size"`
+	Expiration common.Timestamp `json:"expiration"`
+	Blobbers   []string         `json:"blobbers"`
+}
+
+func newAllocationEvent(sa *StorageAllocation) *allocationEvent {
+	ae := &allocationEvent{
+		ID:         sa.ID,
+		Owner:      sa.Owner,
+		Size:       sa.Size,
+		Expiration: sa.Expiration,
+	}
+	for _, d := range sa.BlobberDetails {
+		ae.Blobbers = append(ae.Blobbers, d.BlobberID)
+	}
+	return ae
+}
+
+// stakePoolEvent - the payload of the stake pool events
+type stakePoolEvent struct {
+	BlobberID datastore.Key    `json:"blobber_id"`
+	PoolID    datastore.Key    `json:"pool_id"`
+	ClientID  datastore.Key    `json:"client_id"`
+	Amount    state.Balance    `json:"amount,omitempty"`
+	Unstake   common.Timestamp `json:"unstake,omitempty"`
+}
+
+// challengeEvent - the payload of the challenge events
+type challengeEvent struct {
+	ChallengeID  string `json:"challenge_id"`
+	AllocationID string `json:"allocation_id"`
+	BlobberID    string `json:"blobber_id"`
+	Success      int    `json:"success"`
+	Failure      int    `json:"failure"`
+}
@@ -413,6 +413,10 @@ func TestFreeAllocationRequest(t *testing.T) {
 					pool.ExpireAt == txn.CreationDate+toSeconds(conf.FreeAllocationSettings.Duration)
 			})).Return("", nil).Once()
 
+		balances.On(
+			"EmitEvent", EventNewAllocation, mockTransactionHash, mock.Anything,
+		).Return().Once()
+
 		return args{ssc, txn, input, balances}
 	}
 
@@ -745,6 +749,10 @@ func TestUpdateFreeStorageRequest(t *testing.T) {
 			}),
 		).Return("", nil).Once()
 
+		balances.On(
+			"EmitEvent", EventUpdateAllocation, p.allocationId, mock.Anything,
+		).Return().Once()
+
 		return args{ssc, txn, input, balances}
 	}
 
@@ -47,7 +47,7 @@ func (sc *mockStateContext) Validate() error                             { retur
 func (sc *mockStateContext) GetSignatureScheme() encryption.SignatureScheme {
 	return encryption.NewBLS0ChainScheme()
 }
-func (sc *mockStateContext) AddSignedTransfer(_ *state.SignedTransfer)             { return }
+func (sc *mockStateContext) AddSignedTransfer(_ *state.SignedTransfer) error       { return nil }
 func (sc *mockStateContext) DeleteTrieNode(_ datastore.Key) (datastore.Key, error) { return "", nil }
 func (sc *mockStateContext) GetChainCurrentMagicBlock() *block.MagicBlock          { return nil }
 func (sc *mockStateContext) GetClientBalance(_ datastore.Key) (state.Balance, error) {
@@ -62,6 +62,18 @@ func (sc *mockStateContext) GetMints() []*state.Mint {
 	return sc.ctx.GetMints()
 }
 
+func (sc *mockStateContext) AddLog(l *transaction.Log) {
+	sc.ctx.AddLog(l)
+}
+
+func (sc *mockStateContext) GetLogs() []*transaction.Log {
+	return sc.ctx.GetLogs()
+}
+
+func (sc *mockStateContext) EmitEvent(eventType, tag string, payload interface{}) {
+	sc.ctx.EmitEvent(eventType, tag, payload)
+}
+
 func (sc *mockStateContext) GetLastestFinalizedMagicBlock() *block.Block {
 	return nil
 }
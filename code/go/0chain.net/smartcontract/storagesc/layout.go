@@ -0,0 +1,479 @@
+package storagesc
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	chainstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+// The storage SC state layouts. Under the legacy layout the lists of all
+// blobbers, all validators and all allocations are single MPT nodes and the
+// minted tokens are kept in the SC configurations, so that any two
+// transactions updating a blobber in the list, adding a validator or an
+// allocation or minting tokens conflict with each other and with every
+// transaction reading the configurations. Under the partitioned layout the
+// lists are split in partitions by IDs and the minted tokens are kept apart
+// from the read-mostly configurations.
+// The SC owner moves the state to the partitioned layout by the
+// migrate_state_layout function.
+const (
+	legacyStateLayout      = 0
+	partitionedStateLayout = 1
+)
+
+// number of partitions of the lists of all blobbers, all validators and
+// all allocations
+const listPartitions = 16
+
+func allBlobbersPartitionKey(i int) datastore.Key {
+	return datastore.Key(ADDRESS + encryption.Hash("all_blobbers:"+strconv.Itoa(i)))
+}
+
+func allValidatorsPartitionKey(i int) datastore.Key {
+	return datastore.Key(ADDRESS + encryption.Hash("all_validators:"+strconv.Itoa(i)))
+}
+
+func allAllocationsPartitionKey(i int) datastore.Key {
+	return datastore.Key(ADDRESS + encryption.Hash("all_allocations:"+strconv.Itoa(i)))
+}
+
+// listPartition returns partition of given blobber or allocation
+func listPartition(id string) int {
+	var hash = encryption.RawHash(id)
+	return int(binary.BigEndian.Uint32(hash) % listPartitions)
+}
+
+// listPartitionsOf returns sorted unique partitions of given IDs
+func listPartitionsOf(ids []string) (parts []int) {
+	var seen = make(map[int]bool, len(ids))
+	for _, id := range ids {
+		var i = listPartition(id)
+		if !seen[i] {
+			seen[i] = true
+			parts = append(parts, i)
+		}
+	}
+	sort.Ints(parts)
+	return
+}
+
+func allListPartitions() (parts []int) {
+	parts = make([]int, 0, listPartitions)
+	for i := 0; i < listPartitions; i++ {
+		parts = append(parts, i)
+	}
+	return
+}
+
+// getStateLayout returns state layout of the SC, the legacy one if the SC
+// configurations are not set up yet
+func (ssc *StorageSmartContract) getStateLayout(
+	balances chainstate.StateContextI) (layout int, err error) {
+
+	var conf *scConfig
+	conf, err = ssc.getConfig(balances, false)
+	if err == util.ErrValueNotPresent {
+		return legacyStateLayout, nil
+	}
+	if err != nil {
+		return
+	}
+	return conf.StateLayout, nil
+}
+
+//
+// blobbers
+//
+
+// getAllBlobbers returns list of all blobbers, partitions of the list are
+// merged in order of the blobbers IDs
+func getAllBlobbers(layout int, balances chainstate.StateContextI) (
+	*StorageNodes, error) {
+
+	if layout < partitionedStateLayout {
+		return getBlobbersNode(ALL_BLOBBERS_KEY, balances)
+	}
+	return getBlobbersPartitions(allListPartitions(), balances)
+}
+
+// getBlobbersPartitionsOf returns list of all blobbers under the legacy
+// layout or the partitions containing given blobbers
+func getBlobbersPartitionsOf(layout int, balances chainstate.StateContextI,
+	ids ...string) (*StorageNodes, error) {
+
+	if layout < partitionedStateLayout {
+		return getBlobbersNode(ALL_BLOBBERS_KEY, balances)
+	}
+	return getBlobbersPartitions(listPartitionsOf(ids), balances)
+}
+
+func getBlobbersPartitions(parts []int, balances chainstate.StateContextI) (
+	*StorageNodes, error) {
+
+	var all = new(StorageNodes)
+	for _, i := range parts {
+		var part, err = getBlobbersNode(allBlobbersPartitionKey(i), balances)
+		if err != nil {
+			return nil, err
+		}
+		all.Nodes = append(all.Nodes, part.Nodes...)
+	}
+	sort.Slice(all.Nodes, func(i, j int) bool {
+		return all.Nodes[i].ID < all.Nodes[j].ID
+	})
+	return all, nil
+}
+
+// saveBlobbersPartitionsOf saves list of all blobbers under the legacy layout
+// or the partitions containing given blobbers; the list should be loaded by
+// getBlobbersPartitionsOf with the same blobbers or by getAllBlobbers
+func saveBlobbersPartitionsOf(layout int, all *StorageNodes,
+	balances chainstate.StateContextI, ids ...string) (err error) {
+
+	if layout < partitionedStateLayout {
+		_, err = balances.InsertTrieNode(ALL_BLOBBERS_KEY, all)
+		return
+	}
+	for _, i := range listPartitionsOf(ids) {
+		var part = new(StorageNodes)
+		for _, b := range all.Nodes {
+			if listPartition(b.ID) == i {
+				part.Nodes = append(part.Nodes, b)
+			}
+		}
+		_, err = balances.InsertTrieNode(allBlobbersPartitionKey(i), part)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func blobbersIDs(blobbers []*StorageNode) (ids []string) {
+	ids = make([]string, 0, len(blobbers))
+	for _, b := range blobbers {
+		ids = append(ids, b.ID)
+	}
+	return
+}
+
+//
+// validators
+//
+
+// getAllValidators returns list of all validators, partitions of the list
+// are merged in order of the validators IDs
+func getAllValidators(layout int, balances chainstate.StateContextI) (
+	*ValidatorNodes, error) {
+
+	if layout < partitionedStateLayout {
+		return getValidatorsNode(ALL_VALIDATORS_KEY, balances)
+	}
+	return getValidatorsPartitions(allListPartitions(), balances)
+}
+
+// getValidatorsPartitionsOf returns list of all validators under the legacy
+// layout or the partitions containing given validators
+func getValidatorsPartitionsOf(layout int, balances chainstate.StateContextI,
+	ids ...string) (*ValidatorNodes, error) {
+
+	if layout < partitionedStateLayout {
+		return getValidatorsNode(ALL_VALIDATORS_KEY, balances)
+	}
+	return getValidatorsPartitions(listPartitionsOf(ids), balances)
+}
+
+func getValidatorsPartitions(parts []int, balances chainstate.StateContextI) (
+	*ValidatorNodes, error) {
+
+	var all = new(ValidatorNodes)
+	for _, i := range parts {
+		var part, err = getValidatorsNode(allValidatorsPartitionKey(i),
+			balances)
+		if err != nil {
+			return nil, err
+		}
+		all.Nodes = append(all.Nodes, part.Nodes...)
+	}
+	sort.SliceStable(all.Nodes, func(i, j int) bool {
+		return all.Nodes[i].ID < all.Nodes[j].ID
+	})
+	return all, nil
+}
+
+// saveValidatorsPartitionsOf saves list of all validators under the legacy
+// layout or the partitions containing given validators; the list should be
+// loaded by getValidatorsPartitionsOf with the same validators or by
+// getAllValidators
+func saveValidatorsPartitionsOf(layout int, all *ValidatorNodes,
+	balances chainstate.StateContextI, ids ...string) (err error) {
+
+	if layout < partitionedStateLayout {
+		_, err = balances.InsertTrieNode(ALL_VALIDATORS_KEY, all)
+		return
+	}
+	for _, i := range listPartitionsOf(ids) {
+		var part = new(ValidatorNodes)
+		for _, v := range all.Nodes {
+			if listPartition(v.ID) == i {
+				part.Nodes = append(part.Nodes, v)
+			}
+		}
+		_, err = balances.InsertTrieNode(allValidatorsPartitionKey(i), part)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func validatorsIDs(validators []*ValidationNode) (ids []string) {
+	ids = make([]string, 0, len(validators))
+	for _, v := range validators {
+		ids = append(ids, v.ID)
+	}
+	return
+}
+
+//
+// allocations
+//
+
+// getAllAllocations returns list of all allocations, partitions of the list
+// are merged in order of the allocations IDs
+func getAllAllocations(layout int, balances chainstate.StateContextI) (
+	*Allocations, error) {
+
+	if layout < partitionedStateLayout {
+		return getAllocationsNode(ALL_ALLOCATIONS_KEY, balances)
+	}
+	return getAllocationsPartitions(allListPartitions(), balances)
+}
+
+// getAllocationsPartitionsOf returns list of all allocations under the
+// legacy layout or the partitions containing given allocations
+func getAllocationsPartitionsOf(layout int, balances chainstate.StateContextI,
+	ids ...string) (*Allocations, error) {
+
+	if layout < partitionedStateLayout {
+		return getAllocationsNode(ALL_ALLOCATIONS_KEY, balances)
+	}
+	return getAllocationsPartitions(listPartitionsOf(ids), balances)
+}
+
+func getAllocationsPartitions(parts []int,
+	balances chainstate.StateContextI) (*Allocations, error) {
+
+	var all = new(Allocations)
+	for _, i := range parts {
+		var part, err = getAllocationsNode(allAllocationsPartitionKey(i),
+			balances)
+		if err != nil {
+			return nil, err
+		}
+		all.List = append(all.List, part.List...)
+	}
+	sort.Strings(all.List)
+	return all, nil
+}
+
+// saveAllocationsPartitionsOf saves list of all allocations under the legacy
+// layout or the partitions containing given allocations
+func saveAllocationsPartitionsOf(layout int, all *Allocations,
+	balances chainstate.StateContextI, ids ...string) (err error) {
+
+	if layout < partitionedStateLayout {
+		_, err = balances.InsertTrieNode(ALL_ALLOCATIONS_KEY, all)
+		return
+	}
+	for _, i := range listPartitionsOf(ids) {
+		var part = new(Allocations)
+		for _, id := range all.List {
+			if listPartition(id) == i {
+				part.List = append(part.List, id)
+			}
+		}
+		_, err = balances.InsertTrieNode(allAllocationsPartitionKey(i), part)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+//
+// minted tokens
+//
+
+func mintedKey(scKey string) datastore.Key {
+	return datastore.Key(scKey + ":minted")
+}
+
+// mintedTokens is tokens minted by the SC, kept apart from the SC
+// configurations under the partitioned layout.
+type mintedTokens struct {
+	Minted state.Balance `json:"minted"`
+}
+
+func (mt *mintedTokens) Encode() []byte {
+	var b, err = json.Marshal(mt)
+	if err != nil {
+		panic(err) // must never happen
+	}
+	return b
+}
+
+func (mt *mintedTokens) Decode(p []byte) error {
+	return json.Unmarshal(p, mt)
+}
+
+// loadMinted sets minted tokens of given configurations kept apart from
+// them under the partitioned layout; it should be called before the
+// configurations minting tokens are used
+func (ssc *StorageSmartContract) loadMinted(conf *scConfig,
+	balances chainstate.StateContextI) (err error) {
+
+	if conf.StateLayout < partitionedStateLayout {
+		return // kept in the configurations
+	}
+	var (
+		mt  mintedTokens
+		val util.Serializable
+	)
+	if val, err = balances.GetTrieNode(mintedKey(ssc.ID)); err != nil {
+		return fmt.Errorf("getting minted tokens: %v", err)
+	}
+	if err = mt.Decode(val.Encode()); err != nil {
+		return fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	conf.Minted = mt.Minted
+	return
+}
+
+// saveMinted saves minted tokens of given configurations, that's the
+// configurations under the legacy layout
+func (ssc *StorageSmartContract) saveMinted(conf *scConfig,
+	balances chainstate.StateContextI) (err error) {
+
+	if conf.StateLayout < partitionedStateLayout {
+		_, err = balances.InsertTrieNode(scConfigKey(ssc.ID), conf)
+		return
+	}
+	_, err = balances.InsertTrieNode(mintedKey(ssc.ID),
+		&mintedTokens{Minted: conf.Minted})
+	return
+}
+
+//
+// migration
+//
+
+func deleteLegacyNode(key datastore.Key,
+	balances chainstate.StateContextI) (err error) {
+
+	if _, err = balances.GetTrieNode(key); err == util.ErrValueNotPresent {
+		return nil // nothing to delete
+	}
+	if err != nil {
+		return
+	}
+	_, err = balances.DeleteTrieNode(key)
+	return
+}
+
+// migrateStateLayout moves the SC state to the partitioned layout: the
+// lists of all blobbers, all validators and all allocations are split in
+// partitions and the minted tokens are moved apart from the configurations
+func (ssc *StorageSmartContract) migrateStateLayout(
+	t *transaction.Transaction, _ []byte,
+	balances chainstate.StateContextI) (resp string, err error) {
+
+	if t.ClientID != owner {
+		return "", common.NewError("migrate_state_layout",
+			"unauthorized access - only the owner can migrate the state")
+	}
+
+	var conf *scConfig
+	if conf, err = ssc.getConfig(balances, true); err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"can't get config: "+err.Error())
+	}
+
+	if conf.StateLayout >= partitionedStateLayout {
+		return "", common.NewError("migrate_state_layout",
+			"state is already partitioned")
+	}
+
+	var blobbers *StorageNodes
+	if blobbers, err = getAllBlobbers(legacyStateLayout, balances); err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"getting all blobbers list: "+err.Error())
+	}
+	err = saveBlobbersPartitionsOf(partitionedStateLayout, blobbers, balances,
+		blobbersIDs(blobbers.Nodes)...)
+	if err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"saving all blobbers partitions: "+err.Error())
+	}
+	if err = deleteLegacyNode(ALL_BLOBBERS_KEY, balances); err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"deleting all blobbers list: "+err.Error())
+	}
+
+	var validators *ValidatorNodes
+	validators, err = getAllValidators(legacyStateLayout, balances)
+	if err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"getting all validators list: "+err.Error())
+	}
+	err = saveValidatorsPartitionsOf(partitionedStateLayout, validators,
+		balances, validatorsIDs(validators.Nodes)...)
+	if err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"saving all validators partitions: "+err.Error())
+	}
+	if err = deleteLegacyNode(ALL_VALIDATORS_KEY, balances); err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"deleting all validators list: "+err.Error())
+	}
+
+	var allocs *Allocations
+	if allocs, err = getAllAllocations(legacyStateLayout, balances); err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"getting all allocations list: "+err.Error())
+	}
+	err = saveAllocationsPartitionsOf(partitionedStateLayout, allocs, balances,
+		allocs.List...)
+	if err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"saving all allocations partitions: "+err.Error())
+	}
+	if err = deleteLegacyNode(ALL_ALLOCATIONS_KEY, balances); err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"deleting all allocations list: "+err.Error())
+	}
+
+	conf.StateLayout = partitionedStateLayout
+	if err = ssc.saveMinted(conf, balances); err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"saving minted tokens: "+err.Error())
+	}
+	conf.Minted = 0 // kept apart
+	_, err = balances.InsertTrieNode(scConfigKey(ssc.ID), conf)
+	if err != nil {
+		return "", common.NewError("migrate_state_layout",
+			"saving configurations: "+err.Error())
+	}
+
+	return "state migrated to the partitioned layout", nil
+}
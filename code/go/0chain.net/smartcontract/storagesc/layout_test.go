@@ -0,0 +1,149 @@
+package storagesc
+
+import (
+	"testing"
+	"time"
+
+	"0chain.net/core/common"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_listPartitionsOf(t *testing.T) {
+	var ids = []string{"a", "b", "c", "d", "e", "a"}
+	var parts = listPartitionsOf(ids)
+	require.True(t, len(parts) > 0 && len(parts) <= 5)
+	for i, p := range parts {
+		require.True(t, p >= 0 && p < listPartitions)
+		if i > 0 {
+			require.True(t, parts[i-1] < p, "sorted and unique")
+		}
+	}
+	require.Equal(t, []int{listPartition("a")}, listPartitionsOf([]string{"a"}))
+	require.NotEqual(t, allBlobbersPartitionKey(0), allBlobbersPartitionKey(1))
+	require.NotEqual(t, allBlobbersPartitionKey(0), allAllocationsPartitionKey(0))
+}
+
+func TestStorageSmartContract_migrateStateLayout(t *testing.T) {
+	var (
+		ssc      = newTestStorageSC()
+		balances = newTestBalances(t, false)
+		client   = newClient(100*x10, balances)
+		now      = int64(10)
+	)
+	allocID, blobs := addAllocation(t, ssc, client, now,
+		int64(toSeconds(time.Hour)), 0, balances)
+
+	conf, err := ssc.getConfig(balances, false)
+	require.NoError(t, err)
+	conf.Minted = 7 * x10
+	mustSave(t, scConfigKey(ssc.ID), conf, balances)
+
+	blobbers, err := ssc.getBlobbersList(balances)
+	require.NoError(t, err)
+	allocs, err := ssc.getAllAllocationsList(balances)
+	require.NoError(t, err)
+	require.Equal(t, []string{allocID}, []string(allocs.List))
+	for i := 0; i < 3; i++ {
+		addValidator(t, ssc, now, balances)
+	}
+	validators, err := ssc.getValidatorsList(balances)
+	require.NoError(t, err)
+	require.Len(t, validators.Nodes, 3)
+
+	var migrate = func(clientID string) error {
+		var tx = newTransaction(clientID, ADDRESS, 0, now)
+		balances.setTransaction(t, tx)
+		_, err := ssc.Execute(tx, "migrate_state_layout", nil, balances)
+		return err
+	}
+	require.Error(t, migrate(client.id), "not the owner")
+	require.NoError(t, migrate(owner))
+	require.Error(t, migrate(owner), "already migrated")
+
+	// the legacy lists are replaced with the partitions
+	require.NotContains(t, balances.tree, ALL_BLOBBERS_KEY)
+	require.NotContains(t, balances.tree, ALL_ALLOCATIONS_KEY)
+	require.NotContains(t, balances.tree, ALL_VALIDATORS_KEY)
+
+	migrated, err := ssc.getBlobbersList(balances)
+	require.NoError(t, err)
+	require.Equal(t, blobbers.Encode(), migrated.Encode())
+	migratedAllocs, err := ssc.getAllAllocationsList(balances)
+	require.NoError(t, err)
+	require.Equal(t, allocs.Encode(), migratedAllocs.Encode())
+	migratedValidators, err := ssc.getValidatorsList(balances)
+	require.NoError(t, err)
+	require.Equal(t, validators.Encode(), migratedValidators.Encode())
+
+	// the minted tokens are kept apart from the configurations
+	conf, err = ssc.getConfig(balances, false)
+	require.NoError(t, err)
+	require.Equal(t, partitionedStateLayout, conf.StateLayout)
+	require.Zero(t, conf.Minted)
+	require.NoError(t, ssc.loadMinted(conf, balances))
+	require.EqualValues(t, 7*x10, conf.Minted)
+
+	// a health check changes the partition of the blobber only
+	var (
+		blob  = blobs[0]
+		part  = allBlobbersPartitionKey(listPartition(blob.id))
+		parts = make(map[int][]byte)
+	)
+	for i := 0; i < listPartitions; i++ {
+		if val, ok := balances.tree[allBlobbersPartitionKey(i)]; ok {
+			parts[i] = val.Encode()
+		}
+	}
+	var tx = newTransaction(blob.id, ADDRESS, 0, now+5)
+	balances.setTransaction(t, tx)
+	_, err = ssc.blobberHealthCheck(tx, nil, balances)
+	require.NoError(t, err)
+	for i, before := range parts {
+		var key = allBlobbersPartitionKey(i)
+		if key == part {
+			require.NotEqual(t, before, balances.tree[key].Encode())
+			continue
+		}
+		require.Equal(t, before, balances.tree[key].Encode())
+	}
+
+	migrated, err = ssc.getBlobbersList(balances)
+	require.NoError(t, err)
+	b, ok := migrated.Nodes.get(blob.id)
+	require.True(t, ok)
+	require.EqualValues(t, now+5, b.LastHealthCheck)
+
+	// new allocations are added to the partitions
+	var nar = newAllocationRequest{
+		DataShards:                 10,
+		ParityShards:               10,
+		Expiration:                 common.Timestamp(toSeconds(time.Hour)),
+		Owner:                      client.id,
+		OwnerPublicKey:             client.pk,
+		ReadPriceRange:             PriceRange{1 * x10, 10 * x10},
+		WritePriceRange:            PriceRange{2 * x10, 20 * x10},
+		Size:                       2 * GB,
+		MaxChallengeCompletionTime: 200 * time.Hour,
+	}
+	resp, err := nar.callNewAllocReq(t, client.id, 15*x10, ssc, now+5,
+		balances)
+	require.NoError(t, err)
+	var alloc StorageAllocation
+	require.NoError(t, alloc.Decode([]byte(resp)))
+
+	allocs, err = ssc.getAllAllocationsList(balances)
+	require.NoError(t, err)
+	require.Len(t, allocs.List, 2)
+	require.True(t, allocs.has(alloc.ID))
+	require.NotContains(t, balances.tree, ALL_ALLOCATIONS_KEY)
+
+	// new validators are added to the partitions
+	var valid = addValidator(t, ssc, now+5, balances)
+	validators, err = ssc.getValidatorsList(balances)
+	require.NoError(t, err)
+	require.Len(t, validators.Nodes, 4)
+	part = allValidatorsPartitionKey(listPartition(valid.id))
+	require.Contains(t, string(balances.tree[part].Encode()), valid.id)
+	require.NotContains(t, balances.tree, ALL_VALIDATORS_KEY)
+}
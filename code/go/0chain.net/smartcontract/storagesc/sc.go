@@ -289,6 +289,8 @@ func (sc *StorageSmartContract) Execute(t *transaction.Transaction,
 
 	case "update_settings":
 		resp, err = sc.updateSettings(t, input, balances)
+	case "migrate_state_layout":
+		resp, err = sc.migrateStateLayout(t, input, balances)
 
 	default:
 		err = common.NewErrorf("invalid_storage_function_name",
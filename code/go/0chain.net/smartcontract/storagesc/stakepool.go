@@ -926,6 +926,10 @@ func (ssc *StorageSmartContract) stakePoolLock(t *transaction.Transaction,
 		return "", common.NewErrorf("stake_pool_lock_failed",
 			"can't get SC configurations: %v", err)
 	}
+	if err = ssc.loadMinted(conf, balances); err != nil {
+		return "", common.NewErrorf("stake_pool_lock_failed",
+			"can't get minted tokens: %v", err)
+	}
 
 	if t.Value < int64(conf.StakePool.MinLock) {
 		return "", common.NewError("stake_pool_lock_failed",
@@ -962,8 +966,7 @@ func (ssc *StorageSmartContract) stakePoolLock(t *transaction.Transaction,
 	}
 
 	// save configuration (minted tokens)
-	_, err = balances.InsertTrieNode(scConfigKey(ssc.ID), conf)
-	if err != nil {
+	if err = ssc.saveMinted(conf, balances); err != nil {
 		return "", common.NewErrorf("stake_pool_lock_failed",
 			"saving configurations: %v", err)
 	}
@@ -993,6 +996,12 @@ func (ssc *StorageSmartContract) stakePoolLock(t *transaction.Transaction,
 			"saving stake pool: %v", err)
 	}
 
+	balances.EmitEvent(EventStakePoolLock, dp.ID, &stakePoolEvent{
+		BlobberID: spr.BlobberID,
+		PoolID:    dp.ID,
+		ClientID:  t.ClientID,
+		Amount:    state.Balance(t.Value),
+	})
 	return
 }
 
@@ -1016,6 +1025,10 @@ func (ssc *StorageSmartContract) stakePoolUnlock(t *transaction.Transaction,
 		return "", common.NewErrorf("stake_pool_unlock_failed",
 			"can't get SC configurations: %v", err)
 	}
+	if err = ssc.loadMinted(conf, balances); err != nil {
+		return "", common.NewErrorf("stake_pool_unlock_failed",
+			"can't get minted tokens: %v", err)
+	}
 
 	if sp, err = ssc.getStakePool(spr.BlobberID, balances); err != nil {
 		return "", common.NewErrorf("stake_pool_unlock_failed",
@@ -1030,8 +1043,7 @@ func (ssc *StorageSmartContract) stakePoolUnlock(t *transaction.Transaction,
 	conf.Minted += info.minted
 
 	// save configuration (minted tokens)
-	_, err = balances.InsertTrieNode(scConfigKey(ssc.ID), conf)
-	if err != nil {
+	if err = ssc.saveMinted(conf, balances); err != nil {
 		return "", common.NewErrorf("stake_pool_unlock_failed",
 			"saving configuration: %v", err)
 	}
@@ -1059,6 +1071,12 @@ func (ssc *StorageSmartContract) stakePoolUnlock(t *transaction.Transaction,
 			return "", common.NewErrorf("stake_pool_unlock_failed",
 				"saving stake pool: %v", err)
 		}
+		balances.EmitEvent(EventStakePoolUnlock, spr.PoolID, &stakePoolEvent{
+			BlobberID: spr.BlobberID,
+			PoolID:    spr.PoolID,
+			ClientID:  t.ClientID,
+			Unstake:   unstake,
+		})
 		return toJson(&unlockResponse{Unstake: unstake}), nil
 	}
 
@@ -1079,6 +1097,11 @@ func (ssc *StorageSmartContract) stakePoolUnlock(t *transaction.Transaction,
 			"saving stake pool: %v", err)
 	}
 
+	balances.EmitEvent(EventStakePoolUnlock, spr.PoolID, &stakePoolEvent{
+		BlobberID: spr.BlobberID,
+		PoolID:    spr.PoolID,
+		ClientID:  t.ClientID,
+	})
 	return
 }
 
@@ -1096,6 +1119,10 @@ func (ssc *StorageSmartContract) stakePoolPayInterests(
 		return "", common.NewError("stake_pool_take_rewards_failed",
 			"can't get SC configurations: "+err.Error())
 	}
+	if err = ssc.loadMinted(conf, balances); err != nil {
+		return "", common.NewError("stake_pool_take_rewards_failed",
+			"can't get minted tokens: "+err.Error())
+	}
 
 	var spr stakePoolRequest
 	if err = spr.decode(input); err != nil {
@@ -1117,8 +1144,7 @@ func (ssc *StorageSmartContract) stakePoolPayInterests(
 	conf.Minted += info.minted
 
 	// save configuration (minted tokens)
-	_, err = balances.InsertTrieNode(scConfigKey(ssc.ID), conf)
-	if err != nil {
+	if err = ssc.saveMinted(conf, balances); err != nil {
 		return "", common.NewError("stake_pool_take_rewards_failed",
 			"saving configurations: "+err.Error())
 	}
@@ -1153,6 +1179,9 @@ func (ssc *StorageSmartContract) getStakePoolStatHandler(ctx context.Context,
 	if conf, err = ssc.getConfig(balances, false); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetConfigErrMsg)
 	}
+	if err = ssc.loadMinted(conf, balances); err != nil {
+		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetConfigErrMsg)
+	}
 
 	if blobber, err = ssc.getBlobber(blobberID, balances); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetBlobberMsg)
@@ -1184,6 +1213,9 @@ func (ssc *StorageSmartContract) getUserStakePoolStatHandler(ctx context.Context
 	if conf, err = ssc.getConfig(balances, false); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetConfigErrMsg)
 	}
+	if err = ssc.loadMinted(conf, balances); err != nil {
+		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetConfigErrMsg)
+	}
 
 	var (
 		rate   = conf.StakePool.InterestRate
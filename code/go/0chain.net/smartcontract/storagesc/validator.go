@@ -7,11 +7,20 @@ import (
 	c_state "0chain.net/chaincore/chain/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
+	"0chain.net/core/datastore"
 )
 
 func (sc *StorageSmartContract) getValidatorsList(balances c_state.StateContextI) (*ValidatorNodes, error) {
+	layout, err := sc.getStateLayout(balances)
+	if err != nil {
+		return nil, err
+	}
+	return getAllValidators(layout, balances)
+}
+
+func getValidatorsNode(key datastore.Key, balances c_state.StateContextI) (*ValidatorNodes, error) {
 	allValidatorsList := &ValidatorNodes{}
-	allValidatorsBytes, err := balances.GetTrieNode(ALL_VALIDATORS_KEY)
+	allValidatorsBytes, err := balances.GetTrieNode(key)
 	if allValidatorsBytes == nil {
 		return allValidatorsList, nil
 	}
@@ -26,7 +35,11 @@ func (sc *StorageSmartContract) getValidatorsList(balances c_state.StateContextI
 }
 
 func (sc *StorageSmartContract) addValidator(t *transaction.Transaction, input []byte, balances c_state.StateContextI) (string, error) {
-	allValidatorsList, err := sc.getValidatorsList(balances)
+	layout, err := sc.getStateLayout(balances)
+	if err != nil {
+		return "", common.NewError("add_validator_failed", "Failed to get state layout."+err.Error())
+	}
+	allValidatorsList, err := getValidatorsPartitionsOf(layout, balances, t.ClientID)
 	if err != nil {
 		return "", common.NewError("add_validator_failed", "Failed to get validator list."+err.Error())
 	}
@@ -41,7 +54,7 @@ func (sc *StorageSmartContract) addValidator(t *transaction.Transaction, input [
 	if blobberBytes == nil {
 		allValidatorsList.Nodes = append(allValidatorsList.Nodes, newValidator)
 		// allValidatorsBytes, _ := json.Marshal(allValidatorsList)
-		balances.InsertTrieNode(ALL_VALIDATORS_KEY, allValidatorsList)
+		saveValidatorsPartitionsOf(layout, allValidatorsList, balances, newValidator.ID)
 		balances.InsertTrieNode(newValidator.GetKey(sc.ID), newValidator)
 
 		sc.statIncr(statAddValidator)
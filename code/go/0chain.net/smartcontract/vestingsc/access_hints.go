@@ -0,0 +1,49 @@
+package vestingsc
+
+import (
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+)
+
+// GetAccessHints implements sci.AccessHinter. The destinations of a pool
+// are only known from the pool, so triggering and deleting it are unknown.
+func (vsc *VestingSmartContract) GetAccessHints(t *transaction.Transaction,
+	funcName string, input []byte) *sci.AccessHints {
+
+	switch funcName {
+	case "add":
+		return &sci.AccessHints{
+			Reads: []datastore.Key{scConfigKey(vsc.ID)},
+			Writes: []datastore.Key{
+				clientPoolsKey(vsc.ID, t.ClientID),
+				poolKey(vsc.ID, t.Hash),
+			},
+		}
+
+	case "stop":
+		var sr stopRequest
+		if err := sr.decode(input); err != nil {
+			return nil
+		}
+		return &sci.AccessHints{
+			Writes:  []datastore.Key{sr.PoolID},
+			Clients: []datastore.Key{vsc.ID, sr.Destination},
+		}
+
+	case "unlock":
+		var ur poolRequest
+		if err := ur.decode(input); err != nil {
+			return nil
+		}
+		// the owner drains the pool, a destination gets its vested tokens
+		return &sci.AccessHints{
+			Writes:  []datastore.Key{ur.PoolID},
+			Clients: []datastore.Key{vsc.ID},
+		}
+
+	case "vestingsc-update-settings":
+		return &sci.AccessHints{Serial: true}
+	}
+	return nil
+}
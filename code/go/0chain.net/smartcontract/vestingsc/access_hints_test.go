@@ -0,0 +1,165 @@
+package vestingsc
+
+import (
+	"testing"
+	"time"
+
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+// accessRecorder - the test balances recording the trie nodes and the
+// accounts a smart contract function accesses
+type accessRecorder struct {
+	*testBalances
+	reads, writes, clients map[datastore.Key]bool
+}
+
+func newAccessRecorder(tb *testBalances) *accessRecorder {
+	return &accessRecorder{
+		testBalances: tb,
+		reads:        make(map[datastore.Key]bool),
+		writes:       make(map[datastore.Key]bool),
+		clients:      make(map[datastore.Key]bool),
+	}
+}
+
+func (ar *accessRecorder) GetTrieNode(key datastore.Key) (util.Serializable, error) {
+	ar.reads[key] = true
+	return ar.testBalances.GetTrieNode(key)
+}
+
+func (ar *accessRecorder) InsertTrieNode(key datastore.Key, node util.Serializable) (datastore.Key, error) {
+	ar.writes[key] = true
+	return ar.testBalances.InsertTrieNode(key, node)
+}
+
+func (ar *accessRecorder) DeleteTrieNode(key datastore.Key) (datastore.Key, error) {
+	ar.writes[key] = true
+	return ar.testBalances.DeleteTrieNode(key)
+}
+
+func (ar *accessRecorder) GetClientBalance(clientID datastore.Key) (state.Balance, error) {
+	ar.clients[clientID] = true
+	return ar.testBalances.GetClientBalance(clientID)
+}
+
+func (ar *accessRecorder) AddTransfer(t *state.Transfer) error {
+	ar.clients[t.ClientID], ar.clients[t.ToClientID] = true, true
+	return ar.testBalances.AddTransfer(t)
+}
+
+func (ar *accessRecorder) AddMint(m *state.Mint) error {
+	ar.clients[m.ToClientID] = true
+	return ar.testBalances.AddMint(m)
+}
+
+// requireCovered - the hints declare everything the function accessed
+func (ar *accessRecorder) requireCovered(t *testing.T,
+	txn *transaction.Transaction, hints *sci.AccessHints) {
+
+	t.Helper()
+	var declared = func(lists ...[]datastore.Key) map[datastore.Key]bool {
+		set := make(map[datastore.Key]bool)
+		for _, list := range lists {
+			for _, k := range list {
+				set[k] = true
+			}
+		}
+		return set
+	}
+	var (
+		reads   = declared(hints.Reads, hints.Writes)
+		writes  = declared(hints.Writes)
+		clients = declared(hints.Clients, []datastore.Key{txn.ClientID})
+	)
+	if txn.Value > 0 {
+		clients[txn.ToClientID] = true
+	}
+	for k := range ar.reads {
+		require.True(t, reads[k], "undeclared read: %s", k)
+	}
+	for k := range ar.writes {
+		require.True(t, writes[k], "undeclared write: %s", k)
+	}
+	for k := range ar.clients {
+		require.True(t, clients[k], "undeclared client: %s", k)
+	}
+}
+
+func TestVestingSmartContract_GetAccessHints(t *testing.T) {
+	var (
+		vsc      = newTestVestingSC()
+		balances = newTestBalances()
+		client   = newClient(1200e10, balances)
+		tp       = common.Timestamp(0)
+		addTx    = newTransaction(client.id, vsc.ID, 800e10, tp)
+	)
+	configureConfig()
+
+	var ar = addRequest{
+		Description: "for something",
+		StartTime:   10,
+		Duration:    2 * time.Second,
+		Destinations: destinations{
+			&destination{ID: "one", Amount: 10},
+			&destination{ID: "two", Amount: 20},
+		},
+	}
+	var poolID = poolKey(vsc.ID, addTx.Hash)
+	tests := []struct {
+		name     string
+		funcName string
+		txn      *transaction.Transaction
+		input    []byte
+	}{
+		{
+			name:     "add",
+			funcName: "add",
+			txn:      addTx,
+			input:    mustEncode(t, &ar),
+		},
+		{
+			name:     "stop",
+			funcName: "stop",
+			txn:      newTransaction(client.id, vsc.ID, 0, tp+11),
+			input:    mustEncode(t, &stopRequest{PoolID: poolID, Destination: "one"}),
+		},
+		{
+			name:     "unlock",
+			funcName: "unlock",
+			txn:      newTransaction(client.id, vsc.ID, 0, tp+11),
+			input:    mustEncode(t, &poolRequest{PoolID: poolID}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hints := vsc.GetAccessHints(tt.txn, tt.funcName, tt.input)
+			require.NotNil(t, hints)
+			require.False(t, hints.Serial)
+
+			balances.txn = tt.txn
+			rec := newAccessRecorder(balances)
+			_, err := vsc.Execute(tt.txn, tt.funcName, tt.input, rec)
+			require.NoError(t, err)
+			rec.requireCovered(t, tt.txn, hints)
+		})
+	}
+
+	t.Run("serial", func(t *testing.T) {
+		hints := vsc.GetAccessHints(addTx, "vestingsc-update-settings", nil)
+		require.NotNil(t, hints)
+		require.True(t, hints.Serial)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		require.Nil(t, vsc.GetAccessHints(addTx, "trigger", nil))
+		require.Nil(t, vsc.GetAccessHints(addTx, "unlock", []byte("}{")))
+	})
+}
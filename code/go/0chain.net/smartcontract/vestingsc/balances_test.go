@@ -43,8 +43,8 @@ func (tb *testBalances) SetStateContext(*state.State) error           { return n
 func (tb *testBalances) AddMint(*state.Mint) error                    { return nil }
 func (tb *testBalances) GetTransfers() []*state.Transfer              { return nil }
 func (tb *testBalances) GetChainCurrentMagicBlock() *block.MagicBlock { return nil }
-func (tb *testBalances) AddSignedTransfer(st *state.SignedTransfer) {
-
+func (tb *testBalances) AddSignedTransfer(st *state.SignedTransfer) error {
+	return nil
 }
 func (tb *testBalances) SetMagicBlock(block *block.MagicBlock) {}
 func (tb *testBalances) GetLastestFinalizedMagicBlock() *block.Block {
@@ -57,6 +57,9 @@ func (tb *testBalances) GetSignatureScheme() encryption.SignatureScheme {
 func (tb *testBalances) GetSignedTransfers() []*state.SignedTransfer {
 	return nil
 }
+func (tb *testBalances) AddLog(*transaction.Log)               {}
+func (tb *testBalances) GetLogs() []*transaction.Log           { return nil }
+func (tb *testBalances) EmitEvent(string, string, interface{}) {}
 func (tb *testBalances) DeleteTrieNode(key datastore.Key) (
 	datastore.Key, error) {
 
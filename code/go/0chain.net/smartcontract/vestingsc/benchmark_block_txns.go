@@ -0,0 +1,29 @@
+package vestingsc
+
+import (
+	"github.com/spf13/viper"
+
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	bk "0chain.net/smartcontract/benchmark"
+)
+
+// BenchmarkBlockTxns - the vesting transactions of the mixed blocks of the
+// parallel execution benchmark. A client triggers its own vesting pool.
+func BenchmarkBlockTxns() []bk.BlockTxn {
+	return []bk.BlockTxn{
+		{
+			Name: "vesting.trigger",
+			Build: func(data bk.BenchData, client int) *transaction.Transaction {
+				return bk.SmartContractTransaction(
+					data.Clients[client],
+					ADDRESS,
+					"trigger",
+					0,
+					common.Timestamp(viper.GetInt64(bk.Now)),
+					&poolRequest{PoolID: geMockVestingPoolId(client)},
+				)
+			},
+		},
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"strconv"
 
 	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/state"
 	"0chain.net/core/common"
 	"0chain.net/core/encryption"
+	"0chain.net/core/util"
 	"0chain.net/core/viper"
 	"0chain.net/smartcontract/benchmark"
 )
@@ -13,6 +15,15 @@ import (
 const mockVpBalance = 100e10
 const mockDestinationBalance = 1e10
 
+// FundMockVestingSmartContract - the vesting SC account holds the tokens
+// of all the mock vesting pools
+func FundMockVestingSmartContract(pMpt *util.MerklePatriciaTrie) {
+	is := &state.State{}
+	_ = is.SetTxnHash("0000000000000000000000000000000000000000000000000000000000000000")
+	is.Balance = state.Balance(mockVpBalance * viper.GetInt64(benchmark.NumClients))
+	_, _ = pMpt.Insert(util.Path(ADDRESS), is)
+}
+
 func AddVestingPools(
 	clients []string,
 	balances cstate.StateContextI,
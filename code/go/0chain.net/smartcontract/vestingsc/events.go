@@ -0,0 +1,33 @@
+package vestingsc
+
+import (
+	"0chain.net/chaincore/state"
+	"0chain.net/core/datastore"
+)
+
+// the types of the events emitted by the vesting smart contract, the tag of
+// an event is the ID of the vesting pool
+const (
+	EventAddPool     = "add_vesting_pool"
+	EventStopVesting = "stop_vesting"
+	EventDeletePool  = "delete_vesting_pool"
+	EventUnlockPool  = "unlock_vesting_pool"
+	EventTriggerPool = "trigger_vesting_pool"
+)
+
+// poolEvent - the payload of the vesting pool events
+type poolEvent struct {
+	PoolID      datastore.Key `json:"pool_id"`
+	ClientID    datastore.Key `json:"client_id"`
+	Balance     state.Balance `json:"balance"`
+	Destination datastore.Key `json:"destination,omitempty"`
+}
+
+func newPoolEvent(vp *vestingPool, clientID, destination datastore.Key) *poolEvent {
+	return &poolEvent{
+		PoolID:      vp.ID,
+		ClientID:    clientID,
+		Balance:     vp.Balance,
+		Destination: destination,
+	}
+}
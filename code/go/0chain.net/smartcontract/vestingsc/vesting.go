@@ -607,6 +607,7 @@ func (vsc *VestingSmartContract) add(t *transaction.Transaction,
 			"can't save pool: "+err.Error())
 	}
 
+	balances.EmitEvent(EventAddPool, vp.ID, newPoolEvent(vp, t.ClientID, ""))
 	return string(vp.Encode()), nil
 }
 
@@ -654,6 +655,8 @@ func (vsc *VestingSmartContract) stop(t *transaction.Transaction,
 			"saving pool: "+err.Error())
 	}
 
+	balances.EmitEvent(EventStopVesting, vp.ID,
+		newPoolEvent(vp, t.ClientID, sr.Destination))
 	return sr.Destination + " has deleted from the vesting pool", nil
 }
 
@@ -733,6 +736,7 @@ func (vsc *VestingSmartContract) delete(t *transaction.Transaction,
 			"can't delete vesting pool: "+err.Error())
 	}
 
+	balances.EmitEvent(EventDeletePool, vp.ID, newPoolEvent(vp, t.ClientID, ""))
 	return `{"pool_id":"` + vp.ID + `","action":"deleted"}`, nil
 }
 
@@ -777,6 +781,7 @@ func (vsc *VestingSmartContract) unlock(t *transaction.Transaction,
 			"saving pool: "+err.Error())
 	}
 
+	balances.EmitEvent(EventUnlockPool, vp.ID, newPoolEvent(vp, t.ClientID, ""))
 	return
 }
 
@@ -825,6 +830,7 @@ func (vsc *VestingSmartContract) trigger(t *transaction.Transaction,
 			"saving pool: "+err.Error())
 	}
 
+	balances.EmitEvent(EventTriggerPool, vp.ID, newPoolEvent(vp, t.ClientID, ""))
 	return //
 }
 
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS zerochain.client_txn (
+client_id text,
+round bigint,
+txn_hash text,
+direction text,
+type int,
+value bigint,
+status int,
+PRIMARY KEY (client_id, round, txn_hash, direction)
+) WITH CLUSTERING ORDER BY (round DESC, txn_hash DESC, direction DESC);
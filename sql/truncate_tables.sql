@@ -1 +1,3 @@
 truncate zerochain.txn_summary;
+truncate zerochain.txn_event;
+truncate zerochain.client_txn;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS zerochain.txn_event (
+round bigint,
+idx int,
+block_hash text,
+txn_hash text,
+contract text,
+client_id text,
+type text,
+tag text,
+data text,
+PRIMARY KEY (round, idx)
+);